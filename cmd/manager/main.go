@@ -43,12 +43,21 @@ var log = logf.Log.WithName("cmd")
 var urlOnlyKubeconfig string
 var showVersion bool
 var showDigest bool
+var printImages bool
+var printImagesFormat string
+var imageRegistry string
 
 func init() {
 	flag.StringVar(&urlOnlyKubeconfig, "url-only-kubeconfig", "",
 		"Path to a kubeconfig, but only for the apiserver url.")
 	flag.BoolVar(&showVersion, "version", false,
 		"Show version information")
+	flag.BoolVar(&printImages, "print-images", false,
+		"Print the images of all components and exit")
+	flag.StringVar(&printImagesFormat, "print-images-format", string(components.ImageFormatPlain),
+		"Format of the --print-images output, one of plain, json or skopeo")
+	flag.StringVar(&imageRegistry, "registry", "",
+		"Registry to use for the images printed by --print-images instead of the default registries")
 }
 
 func printVersion() {
@@ -71,28 +80,18 @@ func main() {
 
 	if showVersion {
 		fmt.Println("Operator:", version.VERSION)
-		fmt.Println(components.GetReference(components.ComponentCalicoNode, ""))
-		fmt.Println(components.GetReference(components.ComponentCalicoCNI, ""))
-		fmt.Println(components.GetReference(components.ComponentCalicoTypha, ""))
-		fmt.Println(components.GetReference(components.ComponentCalicoKubeControllers, ""))
-		fmt.Println(components.GetReference(components.ComponentFlexVolume, ""))
-		fmt.Println(components.GetReference(components.ComponentTigeraNode, ""))
-		fmt.Println(components.GetReference(components.ComponentTigeraTypha, ""))
-		fmt.Println(components.GetReference(components.ComponentTigeraKubeControllers, ""))
-		fmt.Println(components.GetReference(components.ComponentAPIServer, ""))
-		fmt.Println(components.GetReference(components.ComponentQueryServer, ""))
-		fmt.Println(components.GetReference(components.ComponentComplianceController, ""))
-		fmt.Println(components.GetReference(components.ComponentComplianceReporter, ""))
-		fmt.Println(components.GetReference(components.ComponentComplianceServer, ""))
-		fmt.Println(components.GetReference(components.ComponentComplianceSnapshotter, ""))
-		fmt.Println(components.GetReference(components.ComponentComplianceBenchmarker, ""))
-		fmt.Println(components.GetReference(components.ComponentIntrusionDetectionController, ""))
-		fmt.Println(components.GetReference(components.ComponentElasticTseeInstaller, ""))
-		fmt.Println(components.GetReference(components.ComponentManager, ""))
-		fmt.Println(components.GetReference(components.ComponentManagerProxy, ""))
-		fmt.Println(components.GetReference(components.ComponentGuardian, ""))
-		fmt.Println(components.GetReference(components.ComponentFluentd, ""))
-		fmt.Println(components.GetReference(components.ComponentEsCurator, ""))
+		if err := components.PrintImages(os.Stdout, components.ImageFormatPlain, ""); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	if printImages {
+		if err := components.PrintImages(os.Stdout, components.ImageFormat(printImagesFormat), imageRegistry); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
 		os.Exit(0)
 	}
 
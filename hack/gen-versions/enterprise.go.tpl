@@ -85,22 +85,22 @@ var (
 		Image:   "{{ .Image }}",
 	}
 	{{ end }}
-	{{ with index . "es-curator" }}
-	ComponentEsCurator = component{
+	{{ with index . "es-proxy" }}
+	ComponentEsProxy = component{
 		Version: "{{ .Version }}",
 		Digest:  "{{ .Digest }}",
 		Image:   "{{ .Image }}",
 	}
 	{{ end }}
-	{{ with index . "es-proxy" }}
-	ComponentEsProxy = component{
+	{{ with .fluentd }}
+	ComponentFluentd = component{
 		Version: "{{ .Version }}",
 		Digest:  "{{ .Digest }}",
 		Image:   "{{ .Image }}",
 	}
 	{{ end }}
-	{{ with .fluentd }}
-	ComponentFluentd = component{
+	{{ with index . "fluent-bit" }}
+	ComponentFluentBit = component{
 		Version: "{{ .Version }}",
 		Digest:  "{{ .Digest }}",
 		Image:   "{{ .Image }}",
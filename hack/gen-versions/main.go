@@ -39,6 +39,7 @@ var (
 	eeVersionsPath string
 	osVersionsPath string
 	gcrBearer      string
+	overridesPath  string
 )
 
 func main() {
@@ -48,6 +49,7 @@ func main() {
 	flag.StringVar(&eeVersionsPath, "ee-versions", "", "path to calico versions file")
 	flag.StringVar(&osVersionsPath, "os-versions", "", "path to enterprise versions file")
 	flag.StringVar(&gcrBearer, "gcr-bearer", "", "output of 'gcloud auth print-access-token")
+	flag.StringVar(&overridesPath, "overrides", "", "path to an Installation manifest whose image overrides are validated against the registry")
 	flag.Parse()
 
 	if debug {
@@ -94,6 +96,12 @@ func run(versionsPath, tpl, defaultRegistry string) error {
 		if err := updateDigests(vz, defaultRegistry); err != nil {
 			return fmt.Errorf("failed to get digest for components: %v", err)
 		}
+
+		if overridesPath != "" {
+			if err := validateOverrides(vz, overridesPath, defaultRegistry); err != nil {
+				return fmt.Errorf("invalid image overrides: %v", err)
+			}
+		}
 	}
 
 	return render(tpl, vz)
@@ -0,0 +1,107 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/tigera/operator/pkg/components"
+)
+
+// imageSettings holds the image fields of an Installation spec.
+type imageSettings struct {
+	Registry       string          `yaml:"registry"`
+	ImagePath      string          `yaml:"imagePath"`
+	ImagePrefix    string          `yaml:"imagePrefix"`
+	ImageOverrides []imageOverride `yaml:"imageOverrides"`
+}
+
+type imageOverride struct {
+	Component string `yaml:"component"`
+	Image     string `yaml:"image"`
+	Version   string `yaml:"version"`
+	Digest    string `yaml:"digest"`
+}
+
+// readImageSettings reads the image settings from an Installation manifest.
+func readImageSettings(installationPath string) (*imageSettings, error) {
+	f, err := ioutil.ReadFile(installationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	i := struct {
+		Spec imageSettings
+	}{}
+	if err := yaml.Unmarshal(f, &i); err != nil {
+		return nil, err
+	}
+
+	return &i.Spec, nil
+}
+
+// validateOverrides checks that each image override of the Installation for one of the given
+// Components refers to an image that exists and, if the override sets a digest, that the digest
+// matches the one in the registry. Overrides for components not in cs are ignored.
+func validateOverrides(cs Components, installationPath, defaultReg string) error {
+	settings, err := readImageSettings(installationPath)
+	if err != nil {
+		return fmt.Errorf("failed to read image overrides: %v", err)
+	}
+
+	registry := strings.TrimSuffix(settings.Registry, "/")
+	if registry == "" {
+		registry = defaultReg
+	}
+
+	for _, o := range settings.ImageOverrides {
+		var component *Component
+		for _, c := range cs {
+			if c.Image == o.Component {
+				component = c
+				break
+			}
+		}
+		if component == nil {
+			continue
+		}
+
+		image := o.Image
+		if image == "" {
+			image = components.ApplyImagePath(component.Image, settings.ImagePath, settings.ImagePrefix)
+		}
+		version := o.Version
+		if version == "" {
+			version = component.Version
+		}
+
+		digest, err := getDigest(registry, image, version)
+		if err != nil {
+			return fmt.Errorf("failed to get digest for override of '%s' ('%s/%s:%s'): %v", o.Component, registry, image, version, err)
+		}
+		if o.Digest != "" && o.Digest != digest {
+			return fmt.Errorf("digest %s in override of '%s' does not match '%s/%s:%s' (%s)", o.Digest, o.Component, registry, image, version, digest)
+		}
+
+		log.Printf("validated override of %s: %s/%s:%s@%s", o.Component, registry, image, version, digest)
+	}
+
+	return nil
+}
@@ -15,6 +15,8 @@
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -33,6 +35,147 @@ type LogCollectorSpec struct {
 	// Configuration for importing audit logs from managed kubernetes cluster log sources.
 	// +optional
 	AdditionalSources *AdditionalLogSourceSpec `json:"additionalSources,omitempty"`
+
+	// Configuration for buffering logs before they are sent to Elasticsearch and the additional stores. Managed
+	// clusters always buffer logs in files, with the default settings if this is not set, so that logs are kept while
+	// the tunnel to the management cluster is down.
+	// +optional
+	Buffer *FluentdBufferSpec `json:"buffer,omitempty"`
+
+	// ResourceRequirements defines the CPU and memory limits and requests of each fluentd container.
+	// +optional
+	ResourceRequirements *corev1.ResourceRequirements `json:"resourceRequirements,omitempty"`
+
+	// Collector selects the log collector that runs on each node. Fluent Bit uses less memory and CPU than fluentd,
+	// but only collects flow and DNS logs and only sends them to Elasticsearch, S3 and syslog, without filters. In a
+	// managed cluster, Fluent Bit also requires the operator.tigera.io/log-collector: FluentBit annotation on the
+	// ManagedCluster resource in the management cluster, which then creates the indices that Fluent Bit writes to.
+	// Default: Fluentd
+	// +optional
+	Collector LogCollectorType `json:"collector,omitempty"`
+
+	// FlowLogs configures how flow logs are generated on each node. The settings that are specified are applied to
+	// the default FelixConfiguration and the others are left as they are, unless they were applied before, in which
+	// case they are reset to the Felix defaults. The destination is also set in the env of calico-node, so it takes
+	// precedence over the FelixConfiguration.
+	// +optional
+	FlowLogs *FlowLogsSpec `json:"flowLogs,omitempty"`
+
+	// ComponentLogs configures the collection of the logs of the components that are not part of Calico.
+	// +optional
+	ComponentLogs *ComponentLogsSpec `json:"componentLogs,omitempty"`
+}
+
+// LogCollectorType is the log collector that runs on each node.
+// +kubebuilder:validation:Enum=Fluentd;FluentBit
+type LogCollectorType string
+
+const (
+	LogCollectorTypeFluentd   LogCollectorType = "Fluentd"
+	LogCollectorTypeFluentBit LogCollectorType = "FluentBit"
+)
+
+// FlowLogAggregation is how flow logs are aggregated. Flows are aggregated when they only differ in the source port,
+// with SourcePort, or also in the names of the pods of the same ReplicaSet, DaemonSet or other owner, with PodPrefix.
+// +kubebuilder:validation:Enum=None;SourcePort;PodPrefix
+type FlowLogAggregation string
+
+const (
+	FlowLogAggregationNone       FlowLogAggregation = "None"
+	FlowLogAggregationSourcePort FlowLogAggregation = "SourcePort"
+	FlowLogAggregationPodPrefix  FlowLogAggregation = "PodPrefix"
+)
+
+// FlowLogsDestination is where flow logs are written.
+// +kubebuilder:validation:Enum=File;None
+type FlowLogsDestination string
+
+const (
+	// FlowLogsDestinationFile writes flow logs to files on each node, which are collected into Elasticsearch.
+	FlowLogsDestinationFile FlowLogsDestination = "File"
+	// FlowLogsDestinationNone disables flow logs.
+	FlowLogsDestinationNone FlowLogsDestination = "None"
+)
+
+// FlowLogsSpec defines how flow logs are generated on each node.
+type FlowLogsSpec struct {
+	// AggregationForAllowed is how flow logs of allowed flows are aggregated.
+	// Default: PodPrefix
+	// +optional
+	AggregationForAllowed FlowLogAggregation `json:"aggregationForAllowed,omitempty"`
+
+	// AggregationForDenied is how flow logs of denied flows are aggregated.
+	// Default: SourcePort
+	// +optional
+	AggregationForDenied FlowLogAggregation `json:"aggregationForDenied,omitempty"`
+
+	// FlushInterval is the interval in seconds at which flow logs are written.
+	// Default: 300
+	// +optional
+	FlushInterval int32 `json:"flushInterval,omitempty"`
+
+	// PerNodeLimit is the maximum number of flow logs written by each node in each flush interval. Flow logs over
+	// the limit are dropped, 0 means no limit.
+	// Default: 0
+	// +optional
+	PerNodeLimit *int32 `json:"perNodeLimit,omitempty"`
+
+	// Destination is where flow logs are written.
+	// Default: File
+	// +optional
+	Destination FlowLogsDestination `json:"destination,omitempty"`
+}
+
+// ComponentLogsSpec defines which logs of the components that are not part of Calico are collected.
+type ComponentLogsSpec struct {
+	// Containers enables the collection of the logs of the containers in the namespaces managed by the operator, such
+	// as the operator itself, the Tigera API server, compliance and the manager. They are written to the
+	// tigera_secure_ee_component_logs indices, whose retention is set by the ComponentLogs retention of the LogStorage.
+	// +optional
+	Containers bool `json:"containers,omitempty"`
+
+	// KubeAPIServerAuditLogPath is the path of the kube-apiserver audit log on the control plane nodes. When it is set,
+	// the audit log is collected into the Kubernetes audit log indices, whose retention is set by the AuditReports
+	// retention of the LogStorage.
+	// +optional
+	KubeAPIServerAuditLogPath string `json:"kubeAPIServerAuditLogPath,omitempty"`
+}
+
+// FluentdBufferSpec defines how fluentd buffers logs. When it is set, logs are buffered in files on the host, so
+// that they survive restarts of the fluentd pods and do not grow their memory use.
+type FluentdBufferSpec struct {
+	// HostPath is the directory on each node where the buffers are kept.
+	// Default: /var/lib/calico/fluentd-buffers
+	// +optional
+	HostPath string `json:"hostPath,omitempty"`
+
+	// TotalLimitSize is the maximum size of the buffer of each log store. Logs that arrive while a buffer is full are
+	// dropped and the overflow is reported in the status.
+	// Default: 512Mi
+	// +optional
+	TotalLimitSize *resource.Quantity `json:"totalLimitSize,omitempty"`
+
+	// FlushInterval is the interval in seconds at which buffered logs are sent to Elasticsearch, and to the
+	// additional stores that do not set their own.
+	// Default: 5
+	// +optional
+	FlushInterval int32 `json:"flushInterval,omitempty"`
+
+	// RetryWait is the time in seconds before the first retry of a failed flush. The wait doubles with each retry.
+	// Default: 1
+	// +optional
+	RetryWait int32 `json:"retryWait,omitempty"`
+
+	// RetryMaxInterval is the maximum time in seconds between retries of a failed flush.
+	// Default: 60
+	// +optional
+	RetryMaxInterval int32 `json:"retryMaxInterval,omitempty"`
+
+	// RetryTimeout is the time in seconds after which a flush that keeps failing is given up and its logs are
+	// dropped.
+	// Default: 259200
+	// +optional
+	RetryTimeout int32 `json:"retryTimeout,omitempty"`
 }
 
 type AdditionalLogStoreSpec struct {
@@ -42,13 +185,46 @@ type AdditionalLogStoreSpec struct {
 	// If specified, enables exporting of flow, audit, and DNS logs to syslog.
 	// +optional
 	Syslog *SyslogStoreSpec `json:"syslog,omitempty"`
+	// If specified, enables exporting of flow, audit, and DNS logs to Splunk.
+	// +optional
+	Splunk *SplunkStoreSpec `json:"splunk,omitempty"`
+	// If specified, enables exporting of flow, audit, and DNS logs to a Kafka topic.
+	// +optional
+	Kafka *KafkaStoreSpec `json:"kafka,omitempty"`
+	// If specified, enables exporting of flow, audit, and DNS logs to an HTTP endpoint.
+	// +optional
+	HTTP *HTTPStoreSpec `json:"http,omitempty"`
 }
 
+// StoreLogType is a type of log that can be sent to an additional log store.
+// +kubebuilder:validation:Enum=Flows;DNS;EEAudit;KubeAudit;IDSEvents;BGP;L7
+type StoreLogType string
+
+const (
+	StoreLogTypeFlows     StoreLogType = "Flows"
+	StoreLogTypeDNS       StoreLogType = "DNS"
+	StoreLogTypeEEAudit   StoreLogType = "EEAudit"
+	StoreLogTypeKubeAudit StoreLogType = "KubeAudit"
+	StoreLogTypeIDSEvents StoreLogType = "IDSEvents"
+	StoreLogTypeBGP       StoreLogType = "BGP"
+	StoreLogTypeL7        StoreLogType = "L7"
+)
+
 type AdditionalLogSourceSpec struct {
 	// If specified with EKS Provider in Installation, enables fetching EKS
 	// audit logs.
 	// +optional
 	EksCloudwatchLog *EksCloudwatchLogsSpec `json:"eksCloudwatchLog,omitempty"`
+
+	// If specified with AKS Provider in Installation, enables fetching AKS
+	// audit logs from Azure Monitor.
+	// +optional
+	AzureMonitorLog *AzureMonitorLogsSpec `json:"azureMonitorLog,omitempty"`
+
+	// If specified with GKE Provider in Installation, enables fetching GKE
+	// audit logs from Cloud Logging.
+	// +optional
+	GcpLoggingLog *GcpLoggingLogsSpec `json:"gcpLoggingLog,omitempty"`
 }
 
 // S3StoreSpec defines configuration for exporting logs to Amazon S3.
@@ -62,18 +238,181 @@ type S3StoreSpec struct {
 
 	// Path in the S3 bucket where to send logs
 	BucketPath string `json:"bucketPath"`
+
+	// LogTypes are the types of logs that are sent to this store.
+	// Default: Flows, DNS, EEAudit, KubeAudit
+	// +optional
+	LogTypes []StoreLogType `json:"logTypes,omitempty"`
+
+	// Filter is fluentd configuration, one or more <filter> directives, that is applied to logs before they are
+	// sent to this store.
+	// +optional
+	Filter string `json:"filter,omitempty"`
+
+	// FlushInterval is the interval in seconds at which buffered logs are sent to this store.
+	// Default: the flush interval of the buffer configuration, or 5
+	// +optional
+	FlushInterval int32 `json:"flushInterval,omitempty"`
 }
 
+// SyslogFormat is the format of the messages sent to syslog.
+// +kubebuilder:validation:Enum=RFC5424;RFC3164
+type SyslogFormat string
+
+const (
+	SyslogFormatRFC5424 SyslogFormat = "RFC5424"
+	SyslogFormatRFC3164 SyslogFormat = "RFC3164"
+)
+
 // SyslogStoreSpec defines configuration for exporting lgos to syslog.
 type SyslogStoreSpec struct {
-	// Location of the syslog server. example: tcp://1.2.3.4:601
+	// Location of the syslog server. The protocol is one of tcp, udp or tls. A tls server is verified with the CA
+	// bundle under the key ca.crt of the log-collector-syslog-credentials Secret in the tigera-operator namespace,
+	// or the system CAs if there is none. A client certificate and key for mutual TLS are read from the keys tls.crt
+	// and tls.key of the same Secret, if present. example: tls://1.2.3.4:6514
 	Endpoint string `json:"endpoint"`
 
+	// Format is the format of the messages sent to syslog.
+	// Default: RFC3164
+	// +optional
+	Format SyslogFormat `json:"format,omitempty"`
+
 	// PacketSize defines the maximum size of packets to send to syslog.
 	// In general this is only needed if you notice long logs being truncated.
 	// Default: 1024
 	// +optional
 	PacketSize *int32 `json:"packetsize,omitempty"`
+
+	// LogTypes are the types of logs that are sent to this store.
+	// Default: Flows, EEAudit, KubeAudit
+	// +optional
+	LogTypes []StoreLogType `json:"logTypes,omitempty"`
+
+	// Filter is fluentd configuration, one or more <filter> directives, that is applied to logs before they are
+	// sent to this store. example: a grep filter that excludes records whose action is not deny sends only denied
+	// flows.
+	// +optional
+	Filter string `json:"filter,omitempty"`
+
+	// FlushInterval is the interval in seconds at which buffered logs are sent to this store.
+	// Default: the flush interval of the buffer configuration, or 5
+	// +optional
+	FlushInterval int32 `json:"flushInterval,omitempty"`
+}
+
+// SplunkStoreSpec defines configuration for exporting logs to the Splunk HTTP Event Collector. The HEC token is
+// read from the key token of the log-collector-splunk-credentials Secret in the tigera-operator namespace.
+type SplunkStoreSpec struct {
+	// Location of the Splunk HTTP Event Collector. example: https://1.2.3.4:8088
+	Endpoint string `json:"endpoint"`
+
+	// Index is the Splunk index that logs are sent to.
+	// Default: the default index of the HEC token
+	// +optional
+	Index string `json:"index,omitempty"`
+
+	// LogTypes are the types of logs that are sent to this store.
+	// Default: Flows, DNS, EEAudit, KubeAudit
+	// +optional
+	LogTypes []StoreLogType `json:"logTypes,omitempty"`
+
+	// Filter is fluentd configuration, one or more <filter> directives, that is applied to logs before they are
+	// sent to this store.
+	// +optional
+	Filter string `json:"filter,omitempty"`
+
+	// FlushInterval is the interval in seconds at which buffered logs are sent to this store.
+	// Default: the flush interval of the buffer configuration, or 5
+	// +optional
+	FlushInterval int32 `json:"flushInterval,omitempty"`
+}
+
+// KafkaSASLMechanism is a SASL mechanism used to authenticate with Kafka brokers.
+// +kubebuilder:validation:Enum=PLAIN;SCRAM-SHA-256;SCRAM-SHA-512
+type KafkaSASLMechanism string
+
+const (
+	KafkaSASLMechanismPlain       KafkaSASLMechanism = "PLAIN"
+	KafkaSASLMechanismScramSHA256 KafkaSASLMechanism = "SCRAM-SHA-256"
+	KafkaSASLMechanismScramSHA512 KafkaSASLMechanism = "SCRAM-SHA-512"
+)
+
+// KafkaStoreSpec defines configuration for exporting logs to a Kafka topic. Credentials are read from the
+// log-collector-kafka-credentials Secret in the tigera-operator namespace.
+type KafkaStoreSpec struct {
+	// Brokers are the addresses of the Kafka brokers. example: kafka-0.kafka:9092
+	Brokers []string `json:"brokers"`
+
+	// Topic is the Kafka topic that logs are written to.
+	Topic string `json:"topic"`
+
+	// TLS enables TLS connections to the brokers. The brokers are verified with the CA bundle under the key ca.crt of
+	// the credentials Secret, if it has one. A client certificate and key for mutual TLS are read from the keys
+	// tls.crt and tls.key, if present.
+	// +optional
+	TLS bool `json:"tls,omitempty"`
+
+	// SASLMechanism enables SASL authentication with the username and password under the keys username and
+	// password of the credentials Secret.
+	// +optional
+	SASLMechanism KafkaSASLMechanism `json:"saslMechanism,omitempty"`
+
+	// LogTypes are the types of logs that are sent to this store.
+	// Default: Flows, DNS, EEAudit, KubeAudit
+	// +optional
+	LogTypes []StoreLogType `json:"logTypes,omitempty"`
+
+	// Filter is fluentd configuration, one or more <filter> directives, that is applied to logs before they are
+	// sent to this store.
+	// +optional
+	Filter string `json:"filter,omitempty"`
+
+	// FlushInterval is the interval in seconds at which buffered logs are sent to this store.
+	// Default: the flush interval of the buffer configuration, or 5
+	// +optional
+	FlushInterval int32 `json:"flushInterval,omitempty"`
+}
+
+// HTTPStoreAuthType is the authentication used with an HTTP log store.
+// +kubebuilder:validation:Enum=None;Basic;Bearer
+type HTTPStoreAuthType string
+
+const (
+	HTTPStoreAuthTypeNone   HTTPStoreAuthType = "None"
+	HTTPStoreAuthTypeBasic  HTTPStoreAuthType = "Basic"
+	HTTPStoreAuthTypeBearer HTTPStoreAuthType = "Bearer"
+)
+
+// HTTPStoreSpec defines configuration for exporting logs to an HTTP endpoint. Logs are posted as JSON. Credentials
+// are read from the log-collector-http-credentials Secret in the tigera-operator namespace.
+type HTTPStoreSpec struct {
+	// Endpoint is the URL that logs are posted to. example: https://logs.example.com/ingest
+	Endpoint string `json:"endpoint"`
+
+	// Headers are added to each request.
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Auth is the authentication used with the endpoint. Basic uses the keys username and password of the
+	// credentials Secret and Bearer uses the key token.
+	// Default: None
+	// +optional
+	Auth HTTPStoreAuthType `json:"auth,omitempty"`
+
+	// LogTypes are the types of logs that are sent to this store.
+	// Default: Flows, DNS, EEAudit, KubeAudit
+	// +optional
+	LogTypes []StoreLogType `json:"logTypes,omitempty"`
+
+	// Filter is fluentd configuration, one or more <filter> directives, that is applied to logs before they are
+	// sent to this store.
+	// +optional
+	Filter string `json:"filter,omitempty"`
+
+	// FlushInterval is the interval in seconds at which buffered logs are sent to this store.
+	// Default: the flush interval of the buffer configuration, or 5
+	// +optional
+	FlushInterval int32 `json:"flushInterval,omitempty"`
 }
 
 // EksConfigSpec defines configuration for fetching EKS audit logs.
@@ -93,6 +432,45 @@ type EksCloudwatchLogsSpec struct {
 	// Default: 600
 	// +optional
 	FetchInterval int32 `json:"fetchInterval,omitempty"`
+
+	// ARN of the IAM role to assume with IAM roles for service accounts (IRSA). When set, the eks-log-forwarder
+	// service account is annotated with the role and static AWS keys in tigera-eks-log-forwarder-secret are not used.
+	// +optional
+	RoleARN string `json:"roleARN,omitempty"`
+}
+
+// AzureMonitorLogsSpec defines configuration for fetching AKS audit logs from the Log Analytics workspace that the
+// diagnostic settings of the cluster send them to. The credentials of a service principal with read access to the
+// workspace are read from the keys tenant-id, client-id and client-secret of the tigera-aks-log-forwarder-secret
+// Secret in the tigera-operator namespace.
+type AzureMonitorLogsSpec struct {
+	// ID of the Log Analytics workspace containing AKS audit logs.
+	WorkspaceID string `json:"workspaceID"`
+
+	// Azure Monitor audit logs fetching interval in seconds.
+	// Default: 600
+	// +optional
+	FetchInterval int32 `json:"fetchInterval,omitempty"`
+}
+
+// GcpLoggingLogsSpec defines configuration for fetching GKE audit logs from Cloud Logging.
+type GcpLoggingLogsSpec struct {
+	// ID of the GCP project the GKE cluster is hosted in.
+	ProjectID string `json:"projectID"`
+
+	// Name of the GKE cluster whose audit logs are fetched.
+	ClusterName string `json:"clusterName"`
+
+	// Cloud Logging audit logs fetching interval in seconds.
+	// Default: 600
+	// +optional
+	FetchInterval int32 `json:"fetchInterval,omitempty"`
+
+	// Email of the GCP service account to use with Workload Identity. When set, the gke-log-forwarder service
+	// account is annotated with it. Otherwise a service account key is read from the key key.json of the
+	// tigera-gke-log-forwarder-secret Secret in the tigera-operator namespace.
+	// +optional
+	ServiceAccount string `json:"serviceAccount,omitempty"`
 }
 
 // LogCollectorStatus defines the observed state of Tigera flow and DNS log collection
@@ -100,6 +478,26 @@ type EksCloudwatchLogsSpec struct {
 type LogCollectorStatus struct {
 	// State provides user-readable status.
 	State string `json:"state,omitempty"`
+
+	// BufferOverflows lists the fluentd outputs whose buffers are full, as reported by the fluentd monitor.
+	// +optional
+	BufferOverflows []FluentdBufferOverflow `json:"bufferOverflows,omitempty"`
+
+	// FlowLogs are the flow log settings in effect in the default FelixConfiguration, with defaults filled in.
+	// +optional
+	FlowLogs *FlowLogsSpec `json:"flowLogs,omitempty"`
+}
+
+// FluentdBufferOverflow reports a fluentd output whose buffer is full, so that new logs for it are dropped.
+type FluentdBufferOverflow struct {
+	// Node is the node of the fluentd pod.
+	Node string `json:"node"`
+
+	// Output is the ID of the fluentd output plugin.
+	Output string `json:"output"`
+
+	// QueuedBytes is the size of the logs in the buffer.
+	QueuedBytes int64 `json:"queuedBytes"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -16,6 +16,7 @@ package v1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -37,12 +38,190 @@ type LogStorageStatus struct {
 	// KibanaHash represents the current revision and configuration of the installed Kibana dashboard. This
 	// is an opaque string which can be monitored for changes to perform actions when Kibana is modified.
 	KibanaHash string `json:"kibanaHash,omitempty"`
+
+	// IndexLifecycleErrors lists the indices whose index lifecycle management, which enforces retention, has failed.
+	// +optional
+	IndexLifecycleErrors []IndexLifecycleError `json:"indexLifecycleErrors,omitempty"`
+
+	// NodeSets reports the number of nodes of each node set of the Elasticsearch cluster and the progress of changes
+	// to the number of nodes and their storage.
+	// +optional
+	NodeSets []NodeSetStatus `json:"nodeSets,omitempty"`
+
+	// Health reports the health of the Elasticsearch cluster and the disk usage of its data nodes, as last polled by
+	// the operator.
+	// +optional
+	Health *ElasticsearchHealth `json:"health,omitempty"`
+
+	// SavedObjects reports the import of the saved objects in each ConfigMap listed in the Kibana spec and its spaces.
+	// +optional
+	SavedObjects []SavedObjectsImport `json:"savedObjects,omitempty"`
+
+	// Archive reports the logs archived into the snapshot repository.
+	// +optional
+	Archive *ArchiveStatus `json:"archive,omitempty"`
+}
+
+// ArchiveStatus reports the logs archived into the snapshot repository.
+type ArchiveStatus struct {
+	// Indices is the number of archived indices.
+	Indices int32 `json:"indices"`
+
+	// OldestLog is the start of the oldest archived logs.
+	// +optional
+	OldestLog *metav1.Time `json:"oldestLog,omitempty"`
+
+	// NewestLog is the end of the newest archived logs.
+	// +optional
+	NewestLog *metav1.Time `json:"newestLog,omitempty"`
+
+	// InProgress is the name of the index being archived.
+	// +optional
+	InProgress string `json:"inProgress,omitempty"`
+
+	// Error describes why the last index that was archived could not be archived. It is retried.
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// SavedObjectsImport reports the import of the saved objects in a ConfigMap into Kibana.
+type SavedObjectsImport struct {
+	// ConfigMapName is the name of the ConfigMap.
+	ConfigMapName string `json:"configMapName"`
+
+	// Space is the ID of the Kibana space the saved objects are imported into, empty for the default space.
+	// +optional
+	Space string `json:"space,omitempty"`
+
+	// Hash identifies the contents of the ConfigMap that were last imported.
+	// +optional
+	Hash string `json:"hash,omitempty"`
+
+	// Error describes why the import failed.
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// ElasticsearchHealthStatus is the health of an Elasticsearch cluster.
+type ElasticsearchHealthStatus string
+
+const (
+	// ElasticsearchHealthGreen means all shards are assigned.
+	ElasticsearchHealthGreen ElasticsearchHealthStatus = "green"
+	// ElasticsearchHealthYellow means all primary shards are assigned but some replica shards are not.
+	ElasticsearchHealthYellow ElasticsearchHealthStatus = "yellow"
+	// ElasticsearchHealthRed means some primary shards are not assigned, so some data can't be searched or written.
+	ElasticsearchHealthRed ElasticsearchHealthStatus = "red"
+)
+
+// DiskWatermark is the highest disk watermark the disk usage of an Elasticsearch data node has exceeded.
+type DiskWatermark string
+
+const (
+	// DiskWatermarkNone means the disk usage is below all watermarks.
+	DiskWatermarkNone DiskWatermark = "None"
+	// DiskWatermarkLow means no new shards are allocated to the node.
+	DiskWatermarkLow DiskWatermark = "Low"
+	// DiskWatermarkHigh means shards are being moved off the node.
+	DiskWatermarkHigh DiskWatermark = "High"
+	// DiskWatermarkFloodStage means the indices with shards on the node only allow reads and deletes.
+	DiskWatermarkFloodStage DiskWatermark = "FloodStage"
+)
+
+// ElasticsearchHealth is the health of the Elasticsearch cluster.
+type ElasticsearchHealth struct {
+	// Status is the health of the cluster, green, yellow or red.
+	Status ElasticsearchHealthStatus `json:"status"`
+
+	// UnassignedShards is the number of shards that are not allocated to any node.
+	UnassignedShards int32 `json:"unassignedShards"`
+
+	// InitializingShards is the number of shards that are being recovered or restored.
+	InitializingShards int32 `json:"initializingShards"`
+
+	// RelocatingShards is the number of shards that are being moved between nodes.
+	RelocatingShards int32 `json:"relocatingShards"`
+
+	// Watermark is the highest disk watermark exceeded by any data node.
+	Watermark DiskWatermark `json:"watermark"`
+
+	// Nodes reports the disk usage of each data node.
+	// +optional
+	Nodes []NodeDiskUsage `json:"nodes,omitempty"`
+}
+
+// NodeDiskUsage is the disk usage of an Elasticsearch data node.
+type NodeDiskUsage struct {
+	// Name is the name of the node.
+	Name string `json:"name"`
+
+	// DiskUsedBytes is the disk space used on the node.
+	DiskUsedBytes int64 `json:"diskUsedBytes"`
+
+	// DiskTotalBytes is the size of the disk of the node.
+	DiskTotalBytes int64 `json:"diskTotalBytes"`
+
+	// DiskPercent is the percentage of the disk of the node that is used.
+	DiskPercent int32 `json:"diskPercent"`
+
+	// Watermark is the highest disk watermark the disk usage of the node has exceeded.
+	Watermark DiskWatermark `json:"watermark"`
+}
+
+type NodeSetPhase string
+
+const (
+	// NodeSetReady means the node set has the number of nodes and the storage in the LogStorage spec.
+	NodeSetReady NodeSetPhase = "Ready"
+	// NodeSetDraining means shards are being moved off the nodes that a scale down removes. The nodes are removed
+	// once they hold no shards.
+	NodeSetDraining NodeSetPhase = "Draining"
+	// NodeSetExpandingVolumes means the volumes of the nodes are being expanded to the storage size in the
+	// LogStorage spec.
+	NodeSetExpandingVolumes NodeSetPhase = "ExpandingVolumes"
+	// NodeSetBlocked means the change in the LogStorage spec is not made because it could lose data or is not
+	// supported. The message explains why.
+	NodeSetBlocked NodeSetPhase = "Blocked"
+)
+
+// NodeSetStatus reports the number of nodes of a node set and the progress of changes to it.
+type NodeSetStatus struct {
+	// Name is the name of the node set, empty for the nodes defined by Nodes.Count.
+	Name string `json:"name,omitempty"`
+
+	// Count is the number of nodes the Elasticsearch cluster is configured with.
+	Count int64 `json:"count"`
+
+	// DesiredCount is the number of nodes in the LogStorage spec.
+	DesiredCount int64 `json:"desiredCount"`
+
+	// Phase is the progress of changes to the node set.
+	Phase NodeSetPhase `json:"phase"`
+
+	// Message describes the progress of changes to the node set or why they are blocked.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// IndexLifecycleError describes an index lifecycle management step that failed for an index.
+type IndexLifecycleError struct {
+	// Index is the name of the index.
+	Index string `json:"index"`
+
+	// Policy is the name of the index lifecycle management policy of the index.
+	Policy string `json:"policy,omitempty"`
+
+	// FailedStep is the step of the policy that failed.
+	FailedStep string `json:"failedStep,omitempty"`
+
+	// Reason describes why the step failed.
+	Reason string `json:"reason,omitempty"`
 }
 
 // LogStorageSpec defines the desired state of Tigera flow and DNS log storage.
 // +k8s:openapi-gen=true
 type LogStorageSpec struct {
-	// Nodes defines the configuration for a set of identical Elasticsearch cluster nodes, each of type master, data, and ingest.
+	// Nodes defines the configuration of the Elasticsearch cluster nodes.
 	Nodes *Nodes `json:"nodes,omitempty"`
 
 	// Index defines the configuration for the indices in the Elasticsearch cluster.
@@ -52,9 +231,343 @@ type LogStorageSpec struct {
 	// Retention defines how long data is retained in the Elasticsearch cluster before it is cleared.
 	// +optional
 	Retention *Retention `json:"retention,omitempty"`
+
+	// External configures log storage to use an existing Elasticsearch cluster instead of one installed by the
+	// operator. When set, ECK, the Elasticsearch cluster and Kibana are not installed and Nodes is ignored.
+	// +optional
+	External *ExternalElasticsearch `json:"external,omitempty"`
+
+	// Snapshots configures a repository for snapshots of the Elasticsearch indices and when snapshots are taken and
+	// deleted. Indices are restored from the snapshots with a LogStorageRestore.
+	// +optional
+	Snapshots *Snapshots `json:"snapshots,omitempty"`
+
+	// PasswordRotationDays is the number of days after which the operator replaces the password of the
+	// Elasticsearch user of each component. The new password is set on a second user of the component, and the
+	// previous user is disabled once the component has been restarted to use it. Passwords are not replaced if it is 0.
+	// Default: 90
+	// +optional
+	PasswordRotationDays *int32 `json:"passwordRotationDays,omitempty"`
+
+	// Kibana configures the Kibana instance installed with the Elasticsearch cluster. It is ignored when External
+	// is set.
+	// +optional
+	Kibana *Kibana `json:"kibana,omitempty"`
+}
+
+// ExternalElasticsearch defines how to connect to an Elasticsearch cluster that is not managed by the operator.
+type ExternalElasticsearch struct {
+	// Endpoint is the HTTPS URL of the Elasticsearch cluster, for example https://elasticsearch.example.com:9200.
+	Endpoint string `json:"endpoint"`
+
+	// CASecretName is the name of a Secret in the tigera-operator namespace containing the CA bundle used to
+	// verify the certificate of the Elasticsearch cluster, under the key tls.crt.
+	CASecretName string `json:"caSecretName"`
+
+	// AdminSecretName is the name of a Secret in the tigera-operator namespace containing the username and
+	// password of an Elasticsearch user that can manage users and roles. The operator uses it to create the
+	// users and roles for each component.
+	AdminSecretName string `json:"adminSecretName"`
+}
+
+// Kibana defines the configuration of the Kibana instance installed with the Elasticsearch cluster.
+type Kibana struct {
+	// Disabled removes Kibana. The Elasticsearch cluster is still installed.
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+
+	// Count defines the number of Kibana instances.
+	// Default: 1
+	// +optional
+	Count int32 `json:"count,omitempty"`
+
+	// ResourceRequirements defines the CPU and memory limits and requests of each Kibana instance.
+	// +optional
+	ResourceRequirements *corev1.ResourceRequirements `json:"resourceRequirements,omitempty"`
+
+	// Auth configures how users log in to Kibana.
+	// +optional
+	Auth *KibanaAuth `json:"auth,omitempty"`
+
+	// SavedObjectsConfigMaps lists ConfigMaps in the tigera-operator namespace holding saved objects, such as
+	// dashboards and index patterns, to import into the default space of Kibana. Each value of a ConfigMap is a saved
+	// objects export in NDJSON format. Objects with the same IDs are replaced when a ConfigMap changes.
+	// +optional
+	SavedObjectsConfigMaps []string `json:"savedObjectsConfigMaps,omitempty"`
+
+	// Spaces are the Kibana spaces created by the operator, in addition to the default space. Spaces removed from the
+	// list are not deleted, so that the objects users saved in them are kept.
+	// +optional
+	Spaces []KibanaSpace `json:"spaces,omitempty"`
+}
+
+// KibanaSpace defines a Kibana space, which organizes saved objects such as dashboards. Spaces require a Kibana
+// license that includes them.
+type KibanaSpace struct {
+	// ID identifies the space in the URLs of Kibana. It may only contain lowercase letters, numbers, underscores and
+	// hyphens, and can't be changed once the space is created.
+	ID string `json:"id"`
+
+	// Name is the name of the space shown to users.
+	Name string `json:"name"`
+
+	// Description describes the space to users.
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// DisabledFeatures lists the IDs of the Kibana features that are hidden in the space, such as dev_tools.
+	// +optional
+	DisabledFeatures []string `json:"disabledFeatures,omitempty"`
+
+	// SavedObjectsConfigMaps lists ConfigMaps in the tigera-operator namespace holding saved objects to import into
+	// the space, in the same format as the SavedObjectsConfigMaps of Kibana.
+	// +optional
+	SavedObjectsConfigMaps []string `json:"savedObjectsConfigMaps,omitempty"`
+}
+
+// KibanaAuthType is a way for users to log in to Kibana.
+// +kubebuilder:validation:Enum=Basic;OIDC;SAML
+type KibanaAuthType string
+
+const (
+	KibanaAuthTypeBasic KibanaAuthType = "Basic"
+	KibanaAuthTypeOIDC  KibanaAuthType = "OIDC"
+	KibanaAuthTypeSAML  KibanaAuthType = "SAML"
+)
+
+// KibanaAuth defines how users log in to Kibana. OIDC and SAML single sign-on are provided by an Elasticsearch realm
+// of the same type and require an Elasticsearch license that includes them. Users can still log in with
+// Elasticsearch usernames and passwords.
+type KibanaAuth struct {
+	// Type configures how users log in to Kibana.
+	// Default: Basic
+	// +optional
+	Type KibanaAuthType `json:"type,omitempty"`
+
+	// BaseURL is the URL users reach Kibana at, for example https://manager.example.com/tigera-kibana. It is
+	// required for OIDC and SAML, to build the URLs the identity provider redirects users to.
+	// +optional
+	BaseURL string `json:"baseURL,omitempty"`
+
+	// OIDC configures the OpenID Connect provider. It is required when Type is OIDC.
+	// +optional
+	OIDC *KibanaOIDC `json:"oidc,omitempty"`
+
+	// SAML configures the SAML identity provider. It is required when Type is SAML.
+	// +optional
+	SAML *KibanaSAML `json:"saml,omitempty"`
+
+	// Roles are the Elasticsearch roles granted to users who log in with OIDC or SAML.
+	// Default: kibana_user, tigera-ee-manager
+	// +optional
+	Roles []string `json:"roles,omitempty"`
+}
+
+// KibanaOIDC defines an OpenID Connect provider. Users log in to Kibana with the same provider as the Manager when it
+// uses OIDC.
+type KibanaOIDC struct {
+	// Authority is the issuer of the OpenID Connect provider. It must be the Authority of the Manager when the Manager
+	// uses OIDC.
+	// Default: the Authority of the Manager
+	// +optional
+	Authority string `json:"authority,omitempty"`
+
+	// ClientID is the client ID of Kibana at the OpenID Connect provider.
+	// Default: the ClientID of the Manager
+	// +optional
+	ClientID string `json:"clientID,omitempty"`
+
+	// ClientSecretName is the name of a Secret in the tigera-operator namespace containing the client secret of
+	// Kibana at the OpenID Connect provider, under the key clientSecret.
+	ClientSecretName string `json:"clientSecretName"`
+
+	// AuthorizationEndpoint is the URL of the authorization endpoint of the OpenID Connect provider.
+	AuthorizationEndpoint string `json:"authorizationEndpoint"`
+
+	// TokenEndpoint is the URL of the token endpoint of the OpenID Connect provider.
+	TokenEndpoint string `json:"tokenEndpoint"`
+
+	// JWKSetURL is the URL of the JSON Web Key Set of the OpenID Connect provider.
+	JWKSetURL string `json:"jwkSetURL"`
+
+	// PrincipalClaim is the claim of the ID token that identifies the user.
+	// Default: sub
+	// +optional
+	PrincipalClaim string `json:"principalClaim,omitempty"`
+}
+
+// KibanaSAML defines a SAML identity provider.
+type KibanaSAML struct {
+	// IDPMetadataURL is the URL of the SAML metadata of the identity provider.
+	IDPMetadataURL string `json:"idpMetadataURL"`
+
+	// IDPEntityID is the entity ID of the identity provider.
+	IDPEntityID string `json:"idpEntityID"`
+
+	// PrincipalAttribute is the SAML attribute that identifies the user.
+	// Default: nameid
+	// +optional
+	PrincipalAttribute string `json:"principalAttribute,omitempty"`
 }
 
-// Nodes defines the configuration for a set of identical Elasticsearch cluster nodes, each of type master, data, and ingest.
+// Snapshots defines where snapshots of the Elasticsearch indices are stored, when they are taken and when they are
+// deleted. Snapshots are taken and deleted by Elasticsearch snapshot lifecycle management on Elasticsearch 7.5 or
+// later. On earlier versions the operator takes and deletes them instead, which only supports schedules that run once
+// a day at a fixed time, in UTC, and an ExpireAfter in days, hours, minutes or seconds.
+type Snapshots struct {
+	// Repository defines where the snapshots are stored.
+	Repository SnapshotRepository `json:"repository"`
+
+	// Schedule is a cron expression, in the syntax of the Elasticsearch cron scheduler, that defines when snapshots
+	// are taken.
+	// Default: 0 30 1 * * ?
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// Indices are the names or wildcard patterns of the indices included in each snapshot.
+	// Default: tigera_secure_ee_*
+	// +optional
+	Indices []string `json:"indices,omitempty"`
+
+	// Retention defines when snapshots are deleted. If not set, snapshots are kept until they are deleted manually.
+	// +optional
+	Retention *SnapshotRetention `json:"retention,omitempty"`
+
+	// Archive moves the indices of the given log types into the repository once their retention period has passed,
+	// instead of deleting them. Archived logs are brought back with a LogStorageRestore.
+	// +optional
+	Archive *SnapshotArchive `json:"archive,omitempty"`
+}
+
+// ArchiveLogType is a type of log that can be archived.
+// +kubebuilder:validation:Enum=Flows;DNS
+type ArchiveLogType string
+
+const (
+	ArchiveLogTypeFlows ArchiveLogType = "Flows"
+	ArchiveLogTypeDNS   ArchiveLogType = "DNS"
+)
+
+// SnapshotArchive defines which logs are archived and how long they are kept. The operator takes a snapshot of each
+// index, one at a time, once the retention period of its log type has passed since it was last written to, and
+// deletes the index once the snapshot has completed. Archived snapshots are not deleted by the snapshot Retention.
+type SnapshotArchive struct {
+	// LogTypes are the types of logs that are archived.
+	// Default: Flows, DNS
+	// +optional
+	LogTypes []ArchiveLogType `json:"logTypes,omitempty"`
+
+	// RetentionDays is the number of days archived logs are kept in the repository, counted from the end of the
+	// logs in each index. If not set, archived logs are kept until they are deleted manually.
+	// +optional
+	RetentionDays *int32 `json:"retentionDays,omitempty"`
+}
+
+// SnapshotRetention defines when snapshots are deleted. Snapshots older than ExpireAfter are deleted unless fewer than
+// MinCount snapshots would be left, and the oldest snapshots are deleted while there are more than MaxCount.
+type SnapshotRetention struct {
+	// ExpireAfter is the age after which a snapshot is deleted, for example 30d.
+	// +optional
+	ExpireAfter string `json:"expireAfter,omitempty"`
+
+	// MinCount is the minimum number of snapshots kept, regardless of their age.
+	// +optional
+	MinCount *int32 `json:"minCount,omitempty"`
+
+	// MaxCount is the maximum number of snapshots kept, regardless of their age.
+	// +optional
+	MaxCount *int32 `json:"maxCount,omitempty"`
+}
+
+// SnapshotRepository defines where snapshots are stored. Exactly one of S3, GCS, Azure or FileSystem must be set.
+//
+// The credentials of the S3, GCS and Azure repositories are only used for an Elasticsearch cluster installed by the
+// operator. For an external Elasticsearch cluster the repository plugin and credentials must be configured on the
+// cluster itself.
+type SnapshotRepository struct {
+	// S3 stores snapshots in an Amazon S3 bucket or in a bucket of an S3 compatible service, such as MinIO.
+	// +optional
+	S3 *S3SnapshotRepository `json:"s3,omitempty"`
+
+	// GCS stores snapshots in a Google Cloud Storage bucket.
+	// +optional
+	GCS *GCSSnapshotRepository `json:"gcs,omitempty"`
+
+	// Azure stores snapshots in an Azure Blob Storage container.
+	// +optional
+	Azure *AzureSnapshotRepository `json:"azure,omitempty"`
+
+	// FileSystem stores snapshots in a volume shared by all the Elasticsearch nodes.
+	// +optional
+	FileSystem *FileSystemSnapshotRepository `json:"fileSystem,omitempty"`
+
+	// PluginURL is the http, https or file URL of the zip file of the Elasticsearch plugin that provides the S3, GCS
+	// or Azure repository, for clusters that can't download it from the Elastic plugin repository, such as
+	// air-gapped clusters. The plugin must have the same version as Elasticsearch.
+	// Default: the plugin is downloaded from https://artifacts.elastic.co
+	// +optional
+	PluginURL string `json:"pluginURL,omitempty"`
+}
+
+// S3SnapshotRepository defines an S3 bucket that snapshots are stored in.
+type S3SnapshotRepository struct {
+	// Bucket is the name of the bucket.
+	Bucket string `json:"bucket"`
+
+	// BasePath is the path within the bucket that snapshots are stored under.
+	// +optional
+	BasePath string `json:"basePath,omitempty"`
+
+	// Endpoint is the URL of an S3 compatible service, for example http://minio.minio.svc:9000.
+	// Default: the Amazon S3 endpoint
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// CredentialsSecretName is the name of a Secret in the tigera-operator namespace containing the access_key and
+	// secret_key used to access the bucket.
+	// +optional
+	CredentialsSecretName string `json:"credentialsSecretName,omitempty"`
+}
+
+// GCSSnapshotRepository defines a Google Cloud Storage bucket that snapshots are stored in.
+type GCSSnapshotRepository struct {
+	// Bucket is the name of the bucket.
+	Bucket string `json:"bucket"`
+
+	// BasePath is the path within the bucket that snapshots are stored under.
+	// +optional
+	BasePath string `json:"basePath,omitempty"`
+
+	// CredentialsSecretName is the name of a Secret in the tigera-operator namespace containing the JSON key of the
+	// service account used to access the bucket, under the key credentials_file.
+	// +optional
+	CredentialsSecretName string `json:"credentialsSecretName,omitempty"`
+}
+
+// AzureSnapshotRepository defines an Azure Blob Storage container that snapshots are stored in.
+type AzureSnapshotRepository struct {
+	// Container is the name of the container.
+	Container string `json:"container"`
+
+	// BasePath is the path within the container that snapshots are stored under.
+	// +optional
+	BasePath string `json:"basePath,omitempty"`
+
+	// CredentialsSecretName is the name of a Secret in the tigera-operator namespace containing the account name and
+	// key used to access the container, under the keys account and key.
+	// +optional
+	CredentialsSecretName string `json:"credentialsSecretName,omitempty"`
+}
+
+// FileSystemSnapshotRepository defines a shared volume that snapshots are stored in.
+type FileSystemSnapshotRepository struct {
+	// PersistentVolumeClaimName is the name of a ReadWriteMany PersistentVolumeClaim in the tigera-elasticsearch
+	// namespace. The volume is mounted on every Elasticsearch node.
+	PersistentVolumeClaimName string `json:"persistentVolumeClaimName"`
+}
+
+// Nodes defines the configuration of the Elasticsearch cluster nodes. Either Count, and optionally
+// ResourceRequirements, is set for a set of identical nodes, each of type master, data, and ingest, or NodeSets is set.
 type Nodes struct {
 	// Count defines the number of nodes in the Elasticsearch cluster.
 	Count int64 `json:"count,omitempty"`
@@ -62,6 +575,90 @@ type Nodes struct {
 	// ResourceRequirements defines the resource limits and requirements for the Elasticsearch cluster.
 	// +optional
 	ResourceRequirements *corev1.ResourceRequirements `json:"resourceRequirements,omitempty"`
+
+	// NodeSets defines groups of Elasticsearch nodes, each with their own roles, resources, storage and scheduling
+	// constraints. At least one node must be master-eligible and the number of master-eligible nodes must be odd.
+	// +optional
+	NodeSets []NodeSet `json:"nodeSets,omitempty"`
+}
+
+// ElasticsearchNodeRole is a role of an Elasticsearch node.
+// +kubebuilder:validation:Enum=Master;Data;Ingest
+type ElasticsearchNodeRole string
+
+const (
+	ElasticsearchNodeRoleMaster ElasticsearchNodeRole = "Master"
+	ElasticsearchNodeRoleData   ElasticsearchNodeRole = "Data"
+	ElasticsearchNodeRoleIngest ElasticsearchNodeRole = "Ingest"
+)
+
+// ElasticsearchDataTier is the tier of the data held by an Elasticsearch data node.
+type ElasticsearchDataTier string
+
+const (
+	ElasticsearchDataTierHot  ElasticsearchDataTier = "Hot"
+	ElasticsearchDataTierWarm ElasticsearchDataTier = "Warm"
+)
+
+// NodeSet defines a group of identical Elasticsearch nodes.
+type NodeSet struct {
+	// Name identifies the node set. It must be unique, at most 23 characters long and consist of lower case
+	// alphanumeric characters or '-'.
+	Name string `json:"name"`
+
+	// Count defines the number of nodes in the node set.
+	Count int64 `json:"count"`
+
+	// Roles defines the roles of the nodes in the node set.
+	// Default: Master, Data, Ingest
+	// +optional
+	Roles []ElasticsearchNodeRole `json:"roles,omitempty"`
+
+	// DataTier defines the tier of the data held by the nodes in the node set. It may only be set for node sets
+	// with the Data role.
+	// Default: Hot
+	// +optional
+	// +kubebuilder:validation:Enum=Hot;Warm
+	DataTier ElasticsearchDataTier `json:"dataTier,omitempty"`
+
+	// ResourceRequirements defines the CPU and memory limits and requests of each node in the node set.
+	// +optional
+	ResourceRequirements *corev1.ResourceRequirements `json:"resourceRequirements,omitempty"`
+
+	// StorageSize defines the size of the volume of each node in the node set.
+	// Default: 10Gi
+	// +optional
+	StorageSize *resource.Quantity `json:"storageSize,omitempty"`
+
+	// StorageClassName defines the storage class of the volume of each node in the node set.
+	// Default: tigera-elasticsearch
+	// +optional
+	StorageClassName string `json:"storageClassName,omitempty"`
+
+	// NodeSelector restricts the nodes in the node set to Kubernetes nodes with matching labels.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations allows the nodes in the node set to be scheduled on Kubernetes nodes with matching taints.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity defines the scheduling constraints of the nodes in the node set.
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+}
+
+// HasRole returns true if the nodes in the node set have the given role.
+func (n NodeSet) HasRole(role ElasticsearchNodeRole) bool {
+	if len(n.Roles) == 0 {
+		return true
+	}
+	for _, r := range n.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
 }
 
 // Indices defines the configuration for the indices in an Elasticsearch cluster.
@@ -69,6 +666,55 @@ type Indices struct {
 	// Replicas defines how many replicas each index will have. See https://www.elastic.co/guide/en/elasticsearch/reference/current/scalability.html
 	// +optional
 	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Shards defines how many primary shards each index will have.
+	// Default: 5
+	// +optional
+	Shards *int32 `json:"shards,omitempty"`
+
+	// Flows overrides the replicas and shards of the flow log indices.
+	// +optional
+	Flows *IndexSettings `json:"flows,omitempty"`
+
+	// DNSLogs overrides the replicas and shards of the DNS log indices.
+	// +optional
+	DNSLogs *IndexSettings `json:"dnsLogs,omitempty"`
+
+	// AuditLogs overrides the replicas and shards of the audit log indices.
+	// +optional
+	AuditLogs *IndexSettings `json:"auditLogs,omitempty"`
+
+	// Snapshots overrides the replicas and shards of the compliance snapshot indices.
+	// +optional
+	Snapshots *IndexSettings `json:"snapshots,omitempty"`
+
+	// ComplianceReports overrides the replicas and shards of the compliance report indices.
+	// +optional
+	ComplianceReports *IndexSettings `json:"complianceReports,omitempty"`
+
+	// BenchmarkResults overrides the replicas and shards of the compliance benchmark result indices.
+	// +optional
+	BenchmarkResults *IndexSettings `json:"benchmarkResults,omitempty"`
+
+	// IntrusionDetectionEvents overrides the replicas and shards of the intrusion detection event indices.
+	// +optional
+	IntrusionDetectionEvents *IndexSettings `json:"intrusionDetectionEvents,omitempty"`
+
+	// ComponentLogs overrides the replicas and shards of the component log indices.
+	// +optional
+	ComponentLogs *IndexSettings `json:"componentLogs,omitempty"`
+}
+
+// IndexSettings overrides the replicas and shards of the indices of one type of log. Unset values default to the
+// replicas and shards of all indices.
+type IndexSettings struct {
+	// Replicas defines how many replicas each index of this type will have.
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Shards defines how many primary shards each index of this type will have.
+	// +optional
+	Shards *int32 `json:"shards,omitempty"`
 }
 
 // Retention defines how long data is retained in an Elasticsearch cluster before it is cleared.
@@ -102,6 +748,22 @@ type Retention struct {
 	// Default: 367
 	// +optional
 	ComplianceReports *int32 `json:"complianceReports"`
+
+	// DNSLogs configures the retention period for DNS logs, in days.
+	// Default: 8
+	// +optional
+	DNSLogs *int32 `json:"dnsLogs,omitempty"`
+
+	// IntrusionDetectionEvents configures the retention period for intrusion detection events, in days.
+	// Default: 365
+	// +optional
+	IntrusionDetectionEvents *int32 `json:"intrusionDetectionEvents,omitempty"`
+
+	// ComponentLogs configures the retention period for the container logs of the components managed by the
+	// operator, in days.
+	// Default: 8
+	// +optional
+	ComponentLogs *int32 `json:"componentLogs,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -137,6 +799,15 @@ func (ls LogStorage) Replicas() int {
 	return int(*ls.Spec.Indices.Replicas)
 }
 
+func (ls LogStorage) Shards() int {
+	return int(*ls.Spec.Indices.Shards)
+}
+
+// KibanaDisabled returns true if Kibana is not installed with the Elasticsearch cluster.
+func (ls LogStorage) KibanaDisabled() bool {
+	return ls.Spec.Kibana != nil && ls.Spec.Kibana.Disabled
+}
+
 func init() {
 	SchemeBuilder.Register(&LogStorage{}, &LogStorageList{})
 }
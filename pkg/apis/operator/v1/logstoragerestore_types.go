@@ -0,0 +1,116 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	LogStorageRestoreStateInProgress = "InProgress"
+	LogStorageRestoreStateCompleted  = "Completed"
+	LogStorageRestoreStateFailed     = "Failed"
+)
+
+// LogStorageRestoreSpec defines the snapshot and the indices to restore. Exactly one of Snapshot or Archive must be
+// set.
+// +k8s:openapi-gen=true
+type LogStorageRestoreSpec struct {
+	// Snapshot is the name of the snapshot, in the snapshot repository configured on the LogStorage, that indices are
+	// restored from.
+	// +optional
+	Snapshot string `json:"snapshot,omitempty"`
+
+	// Archive restores the archived logs of a log type from a period of time. The indices holding them are restored
+	// with their original names, so they are searched along with the logs that have not been archived. Restored
+	// indices are not archived or deleted again and must be deleted manually.
+	// +optional
+	Archive *ArchiveRestore `json:"archive,omitempty"`
+
+	// Indices are the names or wildcard patterns of the indices to restore.
+	// Default: all the indices in the snapshot
+	// +optional
+	Indices []string `json:"indices,omitempty"`
+
+	// RenamePattern is a regular expression matched against the names of the restored indices. An index can only be
+	// restored over an index with the same name if that index is closed, so indices that still exist must be renamed.
+	// +optional
+	RenamePattern string `json:"renamePattern,omitempty"`
+
+	// RenameReplacement replaces the part of the names of the restored indices matched by RenamePattern. It may
+	// reference the groups of RenamePattern, for example restored_$1.
+	// +optional
+	RenameReplacement string `json:"renameReplacement,omitempty"`
+}
+
+// ArchiveRestore selects archived logs by log type and time.
+type ArchiveRestore struct {
+	// LogType is the type of the logs to restore.
+	LogType ArchiveLogType `json:"logType"`
+
+	// From is the start of the period of time to restore logs from.
+	From metav1.Time `json:"from"`
+
+	// To is the end of the period of time to restore logs from.
+	To metav1.Time `json:"to"`
+}
+
+// LogStorageRestoreStatus defines the observed state of the restore.
+// +k8s:openapi-gen=true
+type LogStorageRestoreStatus struct {
+	// State is InProgress while the indices are restored, then Completed or Failed.
+	State string `json:"state,omitempty"`
+
+	// Message describes the progress of the restore or why it failed.
+	Message string `json:"message,omitempty"`
+
+	// PendingSnapshots are the archived snapshots that have not been restored yet, for an archive restore. The
+	// first is being restored.
+	// +optional
+	PendingSnapshots []string `json:"pendingSnapshots,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +genclient
+// +genclient:nonNamespaced
+
+// LogStorageRestore restores indices from a snapshot taken of the Elasticsearch cluster of the LogStorage. Each
+// restore is started once, when the resource is created. Progress and failures are reported in its status and in
+// the log-storage TigeraStatus.
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+type LogStorageRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Specification of the restore.
+	Spec LogStorageRestoreSpec `json:"spec,omitempty"`
+
+	// Most recently observed state of the restore.
+	Status LogStorageRestoreStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LogStorageRestoreList contains a list of LogStorageRestore.
+type LogStorageRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LogStorageRestore `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LogStorageRestore{}, &LogStorageRestoreList{})
+}
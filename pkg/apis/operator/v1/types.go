@@ -38,6 +38,28 @@ type InstallationSpec struct {
 	// +optional
 	ImagePullSecrets []v1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
 
+	// ImagePath replaces the path of all component images, for registries that store images under a
+	// different layout. For example, with an ImagePath of "mirror" the calico/node image is pulled
+	// from <registry>mirror/node.
+	// +optional
+	ImagePath string `json:"imagePath,omitempty"`
+
+	// ImagePrefix is prepended to the name of all component images. For example, with an ImagePath of
+	// "mirror" and an ImagePrefix of "calico-" the calico/node image is pulled from <registry>mirror/calico-node.
+	// +optional
+	ImagePrefix string `json:"imagePrefix,omitempty"`
+
+	// ImageReferenceMode selects whether component images are referenced by digest or by tag.
+	// Default: Digest
+	// +optional
+	// +kubebuilder:validation:Enum=Digest,Tag
+	ImageReferenceMode ImageReferenceMode `json:"imageReferenceMode,omitempty"`
+
+	// ImageOverrides replaces the images used for individual components. The registry is still applied
+	// to overridden images.
+	// +optional
+	ImageOverrides []ImageOverride `json:"imageOverrides,omitempty"`
+
 	// KubernetesProvider specifies a particular provider of the Kubernetes platform and enables provider-specific configuration.
 	// If the specified value is empty, the Operator will attempt to automatically determine the current provider.
 	// If the specified value is not empty, the Operator will still attempt auto-detection, but
@@ -66,6 +88,60 @@ type InstallationSpec struct {
 	// NodeMetricsPort specifies which port calico/node serves metrics on. If omitted, then metrics are disabled.
 	// +optional
 	NodeMetricsPort *int32 `json:"nodeMetricsPort,omitempty"`
+
+	// NodeUpdateStrategy configures how calico/node is updated when its configuration or images change.
+	// If omitted, calico/node is updated with a standard rolling update across all nodes.
+	// +optional
+	NodeUpdateStrategy *NodeUpdateStrategy `json:"nodeUpdateStrategy,omitempty"`
+}
+
+// NodeUpdateStrategy configures a controlled update of calico/node. A set of canary nodes is updated
+// first, and the remaining nodes are only updated, in batches, once calico/node is healthy on the canaries.
+// If calico/node does not become healthy on an updated node, the update is paused.
+type NodeUpdateStrategy struct {
+	// CanaryNodeSelector selects the nodes that are updated first. If omitted, no canary nodes are used
+	// and the update proceeds directly in batches.
+	// +optional
+	CanaryNodeSelector map[string]string `json:"canaryNodeSelector,omitempty"`
+
+	// BatchSize is the maximum number of nodes updated at the same time once the canary nodes are healthy.
+	// Default: 1
+	// +optional
+	BatchSize *int32 `json:"batchSize,omitempty"`
+
+	// HealthTimeoutSeconds is how long an updated calico/node pod may take to become ready before the
+	// update is paused and the installation is marked degraded.
+	// Default: 300
+	// +optional
+	HealthTimeoutSeconds *int32 `json:"healthTimeoutSeconds,omitempty"`
+}
+
+// ImageReferenceMode represents how component images are referenced. Valid options are: Digest, Tag.
+type ImageReferenceMode string
+
+const (
+	ImageReferenceModeDigest ImageReferenceMode = "Digest"
+	ImageReferenceModeTag    ImageReferenceMode = "Tag"
+)
+
+// ImageOverride replaces the image used for a single component.
+type ImageOverride struct {
+	// Component is the default image of the component to override, for example calico/node.
+	Component string `json:"component"`
+
+	// Image is the image to use instead of the default, without the registry. The ImagePath and
+	// ImagePrefix are not applied to it. If omitted, the default image is used.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Version is the tag of the image to use. If omitted, the default version is used.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Digest is the digest of the image to use, for example sha256:<hash>. If the Version is overridden
+	// without a Digest, the image is referenced by tag.
+	// +optional
+	Digest string `json:"digest,omitempty"`
 }
 
 // Provider represents a particular provider or flavor of Kubernetes. Valid options
@@ -241,6 +317,12 @@ type InstallationStatus struct {
 	// Variant is the most recently observed installed variant - one of Calico or TigeraSecureEnterprise
 	// +kubebuilder:validation:Enum=Calico,TigeraSecureEnterprise
 	Variant ProductVariant `json:"variant,omitempty"`
+
+	// ProductVersion is the most recently observed installed version of the product variant.
+	ProductVersion string `json:"productVersion,omitempty"`
+
+	// OperatorVersion is the version of the operator that most recently installed the product.
+	OperatorVersion string `json:"operatorVersion,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
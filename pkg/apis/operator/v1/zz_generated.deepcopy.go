@@ -110,6 +110,16 @@ func (in *AdditionalLogSourceSpec) DeepCopyInto(out *AdditionalLogSourceSpec) {
 		*out = new(EksCloudwatchLogsSpec)
 		**out = **in
 	}
+	if in.AzureMonitorLog != nil {
+		in, out := &in.AzureMonitorLog, &out.AzureMonitorLog
+		*out = new(AzureMonitorLogsSpec)
+		**out = **in
+	}
+	if in.GcpLoggingLog != nil {
+		in, out := &in.GcpLoggingLog, &out.GcpLoggingLog
+		*out = new(GcpLoggingLogsSpec)
+		**out = **in
+	}
 	return
 }
 
@@ -129,13 +139,28 @@ func (in *AdditionalLogStoreSpec) DeepCopyInto(out *AdditionalLogStoreSpec) {
 	if in.S3 != nil {
 		in, out := &in.S3, &out.S3
 		*out = new(S3StoreSpec)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Syslog != nil {
 		in, out := &in.Syslog, &out.Syslog
 		*out = new(SyslogStoreSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Splunk != nil {
+		in, out := &in.Splunk, &out.Splunk
+		*out = new(SplunkStoreSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Kafka != nil {
+		in, out := &in.Kafka, &out.Kafka
+		*out = new(KafkaStoreSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = new(HTTPStoreSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -149,6 +174,48 @@ func (in *AdditionalLogStoreSpec) DeepCopy() *AdditionalLogStoreSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArchiveRestore) DeepCopyInto(out *ArchiveRestore) {
+	*out = *in
+	in.From.DeepCopyInto(&out.From)
+	in.To.DeepCopyInto(&out.To)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArchiveRestore.
+func (in *ArchiveRestore) DeepCopy() *ArchiveRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(ArchiveRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArchiveStatus) DeepCopyInto(out *ArchiveStatus) {
+	*out = *in
+	if in.OldestLog != nil {
+		in, out := &in.OldestLog, &out.OldestLog
+		*out = (*in).DeepCopy()
+	}
+	if in.NewestLog != nil {
+		in, out := &in.NewestLog, &out.NewestLog
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArchiveStatus.
+func (in *ArchiveStatus) DeepCopy() *ArchiveStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ArchiveStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Auth) DeepCopyInto(out *Auth) {
 	*out = *in
@@ -165,6 +232,38 @@ func (in *Auth) DeepCopy() *Auth {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureMonitorLogsSpec) DeepCopyInto(out *AzureMonitorLogsSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureMonitorLogsSpec.
+func (in *AzureMonitorLogsSpec) DeepCopy() *AzureMonitorLogsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureMonitorLogsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AzureSnapshotRepository) DeepCopyInto(out *AzureSnapshotRepository) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AzureSnapshotRepository.
+func (in *AzureSnapshotRepository) DeepCopy() *AzureSnapshotRepository {
+	if in == nil {
+		return nil
+	}
+	out := new(AzureSnapshotRepository)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CalicoNetworkSpec) DeepCopyInto(out *CalicoNetworkSpec) {
 	*out = *in
@@ -302,329 +401,782 @@ func (in *ComplianceStatus) DeepCopy() *ComplianceStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EksCloudwatchLogsSpec) DeepCopyInto(out *EksCloudwatchLogsSpec) {
+func (in *ComponentLogsSpec) DeepCopyInto(out *ComponentLogsSpec) {
 	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EksCloudwatchLogsSpec.
-func (in *EksCloudwatchLogsSpec) DeepCopy() *EksCloudwatchLogsSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentLogsSpec.
+func (in *ComponentLogsSpec) DeepCopy() *ComponentLogsSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(EksCloudwatchLogsSpec)
+	out := new(ComponentLogsSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IPPool) DeepCopyInto(out *IPPool) {
+func (in *EksCloudwatchLogsSpec) DeepCopyInto(out *EksCloudwatchLogsSpec) {
 	*out = *in
-	if in.BlockSize != nil {
-		in, out := &in.BlockSize, &out.BlockSize
-		*out = new(int32)
-		**out = **in
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPPool.
-func (in *IPPool) DeepCopy() *IPPool {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EksCloudwatchLogsSpec.
+func (in *EksCloudwatchLogsSpec) DeepCopy() *EksCloudwatchLogsSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(IPPool)
+	out := new(EksCloudwatchLogsSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Indices) DeepCopyInto(out *Indices) {
+func (in *ElasticsearchHealth) DeepCopyInto(out *ElasticsearchHealth) {
 	*out = *in
-	if in.Replicas != nil {
-		in, out := &in.Replicas, &out.Replicas
-		*out = new(int32)
-		**out = **in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]NodeDiskUsage, len(*in))
+		copy(*out, *in)
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Indices.
-func (in *Indices) DeepCopy() *Indices {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticsearchHealth.
+func (in *ElasticsearchHealth) DeepCopy() *ElasticsearchHealth {
 	if in == nil {
 		return nil
 	}
-	out := new(Indices)
+	out := new(ElasticsearchHealth)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Installation) DeepCopyInto(out *Installation) {
+func (in *ExternalElasticsearch) DeepCopyInto(out *ExternalElasticsearch) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Installation.
-func (in *Installation) DeepCopy() *Installation {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalElasticsearch.
+func (in *ExternalElasticsearch) DeepCopy() *ExternalElasticsearch {
 	if in == nil {
 		return nil
 	}
-	out := new(Installation)
+	out := new(ExternalElasticsearch)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Installation) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InstallationList) DeepCopyInto(out *InstallationList) {
+func (in *FileSystemSnapshotRepository) DeepCopyInto(out *FileSystemSnapshotRepository) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	out.ListMeta = in.ListMeta
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]Installation, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallationList.
-func (in *InstallationList) DeepCopy() *InstallationList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileSystemSnapshotRepository.
+func (in *FileSystemSnapshotRepository) DeepCopy() *FileSystemSnapshotRepository {
 	if in == nil {
 		return nil
 	}
-	out := new(InstallationList)
+	out := new(FileSystemSnapshotRepository)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *InstallationList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InstallationSpec) DeepCopyInto(out *InstallationSpec) {
+func (in *FlowLogsSpec) DeepCopyInto(out *FlowLogsSpec) {
 	*out = *in
-	if in.ImagePullSecrets != nil {
-		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
-		*out = make([]corev1.LocalObjectReference, len(*in))
-		copy(*out, *in)
-	}
-	if in.CalicoNetwork != nil {
-		in, out := &in.CalicoNetwork, &out.CalicoNetwork
-		*out = new(CalicoNetworkSpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.ControlPlaneNodeSelector != nil {
-		in, out := &in.ControlPlaneNodeSelector, &out.ControlPlaneNodeSelector
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.NodeMetricsPort != nil {
-		in, out := &in.NodeMetricsPort, &out.NodeMetricsPort
+	if in.PerNodeLimit != nil {
+		in, out := &in.PerNodeLimit, &out.PerNodeLimit
 		*out = new(int32)
 		**out = **in
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallationSpec.
-func (in *InstallationSpec) DeepCopy() *InstallationSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlowLogsSpec.
+func (in *FlowLogsSpec) DeepCopy() *FlowLogsSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(InstallationSpec)
+	out := new(FlowLogsSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InstallationStatus) DeepCopyInto(out *InstallationStatus) {
+func (in *FluentdBufferOverflow) DeepCopyInto(out *FluentdBufferOverflow) {
 	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallationStatus.
-func (in *InstallationStatus) DeepCopy() *InstallationStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FluentdBufferOverflow.
+func (in *FluentdBufferOverflow) DeepCopy() *FluentdBufferOverflow {
 	if in == nil {
 		return nil
 	}
-	out := new(InstallationStatus)
+	out := new(FluentdBufferOverflow)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IntrusionDetection) DeepCopyInto(out *IntrusionDetection) {
+func (in *FluentdBufferSpec) DeepCopyInto(out *FluentdBufferSpec) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	if in.TotalLimitSize != nil {
+		in, out := &in.TotalLimitSize, &out.TotalLimitSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntrusionDetection.
-func (in *IntrusionDetection) DeepCopy() *IntrusionDetection {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FluentdBufferSpec.
+func (in *FluentdBufferSpec) DeepCopy() *FluentdBufferSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(IntrusionDetection)
+	out := new(FluentdBufferSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *IntrusionDetection) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IntrusionDetectionList) DeepCopyInto(out *IntrusionDetectionList) {
+func (in *GCSSnapshotRepository) DeepCopyInto(out *GCSSnapshotRepository) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	out.ListMeta = in.ListMeta
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]IntrusionDetection, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntrusionDetectionList.
-func (in *IntrusionDetectionList) DeepCopy() *IntrusionDetectionList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GCSSnapshotRepository.
+func (in *GCSSnapshotRepository) DeepCopy() *GCSSnapshotRepository {
 	if in == nil {
 		return nil
 	}
-	out := new(IntrusionDetectionList)
+	out := new(GCSSnapshotRepository)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *IntrusionDetectionList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IntrusionDetectionSpec) DeepCopyInto(out *IntrusionDetectionSpec) {
+func (in *GcpLoggingLogsSpec) DeepCopyInto(out *GcpLoggingLogsSpec) {
 	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntrusionDetectionSpec.
-func (in *IntrusionDetectionSpec) DeepCopy() *IntrusionDetectionSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GcpLoggingLogsSpec.
+func (in *GcpLoggingLogsSpec) DeepCopy() *GcpLoggingLogsSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(IntrusionDetectionSpec)
+	out := new(GcpLoggingLogsSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IntrusionDetectionStatus) DeepCopyInto(out *IntrusionDetectionStatus) {
+func (in *HTTPStoreSpec) DeepCopyInto(out *HTTPStoreSpec) {
 	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LogTypes != nil {
+		in, out := &in.LogTypes, &out.LogTypes
+		*out = make([]StoreLogType, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntrusionDetectionStatus.
-func (in *IntrusionDetectionStatus) DeepCopy() *IntrusionDetectionStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPStoreSpec.
+func (in *HTTPStoreSpec) DeepCopy() *HTTPStoreSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(IntrusionDetectionStatus)
+	out := new(HTTPStoreSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LogCollector) DeepCopyInto(out *LogCollector) {
+func (in *IPPool) DeepCopyInto(out *IPPool) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	if in.BlockSize != nil {
+		in, out := &in.BlockSize, &out.BlockSize
+		*out = new(int32)
+		**out = **in
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogCollector.
-func (in *LogCollector) DeepCopy() *LogCollector {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPPool.
+func (in *IPPool) DeepCopy() *IPPool {
 	if in == nil {
 		return nil
 	}
-	out := new(LogCollector)
+	out := new(IPPool)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LogCollector) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageOverride) DeepCopyInto(out *ImageOverride) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageOverride.
+func (in *ImageOverride) DeepCopy() *ImageOverride {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(ImageOverride)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LogCollectorList) DeepCopyInto(out *LogCollectorList) {
+func (in *IndexLifecycleError) DeepCopyInto(out *IndexLifecycleError) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	out.ListMeta = in.ListMeta
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]LogCollector, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogCollectorList.
-func (in *LogCollectorList) DeepCopy() *LogCollectorList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexLifecycleError.
+func (in *IndexLifecycleError) DeepCopy() *IndexLifecycleError {
 	if in == nil {
 		return nil
 	}
-	out := new(LogCollectorList)
+	out := new(IndexLifecycleError)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LogCollectorList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexSettings) DeepCopyInto(out *IndexSettings) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Shards != nil {
+		in, out := &in.Shards, &out.Shards
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexSettings.
+func (in *IndexSettings) DeepCopy() *IndexSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Indices) DeepCopyInto(out *Indices) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Shards != nil {
+		in, out := &in.Shards, &out.Shards
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Flows != nil {
+		in, out := &in.Flows, &out.Flows
+		*out = new(IndexSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DNSLogs != nil {
+		in, out := &in.DNSLogs, &out.DNSLogs
+		*out = new(IndexSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AuditLogs != nil {
+		in, out := &in.AuditLogs, &out.AuditLogs
+		*out = new(IndexSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Snapshots != nil {
+		in, out := &in.Snapshots, &out.Snapshots
+		*out = new(IndexSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ComplianceReports != nil {
+		in, out := &in.ComplianceReports, &out.ComplianceReports
+		*out = new(IndexSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BenchmarkResults != nil {
+		in, out := &in.BenchmarkResults, &out.BenchmarkResults
+		*out = new(IndexSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IntrusionDetectionEvents != nil {
+		in, out := &in.IntrusionDetectionEvents, &out.IntrusionDetectionEvents
+		*out = new(IndexSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ComponentLogs != nil {
+		in, out := &in.ComponentLogs, &out.ComponentLogs
+		*out = new(IndexSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Indices.
+func (in *Indices) DeepCopy() *Indices {
+	if in == nil {
+		return nil
+	}
+	out := new(Indices)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Installation) DeepCopyInto(out *Installation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Installation.
+func (in *Installation) DeepCopy() *Installation {
+	if in == nil {
+		return nil
+	}
+	out := new(Installation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Installation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstallationList) DeepCopyInto(out *InstallationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Installation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallationList.
+func (in *InstallationList) DeepCopy() *InstallationList {
+	if in == nil {
+		return nil
+	}
+	out := new(InstallationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *InstallationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstallationSpec) DeepCopyInto(out *InstallationSpec) {
+	*out = *in
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]corev1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.ImageOverrides != nil {
+		in, out := &in.ImageOverrides, &out.ImageOverrides
+		*out = make([]ImageOverride, len(*in))
+		copy(*out, *in)
+	}
+	if in.CalicoNetwork != nil {
+		in, out := &in.CalicoNetwork, &out.CalicoNetwork
+		*out = new(CalicoNetworkSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ControlPlaneNodeSelector != nil {
+		in, out := &in.ControlPlaneNodeSelector, &out.ControlPlaneNodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.NodeMetricsPort != nil {
+		in, out := &in.NodeMetricsPort, &out.NodeMetricsPort
+		*out = new(int32)
+		**out = **in
+	}
+	if in.NodeUpdateStrategy != nil {
+		in, out := &in.NodeUpdateStrategy, &out.NodeUpdateStrategy
+		*out = new(NodeUpdateStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallationSpec.
+func (in *InstallationSpec) DeepCopy() *InstallationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(InstallationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstallationStatus) DeepCopyInto(out *InstallationStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallationStatus.
+func (in *InstallationStatus) DeepCopy() *InstallationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(InstallationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IntrusionDetection) DeepCopyInto(out *IntrusionDetection) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	out.Status = in.Status
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntrusionDetection.
+func (in *IntrusionDetection) DeepCopy() *IntrusionDetection {
+	if in == nil {
+		return nil
+	}
+	out := new(IntrusionDetection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IntrusionDetection) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IntrusionDetectionList) DeepCopyInto(out *IntrusionDetectionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]IntrusionDetection, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntrusionDetectionList.
+func (in *IntrusionDetectionList) DeepCopy() *IntrusionDetectionList {
+	if in == nil {
+		return nil
+	}
+	out := new(IntrusionDetectionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IntrusionDetectionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IntrusionDetectionSpec) DeepCopyInto(out *IntrusionDetectionSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntrusionDetectionSpec.
+func (in *IntrusionDetectionSpec) DeepCopy() *IntrusionDetectionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IntrusionDetectionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IntrusionDetectionStatus) DeepCopyInto(out *IntrusionDetectionStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntrusionDetectionStatus.
+func (in *IntrusionDetectionStatus) DeepCopy() *IntrusionDetectionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IntrusionDetectionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaStoreSpec) DeepCopyInto(out *KafkaStoreSpec) {
+	*out = *in
+	if in.Brokers != nil {
+		in, out := &in.Brokers, &out.Brokers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LogTypes != nil {
+		in, out := &in.LogTypes, &out.LogTypes
+		*out = make([]StoreLogType, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaStoreSpec.
+func (in *KafkaStoreSpec) DeepCopy() *KafkaStoreSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaStoreSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Kibana) DeepCopyInto(out *Kibana) {
+	*out = *in
+	if in.ResourceRequirements != nil {
+		in, out := &in.ResourceRequirements, &out.ResourceRequirements
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(KibanaAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SavedObjectsConfigMaps != nil {
+		in, out := &in.SavedObjectsConfigMaps, &out.SavedObjectsConfigMaps
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Spaces != nil {
+		in, out := &in.Spaces, &out.Spaces
+		*out = make([]KibanaSpace, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Kibana.
+func (in *Kibana) DeepCopy() *Kibana {
+	if in == nil {
+		return nil
+	}
+	out := new(Kibana)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KibanaAuth) DeepCopyInto(out *KibanaAuth) {
+	*out = *in
+	if in.OIDC != nil {
+		in, out := &in.OIDC, &out.OIDC
+		*out = new(KibanaOIDC)
+		**out = **in
+	}
+	if in.SAML != nil {
+		in, out := &in.SAML, &out.SAML
+		*out = new(KibanaSAML)
+		**out = **in
+	}
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KibanaAuth.
+func (in *KibanaAuth) DeepCopy() *KibanaAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(KibanaAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KibanaOIDC) DeepCopyInto(out *KibanaOIDC) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KibanaOIDC.
+func (in *KibanaOIDC) DeepCopy() *KibanaOIDC {
+	if in == nil {
+		return nil
+	}
+	out := new(KibanaOIDC)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KibanaSAML) DeepCopyInto(out *KibanaSAML) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KibanaSAML.
+func (in *KibanaSAML) DeepCopy() *KibanaSAML {
+	if in == nil {
+		return nil
+	}
+	out := new(KibanaSAML)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KibanaSpace) DeepCopyInto(out *KibanaSpace) {
+	*out = *in
+	if in.DisabledFeatures != nil {
+		in, out := &in.DisabledFeatures, &out.DisabledFeatures
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SavedObjectsConfigMaps != nil {
+		in, out := &in.SavedObjectsConfigMaps, &out.SavedObjectsConfigMaps
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KibanaSpace.
+func (in *KibanaSpace) DeepCopy() *KibanaSpace {
+	if in == nil {
+		return nil
+	}
+	out := new(KibanaSpace)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogCollector) DeepCopyInto(out *LogCollector) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogCollector.
+func (in *LogCollector) DeepCopy() *LogCollector {
+	if in == nil {
+		return nil
+	}
+	out := new(LogCollector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LogCollector) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogCollectorList) DeepCopyInto(out *LogCollectorList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LogCollector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogCollectorList.
+func (in *LogCollectorList) DeepCopy() *LogCollectorList {
+	if in == nil {
+		return nil
+	}
+	out := new(LogCollectorList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LogCollectorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
 	return nil
 }
 
@@ -641,6 +1193,26 @@ func (in *LogCollectorSpec) DeepCopyInto(out *LogCollectorSpec) {
 		*out = new(AdditionalLogSourceSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Buffer != nil {
+		in, out := &in.Buffer, &out.Buffer
+		*out = new(FluentdBufferSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResourceRequirements != nil {
+		in, out := &in.ResourceRequirements, &out.ResourceRequirements
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FlowLogs != nil {
+		in, out := &in.FlowLogs, &out.FlowLogs
+		*out = new(FlowLogsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ComponentLogs != nil {
+		in, out := &in.ComponentLogs, &out.ComponentLogs
+		*out = new(ComponentLogsSpec)
+		**out = **in
+	}
 	return
 }
 
@@ -657,6 +1229,16 @@ func (in *LogCollectorSpec) DeepCopy() *LogCollectorSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LogCollectorStatus) DeepCopyInto(out *LogCollectorStatus) {
 	*out = *in
+	if in.BufferOverflows != nil {
+		in, out := &in.BufferOverflows, &out.BufferOverflows
+		*out = make([]FluentdBufferOverflow, len(*in))
+		copy(*out, *in)
+	}
+	if in.FlowLogs != nil {
+		in, out := &in.FlowLogs, &out.FlowLogs
+		*out = new(FlowLogsSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -676,7 +1258,7 @@ func (in *LogStorage) DeepCopyInto(out *LogStorage) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -699,38 +1281,146 @@ func (in *LogStorage) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LogStorageList) DeepCopyInto(out *LogStorageList) {
+func (in *LogStorageList) DeepCopyInto(out *LogStorageList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LogStorage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogStorageList.
+func (in *LogStorageList) DeepCopy() *LogStorageList {
+	if in == nil {
+		return nil
+	}
+	out := new(LogStorageList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LogStorageList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogStorageRestore) DeepCopyInto(out *LogStorageRestore) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogStorageRestore.
+func (in *LogStorageRestore) DeepCopy() *LogStorageRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(LogStorageRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LogStorageRestore) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogStorageRestoreList) DeepCopyInto(out *LogStorageRestoreList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LogStorageRestore, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogStorageRestoreList.
+func (in *LogStorageRestoreList) DeepCopy() *LogStorageRestoreList {
+	if in == nil {
+		return nil
+	}
+	out := new(LogStorageRestoreList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LogStorageRestoreList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogStorageRestoreSpec) DeepCopyInto(out *LogStorageRestoreSpec) {
+	*out = *in
+	if in.Archive != nil {
+		in, out := &in.Archive, &out.Archive
+		*out = new(ArchiveRestore)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Indices != nil {
+		in, out := &in.Indices, &out.Indices
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogStorageRestoreSpec.
+func (in *LogStorageRestoreSpec) DeepCopy() *LogStorageRestoreSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LogStorageRestoreSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogStorageRestoreStatus) DeepCopyInto(out *LogStorageRestoreStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	out.ListMeta = in.ListMeta
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]LogStorage, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.PendingSnapshots != nil {
+		in, out := &in.PendingSnapshots, &out.PendingSnapshots
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogStorageList.
-func (in *LogStorageList) DeepCopy() *LogStorageList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogStorageRestoreStatus.
+func (in *LogStorageRestoreStatus) DeepCopy() *LogStorageRestoreStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(LogStorageList)
+	out := new(LogStorageRestoreStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LogStorageList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LogStorageSpec) DeepCopyInto(out *LogStorageSpec) {
 	*out = *in
@@ -749,6 +1439,26 @@ func (in *LogStorageSpec) DeepCopyInto(out *LogStorageSpec) {
 		*out = new(Retention)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.External != nil {
+		in, out := &in.External, &out.External
+		*out = new(ExternalElasticsearch)
+		**out = **in
+	}
+	if in.Snapshots != nil {
+		in, out := &in.Snapshots, &out.Snapshots
+		*out = new(Snapshots)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PasswordRotationDays != nil {
+		in, out := &in.PasswordRotationDays, &out.PasswordRotationDays
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Kibana != nil {
+		in, out := &in.Kibana, &out.Kibana
+		*out = new(Kibana)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -765,6 +1475,31 @@ func (in *LogStorageSpec) DeepCopy() *LogStorageSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LogStorageStatus) DeepCopyInto(out *LogStorageStatus) {
 	*out = *in
+	if in.IndexLifecycleErrors != nil {
+		in, out := &in.IndexLifecycleErrors, &out.IndexLifecycleErrors
+		*out = make([]IndexLifecycleError, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeSets != nil {
+		in, out := &in.NodeSets, &out.NodeSets
+		*out = make([]NodeSetStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Health != nil {
+		in, out := &in.Health, &out.Health
+		*out = new(ElasticsearchHealth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SavedObjects != nil {
+		in, out := &in.SavedObjects, &out.SavedObjects
+		*out = make([]SavedObjectsImport, len(*in))
+		copy(*out, *in)
+	}
+	if in.Archive != nil {
+		in, out := &in.Archive, &out.Archive
+		*out = new(ArchiveStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -978,6 +1713,121 @@ func (in *NodeAddressAutodetection) DeepCopy() *NodeAddressAutodetection {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeDiskUsage) DeepCopyInto(out *NodeDiskUsage) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeDiskUsage.
+func (in *NodeDiskUsage) DeepCopy() *NodeDiskUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeDiskUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeSet) DeepCopyInto(out *NodeSet) {
+	*out = *in
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]ElasticsearchNodeRole, len(*in))
+		copy(*out, *in)
+	}
+	if in.ResourceRequirements != nil {
+		in, out := &in.ResourceRequirements, &out.ResourceRequirements
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StorageSize != nil {
+		in, out := &in.StorageSize, &out.StorageSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(corev1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeSet.
+func (in *NodeSet) DeepCopy() *NodeSet {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeSetStatus) DeepCopyInto(out *NodeSetStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeSetStatus.
+func (in *NodeSetStatus) DeepCopy() *NodeSetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeSetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeUpdateStrategy) DeepCopyInto(out *NodeUpdateStrategy) {
+	*out = *in
+	if in.CanaryNodeSelector != nil {
+		in, out := &in.CanaryNodeSelector, &out.CanaryNodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.BatchSize != nil {
+		in, out := &in.BatchSize, &out.BatchSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.HealthTimeoutSeconds != nil {
+		in, out := &in.HealthTimeoutSeconds, &out.HealthTimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeUpdateStrategy.
+func (in *NodeUpdateStrategy) DeepCopy() *NodeUpdateStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeUpdateStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Nodes) DeepCopyInto(out *Nodes) {
 	*out = *in
@@ -986,6 +1836,13 @@ func (in *Nodes) DeepCopyInto(out *Nodes) {
 		*out = new(corev1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.NodeSets != nil {
+		in, out := &in.NodeSets, &out.NodeSets
+		*out = make([]NodeSet, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -1022,6 +1879,21 @@ func (in *Retention) DeepCopyInto(out *Retention) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.DNSLogs != nil {
+		in, out := &in.DNSLogs, &out.DNSLogs
+		*out = new(int32)
+		**out = **in
+	}
+	if in.IntrusionDetectionEvents != nil {
+		in, out := &in.IntrusionDetectionEvents, &out.IntrusionDetectionEvents
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ComponentLogs != nil {
+		in, out := &in.ComponentLogs, &out.ComponentLogs
+		*out = new(int32)
+		**out = **in
+	}
 	return
 }
 
@@ -1035,9 +1907,30 @@ func (in *Retention) DeepCopy() *Retention {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3SnapshotRepository) DeepCopyInto(out *S3SnapshotRepository) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3SnapshotRepository.
+func (in *S3SnapshotRepository) DeepCopy() *S3SnapshotRepository {
+	if in == nil {
+		return nil
+	}
+	out := new(S3SnapshotRepository)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *S3StoreSpec) DeepCopyInto(out *S3StoreSpec) {
 	*out = *in
+	if in.LogTypes != nil {
+		in, out := &in.LogTypes, &out.LogTypes
+		*out = make([]StoreLogType, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -1051,6 +1944,163 @@ func (in *S3StoreSpec) DeepCopy() *S3StoreSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SavedObjectsImport) DeepCopyInto(out *SavedObjectsImport) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SavedObjectsImport.
+func (in *SavedObjectsImport) DeepCopy() *SavedObjectsImport {
+	if in == nil {
+		return nil
+	}
+	out := new(SavedObjectsImport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotArchive) DeepCopyInto(out *SnapshotArchive) {
+	*out = *in
+	if in.LogTypes != nil {
+		in, out := &in.LogTypes, &out.LogTypes
+		*out = make([]ArchiveLogType, len(*in))
+		copy(*out, *in)
+	}
+	if in.RetentionDays != nil {
+		in, out := &in.RetentionDays, &out.RetentionDays
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotArchive.
+func (in *SnapshotArchive) DeepCopy() *SnapshotArchive {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotArchive)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotRepository) DeepCopyInto(out *SnapshotRepository) {
+	*out = *in
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(S3SnapshotRepository)
+		**out = **in
+	}
+	if in.GCS != nil {
+		in, out := &in.GCS, &out.GCS
+		*out = new(GCSSnapshotRepository)
+		**out = **in
+	}
+	if in.Azure != nil {
+		in, out := &in.Azure, &out.Azure
+		*out = new(AzureSnapshotRepository)
+		**out = **in
+	}
+	if in.FileSystem != nil {
+		in, out := &in.FileSystem, &out.FileSystem
+		*out = new(FileSystemSnapshotRepository)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotRepository.
+func (in *SnapshotRepository) DeepCopy() *SnapshotRepository {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotRepository)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotRetention) DeepCopyInto(out *SnapshotRetention) {
+	*out = *in
+	if in.MinCount != nil {
+		in, out := &in.MinCount, &out.MinCount
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxCount != nil {
+		in, out := &in.MaxCount, &out.MaxCount
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotRetention.
+func (in *SnapshotRetention) DeepCopy() *SnapshotRetention {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotRetention)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Snapshots) DeepCopyInto(out *Snapshots) {
+	*out = *in
+	in.Repository.DeepCopyInto(&out.Repository)
+	if in.Indices != nil {
+		in, out := &in.Indices, &out.Indices
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(SnapshotRetention)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Archive != nil {
+		in, out := &in.Archive, &out.Archive
+		*out = new(SnapshotArchive)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Snapshots.
+func (in *Snapshots) DeepCopy() *Snapshots {
+	if in == nil {
+		return nil
+	}
+	out := new(Snapshots)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SplunkStoreSpec) DeepCopyInto(out *SplunkStoreSpec) {
+	*out = *in
+	if in.LogTypes != nil {
+		in, out := &in.LogTypes, &out.LogTypes
+		*out = make([]StoreLogType, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SplunkStoreSpec.
+func (in *SplunkStoreSpec) DeepCopy() *SplunkStoreSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SplunkStoreSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SyslogStoreSpec) DeepCopyInto(out *SyslogStoreSpec) {
 	*out = *in
@@ -1059,6 +2109,11 @@ func (in *SyslogStoreSpec) DeepCopyInto(out *SyslogStoreSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.LogTypes != nil {
+		in, out := &in.LogTypes, &out.LogTypes
+		*out = make([]StoreLogType, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
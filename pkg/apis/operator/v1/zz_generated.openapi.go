@@ -28,6 +28,9 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/tigera/operator/pkg/apis/operator/v1.LogCollectorSpec":                schema_pkg_apis_operator_v1_LogCollectorSpec(ref),
 		"github.com/tigera/operator/pkg/apis/operator/v1.LogCollectorStatus":              schema_pkg_apis_operator_v1_LogCollectorStatus(ref),
 		"github.com/tigera/operator/pkg/apis/operator/v1.LogStorage":                      schema_pkg_apis_operator_v1_LogStorage(ref),
+		"github.com/tigera/operator/pkg/apis/operator/v1.LogStorageRestore":               schema_pkg_apis_operator_v1_LogStorageRestore(ref),
+		"github.com/tigera/operator/pkg/apis/operator/v1.LogStorageRestoreSpec":           schema_pkg_apis_operator_v1_LogStorageRestoreSpec(ref),
+		"github.com/tigera/operator/pkg/apis/operator/v1.LogStorageRestoreStatus":         schema_pkg_apis_operator_v1_LogStorageRestoreStatus(ref),
 		"github.com/tigera/operator/pkg/apis/operator/v1.LogStorageSpec":                  schema_pkg_apis_operator_v1_LogStorageSpec(ref),
 		"github.com/tigera/operator/pkg/apis/operator/v1.LogStorageStatus":                schema_pkg_apis_operator_v1_LogStorageStatus(ref),
 		"github.com/tigera/operator/pkg/apis/operator/v1.ManagementClusterConnection":     schema_pkg_apis_operator_v1_ManagementClusterConnection(ref),
@@ -308,6 +311,40 @@ func schema_pkg_apis_operator_v1_InstallationSpec(ref common.ReferenceCallback)
 							},
 						},
 					},
+					"imagePath": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ImagePath replaces the path of all component images, for registries that store images under a different layout. For example, with an ImagePath of \"mirror\" the calico/node image is pulled from <registry>mirror/node.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"imagePrefix": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ImagePrefix is prepended to the name of all component images. For example, with an ImagePath of \"mirror\" and an ImagePrefix of \"calico-\" the calico/node image is pulled from <registry>mirror/calico-node.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"imageReferenceMode": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ImageReferenceMode selects whether component images are referenced by digest or by tag. Default: Digest",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"imageOverrides": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ImageOverrides replaces the images used for individual components. The registry is still applied to overridden images.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/tigera/operator/pkg/apis/operator/v1.ImageOverride"),
+									},
+								},
+							},
+						},
+					},
 					"kubernetesProvider": {
 						SchemaProps: spec.SchemaProps{
 							Description: "KubernetesProvider specifies a particular provider of the Kubernetes platform. This is often auto-detected. If specified, this enables provider-specific configuration and must match the auto-detected value (if any).",
@@ -349,11 +386,17 @@ func schema_pkg_apis_operator_v1_InstallationSpec(ref common.ReferenceCallback)
 							Format:      "int32",
 						},
 					},
+					"nodeUpdateStrategy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NodeUpdateStrategy configures how calico/node is updated when its configuration or images change. If omitted, calico/node is updated with a standard rolling update across all nodes.",
+							Ref:         ref("github.com/tigera/operator/pkg/apis/operator/v1.NodeUpdateStrategy"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/tigera/operator/pkg/apis/operator/v1.CalicoNetworkSpec", "k8s.io/api/core/v1.LocalObjectReference"},
+			"github.com/tigera/operator/pkg/apis/operator/v1.CalicoNetworkSpec", "github.com/tigera/operator/pkg/apis/operator/v1.ImageOverride", "github.com/tigera/operator/pkg/apis/operator/v1.NodeUpdateStrategy", "k8s.io/api/core/v1.LocalObjectReference"},
 	}
 }
 
@@ -370,6 +413,20 @@ func schema_pkg_apis_operator_v1_InstallationStatus(ref common.ReferenceCallback
 							Format:      "",
 						},
 					},
+					"productVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ProductVersion is the most recently observed installed version of the product variant.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"operatorVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "OperatorVersion is the version of the operator that most recently installed the product.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
 			},
 		},
@@ -517,11 +574,42 @@ func schema_pkg_apis_operator_v1_LogCollectorSpec(ref common.ReferenceCallback)
 							Ref:         ref("github.com/tigera/operator/pkg/apis/operator/v1.AdditionalLogSourceSpec"),
 						},
 					},
+					"buffer": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Configuration for buffering logs before they are sent to Elasticsearch and the additional stores. Managed clusters always buffer logs in files, with the default settings if this is not set, so that logs are kept while the tunnel to the management cluster is down.",
+							Ref:         ref("github.com/tigera/operator/pkg/apis/operator/v1.FluentdBufferSpec"),
+						},
+					},
+					"resourceRequirements": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ResourceRequirements defines the CPU and memory limits and requests of each fluentd container.",
+							Ref:         ref("k8s.io/api/core/v1.ResourceRequirements"),
+						},
+					},
+					"collector": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Collector selects the log collector that runs on each node. Fluent Bit uses less memory and CPU than fluentd, but only collects flow and DNS logs and only sends them to Elasticsearch, S3 and syslog, without filters. In a managed cluster, Fluent Bit also requires the operator.tigera.io/log-collector: FluentBit annotation on the ManagedCluster resource in the management cluster, which then creates the indices that Fluent Bit writes to. Default: Fluentd",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"flowLogs": {
+						SchemaProps: spec.SchemaProps{
+							Description: "FlowLogs configures how flow logs are generated on each node. The settings that are specified are applied to the default FelixConfiguration and the others are left as they are, unless they were applied before, in which case they are reset to the Felix defaults. The destination is also set in the env of calico-node, so it takes precedence over the FelixConfiguration.",
+							Ref:         ref("github.com/tigera/operator/pkg/apis/operator/v1.FlowLogsSpec"),
+						},
+					},
+					"componentLogs": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ComponentLogs configures the collection of the logs of the components that are not part of Calico.",
+							Ref:         ref("github.com/tigera/operator/pkg/apis/operator/v1.ComponentLogsSpec"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/tigera/operator/pkg/apis/operator/v1.AdditionalLogSourceSpec", "github.com/tigera/operator/pkg/apis/operator/v1.AdditionalLogStoreSpec"},
+			"github.com/tigera/operator/pkg/apis/operator/v1.AdditionalLogSourceSpec", "github.com/tigera/operator/pkg/apis/operator/v1.AdditionalLogStoreSpec", "github.com/tigera/operator/pkg/apis/operator/v1.ComponentLogsSpec", "github.com/tigera/operator/pkg/apis/operator/v1.FlowLogsSpec", "github.com/tigera/operator/pkg/apis/operator/v1.FluentdBufferSpec", "k8s.io/api/core/v1.ResourceRequirements"},
 	}
 }
 
@@ -538,10 +626,30 @@ func schema_pkg_apis_operator_v1_LogCollectorStatus(ref common.ReferenceCallback
 							Format:      "",
 						},
 					},
+					"bufferOverflows": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BufferOverflows lists the fluentd outputs whose buffers are full, as reported by the fluentd monitor.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/tigera/operator/pkg/apis/operator/v1.FluentdBufferOverflow"),
+									},
+								},
+							},
+						},
+					},
+					"flowLogs": {
+						SchemaProps: spec.SchemaProps{
+							Description: "FlowLogs are the flow log settings in effect in the default FelixConfiguration, with defaults filled in.",
+							Ref:         ref("github.com/tigera/operator/pkg/apis/operator/v1.FlowLogsSpec"),
+						},
+					},
 				},
 			},
 		},
-		Dependencies: []string{},
+		Dependencies: []string{
+			"github.com/tigera/operator/pkg/apis/operator/v1.FlowLogsSpec", "github.com/tigera/operator/pkg/apis/operator/v1.FluentdBufferOverflow"},
 	}
 }
 
@@ -590,6 +698,147 @@ func schema_pkg_apis_operator_v1_LogStorage(ref common.ReferenceCallback) common
 	}
 }
 
+func schema_pkg_apis_operator_v1_LogStorageRestore(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "LogStorageRestore restores indices from a snapshot taken of the Elasticsearch cluster of the LogStorage. Each restore is started once, when the resource is created. Progress and failures are reported in its status and in the log-storage TigeraStatus.",
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Specification of the restore.",
+							Ref:         ref("github.com/tigera/operator/pkg/apis/operator/v1.LogStorageRestoreSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Most recently observed state of the restore.",
+							Ref:         ref("github.com/tigera/operator/pkg/apis/operator/v1.LogStorageRestoreStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tigera/operator/pkg/apis/operator/v1.LogStorageRestoreSpec", "github.com/tigera/operator/pkg/apis/operator/v1.LogStorageRestoreStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_pkg_apis_operator_v1_LogStorageRestoreSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "LogStorageRestoreSpec defines the snapshot and the indices to restore. Exactly one of Snapshot or Archive must be set.",
+				Properties: map[string]spec.Schema{
+					"snapshot": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Snapshot is the name of the snapshot, in the snapshot repository configured on the LogStorage, that indices are restored from.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"archive": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Archive restores the archived logs of a log type from a period of time. The indices holding them are restored with their original names, so they are searched along with the logs that have not been archived. Restored indices are not archived or deleted again and must be deleted manually.",
+							Ref:         ref("github.com/tigera/operator/pkg/apis/operator/v1.ArchiveRestore"),
+						},
+					},
+					"indices": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Indices are the names or wildcard patterns of the indices to restore. Default: all the indices in the snapshot",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"renamePattern": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RenamePattern is a regular expression matched against the names of the restored indices. An index can only be restored over an index with the same name if that index is closed, so indices that still exist must be renamed.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"renameReplacement": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RenameReplacement replaces the part of the names of the restored indices matched by RenamePattern. It may reference the groups of RenamePattern, for example restored_$1.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/tigera/operator/pkg/apis/operator/v1.ArchiveRestore"},
+	}
+}
+
+func schema_pkg_apis_operator_v1_LogStorageRestoreStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "LogStorageRestoreStatus defines the observed state of the restore.",
+				Properties: map[string]spec.Schema{
+					"state": {
+						SchemaProps: spec.SchemaProps{
+							Description: "State is InProgress while the indices are restored, then Completed or Failed.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Message describes the progress of the restore or why it failed.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"pendingSnapshots": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PendingSnapshots are the archived snapshots that have not been restored yet, for an archive restore. The first is being restored.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{},
+	}
+}
+
 func schema_pkg_apis_operator_v1_LogStorageSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -598,7 +847,7 @@ func schema_pkg_apis_operator_v1_LogStorageSpec(ref common.ReferenceCallback) co
 				Properties: map[string]spec.Schema{
 					"nodes": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Nodes defines the configuration for a set of identical Elasticsearch cluster nodes, each of type master, data, and ingest.",
+							Description: "Nodes defines the configuration of the Elasticsearch cluster nodes.",
 							Ref:         ref("github.com/tigera/operator/pkg/apis/operator/v1.Nodes"),
 						},
 					},
@@ -614,11 +863,36 @@ func schema_pkg_apis_operator_v1_LogStorageSpec(ref common.ReferenceCallback) co
 							Ref:         ref("github.com/tigera/operator/pkg/apis/operator/v1.Retention"),
 						},
 					},
+					"external": {
+						SchemaProps: spec.SchemaProps{
+							Description: "External configures log storage to use an existing Elasticsearch cluster instead of one installed by the operator. When set, ECK, the Elasticsearch cluster and Kibana are not installed and Nodes is ignored.",
+							Ref:         ref("github.com/tigera/operator/pkg/apis/operator/v1.ExternalElasticsearch"),
+						},
+					},
+					"snapshots": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Snapshots configures a repository for snapshots of the Elasticsearch indices and when snapshots are taken and deleted. Indices are restored from the snapshots with a LogStorageRestore.",
+							Ref:         ref("github.com/tigera/operator/pkg/apis/operator/v1.Snapshots"),
+						},
+					},
+					"passwordRotationDays": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PasswordRotationDays is the number of days after which the operator replaces the password of the Elasticsearch user of each component. The new password is set on a second user of the component, and the previous user is disabled once the component has been restarted to use it. Passwords are not replaced if it is 0. Default: 90",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"kibana": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kibana configures the Kibana instance installed with the Elasticsearch cluster. It is ignored when External is set.",
+							Ref:         ref("github.com/tigera/operator/pkg/apis/operator/v1.Kibana"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/tigera/operator/pkg/apis/operator/v1.Indices", "github.com/tigera/operator/pkg/apis/operator/v1.Nodes", "github.com/tigera/operator/pkg/apis/operator/v1.Retention"},
+			"github.com/tigera/operator/pkg/apis/operator/v1.ExternalElasticsearch", "github.com/tigera/operator/pkg/apis/operator/v1.Indices", "github.com/tigera/operator/pkg/apis/operator/v1.Kibana", "github.com/tigera/operator/pkg/apis/operator/v1.Nodes", "github.com/tigera/operator/pkg/apis/operator/v1.Retention", "github.com/tigera/operator/pkg/apis/operator/v1.Snapshots"},
 	}
 }
 
@@ -649,10 +923,62 @@ func schema_pkg_apis_operator_v1_LogStorageStatus(ref common.ReferenceCallback)
 							Format:      "",
 						},
 					},
+					"indexLifecycleErrors": {
+						SchemaProps: spec.SchemaProps{
+							Description: "IndexLifecycleErrors lists the indices whose index lifecycle management, which enforces retention, has failed.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/tigera/operator/pkg/apis/operator/v1.IndexLifecycleError"),
+									},
+								},
+							},
+						},
+					},
+					"nodeSets": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NodeSets reports the number of nodes of each node set of the Elasticsearch cluster and the progress of changes to the number of nodes and their storage.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/tigera/operator/pkg/apis/operator/v1.NodeSetStatus"),
+									},
+								},
+							},
+						},
+					},
+					"health": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Health reports the health of the Elasticsearch cluster and the disk usage of its data nodes, as last polled by the operator.",
+							Ref:         ref("github.com/tigera/operator/pkg/apis/operator/v1.ElasticsearchHealth"),
+						},
+					},
+					"savedObjects": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SavedObjects reports the import of the saved objects in each ConfigMap listed in the Kibana spec and its spaces.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/tigera/operator/pkg/apis/operator/v1.SavedObjectsImport"),
+									},
+								},
+							},
+						},
+					},
+					"archive": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Archive reports the logs archived into the snapshot repository.",
+							Ref:         ref("github.com/tigera/operator/pkg/apis/operator/v1.ArchiveStatus"),
+						},
+					},
 				},
 			},
 		},
-		Dependencies: []string{},
+		Dependencies: []string{
+			"github.com/tigera/operator/pkg/apis/operator/v1.ArchiveStatus", "github.com/tigera/operator/pkg/apis/operator/v1.ElasticsearchHealth", "github.com/tigera/operator/pkg/apis/operator/v1.IndexLifecycleError", "github.com/tigera/operator/pkg/apis/operator/v1.NodeSetStatus", "github.com/tigera/operator/pkg/apis/operator/v1.SavedObjectsImport"},
 	}
 }
 
@@ -826,6 +1152,27 @@ func schema_pkg_apis_operator_v1_S3StoreSpec(ref common.ReferenceCallback) commo
 							Format:      "",
 						},
 					},
+					"logTypes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LogTypes are the types of logs that are sent to this store. Default: Flows, DNS, EEAudit, KubeAudit",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"filter": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Filter is fluentd configuration, one or more <filter> directives, that is applied to logs before they are sent to this store.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
 				Required: []string{"region", "bucketName", "bucketPath"},
 			},
@@ -0,0 +1,49 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package components
+
+// All lists every component image the operator can deploy. Components added to calico.go or
+// enterprise.go must also be added here so that they are included when listing images for mirroring.
+var All = []component{
+	ComponentCalicoCNI,
+	ComponentCalicoKubeControllers,
+	ComponentCalicoNode,
+	ComponentCalicoTypha,
+	ComponentFlexVolume,
+	ComponentOperatorInit,
+
+	ComponentAPIServer,
+	ComponentComplianceBenchmarker,
+	ComponentComplianceController,
+	ComponentComplianceReporter,
+	ComponentComplianceServer,
+	ComponentComplianceSnapshotter,
+	ComponentEckKibana,
+	ComponentElasticTseeInstaller,
+	ComponentElasticsearch,
+	ComponentElasticsearchOperator,
+	ComponentEsProxy,
+	ComponentFluentd,
+	ComponentFluentBit,
+	ComponentGuardian,
+	ComponentIntrusionDetectionController,
+	ComponentKibana,
+	ComponentManager,
+	ComponentManagerProxy,
+	ComponentQueryServer,
+	ComponentTigeraKubeControllers,
+	ComponentTigeraNode,
+	ComponentTigeraTypha,
+}
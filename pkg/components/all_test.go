@@ -0,0 +1,83 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package components
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Component registry", func() {
+	It("should include every component declared in the package", func() {
+		fset := token.NewFileSet()
+		pkgs, err := parser.ParseDir(fset, ".", nil, 0)
+		Expect(err).NotTo(HaveOccurred())
+
+		// Collect the names of all package level variables initialized with a component literal.
+		declared := []string{}
+		for _, f := range pkgs["components"].Files {
+			for _, decl := range f.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.VAR {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					vs := spec.(*ast.ValueSpec)
+					for i, name := range vs.Names {
+						if i >= len(vs.Values) {
+							continue
+						}
+						if lit, ok := vs.Values[i].(*ast.CompositeLit); ok {
+							if t, ok := lit.Type.(*ast.Ident); ok && t.Name == "component" {
+								declared = append(declared, name.Name)
+							}
+						}
+					}
+				}
+			}
+		}
+		Expect(declared).NotTo(BeEmpty())
+
+		// The names in All are checked through the source of all.go so that a missing
+		// component is reported by name.
+		listed := map[string]bool{}
+		for _, f := range pkgs["components"].Files {
+			ast.Inspect(f, func(n ast.Node) bool {
+				vs, ok := n.(*ast.ValueSpec)
+				if !ok || len(vs.Names) != 1 || vs.Names[0].Name != "All" {
+					return true
+				}
+				for _, elt := range vs.Values[0].(*ast.CompositeLit).Elts {
+					listed[elt.(*ast.Ident).Name] = true
+				}
+				return false
+			})
+		}
+
+		missing := []string{}
+		for _, name := range declared {
+			if !listed[name] {
+				missing = append(missing, name)
+			}
+		}
+		Expect(missing).To(BeEmpty(), "components missing from All: %s", strings.Join(missing, ", "))
+		Expect(All).To(HaveLen(len(declared)))
+	})
+})
@@ -86,13 +86,6 @@ var (
 	}
 	
 	
-	ComponentEsCurator = component{
-		Version: "v2.7.0",
-		Digest:  "sha256:34a8486c7e0d646b17c788adb4bafd41d567a85be5c75f4bfe1d650ebc85d475",
-		Image:   "tigera/es-curator",
-	}
-	
-	
 	ComponentEsProxy = component{
 		Version: "v2.7.0",
 		Digest:  "sha256:9da0f6f55431b9d6737bd915083cf51e09fe537b2072681e8b88a0cee5695259",
@@ -107,6 +100,13 @@ var (
 	}
 	
 	
+	ComponentFluentBit = component{
+		Version: "v2.7.0",
+		Digest:  "",
+		Image:   "tigera/fluent-bit",
+	}
+	
+	
 	ComponentGuardian = component{
 		Version: "v2.7.0",
 		Digest:  "sha256:80db830bbac524904d6837ebdf66dadfe337c7ee26aabcf746fef1b7d82cb749",
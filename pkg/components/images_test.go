@@ -17,28 +17,88 @@ package components
 import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+
+	operator "github.com/tigera/operator/pkg/apis/operator/v1"
 )
 
 var _ = Describe("No registry override", func() {
 	It("should render a calico image correctly", func() {
-		Expect(GetReference(ComponentCalicoNode, "")).To(Equal("docker.io/calico/node:" + ComponentCalicoNode.Digest))
+		Expect(GetReference(ComponentCalicoNode, nil)).To(Equal("docker.io/calico/node@" + ComponentCalicoNode.Digest))
 	})
 	It("should render a tigera image correctly", func() {
-		Expect(GetReference(ComponentTigeraNode, "")).To(Equal("gcr.io/unique-caldron-775/cnx/tigera/cnx-node:" + ComponentTigeraNode.Digest))
+		Expect(GetReference(ComponentTigeraNode, nil)).To(Equal("gcr.io/unique-caldron-775/cnx/tigera/cnx-node@" + ComponentTigeraNode.Digest))
 	})
 	It("should render an ECK image correctly", func() {
-		Expect(GetReference(ComponentElasticsearchOperator, "")).To(Equal("docker.elastic.co/eck/eck-operator:" + ComponentElasticsearchOperator.Digest))
+		Expect(GetReference(ComponentElasticsearchOperator, nil)).To(Equal("docker.elastic.co/eck/eck-operator@" + ComponentElasticsearchOperator.Digest))
+	})
+	It("should reference an image whose digest gen-versions could not get by tag", func() {
+		c := component{Version: "v2.7.0", Image: "tigera/fluent-bit"}
+		Expect(GetReference(c, nil)).To(Equal("gcr.io/unique-caldron-775/cnx/tigera/fluent-bit:v2.7.0"))
 	})
 })
 
 var _ = Describe("registry override", func() {
+	installation := &operator.Installation{Spec: operator.InstallationSpec{Registry: "quay.io/"}}
+
 	It("should render a calico image correctly", func() {
-		Expect(GetReference(ComponentCalicoNode, "quay.io/")).To(Equal("quay.io/calico/node:" + ComponentCalicoNode.Digest))
+		Expect(GetReference(ComponentCalicoNode, installation)).To(Equal("quay.io/calico/node@" + ComponentCalicoNode.Digest))
 	})
 	It("should render a tigera image correctly", func() {
-		Expect(GetReference(ComponentTigeraNode, "quay.io/")).To(Equal("quay.io/tigera/cnx-node:" + ComponentTigeraNode.Digest))
+		Expect(GetReference(ComponentTigeraNode, installation)).To(Equal("quay.io/tigera/cnx-node@" + ComponentTigeraNode.Digest))
 	})
 	It("should render an ECK image correctly", func() {
-		Expect(GetReference(ComponentElasticsearchOperator, "quay.io/")).To(Equal("quay.io/eck/eck-operator:" + ComponentElasticsearchOperator.Digest))
+		Expect(GetReference(ComponentElasticsearchOperator, installation)).To(Equal("quay.io/eck/eck-operator@" + ComponentElasticsearchOperator.Digest))
+	})
+	It("should render the operator init image correctly", func() {
+		Expect(GetOperatorInitReference(installation)).To(Equal("quay.io/tigera/operator-init:" + ComponentOperatorInit.Version))
+	})
+})
+
+var _ = Describe("image path and reference mode", func() {
+	var installation *operator.Installation
+
+	BeforeEach(func() {
+		installation = &operator.Installation{Spec: operator.InstallationSpec{Registry: "mirror.io/"}}
+	})
+
+	It("should replace the image path and prepend the prefix", func() {
+		installation.Spec.ImagePath = "mirror"
+		installation.Spec.ImagePrefix = "calico-"
+		Expect(GetReference(ComponentCalicoNode, installation)).To(Equal("mirror.io/mirror/calico-node@" + ComponentCalicoNode.Digest))
+		Expect(GetOperatorInitReference(installation)).To(Equal("mirror.io/mirror/calico-operator-init:" + ComponentOperatorInit.Version))
+	})
+
+	It("should reference images by tag", func() {
+		installation.Spec.ImageReferenceMode = operator.ImageReferenceModeTag
+		Expect(GetReference(ComponentCalicoNode, installation)).To(Equal("mirror.io/calico/node:" + ComponentCalicoNode.Version))
+	})
+})
+
+var _ = Describe("image overrides", func() {
+	var installation *operator.Installation
+
+	BeforeEach(func() {
+		installation = &operator.Installation{Spec: operator.InstallationSpec{ImagePath: "mirror"}}
+	})
+
+	It("should use the overridden image without applying the image path", func() {
+		installation.Spec.ImageOverrides = []operator.ImageOverride{{Component: "calico/node", Image: "custom/node"}}
+		Expect(GetReference(ComponentCalicoNode, installation)).To(Equal("docker.io/custom/node@" + ComponentCalicoNode.Digest))
+		Expect(GetReference(ComponentCalicoTypha, installation)).To(Equal("docker.io/mirror/typha@" + ComponentCalicoTypha.Digest))
+	})
+
+	It("should reference an overridden version without a digest by tag", func() {
+		installation.Spec.ImageOverrides = []operator.ImageOverride{{Component: "calico/node", Version: "v3.12.1"}}
+		Expect(GetReference(ComponentCalicoNode, installation)).To(Equal("docker.io/mirror/node:v3.12.1"))
+	})
+
+	It("should use an overridden digest", func() {
+		installation.Spec.ImageOverrides = []operator.ImageOverride{{Component: "calico/node", Version: "v3.12.1", Digest: "sha256:abc"}}
+		Expect(GetReference(ComponentCalicoNode, installation)).To(Equal("docker.io/mirror/node@sha256:abc"))
+	})
+
+	It("should reference the operator init image by an overridden digest", func() {
+		installation.Spec.ImageOverrides = []operator.ImageOverride{{Component: "tigera/operator-init", Digest: "sha256:def"}}
+		Expect(GetOperatorInitReference(installation)).To(Equal("gcr.io/unique-caldron-775/cnx/mirror/operator-init@sha256:def"))
 	})
 })
@@ -0,0 +1,121 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package components
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	operator "github.com/tigera/operator/pkg/apis/operator/v1"
+)
+
+// ImageFormat is the output format of PrintImages.
+type ImageFormat string
+
+const (
+	// ImageFormatPlain prints one fully qualified image reference per line.
+	ImageFormatPlain ImageFormat = "plain"
+	// ImageFormatJSON prints a JSON list describing each image.
+	ImageFormatJSON ImageFormat = "json"
+	// ImageFormatSkopeo prints a source file for "skopeo sync --src yaml".
+	ImageFormatSkopeo ImageFormat = "skopeo"
+)
+
+// Image describes the image of a component.
+type Image struct {
+	Reference string `json:"reference"`
+	Registry  string `json:"registry"`
+	Image     string `json:"image"`
+	Version   string `json:"version"`
+	Digest    string `json:"digest,omitempty"`
+}
+
+// skopeoRegistry is the source configuration of a single registry for "skopeo sync".
+type skopeoRegistry struct {
+	Images map[string][]string `yaml:"images"`
+}
+
+// GetImages returns the images of all components. If registry is not empty, it replaces the default
+// registry of every component.
+func GetImages(registry string) []Image {
+	if registry != "" && !strings.HasSuffix(registry, "/") {
+		registry = registry + "/"
+	}
+	installation := &operator.Installation{Spec: operator.InstallationSpec{Registry: registry}}
+
+	images := []Image{}
+	for _, c := range All {
+		r := registry
+		if r == "" {
+			r = defaultRegistry(c)
+		}
+		images = append(images, Image{
+			Reference: GetReference(c, installation),
+			Registry:  r,
+			Image:     c.Image,
+			Version:   c.Version,
+			Digest:    c.Digest,
+		})
+	}
+	return images
+}
+
+// PrintImages writes the images of all components to w in the given format. If registry is not
+// empty, it replaces the default registry of every component.
+func PrintImages(w io.Writer, format ImageFormat, registry string) error {
+	images := GetImages(registry)
+
+	switch format {
+	case ImageFormatPlain:
+		for _, i := range images {
+			if _, err := fmt.Fprintln(w, i.Reference); err != nil {
+				return err
+			}
+		}
+		return nil
+	case ImageFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(images)
+	case ImageFormatSkopeo:
+		// skopeo expects the registry host as the key, with any path of the registry as part
+		// of the image name.
+		sync := map[string]skopeoRegistry{}
+		for _, i := range images {
+			host, path := i.Registry, ""
+			if idx := strings.Index(i.Registry, "/"); idx >= 0 {
+				host, path = i.Registry[:idx], i.Registry[idx+1:]
+			}
+			r, ok := sync[host]
+			if !ok {
+				r = skopeoRegistry{Images: map[string][]string{}}
+				sync[host] = r
+			}
+			r.Images[path+i.Image] = append(r.Images[path+i.Image], i.Version)
+		}
+		out, err := yaml.Marshal(sync)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(out)
+		return err
+	default:
+		return fmt.Errorf("unknown image format %q, should be one of %s, %s, %s", format, ImageFormatPlain, ImageFormatJSON, ImageFormatSkopeo)
+	}
+}
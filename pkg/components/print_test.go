@@ -0,0 +1,64 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package components
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
+)
+
+var _ = Describe("Printing images", func() {
+	var out *bytes.Buffer
+
+	BeforeEach(func() {
+		out = &bytes.Buffer{}
+	})
+
+	It("should print one reference per component", func() {
+		Expect(PrintImages(out, ImageFormatPlain, "")).NotTo(HaveOccurred())
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		Expect(lines).To(HaveLen(len(All)))
+		Expect(lines).To(ContainElement("docker.io/calico/node@" + ComponentCalicoNode.Digest))
+		Expect(lines).To(ContainElement("docker.elastic.co/eck/eck-operator@" + ComponentElasticsearchOperator.Digest))
+	})
+
+	It("should apply the registry override", func() {
+		Expect(PrintImages(out, ImageFormatJSON, "mirror.io")).NotTo(HaveOccurred())
+		images := []Image{}
+		Expect(json.Unmarshal(out.Bytes(), &images)).NotTo(HaveOccurred())
+		Expect(images).To(HaveLen(len(All)))
+		for _, i := range images {
+			Expect(i.Registry).To(Equal("mirror.io/"))
+			Expect(i.Reference).To(HavePrefix("mirror.io/" + i.Image))
+		}
+	})
+
+	It("should print a skopeo sync source file", func() {
+		Expect(PrintImages(out, ImageFormatSkopeo, "")).NotTo(HaveOccurred())
+		sync := map[string]skopeoRegistry{}
+		Expect(yaml.Unmarshal(out.Bytes(), &sync)).NotTo(HaveOccurred())
+		Expect(sync["docker.io"].Images["calico/node"]).To(Equal([]string{ComponentCalicoNode.Version}))
+		Expect(sync["gcr.io"].Images["unique-caldron-775/cnx/tigera/cnx-node"]).To(Equal([]string{ComponentTigeraNode.Version}))
+	})
+
+	It("should reject an unknown format", func() {
+		Expect(PrintImages(out, "xml", "")).To(HaveOccurred())
+	})
+})
@@ -15,6 +15,9 @@ package components
 
 import (
 	"fmt"
+	"strings"
+
+	operator "github.com/tigera/operator/pkg/apis/operator/v1"
 )
 
 type component struct {
@@ -23,27 +26,27 @@ type component struct {
 	Digest  string
 }
 
-// GetReference returns the fully qualified image to use, including registry and version.
-func GetReference(c component, registry string) string {
+// GetReference returns the fully qualified image to use, including registry and version. The registry,
+// image path and prefix, reference mode and image overrides of the installation are applied. A nil
+// installation uses the defaults.
+func GetReference(c component, installation *operator.Installation) string {
+	var spec operator.InstallationSpec
+	if installation != nil {
+		spec = installation.Spec
+	}
+
 	// If a user did not supply a registry, use the default registry
 	// based on component
+	registry := spec.Registry
 	if registry == "" {
-		switch c {
-		case ComponentCalicoNode,
-			ComponentCalicoCNI,
-			ComponentCalicoTypha,
-			ComponentCalicoKubeControllers,
-			ComponentFlexVolume:
-
-			registry = CalicoRegistry
-		case ComponentElasticsearch, ComponentElasticsearchOperator:
-			registry = ECKRegistry
-		default:
-			registry = TigeraRegistry
-		}
+		registry = defaultRegistry(c)
 	}
 
-	return fmt.Sprintf("%s%s@%s", registry, c.Image, c.Digest)
+	image, version, digest := resolveImage(c, spec)
+	if digest == "" || spec.ImageReferenceMode == operator.ImageReferenceModeTag {
+		return fmt.Sprintf("%s%s:%s", registry, image, version)
+	}
+	return fmt.Sprintf("%s%s@%s", registry, image, digest)
 }
 
 // GetOperatorInitReference returns the fully qualified image to use, including registry and version
@@ -52,12 +55,83 @@ func GetReference(c component, registry string) string {
 // Deprecated: GetOperatorInitReference exists to solve a complex dependency where the digest of the operator
 // init image isn't known until it's built, and it's code and docker image share this repository. This function
 // should go away once operatorInit logic is moved into the operator.
-func GetOperatorInitReference(registry string) string {
+func GetOperatorInitReference(installation *operator.Installation) string {
+	var spec operator.InstallationSpec
+	if installation != nil {
+		spec = installation.Spec
+	}
+
 	// If a user did not supply a registry, use the default registry
 	// based on component
+	registry := spec.Registry
 	if registry == "" {
 		registry = TigeraRegistry
 	}
 
-	return fmt.Sprintf("%s%s:%s", registry, ComponentOperatorInit.Image, ComponentOperatorInit.Version)
+	// The digest is only known if it is supplied through an override.
+	image, version, digest := resolveImage(ComponentOperatorInit, spec)
+	if digest == "" || spec.ImageReferenceMode == operator.ImageReferenceModeTag {
+		return fmt.Sprintf("%s%s:%s", registry, image, version)
+	}
+	return fmt.Sprintf("%s%s@%s", registry, image, digest)
+}
+
+// defaultRegistry returns the registry the component is pulled from if the installation does not
+// specify one.
+func defaultRegistry(c component) string {
+	switch c {
+	case ComponentCalicoNode,
+		ComponentCalicoCNI,
+		ComponentCalicoTypha,
+		ComponentCalicoKubeControllers,
+		ComponentFlexVolume:
+
+		return CalicoRegistry
+	case ComponentElasticsearch, ComponentElasticsearchOperator:
+		return ECKRegistry
+	default:
+		return TigeraRegistry
+	}
+}
+
+// resolveImage returns the image, version and digest to use for the component after applying the
+// image overrides, path and prefix of the installation. The returned digest is empty if the image
+// must be referenced by tag.
+func resolveImage(c component, spec operator.InstallationSpec) (image, version, digest string) {
+	image, version, digest = ApplyImagePath(c.Image, spec.ImagePath, spec.ImagePrefix), c.Version, c.Digest
+
+	for _, o := range spec.ImageOverrides {
+		if o.Component != c.Image {
+			continue
+		}
+		if o.Image != "" {
+			image = o.Image
+		}
+		if o.Version != "" {
+			// The default digest belongs to the default version.
+			version, digest = o.Version, ""
+		}
+		if o.Digest != "" {
+			digest = o.Digest
+		}
+		break
+	}
+	return image, version, digest
+}
+
+// ApplyImagePath replaces the path of the image with path, if set, and prepends prefix to its name. It is
+// also used by hack/gen-versions to validate image overrides in the same way.
+func ApplyImagePath(image, path, prefix string) string {
+	dir, name := "", image
+	if i := strings.LastIndex(image, "/"); i >= 0 {
+		dir, name = image[:i], image[i+1:]
+	}
+	if path != "" {
+		dir = strings.Trim(path, "/")
+	}
+	name = prefix + name
+	if dir == "" {
+		return name
+	}
+	return dir + "/" + name
 }
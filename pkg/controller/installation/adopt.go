@@ -0,0 +1,163 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installation
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-logr/logr"
+
+	operator "github.com/tigera/operator/pkg/apis/operator/v1"
+	"github.com/tigera/operator/pkg/controller/utils"
+)
+
+// adoptManifestInstall carries the configuration of a Calico installation made from manifests over into
+// the Installation before it is defaulted, so that moving the components out of kube-system does not
+// change the networking configuration of the cluster. It returns true if the Installation was updated.
+// An error is returned if the existing installation uses settings that cannot be represented by the
+// Installation API, or that conflict with what has been set on the Installation.
+func (r *ReconcileInstallation) adoptManifestInstall(ctx context.Context, log logr.Logger) (bool, error) {
+	needNsMigration, err := r.namespaceMigration.NeedsCoreNamespaceMigration()
+	if err != nil || !needNsMigration {
+		return false, err
+	}
+
+	adopted, report, err := r.namespaceMigration.AdoptExistingInstall()
+	if err != nil {
+		return false, err
+	}
+	if !report.Compatible() {
+		return false, fmt.Errorf("existing installation has settings that cannot be migrated: %s", strings.Join(report.Unmapped, "; "))
+	}
+
+	// Work on the Installation as it was written by the user, not the defaulted version.
+	instance := &operator.Installation{}
+	if err := r.client.Get(ctx, utils.DefaultInstanceKey, instance); err != nil {
+		return false, err
+	}
+
+	updated, err := mergeAdoptedSpec(&instance.Spec, adopted)
+	if err != nil {
+		return false, err
+	}
+	if !updated {
+		return false, nil
+	}
+
+	log.Info("Adopting configuration of existing Calico installation", "inferred", report.Inferred)
+	if err := r.client.Update(ctx, instance); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// mergeAdoptedSpec fills in the fields of spec that are not set from the adopted spec. It returns true if spec
+// was modified, and an error if a field that is set does not match the adopted configuration.
+func mergeAdoptedSpec(spec, adopted *operator.InstallationSpec) (bool, error) {
+	if adopted.CalicoNetwork == nil {
+		return false, nil
+	}
+	if spec.CalicoNetwork == nil {
+		spec.CalicoNetwork = adopted.CalicoNetwork.DeepCopy()
+		return true, nil
+	}
+
+	cn := spec.CalicoNetwork
+	acn := adopted.CalicoNetwork
+	updated := false
+
+	if acn.MTU != nil {
+		if cn.MTU == nil {
+			cn.MTU = acn.MTU
+			updated = true
+		} else if *cn.MTU != *acn.MTU {
+			return false, fmt.Errorf("spec.calicoNetwork.mtu %d does not match existing MTU %d", *cn.MTU, *acn.MTU)
+		}
+	}
+
+	if acn.NodeAddressAutodetectionV4 != nil {
+		if cn.NodeAddressAutodetectionV4 == nil {
+			cn.NodeAddressAutodetectionV4 = acn.NodeAddressAutodetectionV4
+			updated = true
+		} else if !reflect.DeepEqual(cn.NodeAddressAutodetectionV4, acn.NodeAddressAutodetectionV4) {
+			return false, fmt.Errorf("spec.calicoNetwork.nodeAddressAutodetectionV4 does not match existing IP auto-detection method")
+		}
+	}
+
+	if acn.NodeAddressAutodetectionV6 != nil {
+		if cn.NodeAddressAutodetectionV6 == nil {
+			cn.NodeAddressAutodetectionV6 = acn.NodeAddressAutodetectionV6
+			updated = true
+		} else if !reflect.DeepEqual(cn.NodeAddressAutodetectionV6, acn.NodeAddressAutodetectionV6) {
+			return false, fmt.Errorf("spec.calicoNetwork.nodeAddressAutodetectionV6 does not match existing IPv6 auto-detection method")
+		}
+	}
+
+	if acn.FlexVolInitContainerEnabled != nil {
+		if cn.FlexVolInitContainerEnabled == nil {
+			cn.FlexVolInitContainerEnabled = acn.FlexVolInitContainerEnabled
+			updated = true
+		} else if *cn.FlexVolInitContainerEnabled != *acn.FlexVolInitContainerEnabled {
+			return false, fmt.Errorf("spec.calicoNetwork.flexVolInitContainerEnabled does not match existing installation")
+		}
+	}
+
+	if acn.IPPools != nil {
+		if cn.IPPools == nil {
+			cn.IPPools = acn.IPPools
+			updated = true
+		} else if err := comparePools(cn.IPPools, acn.IPPools); err != nil {
+			return false, err
+		}
+	}
+
+	return updated, nil
+}
+
+// comparePools returns an error if the pools do not match the adopted pools. Only the fields that were
+// inferred from the existing installation are compared, since the others may have been defaulted.
+func comparePools(pools, adopted []operator.IPPool) error {
+	if len(pools) != len(adopted) {
+		return fmt.Errorf("spec.calicoNetwork.ipPools has %d pools, existing installation has %d", len(pools), len(adopted))
+	}
+
+	for _, a := range adopted {
+		var p *operator.IPPool
+		for i := range pools {
+			if pools[i].CIDR == a.CIDR {
+				p = &pools[i]
+			}
+		}
+		if p == nil {
+			return fmt.Errorf("spec.calicoNetwork.ipPools does not contain existing IP pool %s", a.CIDR)
+		}
+		if a.Encapsulation != "" && p.Encapsulation != "" && a.Encapsulation != p.Encapsulation {
+			return fmt.Errorf("IP pool %s encapsulation %s does not match existing %s", a.CIDR, p.Encapsulation, a.Encapsulation)
+		}
+		if a.NATOutgoing != "" && p.NATOutgoing != "" && a.NATOutgoing != p.NATOutgoing {
+			return fmt.Errorf("IP pool %s natOutgoing %s does not match existing %s", a.CIDR, p.NATOutgoing, a.NATOutgoing)
+		}
+		if a.NodeSelector != "" && p.NodeSelector != "" && a.NodeSelector != p.NodeSelector {
+			return fmt.Errorf("IP pool %s nodeSelector %s does not match existing %s", a.CIDR, p.NodeSelector, a.NodeSelector)
+		}
+		if a.BlockSize != nil && p.BlockSize != nil && *a.BlockSize != *p.BlockSize {
+			return fmt.Errorf("IP pool %s blockSize %d does not match existing %d", a.CIDR, *p.BlockSize, *a.BlockSize)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installation
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	operator "github.com/tigera/operator/pkg/apis/operator/v1"
+)
+
+var _ = Describe("Merging an adopted manifest installation", func() {
+	var adopted *operator.InstallationSpec
+	var mtu int32 = 1440
+
+	BeforeEach(func() {
+		adopted = &operator.InstallationSpec{
+			CalicoNetwork: &operator.CalicoNetworkSpec{
+				MTU: &mtu,
+				IPPools: []operator.IPPool{{
+					CIDR:          "10.244.0.0/16",
+					Encapsulation: operator.EncapsulationVXLAN,
+				}},
+			},
+		}
+	})
+
+	It("should fill in an empty Installation", func() {
+		spec := &operator.InstallationSpec{}
+		updated, err := mergeAdoptedSpec(spec, adopted)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated).To(BeTrue())
+		Expect(spec.CalicoNetwork).To(Equal(adopted.CalicoNetwork))
+	})
+
+	It("should accept a defaulted Installation that matches", func() {
+		var blockSize int32 = 26
+		spec := &operator.InstallationSpec{
+			CalicoNetwork: &operator.CalicoNetworkSpec{
+				MTU: &mtu,
+				IPPools: []operator.IPPool{{
+					CIDR:          "10.244.0.0/16",
+					Encapsulation: operator.EncapsulationVXLAN,
+					NATOutgoing:   operator.NATOutgoingEnabled,
+					BlockSize:     &blockSize,
+				}},
+			},
+		}
+		updated, err := mergeAdoptedSpec(spec, adopted)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated).To(BeFalse())
+	})
+
+	It("should reject a conflicting IP pool", func() {
+		spec := &operator.InstallationSpec{
+			CalicoNetwork: &operator.CalicoNetworkSpec{
+				IPPools: []operator.IPPool{{CIDR: "192.168.0.0/16"}},
+			},
+		}
+		_, err := mergeAdoptedSpec(spec, adopted)
+		Expect(err).To(MatchError("spec.calicoNetwork.ipPools does not contain existing IP pool 10.244.0.0/16"))
+	})
+})
@@ -30,6 +30,7 @@ import (
 	"github.com/tigera/operator/pkg/controller/status"
 	"github.com/tigera/operator/pkg/controller/utils"
 	"github.com/tigera/operator/pkg/render"
+	"github.com/tigera/operator/version"
 
 	configv1 "github.com/openshift/api/config/v1"
 
@@ -80,6 +81,7 @@ func newReconciler(mgr manager.Manager, provider operator.Provider, tsee bool) (
 		status:               status.New(mgr.GetClient(), "calico"),
 		typhaAutoscaler:      newTyphaAutoscaler(mgr.GetClient()),
 		namespaceMigration:   nm,
+		nodeUpdater:          newNodeUpdater(mgr.GetClient()),
 		requiresTSEE:         tsee,
 	}
 	r.status.Run()
@@ -127,6 +129,13 @@ func add(mgr manager.Manager, r *ReconcileInstallation) error {
 		return fmt.Errorf("tigera-installation-controller failed to watch ConfigMap %s: %v", cm, err)
 	}
 
+	if r.requiresTSEE {
+		// Watch for the LogCollector, whose flow log destination is set in the env of calico-node.
+		if err = c.Watch(&source.Kind{Type: &operator.LogCollector{}}, &handler.EnqueueRequestForObject{}); err != nil {
+			return fmt.Errorf("tigera-installation-controller failed to watch LogCollector resource: %v", err)
+		}
+	}
+
 	for _, t := range secondaryResources() {
 		pred := predicate.Funcs{
 			CreateFunc: func(e event.CreateEvent) bool {
@@ -184,6 +193,7 @@ type ReconcileInstallation struct {
 	status               status.StatusManager
 	typhaAutoscaler      *typhaAutoscaler
 	namespaceMigration   *migration.CoreNamespaceMigration
+	nodeUpdater          *nodeUpdater
 	requiresTSEE         bool
 }
 
@@ -374,12 +384,36 @@ func (r *ReconcileInstallation) Reconcile(request reconcile.Request) (reconcile.
 	r.status.OnCRFound()
 	reqLogger.V(2).Info("Loaded config", "config", instance)
 
+	// If Calico was installed from manifests, carry its configuration over into the Installation before
+	// the defaults are written back, so the migration does not change the cluster's networking.
+	adopted, err := r.adoptManifestInstall(ctx, reqLogger)
+	if err != nil {
+		r.SetDegraded("Unable to adopt existing Calico installation", err, reqLogger)
+		return reconcile.Result{}, err
+	}
+	if adopted {
+		// Requeue so that the adopted configuration is defaulted and rendered.
+		return reconcile.Result{Requeue: true}, nil
+	}
+
 	// Validate the configuration.
 	if err = validateCustomResource(instance); err != nil {
 		r.SetDegraded("Invalid Installation provided", err, reqLogger)
 		return reconcile.Result{}, err
 	}
 
+	// Refuse to change the installed product if the compatibility matrix of this operator does not
+	// allow upgrading from the versions that are currently installed.
+	if err = checkUpgradePath(instance.Status, instance.Spec.Variant); err != nil {
+		r.SetDegraded("Unsupported upgrade", err, reqLogger)
+		return reconcile.Result{}, err
+	}
+	if upgradeRefused {
+		// The other controllers were not started since the upgrade was refused on startup.
+		log.Info("Upgrade is supported now, rebooting to start the other controllers")
+		os.Exit(0)
+	}
+
 	// Write the discovered configuration back to the API. This is essentially a poor-man's defaulting, and
 	// ensures that we don't surprise anyone by changing defaults in a future version of the operator.
 	if err = r.client.Update(ctx, instance); err != nil {
@@ -453,6 +487,19 @@ func (r *ReconcileInstallation) Reconcile(request reconcile.Request) (reconcile.
 		return reconcile.Result{}, err
 	}
 
+	// The LogCollector decides whether Felix writes flow logs.
+	var logCollector *operator.LogCollector
+	if instance.Spec.Variant == operator.TigeraSecureEnterprise {
+		logCollector = &operator.LogCollector{}
+		if err = r.client.Get(ctx, utils.DefaultTSEEInstanceKey, logCollector); err != nil {
+			if !apierrors.IsNotFound(err) {
+				r.SetDegraded("Error querying LogCollector", err, reqLogger)
+				return reconcile.Result{}, err
+			}
+			logCollector = nil
+		}
+	}
+
 	// Create a component handler to manage the rendered components.
 	handler := utils.NewComponentHandler(log, r.client, r.scheme, instance)
 
@@ -466,6 +513,7 @@ func (r *ReconcileInstallation) Reconcile(request reconcile.Request) (reconcile.
 		instance.Spec.KubernetesProvider,
 		netConf,
 		needNsMigration,
+		logCollector,
 	)
 	if err != nil {
 		log.Error(err, "Error with rendering Calico")
@@ -477,7 +525,7 @@ func (r *ReconcileInstallation) Reconcile(request reconcile.Request) (reconcile.
 	// If we're on OpenShift on AWS render a Job (and needed resources) to
 	// setup the security groups we need for IPIP, BGP, and Typha communication.
 	if openShiftOnAws {
-		awsSetup, err := render.AWSSecurityGroupSetup(instance.Spec.ImagePullSecrets, instance.Spec.Registry)
+		awsSetup, err := render.AWSSecurityGroupSetup(instance.Spec.ImagePullSecrets, instance)
 		if err != nil {
 			// If there is a problem rendering this do not degrade or stop rendering
 			// anything else.
@@ -549,6 +597,21 @@ func (r *ReconcileInstallation) Reconcile(request reconcile.Request) (reconcile.
 		}
 	}
 
+	// Replace the next set of calico/node pods if a controlled update is configured. The update is paused
+	// for as long as calico/node is unhealthy on nodes that have already been updated.
+	if instance.Spec.NodeUpdateStrategy != nil {
+		progress, err := r.nodeUpdater.step(ctx, instance.Spec.NodeUpdateStrategy)
+		if err != nil {
+			r.SetDegraded("calico/node update paused", err, reqLogger)
+			return reconcile.Result{RequeueAfter: 30 * time.Second}, nil
+		}
+		if progress.InProgress() {
+			reqLogger.WithValues("updated", progress.Updated, "total", progress.Total).Info("calico/node update in progress")
+			r.status.ClearDegraded()
+			return reconcile.Result{RequeueAfter: 10 * time.Second}, nil
+		}
+	}
+
 	// We can clear the degraded state now since as far as we know everything is in order.
 	r.status.ClearDegraded()
 
@@ -560,6 +623,8 @@ func (r *ReconcileInstallation) Reconcile(request reconcile.Request) (reconcile.
 
 	// Everything is available - update the CRD status.
 	instance.Status.Variant = instance.Spec.Variant
+	instance.Status.ProductVersion = productVersion(instance.Spec.Variant)
+	instance.Status.OperatorVersion = version.VERSION
 	if err = r.client.Status().Update(ctx, instance); err != nil {
 		return reconcile.Result{}, err
 	}
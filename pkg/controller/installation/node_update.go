@@ -0,0 +1,244 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operator "github.com/tigera/operator/pkg/apis/operator/v1"
+	"github.com/tigera/operator/pkg/common"
+)
+
+const (
+	defaultNodeUpdateBatchSize     = 1
+	defaultNodeUpdateHealthTimeout = 300
+
+	// Label set by the DaemonSet controller on each pod to the generation of the template it was created from.
+	podTemplateGenerationLabel = "pod-template-generation"
+	// Annotation set on the DaemonSet to the generation of its current template. Unlike the generation of the
+	// DaemonSet, it does not change when only other fields of the spec, such as the update strategy, change.
+	templateGenerationAnnotation = "deprecated.daemonset.template.generation"
+)
+
+// nodeUpdater drives a controlled update of calico/node. The calico-node DaemonSet is rendered with the
+// OnDelete update strategy, so a pod only picks up a new template once it is deleted. Each call to step
+// replaces the next set of outdated pods: first those on the canary nodes, then batches of the remaining
+// nodes. No further pods are replaced while an updated pod is not yet healthy, and the update is paused
+// with an error if a pod replaced during the update fails to become healthy.
+type nodeUpdater struct {
+	client client.Client
+	now    func() time.Time
+
+	// rolloutGeneration is the template generation of the update in progress and rolloutStart is when it
+	// was first seen. Only the pods created since then are checked for health, so that restarts of pods
+	// outside of an update do not pause it.
+	rolloutGeneration string
+	rolloutStart      time.Time
+}
+
+func newNodeUpdater(c client.Client) *nodeUpdater {
+	return &nodeUpdater{client: c, now: time.Now}
+}
+
+// nodeUpdateProgress describes the state of a controlled calico/node update.
+type nodeUpdateProgress struct {
+	// Updated is the number of nodes running calico/node from the current DaemonSet template.
+	Updated int
+	// Total is the number of nodes that calico/node should run on.
+	Total int
+}
+
+// InProgress returns true if there are nodes still to be updated.
+func (p nodeUpdateProgress) InProgress() bool {
+	return p.Updated < p.Total
+}
+
+// step replaces the next set of outdated calico/node pods, if the pods that have already been replaced
+// are healthy. It returns an error if the update is paused because a replaced pod is unhealthy. Nothing
+// is checked once no outdated pods remain.
+func (u *nodeUpdater) step(ctx context.Context, strategy *operator.NodeUpdateStrategy) (nodeUpdateProgress, error) {
+	progress := nodeUpdateProgress{}
+
+	ds := &appsv1.DaemonSet{}
+	err := u.client.Get(ctx, types.NamespacedName{Name: common.NodeDaemonSetName, Namespace: common.CalicoNamespace}, ds)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return progress, nil
+		}
+		return progress, err
+	}
+	if ds.Spec.UpdateStrategy.Type != appsv1.OnDeleteDaemonSetStrategyType {
+		// The DaemonSet has not been rendered for a controlled update yet.
+		return progress, nil
+	}
+	generation, ok := ds.Annotations[templateGenerationAnnotation]
+	if !ok {
+		// The template generation has not been recorded yet, so outdated pods cannot be told apart.
+		return progress, nil
+	}
+	progress.Total = int(ds.Status.DesiredNumberScheduled)
+
+	pods := &corev1.PodList{}
+	if err := u.client.List(ctx, pods, client.InNamespace(common.CalicoNamespace), client.MatchingLabels(map[string]string{"k8s-app": "calico-node"})); err != nil {
+		return progress, err
+	}
+
+	canaries, err := u.canaryNodes(ctx, strategy.CanaryNodeSelector)
+	if err != nil {
+		return progress, err
+	}
+
+	var outdatedCanaries, outdated, updated []corev1.Pod
+	pending := 0
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp != nil {
+			pending++
+			continue
+		}
+		if pod.Labels[podTemplateGenerationLabel] != generation {
+			if canaries[pod.Spec.NodeName] {
+				outdatedCanaries = append(outdatedCanaries, pod)
+			} else {
+				outdated = append(outdated, pod)
+			}
+			continue
+		}
+		progress.Updated++
+		updated = append(updated, pod)
+	}
+
+	if len(outdatedCanaries) == 0 && len(outdated) == 0 {
+		// The update is complete, so later restarts of calico/node are not held against it.
+		u.rolloutGeneration = ""
+		return progress, nil
+	}
+	if u.rolloutGeneration != generation {
+		// Creation timestamps only have a resolution of seconds.
+		u.rolloutGeneration, u.rolloutStart = generation, u.now().Truncate(time.Second)
+	}
+
+	timeout := time.Duration(defaultNodeUpdateHealthTimeout) * time.Second
+	if strategy.HealthTimeoutSeconds != nil {
+		timeout = time.Duration(*strategy.HealthTimeoutSeconds) * time.Second
+	}
+	var failed []string
+	for _, pod := range updated {
+		if pod.CreationTimestamp.Time.Before(u.rolloutStart) {
+			// The pod was not replaced during this update.
+			continue
+		}
+		switch {
+		case calicoNodeRestarted(pod):
+			failed = append(failed, pod.Spec.NodeName)
+		case !isPodReady(pod):
+			if u.now().Sub(pod.CreationTimestamp.Time) > timeout {
+				failed = append(failed, pod.Spec.NodeName)
+			} else {
+				pending++
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return progress, fmt.Errorf("calico/node is not healthy on updated nodes %s, update of remaining nodes is paused", strings.Join(failed, ", "))
+	}
+
+	// Wait for replaced pods to be recreated and become ready before moving on.
+	if pending > 0 || len(pods.Items) < progress.Total {
+		return progress, nil
+	}
+
+	if len(outdatedCanaries) > 0 {
+		log.WithValues("count", len(outdatedCanaries)).Info("Updating calico/node on canary nodes")
+		return progress, u.deletePods(ctx, outdatedCanaries)
+	}
+
+	batchSize := defaultNodeUpdateBatchSize
+	if strategy.BatchSize != nil {
+		batchSize = int(*strategy.BatchSize)
+	}
+	if len(outdated) > batchSize {
+		outdated = outdated[:batchSize]
+	}
+	if len(outdated) > 0 {
+		log.WithValues("count", len(outdated)).Info("Updating calico/node on next batch of nodes")
+	}
+	return progress, u.deletePods(ctx, outdated)
+}
+
+// canaryNodes returns the names of the nodes matching the canary node selector.
+func (u *nodeUpdater) canaryNodes(ctx context.Context, selector map[string]string) (map[string]bool, error) {
+	canaries := map[string]bool{}
+	if len(selector) == 0 {
+		return canaries, nil
+	}
+
+	nodes := &corev1.NodeList{}
+	if err := u.client.List(ctx, nodes); err != nil {
+		return nil, err
+	}
+	sel := labels.SelectorFromSet(selector)
+	for _, n := range nodes.Items {
+		if sel.Matches(labels.Set(n.Labels)) {
+			canaries[n.Name] = true
+		}
+	}
+	return canaries, nil
+}
+
+func (u *nodeUpdater) deletePods(ctx context.Context, pods []corev1.Pod) error {
+	for i := range pods {
+		if err := u.client.Delete(ctx, &pods[i]); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// isPodReady returns true if the pod is running and has the ready condition.
+func isPodReady(pod corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// calicoNodeRestarted returns true if the calico-node container of the pod has been restarted, which
+// happens when its Felix liveness check fails.
+func calicoNodeRestarted(pod corev1.Pod) bool {
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.Name == "calico-node" && cs.RestartCount > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,203 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installation
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	operator "github.com/tigera/operator/pkg/apis/operator/v1"
+)
+
+var _ = Describe("Controlled calico/node update", func() {
+	var c client.Client
+	var u *nodeUpdater
+	var strategy *operator.NodeUpdateStrategy
+	ctx := context.Background()
+	now := time.Now()
+	var clock time.Time
+
+	createNodePod := func(node, generation string, ready bool, restarts int32) {
+		status := corev1.ConditionFalse
+		if ready {
+			status = corev1.ConditionTrue
+		}
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "calico-node-" + node + "-" + generation,
+				Namespace:         "calico-system",
+				Labels:            map[string]string{"k8s-app": "calico-node", podTemplateGenerationLabel: generation},
+				CreationTimestamp: metav1.NewTime(clock),
+			},
+			Spec: corev1.PodSpec{NodeName: node},
+			Status: corev1.PodStatus{
+				Phase:             corev1.PodRunning,
+				Conditions:        []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "calico-node", RestartCount: restarts}},
+			},
+		}
+		Expect(c.Create(ctx, pod)).NotTo(HaveOccurred())
+	}
+
+	podNames := func() []string {
+		pods := &corev1.PodList{}
+		Expect(c.List(ctx, pods, client.InNamespace("calico-system"))).NotTo(HaveOccurred())
+		names := []string{}
+		for _, p := range pods.Items {
+			names = append(names, p.Name)
+		}
+		return names
+	}
+
+	BeforeEach(func() {
+		c = fake.NewFakeClientWithScheme(scheme.Scheme)
+		u = newNodeUpdater(c)
+		clock = now.Add(-time.Hour)
+		u.now = func() time.Time { return clock }
+		strategy = &operator.NodeUpdateStrategy{CanaryNodeSelector: map[string]string{"canary": "true"}}
+
+		ds := &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "calico-node",
+				Namespace:   "calico-system",
+				Generation:  3,
+				Annotations: map[string]string{templateGenerationAnnotation: "2"},
+			},
+			Spec: appsv1.DaemonSetSpec{
+				UpdateStrategy: appsv1.DaemonSetUpdateStrategy{Type: appsv1.OnDeleteDaemonSetStrategyType},
+			},
+			Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 3},
+		}
+		Expect(c.Create(ctx, ds)).NotTo(HaveOccurred())
+
+		for _, n := range []string{"node1", "node2", "node3"} {
+			node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: n, Labels: map[string]string{}}}
+			if n == "node3" {
+				node.Labels["canary"] = "true"
+			}
+			Expect(c.Create(ctx, node)).NotTo(HaveOccurred())
+			createNodePod(n, "1", true, 0)
+		}
+		clock = now
+	})
+
+	It("should update the canary nodes first and then continue in batches", func() {
+		progress, err := u.step(ctx, strategy)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(progress.InProgress()).To(BeTrue())
+		Expect(podNames()).To(ConsistOf("calico-node-node1-1", "calico-node-node2-1"))
+
+		// Nothing more is replaced until the canary pod is back.
+		_, err = u.step(ctx, strategy)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(podNames()).To(HaveLen(2))
+
+		createNodePod("node3", "2", true, 0)
+		progress, err = u.step(ctx, strategy)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(progress.Updated).To(Equal(1))
+		Expect(podNames()).To(HaveLen(2))
+		Expect(podNames()).To(ContainElement("calico-node-node3-2"))
+	})
+
+	It("should pause the update if calico/node is not healthy on a canary node", func() {
+		_, err := u.step(ctx, strategy)
+		Expect(err).NotTo(HaveOccurred())
+
+		createNodePod("node3", "2", false, 2)
+		_, err = u.step(ctx, strategy)
+		Expect(err).To(MatchError("calico/node is not healthy on updated nodes node3, update of remaining nodes is paused"))
+		Expect(podNames()).To(ConsistOf("calico-node-node1-1", "calico-node-node2-1", "calico-node-node3-2"))
+	})
+
+	It("should wait for an updated pod to become ready until the health timeout", func() {
+		_, err := u.step(ctx, strategy)
+		Expect(err).NotTo(HaveOccurred())
+		createNodePod("node3", "2", false, 0)
+
+		_, err = u.step(ctx, strategy)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(podNames()).To(HaveLen(3))
+
+		var timeout int32 = 30
+		strategy.HealthTimeoutSeconds = &timeout
+		clock = now.Add(time.Minute)
+		_, err = u.step(ctx, strategy)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should not replace pods when only the DaemonSet generation changes", func() {
+		for _, n := range []string{"node1", "node2", "node3"} {
+			Expect(c.Delete(ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "calico-node-" + n + "-1", Namespace: "calico-system"}})).NotTo(HaveOccurred())
+			createNodePod(n, "2", true, 0)
+		}
+
+		// A change to the spec outside the template, such as the update strategy, bumps only the generation.
+		ds := &appsv1.DaemonSet{}
+		Expect(c.Get(ctx, client.ObjectKey{Name: "calico-node", Namespace: "calico-system"}, ds)).NotTo(HaveOccurred())
+		ds.Generation = 4
+		Expect(c.Update(ctx, ds)).NotTo(HaveOccurred())
+
+		progress, err := u.step(ctx, strategy)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(progress.InProgress()).To(BeFalse())
+		Expect(progress.Updated).To(Equal(3))
+		Expect(podNames()).To(ConsistOf("calico-node-node1-2", "calico-node-node2-2", "calico-node-node3-2"))
+	})
+
+	It("should not check the health of calico/node when no update is in progress", func() {
+		for _, n := range []string{"node1", "node2", "node3"} {
+			Expect(c.Delete(ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "calico-node-" + n + "-1", Namespace: "calico-system"}})).NotTo(HaveOccurred())
+			createNodePod(n, "2", true, 0)
+		}
+
+		// calico-node restarts and a node stays not ready long after the update.
+		Expect(c.Delete(ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "calico-node-node1-2", Namespace: "calico-system"}})).NotTo(HaveOccurred())
+		createNodePod("node1", "2", true, 3)
+		Expect(c.Delete(ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "calico-node-node2-2", Namespace: "calico-system"}})).NotTo(HaveOccurred())
+		createNodePod("node2", "2", false, 0)
+
+		clock = now.Add(time.Hour)
+		progress, err := u.step(ctx, strategy)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(progress.InProgress()).To(BeFalse())
+	})
+
+	It("should only check the health of the pods replaced during the current update", func() {
+		// The pod on node1 was updated and restarted before the update was started by the operator.
+		Expect(c.Delete(ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "calico-node-node1-1", Namespace: "calico-system"}})).NotTo(HaveOccurred())
+		clock = now.Add(-time.Minute)
+		createNodePod("node1", "2", true, 2)
+
+		clock = now
+		_, err := u.step(ctx, strategy)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(podNames()).To(ConsistOf("calico-node-node1-2", "calico-node-node2-1"))
+
+		createNodePod("node3", "2", true, 1)
+		_, err = u.step(ctx, strategy)
+		Expect(err).To(MatchError("calico/node is not healthy on updated nodes node3, update of remaining nodes is paused"))
+	})
+})
@@ -20,6 +20,7 @@ import (
 	"strings"
 
 	operatorv1 "github.com/tigera/operator/pkg/apis/operator/v1"
+	"github.com/tigera/operator/pkg/components"
 	"github.com/tigera/operator/pkg/render"
 )
 
@@ -137,6 +138,66 @@ func validateCustomResource(instance *operatorv1.Installation) error {
 		}
 	}
 
+	if s := instance.Spec.NodeUpdateStrategy; s != nil {
+		if s.BatchSize != nil && *s.BatchSize < 1 {
+			return fmt.Errorf("nodeUpdateStrategy.batchSize must be at least 1")
+		}
+		if s.HealthTimeoutSeconds != nil && *s.HealthTimeoutSeconds < 1 {
+			return fmt.Errorf("nodeUpdateStrategy.healthTimeoutSeconds must be at least 1")
+		}
+	}
+
+	switch instance.Spec.ImageReferenceMode {
+	case "", operatorv1.ImageReferenceModeDigest, operatorv1.ImageReferenceModeTag:
+	default:
+		return fmt.Errorf("%s is invalid for imageReferenceMode, should be one of %s,%s",
+			instance.Spec.ImageReferenceMode, operatorv1.ImageReferenceModeDigest, operatorv1.ImageReferenceModeTag)
+	}
+
+	if err := validateImageOverrides(instance.Spec.ImageOverrides); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateImageOverrides checks that each override names a component once and overrides it with a
+// plain image name, tag and digest.
+func validateImageOverrides(overrides []operatorv1.ImageOverride) error {
+	seen := map[string]bool{}
+	for _, o := range overrides {
+		if o.Component == "" {
+			return fmt.Errorf("imageOverrides.component should not be empty")
+		}
+		if seen[o.Component] {
+			return fmt.Errorf("imageOverrides contains more than one override for %s", o.Component)
+		}
+		seen[o.Component] = true
+
+		known := false
+		for _, c := range components.All {
+			if c.Image == o.Component {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("imageOverrides.component(%s) is not the image of a known component", o.Component)
+		}
+
+		if o.Image == "" && o.Version == "" && o.Digest == "" {
+			return fmt.Errorf("imageOverrides for %s must set at least one of image, version or digest", o.Component)
+		}
+		if strings.ContainsAny(o.Image, ":@") {
+			return fmt.Errorf("imageOverrides.image(%s) must not contain a tag or digest", o.Image)
+		}
+		if strings.ContainsAny(o.Version, ":@/") {
+			return fmt.Errorf("imageOverrides.version(%s) is not a valid tag", o.Version)
+		}
+		if o.Digest != "" && !strings.Contains(o.Digest, ":") {
+			return fmt.Errorf("imageOverrides.digest(%s) must be of the form <algorithm>:<hex>", o.Digest)
+		}
+	}
 	return nil
 }
 
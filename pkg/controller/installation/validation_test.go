@@ -81,4 +81,31 @@ var _ = Describe("Installation validation tests", func() {
 		Expect(err).To(HaveOccurred())
 	})
 
+	It("should validate image overrides", func() {
+		instance.Spec.ImageOverrides = []operator.ImageOverride{
+			{Component: "calico/node", Image: "mirror/node", Version: "v3.12.1", Digest: "sha256:abc"},
+		}
+		Expect(validateCustomResource(instance)).NotTo(HaveOccurred())
+
+		instance.Spec.ImageOverrides[0].Image = "mirror/node:v3.12.1"
+		Expect(validateCustomResource(instance)).To(HaveOccurred())
+
+		instance.Spec.ImageOverrides = []operator.ImageOverride{{Component: "calico/node"}}
+		Expect(validateCustomResource(instance)).To(HaveOccurred())
+
+		instance.Spec.ImageOverrides = []operator.ImageOverride{{Component: "calico/unknown", Version: "v3.12.1"}}
+		Expect(validateCustomResource(instance)).To(MatchError("imageOverrides.component(calico/unknown) is not the image of a known component"))
+
+		instance.Spec.ImageOverrides = []operator.ImageOverride{
+			{Component: "calico/node", Version: "v3.12.1"},
+			{Component: "calico/node", Digest: "sha256:abc"},
+		}
+		Expect(validateCustomResource(instance)).To(MatchError("imageOverrides contains more than one override for calico/node"))
+	})
+
+	It("should not allow an unknown image reference mode", func() {
+		instance.Spec.ImageReferenceMode = "Latest"
+		Expect(validateCustomResource(instance)).To(HaveOccurred())
+	})
+
 })
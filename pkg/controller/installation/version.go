@@ -15,14 +15,42 @@
 package installation
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 
 	gv "github.com/hashicorp/go-version"
+	operator "github.com/tigera/operator/pkg/apis/operator/v1"
+	"github.com/tigera/operator/pkg/components"
 	"github.com/tigera/operator/version"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// upgradeEdge is an upgrade of the installed product supported by the operator. Versions are
+// major.minor versions, patch releases within the same minor version are always compatible.
+type upgradeEdge struct {
+	fromVariant operator.ProductVariant
+	fromVersion string
+	toVariant   operator.ProductVariant
+	toVersion   string
+}
+
+// supportedUpgrades is the compatibility matrix of product upgrades this operator can perform. Any
+// other change to the installed variant or version is refused, since skipping releases or downgrading
+// can leave the CRDs and stored data in a state the installed components do not understand.
+var supportedUpgrades = []upgradeEdge{
+	{operator.Calico, "v3.11", operator.Calico, "v3.12"},
+	{operator.Calico, "v3.12", operator.TigeraSecureEnterprise, "v2.7"},
+	{operator.TigeraSecureEnterprise, "v2.6", operator.TigeraSecureEnterprise, "v2.7"},
+}
+
 var buildVersion *gv.Version
+
+// upgradeRefused is set when the upgrade path was refused on startup, in which case only the installation
+// controller has been started.
+var upgradeRefused bool
 var gitDescribeSuffixRegexp = regexp.MustCompile(`-\d+-\w+$`)
 var versionRegexp = regexp.MustCompile("^" + gv.VersionRegexpRaw + "$")
 
@@ -74,3 +102,84 @@ func versionFromBuildVersion(buildVersion string) (*gv.Version, error) {
 	s := gitDescribeSuffixRegexp.ReplaceAllString(buildVersion, "")
 	return gv.NewVersion(s)
 }
+
+// productVersion returns the version of the given product variant installed by this operator.
+func productVersion(variant operator.ProductVariant) string {
+	if variant == operator.TigeraSecureEnterprise {
+		return components.ComponentTigeraNode.Version
+	}
+	return components.ComponentCalicoNode.Version
+}
+
+// checkUpgradePath validates that the installation recorded in the given status can be changed to the
+// given variant by this operator. Installations that have no recorded versions are always allowed.
+func checkUpgradePath(status operator.InstallationStatus, variant operator.ProductVariant) error {
+	if status.OperatorVersion != "" && buildVersion != nil {
+		installedBy, err := versionFromBuildVersion(status.OperatorVersion)
+		if err == nil && buildVersion.LessThan(installedBy) {
+			return fmt.Errorf("operator %s is older than operator %s which installed the product, downgrading the operator is not supported",
+				buildVersion, installedBy)
+		}
+	}
+
+	if status.ProductVersion == "" {
+		return nil
+	}
+	fromVariant := status.Variant
+	if fromVariant == "" {
+		fromVariant = operator.Calico
+	}
+
+	from, err := gv.NewVersion(status.ProductVersion)
+	if err != nil {
+		return fmt.Errorf("invalid installed product version %q: %s", status.ProductVersion, err)
+	}
+	to, err := gv.NewVersion(productVersion(variant))
+	if err != nil {
+		// Development builds may not have a valid product version, skip the checks.
+		log.Info("No valid product version, skipping upgrade checks")
+		return nil
+	}
+
+	if fromVariant == variant {
+		if to.LessThan(from) {
+			return fmt.Errorf("downgrading %s from %s to %s is not supported", variant, from.Original(), to.Original())
+		}
+		if minorVersion(from) == minorVersion(to) {
+			return nil
+		}
+	}
+
+	for _, e := range supportedUpgrades {
+		if e.fromVariant == fromVariant && e.fromVersion == minorVersion(from) &&
+			e.toVariant == variant && e.toVersion == minorVersion(to) {
+			return nil
+		}
+	}
+	return fmt.Errorf("upgrading %s %s to %s %s is not supported by this operator", fromVariant, from.Original(), variant, to.Original())
+}
+
+// CheckUpgradePath validates on startup that this operator can upgrade the product recorded in the status
+// of the Installation. When it cannot, only the installation controller should be started, so that no
+// controller changes the product or its data, such as Elasticsearch, until the upgrade is resolved.
+func CheckUpgradePath(ctx context.Context, cli client.Client, provider operator.Provider) error {
+	instance, err := GetInstallation(ctx, cli, provider)
+	if err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			// Nothing is installed yet.
+			return nil
+		}
+		return err
+	}
+	if err := checkUpgradePath(instance.Status, instance.Spec.Variant); err != nil {
+		upgradeRefused = true
+		return err
+	}
+	return nil
+}
+
+// minorVersion returns the major.minor part of a version, e.g. v3.12.
+func minorVersion(v *gv.Version) string {
+	s := v.Segments()
+	return fmt.Sprintf("v%d.%d", s[0], s[1])
+}
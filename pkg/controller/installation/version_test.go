@@ -15,6 +15,7 @@
 package installation
 
 import (
+	"context"
 	"fmt"
 
 	. "github.com/onsi/ginkgo"
@@ -22,7 +23,12 @@ import (
 
 	gv "github.com/hashicorp/go-version"
 	"github.com/onsi/ginkgo/extensions/table"
+	"github.com/tigera/operator/pkg/apis"
+	operator "github.com/tigera/operator/pkg/apis/operator/v1"
 	"github.com/tigera/operator/version"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 var _ = Describe("Version validation logic tests", func() {
@@ -71,4 +77,54 @@ var _ = Describe("Version validation logic tests", func() {
 		table.Entry("empty build version", "", "",
 			fmt.Errorf(`Invalid build version: ""`)),
 	)
+
+	table.DescribeTable("should enforce the supported upgrade paths",
+		func(installed operator.InstallationStatus, variant operator.ProductVariant, allowed bool) {
+			buildVersion, _ = gv.NewVersion("v1.3.0")
+			err := checkUpgradePath(installed, variant)
+			if allowed {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(HaveOccurred())
+			}
+		},
+		table.Entry("new installation", operator.InstallationStatus{}, operator.Calico, true),
+		table.Entry("same version", operator.InstallationStatus{
+			Variant: operator.Calico, ProductVersion: productVersion(operator.Calico)}, operator.Calico, true),
+		table.Entry("patch upgrade", operator.InstallationStatus{
+			Variant: operator.Calico, ProductVersion: "v3.12.0-rc1"}, operator.Calico, true),
+		table.Entry("supported minor upgrade", operator.InstallationStatus{
+			Variant: operator.Calico, ProductVersion: "v3.11.2"}, operator.Calico, true),
+		table.Entry("skipping a minor version", operator.InstallationStatus{
+			Variant: operator.Calico, ProductVersion: "v3.10.1"}, operator.Calico, false),
+		table.Entry("downgrade", operator.InstallationStatus{
+			Variant: operator.Calico, ProductVersion: "v3.13.0"}, operator.Calico, false),
+		table.Entry("Calico to Enterprise", operator.InstallationStatus{
+			Variant: operator.Calico, ProductVersion: productVersion(operator.Calico)}, operator.TigeraSecureEnterprise, true),
+		table.Entry("Enterprise to Calico", operator.InstallationStatus{
+			Variant: operator.TigeraSecureEnterprise, ProductVersion: productVersion(operator.TigeraSecureEnterprise)}, operator.Calico, false),
+		table.Entry("operator downgrade", operator.InstallationStatus{
+			Variant: operator.Calico, ProductVersion: productVersion(operator.Calico), OperatorVersion: "v1.4.0"}, operator.Calico, false),
+		table.Entry("operator upgrade", operator.InstallationStatus{
+			Variant: operator.Calico, ProductVersion: productVersion(operator.Calico), OperatorVersion: "v1.2.1-3-ga64"}, operator.Calico, true),
+	)
+
+	It("should record that the upgrade path is refused on startup", func() {
+		defer func() { upgradeRefused = false }()
+		buildVersion, _ = gv.NewVersion("v1.3.0")
+		scheme := runtime.NewScheme()
+		Expect(apis.AddToScheme(scheme)).NotTo(HaveOccurred())
+		cli := fake.NewFakeClientWithScheme(scheme)
+		ctx := context.Background()
+
+		Expect(CheckUpgradePath(ctx, cli, operator.ProviderNone)).NotTo(HaveOccurred())
+		Expect(upgradeRefused).To(BeFalse())
+
+		Expect(cli.Create(ctx, &operator.Installation{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			Status:     operator.InstallationStatus{Variant: operator.Calico, ProductVersion: "v3.10.1"},
+		})).NotTo(HaveOccurred())
+		Expect(CheckUpgradePath(ctx, cli, operator.ProviderNone)).To(HaveOccurred())
+		Expect(upgradeRefused).To(BeTrue())
+	})
 })
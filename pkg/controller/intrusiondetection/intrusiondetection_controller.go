@@ -209,11 +209,15 @@ func (r *ReconcileIntrusionDetection) Reconcile(request reconcile.Request) (reco
 		return reconcile.Result{}, err
 	}
 
-	kibanaPublicCertSecret := &corev1.Secret{}
-	if err := r.client.Get(ctx, types.NamespacedName{Name: render.KibanaPublicCertSecret, Namespace: render.OperatorNamespace()}, kibanaPublicCertSecret); err != nil {
-		reqLogger.Error(err, "Failed to read Kibana public cert secret")
-		r.status.SetDegraded("Failed to read Kibana public cert secret", err.Error())
-		return reconcile.Result{}, err
+	// There is no Kibana when an external Elasticsearch cluster is used or Kibana is disabled.
+	var kibanaPublicCertSecret *corev1.Secret
+	if esClusterConfig.Kibana() {
+		kibanaPublicCertSecret = &corev1.Secret{}
+		if err := r.client.Get(ctx, types.NamespacedName{Name: render.KibanaPublicCertSecret, Namespace: render.OperatorNamespace()}, kibanaPublicCertSecret); err != nil {
+			reqLogger.Error(err, "Failed to read Kibana public cert secret")
+			r.status.SetDegraded("Failed to read Kibana public cert secret", err.Error())
+			return reconcile.Result{}, err
+		}
 	}
 
 	// Create a component handler to manage the rendered component.
@@ -224,7 +228,7 @@ func (r *ReconcileIntrusionDetection) Reconcile(request reconcile.Request) (reco
 	component := render.IntrusionDetection(
 		esSecrets,
 		kibanaPublicCertSecret,
-		network.Spec.Registry,
+		network,
 		esClusterConfig,
 		pullSecrets,
 		r.provider == operatorv1.ProviderOpenShift,
@@ -0,0 +1,185 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logcollector
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/tigera/operator/pkg/render"
+)
+
+// fluentdFilterPlugins are the filter plugins bundled in the fluentd image, with the sections that each of them
+// accepts. Filters with other plugins are not rejected, since fluentd may have more plugins installed, but their
+// sections cannot be checked.
+var fluentdFilterPlugins = map[string][]string{
+	"grep":                {"regexp", "exclude", "and", "or"},
+	"record_transformer":  {"record"},
+	"record_modifier":     {"record", "replace"},
+	"parser":              {"parse"},
+	"stdout":              {"format", "inject"},
+	"geoip":               {"record"},
+	"prometheus":          {"metric", "labels"},
+	"concat":              {},
+	"kubernetes_metadata": {},
+}
+
+var fluentdParamName = regexp.MustCompile(`^@?[A-Za-z_][A-Za-z0-9_]*$`)
+
+// fluentdFilterError is an error in a fluentd filter, with the line that caused it.
+type fluentdFilterError struct {
+	line int
+	text string
+	msg  string
+}
+
+func (e *fluentdFilterError) Error() string {
+	return fmt.Sprintf("line %d %q: %s", e.line, e.text, e.msg)
+}
+
+// fluentdDirective is a section of a fluentd filter, such as <filter> or the <regexp> of a grep filter.
+type fluentdDirective struct {
+	name string
+	line int
+	text string
+	// plugin and pluginLine are the @type of a <filter> and the line it is set on.
+	plugin     string
+	pluginLine int
+	pluginText string
+	// sections are the sections nested in a <filter>, at any depth.
+	sections []*fluentdDirective
+}
+
+// validateFluentdFilters checks the syntax of the flow and DNS filters, so that invalid filters are not rolled out to
+// fluentd, which would fail to start with them. It also returns warnings for the filters that use plugins that are
+// not bundled in the fluentd image.
+func validateFluentdFilters(filters *render.FluentdFilters) ([]string, error) {
+	var warnings []string
+	for _, f := range []struct{ name, config string }{
+		{render.FluentdFilterFlowName, filters.Flow},
+		{render.FluentdFilterDNSName, filters.DNS},
+	} {
+		w, err := validateFluentdFilter(f.config)
+		if err != nil {
+			return nil, fmt.Errorf("%s filter %s", f.name, err)
+		}
+		for _, msg := range w {
+			warnings = append(warnings, fmt.Sprintf("%s filter %s", f.name, msg))
+		}
+	}
+	return warnings, nil
+}
+
+// validateFluentdFilter checks that a filter only consists of well formed <filter> directives, and returns the line
+// of the first error and warnings for the plugins that are not bundled in the fluentd image.
+func validateFluentdFilter(config string) ([]string, error) {
+	var warnings []string
+	var stack []*fluentdDirective
+	for i, raw := range strings.Split(config, "\n") {
+		n, line := i+1, strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "<") {
+			if !strings.HasSuffix(line, ">") {
+				return nil, &fluentdFilterError{n, line, "tag is not terminated with >"}
+			}
+			closing := strings.HasPrefix(line, "</")
+			fields := strings.Fields(strings.TrimPrefix(line[1:len(line)-1], "/"))
+			if len(fields) == 0 {
+				return nil, &fluentdFilterError{n, line, "tag has no name"}
+			}
+
+			if closing {
+				if len(stack) == 0 {
+					return nil, &fluentdFilterError{n, line, "closing tag has no opening tag"}
+				}
+				top := stack[len(stack)-1]
+				if fields[0] != top.name {
+					return nil, &fluentdFilterError{n, line, fmt.Sprintf("expected </%s> to close the tag on line %d", top.name, top.line)}
+				}
+				stack = stack[:len(stack)-1]
+				if len(stack) == 0 {
+					warning, err := top.validate()
+					if err != nil {
+						return nil, err
+					}
+					if warning != "" {
+						warnings = append(warnings, warning)
+					}
+				}
+				continue
+			}
+
+			d := &fluentdDirective{name: fields[0], line: n, text: line}
+			if len(stack) == 0 {
+				if d.name != "filter" {
+					return nil, &fluentdFilterError{n, line, "only <filter> directives are allowed"}
+				}
+			} else {
+				stack[0].sections = append(stack[0].sections, d)
+			}
+			stack = append(stack, d)
+			continue
+		}
+
+		if len(stack) == 0 {
+			return nil, &fluentdFilterError{n, line, "parameter is outside of a <filter> directive"}
+		}
+		fields := strings.Fields(line)
+		if !fluentdParamName.MatchString(fields[0]) {
+			return nil, &fluentdFilterError{n, line, fmt.Sprintf("%q is not a valid parameter name", fields[0])}
+		}
+		if len(stack) == 1 && (fields[0] == "@type" || fields[0] == "type") {
+			if len(fields) < 2 {
+				return nil, &fluentdFilterError{n, line, "plugin type is missing"}
+			}
+			stack[0].plugin, stack[0].pluginLine, stack[0].pluginText = fields[1], n, line
+		}
+	}
+
+	if len(stack) != 0 {
+		top := stack[len(stack)-1]
+		return nil, &fluentdFilterError{top.line, top.text, fmt.Sprintf("<%s> is not closed", top.name)}
+	}
+	return warnings, nil
+}
+
+// validate checks the plugin of a <filter> and the sections nested in it. The sections of the plugins that are not
+// bundled in the fluentd image are not checked, and a warning is returned for them instead.
+func (d *fluentdDirective) validate() (string, error) {
+	if d.plugin == "" {
+		return "", &fluentdFilterError{d.line, d.text, "filter has no @type"}
+	}
+	sections, ok := fluentdFilterPlugins[d.plugin]
+	if !ok {
+		msg := fmt.Sprintf("plugin %q is not bundled in the fluentd image", d.plugin)
+		return (&fluentdFilterError{d.pluginLine, d.pluginText, msg}).Error(), nil
+	}
+	for _, s := range d.sections {
+		allowed := false
+		for _, name := range sections {
+			if s.name == name {
+				allowed = true
+			}
+		}
+		if !allowed {
+			return "", &fluentdFilterError{s.line, s.text, fmt.Sprintf("section <%s> is not supported by the %s plugin", s.name, d.plugin)}
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logcollector
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/tigera/operator/pkg/render"
+)
+
+var _ = Describe("Fluentd filter validation tests", func() {
+	DescribeTable("valid filters",
+		func(config string) {
+			warnings, err := validateFluentdFilter(config)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		},
+		Entry("empty", ""),
+		Entry("grep", `
+# Drop the flows of the kube-system namespace.
+<filter flows>
+  @type grep
+  <exclude>
+    key source_namespace
+    pattern /^kube-system$/
+  </exclude>
+</filter>`),
+		Entry("grep with and", `
+<filter dns>
+  @type grep
+  <and>
+    <regexp>
+      key qtype
+      pattern /^A$/
+    </regexp>
+    <exclude>
+      key client_namespace
+      pattern /^test-/
+    </exclude>
+  </and>
+</filter>`),
+		Entry("record_transformer", `
+<filter flows>
+  @type record_transformer
+  remove_keys policies
+  <record>
+    cluster production
+  </record>
+</filter>
+<filter flows>
+  @type grep
+  <regexp>
+    key action
+    pattern /deny/
+  </regexp>
+</filter>`),
+		Entry("parser", `
+<filter dns>
+  @type parser
+  key_name qname
+  reserve_data true
+  <parse>
+    @type regexp
+    expression /^(?<subdomain>[^.]+)\./
+  </parse>
+</filter>`),
+		Entry("record_modifier", `
+<filter flows>
+  @type record_modifier
+  remove_keys policies
+  <replace>
+    key dest_name_aggr
+    expression /^-$/
+    replace unknown
+  </replace>
+</filter>`),
+	)
+
+	DescribeTable("invalid filters",
+		func(config, line string) {
+			_, err := validateFluentdFilter(config)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix(line))
+		},
+		Entry("missing plugin", "<filter flows>\n  key action\n</filter>", `line 1 "<filter flows>": filter has no @type`),
+		Entry("match directive", "<match **>\n  @type null\n</match>", `line 1 "<match **>": only <filter> directives are allowed`),
+		Entry("unclosed filter", "<filter flows>\n  @type grep\n", `line 1 "<filter flows>": <filter> is not closed`),
+		Entry("unclosed section", "<filter flows>\n  @type grep\n  <regexp>\n    key action\n</filter>", `line 5 "</filter>": expected </regexp>`),
+		Entry("unterminated tag", "<filter flows\n  @type grep\n</filter>", `line 1 "<filter flows": tag is not terminated with >`),
+		Entry("closing tag without opening tag", "</filter>", `line 1 "</filter>": closing tag has no opening tag`),
+		Entry("parameter outside of a filter", "@type grep", `line 1 "@type grep": parameter is outside of a <filter> directive`),
+		Entry("invalid parameter name", "<filter flows>\n  @type grep\n  key: action\n</filter>", `line 3 "key: action": "key:" is not a valid parameter name`),
+		Entry("unsupported section", "<filter flows>\n  @type grep\n  <record>\n    a b\n  </record>\n</filter>", `line 3 "<record>": section <record> is not supported by the grep plugin`),
+	)
+
+	It("should warn about the plugins that are not bundled in the fluentd image", func() {
+		warnings, err := validateFluentdFilter("<filter flows>\n  @type rewrite_tag_filter\n  <rule>\n    key action\n  </rule>\n</filter>")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(Equal([]string{`line 2 "@type rewrite_tag_filter": plugin "rewrite_tag_filter" is not bundled in the fluentd image`}))
+	})
+
+	It("should name the filter with the error", func() {
+		_, err := validateFluentdFilters(&render.FluentdFilters{
+			Flow: "<filter flows>\n  @type grep\n</filter>",
+			DNS:  "<filter dns>\n  @type grep\n  <record>\n    a b\n  </record>\n</filter>",
+		})
+		Expect(err).To(MatchError(`dns filter line 3 "<record>": section <record> is not supported by the grep plugin`))
+	})
+
+	It("should name the filter with the warning", func() {
+		warnings, err := validateFluentdFilters(&render.FluentdFilters{
+			Flow: "<filter flows>\n  @type grep\n</filter>",
+			DNS:  "<filter dns>\n  @type grap\n</filter>",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings).To(Equal([]string{`dns filter line 2 "@type grap": plugin "grap" is not bundled in the fluentd image`}))
+	})
+})
@@ -0,0 +1,184 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logcollector
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"time"
+
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorv1 "github.com/tigera/operator/pkg/apis/operator/v1"
+	"github.com/tigera/operator/pkg/controller/utils"
+	"github.com/tigera/operator/pkg/render"
+)
+
+// flowLogAggregationKinds maps the flow log aggregation levels to the aggregation kinds of Felix.
+var flowLogAggregationKinds = []struct {
+	aggregation operatorv1.FlowLogAggregation
+	kind        int
+}{
+	{operatorv1.FlowLogAggregationNone, 0},
+	{operatorv1.FlowLogAggregationSourcePort, 1},
+	{operatorv1.FlowLogAggregationPodPrefix, 2},
+}
+
+// flowLogsAnnotation records on the default FelixConfiguration the flow log settings that were applied from the
+// LogCollector, so that they are reset when they are removed from the LogCollector.
+const flowLogsAnnotation = "operator.tigera.io/flow-logs"
+
+// defaultFlowLogs are the flow log settings that Felix uses when they are not set in the FelixConfiguration, with flow
+// logs disabled unless the env var of calico-node enables them, and no per node limit.
+var defaultFlowLogs = operatorv1.FlowLogsSpec{
+	AggregationForAllowed: operatorv1.FlowLogAggregationPodPrefix,
+	AggregationForDenied:  operatorv1.FlowLogAggregationSourcePort,
+	FlushInterval:         300,
+	Destination:           operatorv1.FlowLogsDestinationNone,
+}
+
+// reconcileFlowLogs applies the flow log settings of the LogCollector to the default FelixConfiguration, creating
+// it if it does not exist yet, and returns the settings that are in effect.
+func reconcileFlowLogs(ctx context.Context, cli client.Client, spec *operatorv1.FlowLogsSpec) (*operatorv1.FlowLogsSpec, error) {
+	fc := &v3.FelixConfiguration{}
+	if err := cli.Get(ctx, utils.DefaultInstanceKey, fc); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, err
+		}
+		fc = &v3.FelixConfiguration{ObjectMeta: metav1.ObjectMeta{Name: utils.DefaultInstanceKey.Name}}
+		// There is nothing to apply without flow log settings, so Felix keeps its defaults.
+		if spec == nil {
+			return effectiveFlowLogs(&fc.Spec, spec), nil
+		}
+		setFlowLogs(fc, spec)
+		if err := cli.Create(ctx, fc); err != nil {
+			return nil, err
+		}
+		return effectiveFlowLogs(&fc.Spec, spec), nil
+	}
+
+	desired := fc.DeepCopy()
+	setFlowLogs(desired, spec)
+	if !reflect.DeepEqual(fc.Spec, desired.Spec) || !reflect.DeepEqual(fc.Annotations, desired.Annotations) {
+		if err := cli.Update(ctx, desired); err != nil {
+			return nil, err
+		}
+	}
+	return effectiveFlowLogs(&desired.Spec, spec), nil
+}
+
+// setFlowLogs sets the flow log settings that the LogCollector specifies in a FelixConfiguration and records them in
+// its flowLogsAnnotation. The settings recorded before that are no longer specified are reset to the Felix defaults,
+// the others are left as they are. Whether the flow log files are written is set by the FELIX_FLOWLOGSFILEENABLED env
+// var of calico-node, which takes precedence over the FelixConfiguration, so the destination is only set here to
+// match it.
+func setFlowLogs(fc *v3.FelixConfiguration, spec *operatorv1.FlowLogsSpec) {
+	if spec == nil {
+		spec = &operatorv1.FlowLogsSpec{}
+	}
+
+	previous := map[string]bool{}
+	for _, field := range strings.Split(fc.Annotations[flowLogsAnnotation], ",") {
+		previous[field] = true
+	}
+	var applied []string
+	// update records the field if it is specified, and returns whether it must be set, either to the value specified
+	// or to nil to reset a value applied before.
+	update := func(field string, specified bool) bool {
+		if specified {
+			applied = append(applied, field)
+		}
+		return specified || previous[field]
+	}
+
+	if kind := aggregationKind(spec.AggregationForAllowed); update("aggregationForAllowed", kind != nil) {
+		fc.Spec.FlowLogsFileAggregationKindForAllowed = kind
+	}
+	if kind := aggregationKind(spec.AggregationForDenied); update("aggregationForDenied", kind != nil) {
+		fc.Spec.FlowLogsFileAggregationKindForDenied = kind
+	}
+	var interval *metav1.Duration
+	if spec.FlushInterval != 0 {
+		interval = &metav1.Duration{Duration: time.Duration(spec.FlushInterval) * time.Second}
+	}
+	if update("flushInterval", interval != nil) {
+		fc.Spec.FlowLogsFlushInterval = interval
+	}
+	var limit *int
+	if spec.PerNodeLimit != nil {
+		l := int(*spec.PerNodeLimit)
+		limit = &l
+	}
+	if update("perNodeLimit", limit != nil) {
+		fc.Spec.FlowLogsFilePerNodeLimit = limit
+	}
+	var enabled *bool
+	if spec.Destination != "" {
+		e := spec.Destination != operatorv1.FlowLogsDestinationNone
+		enabled = &e
+	}
+	if update("destination", enabled != nil) {
+		fc.Spec.FlowLogsFileEnabled = enabled
+	}
+
+	if len(applied) == 0 {
+		delete(fc.Annotations, flowLogsAnnotation)
+		return
+	}
+	if fc.Annotations == nil {
+		fc.Annotations = map[string]string{}
+	}
+	fc.Annotations[flowLogsAnnotation] = strings.Join(applied, ",")
+}
+
+func aggregationKind(aggregation operatorv1.FlowLogAggregation) *int {
+	for _, a := range flowLogAggregationKinds {
+		if a.aggregation == aggregation {
+			kind := a.kind
+			return &kind
+		}
+	}
+	return nil
+}
+
+// effectiveFlowLogs returns the flow log settings of a FelixConfiguration, with the Felix defaults filled in, and the
+// destination of the flow logs, which the env var of calico-node sets from the LogCollector.
+func effectiveFlowLogs(fc *v3.FelixConfigurationSpec, spec *operatorv1.FlowLogsSpec) *operatorv1.FlowLogsSpec {
+	effective := defaultFlowLogs
+	for _, a := range flowLogAggregationKinds {
+		if fc.FlowLogsFileAggregationKindForAllowed != nil && *fc.FlowLogsFileAggregationKindForAllowed == a.kind {
+			effective.AggregationForAllowed = a.aggregation
+		}
+		if fc.FlowLogsFileAggregationKindForDenied != nil && *fc.FlowLogsFileAggregationKindForDenied == a.kind {
+			effective.AggregationForDenied = a.aggregation
+		}
+	}
+	if fc.FlowLogsFlushInterval != nil {
+		effective.FlushInterval = int32(fc.FlowLogsFlushInterval.Duration / time.Second)
+	}
+	var limit int32
+	if fc.FlowLogsFilePerNodeLimit != nil {
+		limit = int32(*fc.FlowLogsFilePerNodeLimit)
+	}
+	effective.PerNodeLimit = &limit
+	if render.FlowLogsFileEnabled(spec) {
+		effective.Destination = operatorv1.FlowLogsDestinationFile
+	}
+	return &effective
+}
@@ -0,0 +1,189 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logcollector
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
+	operatorv1 "github.com/tigera/operator/pkg/apis/operator/v1"
+	"github.com/tigera/operator/pkg/controller/utils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Flow log settings tests", func() {
+	var cli client.Client
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(v3.AddToScheme(scheme)).ShouldNot(HaveOccurred())
+		cli = fake.NewFakeClientWithScheme(scheme)
+	})
+
+	getFelixConfiguration := func() *v3.FelixConfiguration {
+		fc := &v3.FelixConfiguration{}
+		Expect(cli.Get(ctx, utils.DefaultInstanceKey, fc)).ShouldNot(HaveOccurred())
+		return fc
+	}
+
+	It("should not create the FelixConfiguration when no flow log settings are specified", func() {
+		effective, err := reconcileFlowLogs(ctx, cli, nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(cli.Get(ctx, utils.DefaultInstanceKey, &v3.FelixConfiguration{})).Should(HaveOccurred())
+		Expect(effective).To(Equal(&operatorv1.FlowLogsSpec{
+			AggregationForAllowed: operatorv1.FlowLogAggregationPodPrefix,
+			AggregationForDenied:  operatorv1.FlowLogAggregationSourcePort,
+			FlushInterval:         300,
+			PerNodeLimit:          int32Ptr(0),
+			Destination:           operatorv1.FlowLogsDestinationFile,
+		}))
+	})
+
+	It("should leave the FelixConfiguration as it is when no flow log settings are specified", func() {
+		kind := 0
+		interval := metav1.Duration{Duration: time.Minute}
+		Expect(cli.Create(ctx, &v3.FelixConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			Spec: v3.FelixConfigurationSpec{
+				FlowLogsFileAggregationKindForAllowed: &kind,
+				FlowLogsFlushInterval:                 &interval,
+			},
+		})).ShouldNot(HaveOccurred())
+
+		effective, err := reconcileFlowLogs(ctx, cli, nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		fc := getFelixConfiguration()
+		Expect(fc.Spec.FlowLogsFileEnabled).To(BeNil())
+		Expect(*fc.Spec.FlowLogsFileAggregationKindForAllowed).To(Equal(0))
+		Expect(fc.Spec.FlowLogsFlushInterval.Duration).To(Equal(time.Minute))
+		Expect(effective.AggregationForAllowed).To(Equal(operatorv1.FlowLogAggregationNone))
+		Expect(effective.FlushInterval).To(Equal(int32(60)))
+		Expect(effective.Destination).To(Equal(operatorv1.FlowLogsDestinationFile))
+	})
+
+	It("should apply the flow log settings", func() {
+		kind := 0
+		Expect(cli.Create(ctx, &v3.FelixConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			Spec:       v3.FelixConfigurationSpec{FlowLogsFileAggregationKindForAllowed: &kind},
+		})).ShouldNot(HaveOccurred())
+
+		effective, err := reconcileFlowLogs(ctx, cli, &operatorv1.FlowLogsSpec{
+			AggregationForDenied: operatorv1.FlowLogAggregationNone,
+			FlushInterval:        60,
+			PerNodeLimit:         int32Ptr(1000),
+			Destination:          operatorv1.FlowLogsDestinationNone,
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		fc := getFelixConfiguration()
+		Expect(*fc.Spec.FlowLogsFileEnabled).To(BeFalse())
+		Expect(*fc.Spec.FlowLogsFileAggregationKindForAllowed).To(Equal(0))
+		Expect(*fc.Spec.FlowLogsFileAggregationKindForDenied).To(Equal(0))
+		Expect(fc.Spec.FlowLogsFlushInterval.Duration).To(Equal(time.Minute))
+		Expect(*fc.Spec.FlowLogsFilePerNodeLimit).To(Equal(1000))
+		Expect(effective).To(Equal(&operatorv1.FlowLogsSpec{
+			AggregationForAllowed: operatorv1.FlowLogAggregationNone,
+			AggregationForDenied:  operatorv1.FlowLogAggregationNone,
+			FlushInterval:         60,
+			PerNodeLimit:          int32Ptr(1000),
+			Destination:           operatorv1.FlowLogsDestinationNone,
+		}))
+	})
+
+	It("should keep the flow log settings that are not specified", func() {
+		interval := metav1.Duration{Duration: time.Minute}
+		limit := 1000
+		Expect(cli.Create(ctx, &v3.FelixConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			Spec: v3.FelixConfigurationSpec{
+				FlowLogsFlushInterval:    &interval,
+				FlowLogsFilePerNodeLimit: &limit,
+			},
+		})).ShouldNot(HaveOccurred())
+
+		effective, err := reconcileFlowLogs(ctx, cli, &operatorv1.FlowLogsSpec{
+			AggregationForAllowed: operatorv1.FlowLogAggregationSourcePort,
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		fc := getFelixConfiguration()
+		Expect(fc.Spec.FlowLogsFileEnabled).To(BeNil())
+		Expect(*fc.Spec.FlowLogsFileAggregationKindForAllowed).To(Equal(1))
+		Expect(fc.Spec.FlowLogsFileAggregationKindForDenied).To(BeNil())
+		Expect(fc.Spec.FlowLogsFlushInterval.Duration).To(Equal(time.Minute))
+		Expect(*fc.Spec.FlowLogsFilePerNodeLimit).To(Equal(1000))
+		Expect(effective).To(Equal(&operatorv1.FlowLogsSpec{
+			AggregationForAllowed: operatorv1.FlowLogAggregationSourcePort,
+			AggregationForDenied:  operatorv1.FlowLogAggregationSourcePort,
+			FlushInterval:         60,
+			PerNodeLimit:          int32Ptr(1000),
+			Destination:           operatorv1.FlowLogsDestinationFile,
+		}))
+	})
+
+	It("should reset the flow log settings that are removed from the LogCollector", func() {
+		_, err := reconcileFlowLogs(ctx, cli, &operatorv1.FlowLogsSpec{
+			AggregationForAllowed: operatorv1.FlowLogAggregationNone,
+			FlushInterval:         60,
+			PerNodeLimit:          int32Ptr(1000),
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+		fc := getFelixConfiguration()
+		Expect(fc.Annotations).To(HaveKeyWithValue(flowLogsAnnotation, "aggregationForAllowed,flushInterval,perNodeLimit"))
+
+		By("keeping the settings that were not applied from the LogCollector")
+		kind := 0
+		fc.Spec.FlowLogsFileAggregationKindForDenied = &kind
+		Expect(cli.Update(ctx, fc)).ShouldNot(HaveOccurred())
+
+		effective, err := reconcileFlowLogs(ctx, cli, &operatorv1.FlowLogsSpec{PerNodeLimit: int32Ptr(0)})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		fc = getFelixConfiguration()
+		Expect(fc.Spec.FlowLogsFileAggregationKindForAllowed).To(BeNil())
+		Expect(*fc.Spec.FlowLogsFileAggregationKindForDenied).To(Equal(0))
+		Expect(fc.Spec.FlowLogsFlushInterval).To(BeNil())
+		Expect(*fc.Spec.FlowLogsFilePerNodeLimit).To(Equal(0))
+		Expect(fc.Annotations).To(HaveKeyWithValue(flowLogsAnnotation, "perNodeLimit"))
+		Expect(effective.AggregationForAllowed).To(Equal(operatorv1.FlowLogAggregationPodPrefix))
+		Expect(effective.FlushInterval).To(Equal(int32(300)))
+		Expect(*effective.PerNodeLimit).To(Equal(int32(0)))
+
+		By("resetting all the applied settings when the flow log settings are removed")
+		_, err = reconcileFlowLogs(ctx, cli, nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		fc = getFelixConfiguration()
+		Expect(fc.Spec.FlowLogsFilePerNodeLimit).To(BeNil())
+		Expect(*fc.Spec.FlowLogsFileAggregationKindForDenied).To(Equal(0))
+		Expect(fc.Annotations).NotTo(HaveKey(flowLogsAnnotation))
+	})
+})
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
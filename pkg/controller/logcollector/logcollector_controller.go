@@ -17,6 +17,7 @@ package logcollector
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -57,6 +58,7 @@ func newReconciler(mgr manager.Manager, provider operatorv1.Provider) reconcile.
 		scheme:   mgr.GetScheme(),
 		provider: provider,
 		status:   status.New(mgr.GetClient(), "log-collector"),
+		monitor:  newFluentdMonitor(mgr.GetClient()),
 	}
 	c.status.Run()
 	return c
@@ -83,7 +85,11 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 
 	for _, secretName := range []string{
 		render.ElasticsearchLogCollectorUserSecret, render.ElasticsearchEksLogForwarderUserSecret,
-		render.ElasticsearchPublicCertSecret, render.S3FluentdSecretName, render.EksLogForwarderSecret} {
+		render.ElasticsearchAksLogForwarderUserSecret, render.ElasticsearchGkeLogForwarderUserSecret,
+		render.AksLogForwarderSecret, render.GkeLogForwarderSecret,
+		render.ElasticsearchPublicCertSecret, render.S3FluentdSecretName, render.EksLogForwarderSecret,
+		render.SyslogFluentdSecretName, render.SplunkFluentdSecretName, render.KafkaFluentdSecretName, render.HTTPFluentdSecretName,
+		render.GuardianSecretName} {
 		if err = utils.AddSecretsWatch(c, secretName, render.OperatorNamespace()); err != nil {
 			return fmt.Errorf("log-collector-controller failed to watch the Secret resource(%s): %v", secretName, err)
 		}
@@ -109,9 +115,10 @@ type ReconcileLogCollector struct {
 	scheme   *runtime.Scheme
 	provider operatorv1.Provider
 	status   status.StatusManager
+	monitor  *fluentdMonitor
 }
 
-// GetLogCollector returns the default LogCollector instance with defaults populated.
+// GetLogCollector returns the default LogCollector instance.
 func GetLogCollector(ctx context.Context, cli client.Client) (*operatorv1.LogCollector, error) {
 	// Fetch the instance. We only support a single instance named "tigera-secure".
 	instance := &operatorv1.LogCollector{}
@@ -120,15 +127,6 @@ func GetLogCollector(ctx context.Context, cli client.Client) (*operatorv1.LogCol
 		return nil, err
 	}
 
-	if instance.Spec.AdditionalStores != nil {
-		if instance.Spec.AdditionalStores.Syslog != nil {
-			_, _, _, err := render.ParseEndpoint(instance.Spec.AdditionalStores.Syslog.Endpoint)
-			if err != nil {
-				return nil, fmt.Errorf("Syslog config has invalid Endpoint: %s", err)
-			}
-		}
-	}
-
 	return instance, nil
 }
 
@@ -156,6 +154,13 @@ func (r *ReconcileLogCollector) Reconcile(request reconcile.Request) (reconcile.
 	reqLogger.V(2).Info("Loaded config", "config", instance)
 	r.status.OnCRFound()
 
+	// Validate the configuration.
+	if err = validateCustomResource(instance); err != nil {
+		log.Error(err, "Invalid LogCollector provided")
+		r.status.SetDegraded("Invalid LogCollector provided", err.Error())
+		return reconcile.Result{}, nil
+	}
+
 	if !utils.IsAPIServerReady(r.client, reqLogger) {
 		r.status.SetDegraded("Waiting for Tigera API server to be ready", "")
 		return reconcile.Result{}, nil
@@ -191,6 +196,24 @@ func (r *ReconcileLogCollector) Reconcile(request reconcile.Request) (reconcile.
 		return reconcile.Result{}, err
 	}
 
+	managed := installation.Spec.ClusterManagementType == operatorv1.ClusterManagementTypeManaged
+	if managed {
+		// The logs of a managed cluster are sent through the Guardian tunnel to the Elasticsearch cluster of the
+		// management cluster, into the indices of the name that the tunnel is authenticated with.
+		clusterName, err := managedClusterName(context.Background(), r.client)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				log.Info("Management cluster connection secret is not available, waiting for it to become available")
+				r.status.SetDegraded("Management cluster connection secret is not available, waiting for it to become available", err.Error())
+				return reconcile.Result{}, nil
+			}
+			log.Error(err, "Failed to get the managed cluster name")
+			r.status.SetDegraded("Failed to get the managed cluster name from the management cluster connection secret", err.Error())
+			return reconcile.Result{}, nil
+		}
+		esClusterConfig = esClusterConfig.ForManagedCluster(clusterName)
+	}
+
 	pullSecrets, err := utils.GetNetworkingPullSecrets(installation, r.client)
 	if err != nil {
 		log.Error(err, "Error with Pull secrets")
@@ -198,7 +221,9 @@ func (r *ReconcileLogCollector) Reconcile(request reconcile.Request) (reconcile.
 		return reconcile.Result{}, err
 	}
 
-	esSecrets, err := utils.ElasticsearchSecrets(context.Background(), []string{render.ElasticsearchLogCollectorUserSecret, render.ElasticsearchEksLogForwarderUserSecret}, r.client)
+	esSecrets, err := utils.ElasticsearchSecrets(context.Background(), []string{
+		render.ElasticsearchLogCollectorUserSecret, render.ElasticsearchEksLogForwarderUserSecret,
+		render.ElasticsearchAksLogForwarderUserSecret, render.ElasticsearchGkeLogForwarderUserSecret}, r.client)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			log.Info("Elasticsearch secrets are not available yet, waiting until they become available")
@@ -226,12 +251,92 @@ func (r *ReconcileLogCollector) Reconcile(request reconcile.Request) (reconcile.
 		}
 	}
 
-	filters, err := getFluentdFilters(r.client)
+	var syslogCredential *render.SyslogCredential
+	var splunkCredential *render.SplunkCredential
+	var kafkaCredential *render.KafkaCredential
+	var httpCredential *render.HTTPCredential
+	if instance.Spec.AdditionalStores != nil {
+		if instance.Spec.AdditionalStores.Syslog != nil {
+			syslogCredential, err = getSyslogCredential(r.client)
+			if err != nil {
+				log.Error(err, "Error with syslog credential secret")
+				r.status.SetDegraded("Error with syslog credential secret", err.Error())
+				return reconcile.Result{}, err
+			}
+		}
+		if instance.Spec.AdditionalStores.Splunk != nil {
+			splunkCredential, err = getSplunkCredential(r.client)
+			if err != nil {
+				log.Error(err, "Error with Splunk credential secret")
+				r.status.SetDegraded("Error with Splunk credential secret", err.Error())
+				return reconcile.Result{}, err
+			}
+			if splunkCredential == nil {
+				log.Info("Splunk credential secret does not exist")
+				r.status.SetDegraded("Splunk credential secret does not exist", "")
+				return reconcile.Result{}, nil
+			}
+		}
+		if kafka := instance.Spec.AdditionalStores.Kafka; kafka != nil {
+			kafkaCredential, err = getKafkaCredential(r.client, kafka)
+			if err != nil {
+				log.Error(err, "Error with Kafka credential secret")
+				r.status.SetDegraded("Error with Kafka credential secret", err.Error())
+				return reconcile.Result{}, err
+			}
+			if kafkaCredential == nil && kafka.SASLMechanism != "" {
+				log.Info("Kafka credential secret does not exist")
+				r.status.SetDegraded("Kafka credential secret does not exist", "")
+				return reconcile.Result{}, nil
+			}
+		}
+		if http := instance.Spec.AdditionalStores.HTTP; http != nil && http.Auth != "" && http.Auth != operatorv1.HTTPStoreAuthTypeNone {
+			httpCredential, err = getHTTPCredential(r.client, http.Auth)
+			if err != nil {
+				log.Error(err, "Error with HTTP credential secret")
+				r.status.SetDegraded("Error with HTTP credential secret", err.Error())
+				return reconcile.Result{}, err
+			}
+			if httpCredential == nil {
+				log.Info("HTTP credential secret does not exist")
+				r.status.SetDegraded("HTTP credential secret does not exist", "")
+				return reconcile.Result{}, nil
+			}
+		}
+	}
+
+	filters, err := getFluentdFilters(r.client, render.OperatorNamespace())
 	if err != nil {
 		log.Error(err, "Error retrieving Fluentd filters")
 		r.status.SetDegraded("Error retrieving Fluentd filters", err.Error())
 		return reconcile.Result{}, err
 	}
+	if filters != nil && instance.Spec.Collector == operatorv1.LogCollectorTypeFluentBit {
+		// The filters are fluentd configuration, which Fluent Bit cannot read.
+		log.Info("Fluentd filters are not supported by the Fluent Bit collector")
+		r.status.SetDegraded("Fluentd filters are not supported by the Fluent Bit collector",
+			fmt.Sprintf("remove the %s ConfigMap or use the Fluentd collector", render.FluentdFilterConfigMapName))
+		return reconcile.Result{}, nil
+	}
+
+	var filtersErr error
+	if filters != nil {
+		var warnings []string
+		warnings, filtersErr = validateFluentdFilters(filters)
+		for _, w := range warnings {
+			log.Info("Fluentd filters use a plugin that is not bundled in the fluentd image", "warning", w)
+		}
+		if filtersErr != nil {
+			// Keep the filters that fluentd uses, so that it is not restarted with filters it cannot load.
+			log.Error(filtersErr, "Invalid fluentd filters, the previous filters are kept")
+			filters, err = getFluentdFilters(r.client, render.LogCollectorNamespace)
+			if err != nil {
+				log.Error(err, "Error retrieving the previous Fluentd filters")
+				r.status.SetDegraded("Error retrieving the previous Fluentd filters", err.Error())
+				return reconcile.Result{}, err
+			}
+		}
+	}
 
 	var eksConfig *render.EksCloudwatchLogConfig
 	if installation.Spec.KubernetesProvider == operatorv1.ProviderEKS {
@@ -242,7 +347,8 @@ func (r *ReconcileLogCollector) Reconcile(request reconcile.Request) (reconcile.
 					instance.Spec.AdditionalSources.EksCloudwatchLog.FetchInterval,
 					instance.Spec.AdditionalSources.EksCloudwatchLog.Region,
 					instance.Spec.AdditionalSources.EksCloudwatchLog.GroupName,
-					instance.Spec.AdditionalSources.EksCloudwatchLog.StreamPrefix)
+					instance.Spec.AdditionalSources.EksCloudwatchLog.StreamPrefix,
+					instance.Spec.AdditionalSources.EksCloudwatchLog.RoleARN)
 				if err != nil {
 					log.Error(err, "Error retrieving EKS Cloudwatch Logs configuration")
 					r.status.SetDegraded("Error retrieving EKS Cloudwatch Logs configuration", err.Error())
@@ -252,6 +358,32 @@ func (r *ReconcileLogCollector) Reconcile(request reconcile.Request) (reconcile.
 		}
 	}
 
+	var azureConfig *render.AzureMonitorLogConfig
+	if installation.Spec.KubernetesProvider == operatorv1.ProviderAKS {
+		if instance.Spec.AdditionalSources != nil && instance.Spec.AdditionalSources.AzureMonitorLog != nil {
+			log.Info("Managed kubernetes AKS found, getting necessary credentials and config")
+			azureConfig, err = getAzureMonitorLogConfig(r.client, instance.Spec.AdditionalSources.AzureMonitorLog)
+			if err != nil {
+				log.Error(err, "Error retrieving Azure Monitor Logs configuration")
+				r.status.SetDegraded("Error retrieving Azure Monitor Logs configuration", err.Error())
+				return reconcile.Result{}, err
+			}
+		}
+	}
+
+	var gcpConfig *render.GcpLoggingLogConfig
+	if installation.Spec.KubernetesProvider == operatorv1.ProviderGKE {
+		if instance.Spec.AdditionalSources != nil && instance.Spec.AdditionalSources.GcpLoggingLog != nil {
+			log.Info("Managed kubernetes GKE found, getting necessary credentials and config")
+			gcpConfig, err = getGcpLoggingLogConfig(r.client, instance.Spec.AdditionalSources.GcpLoggingLog)
+			if err != nil {
+				log.Error(err, "Error retrieving GCP Logging configuration")
+				r.status.SetDegraded("Error retrieving GCP Logging configuration", err.Error())
+				return reconcile.Result{}, err
+			}
+		}
+	}
+
 	// Create a component handler to manage the rendered component.
 	handler := utils.NewComponentHandler(log, r.client, r.scheme, instance)
 
@@ -261,8 +393,14 @@ func (r *ReconcileLogCollector) Reconcile(request reconcile.Request) (reconcile.
 		esSecrets,
 		esClusterConfig,
 		s3Credential,
+		syslogCredential,
+		splunkCredential,
+		kafkaCredential,
+		httpCredential,
 		filters,
 		eksConfig,
+		azureConfig,
+		gcpConfig,
 		pullSecrets,
 		installation,
 	)
@@ -272,6 +410,19 @@ func (r *ReconcileLogCollector) Reconcile(request reconcile.Request) (reconcile.
 		return reconcile.Result{}, err
 	}
 
+	flowLogs, err := reconcileFlowLogs(context.Background(), r.client, instance.Spec.FlowLogs)
+	if err != nil {
+		log.Error(err, "Error updating the flow log settings of the FelixConfiguration")
+		r.status.SetDegraded("Error updating the flow log settings of the FelixConfiguration", err.Error())
+		return reconcile.Result{}, err
+	}
+
+	if filtersErr != nil {
+		// The rest of the configuration has been applied, the filters are applied once they are fixed.
+		r.status.SetDegraded("Invalid fluentd filters, the previous filters are still in use", filtersErr.Error())
+		return reconcile.Result{}, nil
+	}
+
 	// Clear the degraded bit if we've reached this far.
 	r.status.ClearDegraded()
 
@@ -281,12 +432,32 @@ func (r *ReconcileLogCollector) Reconcile(request reconcile.Request) (reconcile.
 		return reconcile.Result{RequeueAfter: 30 * time.Second}, nil
 	}
 
+	outputs, err := r.monitor.outputs(context.Background())
+	if err != nil {
+		log.Error(err, "Failed to read the fluentd output metrics")
+	}
+	overflows := bufferOverflows(outputs)
+	for _, o := range overflows {
+		log.Info("Fluentd buffer is full, logs are being dropped", "node", o.Node, "output", o.Output, "queuedBytes", o.QueuedBytes)
+	}
+	if managed {
+		// Elasticsearch is reached through the tunnel, so failing writes mean that the tunnel or the management
+		// cluster is down. The logs are buffered until they can be delivered or the buffers are full.
+		if failures := elasticsearchDeliveryFailures(outputs); len(failures) != 0 {
+			log.Info("Logs cannot be delivered to the management cluster", "failures", failures)
+			r.status.SetDegraded("Logs cannot be delivered to the management cluster through the tunnel", strings.Join(failures, "; "))
+		}
+	}
+
 	// Everything is available - update the CRD status.
 	instance.Status.State = operatorv1.LogControllerStatusReady
+	instance.Status.BufferOverflows = overflows
+	instance.Status.FlowLogs = flowLogs
 	if err = r.client.Status().Update(context.Background(), instance); err != nil {
 		return reconcile.Result{}, err
 	}
-	return reconcile.Result{}, nil
+	// Requeue so that the buffer overflows and delivery failures reported in the status are kept up to date.
+	return reconcile.Result{RequeueAfter: fluentdMonitorInterval}, nil
 }
 
 func getS3Credential(client client.Client) (*render.S3Credential, error) {
@@ -322,11 +493,124 @@ func getS3Credential(client client.Client) (*render.S3Credential, error) {
 	}, nil
 }
 
-func getFluentdFilters(client client.Client) (*render.FluentdFilters, error) {
+// getSyslogCredential returns the TLS credentials of the syslog store, or nil if there are none.
+func getSyslogCredential(client client.Client) (*render.SyslogCredential, error) {
+	secret, err := getCredentialSecret(client, render.SyslogFluentdSecretName)
+	if secret == nil || err != nil {
+		return nil, err
+	}
+
+	if (len(secret.Data[render.SyslogCertName]) == 0) != (len(secret.Data[render.SyslogKeyName]) == 0) {
+		return nil, fmt.Errorf(
+			"Expected secret %q to have both or neither of the fields %q and %q",
+			render.SyslogFluentdSecretName, render.SyslogCertName, render.SyslogKeyName)
+	}
+
+	return &render.SyslogCredential{
+		CA:   secret.Data[render.SyslogCAName],
+		Cert: secret.Data[render.SyslogCertName],
+		Key:  secret.Data[render.SyslogKeyName],
+	}, nil
+}
+
+func getSplunkCredential(client client.Client) (*render.SplunkCredential, error) {
+	secret, err := getCredentialSecret(client, render.SplunkFluentdSecretName)
+	if secret == nil || err != nil {
+		return nil, err
+	}
+
+	if len(secret.Data[render.SplunkTokenName]) == 0 {
+		return nil, fmt.Errorf(
+			"Expected secret %q to have a field named %q",
+			render.SplunkFluentdSecretName, render.SplunkTokenName)
+	}
+
+	return &render.SplunkCredential{
+		Token: secret.Data[render.SplunkTokenName],
+	}, nil
+}
+
+// getKafkaCredential returns the credentials of the Kafka store. The secret is optional unless SASL is enabled, in
+// which case it must hold a username and password.
+func getKafkaCredential(client client.Client, kafka *operatorv1.KafkaStoreSpec) (*render.KafkaCredential, error) {
+	secret, err := getCredentialSecret(client, render.KafkaFluentdSecretName)
+	if secret == nil || err != nil {
+		return nil, err
+	}
+
+	if kafka.SASLMechanism != "" {
+		for _, key := range []string{render.KafkaUsernameName, render.KafkaPasswordName} {
+			if len(secret.Data[key]) == 0 {
+				return nil, fmt.Errorf(
+					"Expected secret %q to have a field named %q",
+					render.KafkaFluentdSecretName, key)
+			}
+		}
+	}
+	if (len(secret.Data[render.KafkaCertName]) == 0) != (len(secret.Data[render.KafkaKeyName]) == 0) {
+		return nil, fmt.Errorf(
+			"Expected secret %q to have both or neither of the fields %q and %q",
+			render.KafkaFluentdSecretName, render.KafkaCertName, render.KafkaKeyName)
+	}
+
+	return &render.KafkaCredential{
+		CA:       secret.Data[render.KafkaCAName],
+		Cert:     secret.Data[render.KafkaCertName],
+		Key:      secret.Data[render.KafkaKeyName],
+		Username: secret.Data[render.KafkaUsernameName],
+		Password: secret.Data[render.KafkaPasswordName],
+	}, nil
+}
+
+func getHTTPCredential(client client.Client, auth operatorv1.HTTPStoreAuthType) (*render.HTTPCredential, error) {
+	secret, err := getCredentialSecret(client, render.HTTPFluentdSecretName)
+	if secret == nil || err != nil {
+		return nil, err
+	}
+
+	keys := []string{render.HTTPTokenName}
+	if auth == operatorv1.HTTPStoreAuthTypeBasic {
+		keys = []string{render.HTTPUsernameName, render.HTTPPasswordName}
+	}
+	for _, key := range keys {
+		if len(secret.Data[key]) == 0 {
+			return nil, fmt.Errorf(
+				"Expected secret %q to have a field named %q",
+				render.HTTPFluentdSecretName, key)
+		}
+	}
+
+	return &render.HTTPCredential{
+		Username: secret.Data[render.HTTPUsernameName],
+		Password: secret.Data[render.HTTPPasswordName],
+		Token:    secret.Data[render.HTTPTokenName],
+	}, nil
+}
+
+// getCredentialSecret returns the named secret from the operator namespace, or nil if it does not exist.
+func getCredentialSecret(client client.Client, name string) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	secretNamespacedName := types.NamespacedName{
+		Name:      name,
+		Namespace: render.OperatorNamespace(),
+	}
+	if err := client.Get(context.Background(), secretNamespacedName, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Failed to read secret %q: %s", name, err)
+	}
+	return secret, nil
+}
+
+// getFluentdFilters returns the filters of the fluentd filters ConfigMap in the given namespace. The ConfigMap in the
+// operator namespace holds the filters provided by the user, the one in the log collector namespace holds the
+// filters that fluentd uses.
+func getFluentdFilters(client client.Client, namespace string) (*render.FluentdFilters, error) {
 	cm := &corev1.ConfigMap{}
 	cmNamespacedName := types.NamespacedName{
 		Name:      render.FluentdFilterConfigMapName,
-		Namespace: render.OperatorNamespace(),
+		Namespace: namespace,
 	}
 	if err := client.Get(context.Background(), cmNamespacedName, cm); err != nil {
 		if errors.IsNotFound(err) {
@@ -341,7 +625,7 @@ func getFluentdFilters(client client.Client) (*render.FluentdFilters, error) {
 	}, nil
 }
 
-func getEksCloudwatchLogConfig(client client.Client, interval int32, region, group, prefix string) (*render.EksCloudwatchLogConfig, error) {
+func getEksCloudwatchLogConfig(client client.Client, interval int32, region, group, prefix, roleARN string) (*render.EksCloudwatchLogConfig, error) {
 	if region == "" {
 		return nil, fmt.Errorf("Missing AWS region info")
 	}
@@ -358,6 +642,17 @@ func getEksCloudwatchLogConfig(client client.Client, interval int32, region, gro
 		interval = 600
 	}
 
+	if roleARN != "" {
+		// The credentials of the role are provided through the service account, so no keys are needed.
+		return &render.EksCloudwatchLogConfig{
+			AwsRegion:     region,
+			GroupName:     group,
+			StreamPrefix:  prefix,
+			FetchInterval: interval,
+			RoleARN:       roleARN,
+		}, nil
+	}
+
 	secret := &corev1.Secret{}
 	secretNamespacedName := types.NamespacedName{
 		Name:      render.EksLogForwarderSecret,
@@ -384,3 +679,69 @@ func getEksCloudwatchLogConfig(client client.Client, interval int32, region, gro
 		FetchInterval: interval,
 	}, nil
 }
+
+func getAzureMonitorLogConfig(client client.Client, spec *operatorv1.AzureMonitorLogsSpec) (*render.AzureMonitorLogConfig, error) {
+	if spec.WorkspaceID == "" {
+		return nil, fmt.Errorf("Missing Log Analytics workspace ID")
+	}
+
+	interval := spec.FetchInterval
+	if interval == 0 {
+		interval = 600
+	}
+
+	secret, err := getCredentialSecret(client, render.AksLogForwarderSecret)
+	if secret == nil || err != nil {
+		return nil, err
+	}
+
+	if len(secret.Data[render.AksLogForwarderTenantId]) == 0 ||
+		len(secret.Data[render.AksLogForwarderClientId]) == 0 ||
+		len(secret.Data[render.AksLogForwarderClientSecret]) == 0 {
+		return nil, fmt.Errorf("Incomplete Azure Monitor credentials")
+	}
+
+	return &render.AzureMonitorLogConfig{
+		TenantId:      secret.Data[render.AksLogForwarderTenantId],
+		ClientId:      secret.Data[render.AksLogForwarderClientId],
+		ClientSecret:  secret.Data[render.AksLogForwarderClientSecret],
+		WorkspaceId:   spec.WorkspaceID,
+		FetchInterval: interval,
+	}, nil
+}
+
+func getGcpLoggingLogConfig(client client.Client, spec *operatorv1.GcpLoggingLogsSpec) (*render.GcpLoggingLogConfig, error) {
+	if spec.ProjectID == "" {
+		return nil, fmt.Errorf("Missing GCP project ID")
+	}
+
+	if spec.ClusterName == "" {
+		return nil, fmt.Errorf("Missing GKE cluster name")
+	}
+
+	config := &render.GcpLoggingLogConfig{
+		ProjectId:      spec.ProjectID,
+		ClusterName:    spec.ClusterName,
+		FetchInterval:  spec.FetchInterval,
+		ServiceAccount: spec.ServiceAccount,
+	}
+	if config.FetchInterval == 0 {
+		config.FetchInterval = 600
+	}
+	if config.ServiceAccount != "" {
+		// Workload Identity provides the credentials of the GCP service account, so no key is needed.
+		return config, nil
+	}
+
+	secret, err := getCredentialSecret(client, render.GkeLogForwarderSecret)
+	if secret == nil || err != nil {
+		return nil, err
+	}
+
+	if len(secret.Data[render.GkeLogForwarderKey]) == 0 {
+		return nil, fmt.Errorf("Incomplete GCP Logging credentials")
+	}
+	config.Key = secret.Data[render.GkeLogForwarderKey]
+
+	return config, nil
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logcollector
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/tigera/operator/pkg/render"
+)
+
+// managedClusterCertKey is the key of the certificate that the managed cluster authenticates to the management cluster
+// with in the management cluster connection secret.
+const managedClusterCertKey = "cert"
+
+// managedClusterName returns the name of the managed cluster, which is the common name of the certificate that Guardian
+// authenticates the tunnel to the management cluster with. The management cluster issues the certificate for its
+// ManagedCluster resource of the same name, and provisions the rollover aliases of the indices with that suffix when
+// the resource is annotated with the Fluent Bit log collector.
+func managedClusterName(ctx context.Context, cli client.Client) (string, error) {
+	secret := &corev1.Secret{}
+	if err := cli.Get(ctx, types.NamespacedName{Name: render.GuardianSecretName, Namespace: render.OperatorNamespace()}, secret); err != nil {
+		return "", err
+	}
+
+	block, _ := pem.Decode(secret.Data[managedClusterCertKey])
+	if block == nil {
+		return "", fmt.Errorf("secret %q has no PEM encoded certificate in the field %q", render.GuardianSecretName, managedClusterCertKey)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("secret %q has an invalid certificate: %s", render.GuardianSecretName, err)
+	}
+	if cert.Subject.CommonName == "" {
+		return "", fmt.Errorf("the certificate in secret %q has no common name", render.GuardianSecretName)
+	}
+	return cert.Subject.CommonName, nil
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logcollector
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/tigera/operator/pkg/render"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Managed cluster name tests", func() {
+	var cli client.Client
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).ShouldNot(HaveOccurred())
+		cli = fake.NewFakeClientWithScheme(scheme)
+	})
+
+	createSecret := func(cert []byte) {
+		Expect(cli.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: render.GuardianSecretName, Namespace: render.OperatorNamespace()},
+			Data:       map[string][]byte{"cert": cert, "key": []byte("key")},
+		})).ShouldNot(HaveOccurred())
+	}
+
+	certificate := func(commonName string) []byte {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).ShouldNot(HaveOccurred())
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: commonName},
+			NotBefore:    time.Now(),
+			NotAfter:     time.Now().Add(time.Hour),
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		Expect(err).ShouldNot(HaveOccurred())
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	}
+
+	It("should return the common name of the tunnel certificate", func() {
+		createSecret(certificate("managed-a"))
+
+		name, err := managedClusterName(ctx, cli)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(name).To(Equal("managed-a"))
+	})
+
+	It("should return a not found error when there is no connection secret", func() {
+		_, err := managedClusterName(ctx, cli)
+		Expect(kerrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should reject a secret without a certificate", func() {
+		createSecret([]byte("foo"))
+
+		_, err := managedClusterName(ctx, cli)
+		Expect(err).To(HaveOccurred())
+		Expect(kerrors.IsNotFound(err)).To(BeFalse())
+	})
+
+	It("should reject a certificate without a common name", func() {
+		createSecret(certificate(""))
+
+		_, err := managedClusterName(ctx, cli)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,192 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logcollector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorv1 "github.com/tigera/operator/pkg/apis/operator/v1"
+	"github.com/tigera/operator/pkg/render"
+)
+
+const (
+	// fluentdMonitorInterval is how often the fluentd outputs are checked for buffer overflows and delivery failures.
+	fluentdMonitorInterval = time.Minute
+
+	// fluentdMonitorTimeout bounds the time taken to read the monitors of all the fluentd pods, so that unresponsive
+	// pods don't hold up the reconcile.
+	fluentdMonitorTimeout = 10 * time.Second
+
+	// fluentdMonitorConcurrency is the number of fluentd monitors read at the same time.
+	fluentdMonitorConcurrency = 20
+)
+
+// fluentdMonitor reads the metrics of the fluentd output plugins from the monitor endpoints of the fluentd pods.
+type fluentdMonitor struct {
+	client client.Client
+	http   *http.Client
+	// timeout bounds the time taken to read the monitors of all the pods.
+	timeout time.Duration
+	// endpoint returns the URL of the monitor endpoint of a fluentd pod.
+	endpoint func(pod *corev1.Pod) string
+}
+
+func newFluentdMonitor(cli client.Client) *fluentdMonitor {
+	return &fluentdMonitor{
+		client:  cli,
+		http:    &http.Client{Timeout: 5 * time.Second},
+		timeout: fluentdMonitorTimeout,
+		endpoint: func(pod *corev1.Pod) string {
+			return fmt.Sprintf("http://%s:%d/api/plugins.json", pod.Status.PodIP, render.FluentdMonitorPort)
+		},
+	}
+}
+
+// fluentdPlugin holds the metrics of a fluentd plugin reported by the monitor agent.
+type fluentdPlugin struct {
+	PluginID       string `json:"plugin_id"`
+	PluginCategory string `json:"plugin_category"`
+	Type           string `json:"type"`
+	// BufferTotalQueuedSize and BufferAvailableSpaceRatio are only reported by plugins with a buffer.
+	BufferTotalQueuedSize     *int64   `json:"buffer_total_queued_size"`
+	BufferAvailableSpaceRatio *float64 `json:"buffer_available_buffer_space_ratios"`
+	// RetryCount is the number of times the plugin has retried to write, and Retry describes the retries since the
+	// last failed write. Retry is empty when the last write succeeded.
+	RetryCount int64 `json:"retry_count"`
+	Retry      struct {
+		Start string `json:"start"`
+		Steps int    `json:"steps"`
+	} `json:"retry"`
+}
+
+// fluentdPlugins is the response of the plugins endpoint of the fluentd monitor agent.
+type fluentdPlugins struct {
+	Plugins []fluentdPlugin `json:"plugins"`
+}
+
+// fluentdOutput is an output plugin of the fluentd pod on a node.
+type fluentdOutput struct {
+	node string
+	fluentdPlugin
+}
+
+// outputs returns the output plugins of the running fluentd pods. The monitors of the pods are read concurrently, within
+// fluentdMonitorTimeout. Pods whose monitor cannot be read in time are skipped, since they may still be starting.
+func (m *fluentdMonitor) outputs(ctx context.Context) ([]fluentdOutput, error) {
+	pods := &corev1.PodList{}
+	if err := m.client.List(ctx, pods, client.InNamespace(render.LogCollectorNamespace), client.MatchingLabels(map[string]string{"k8s-app": render.FluentdNodeName})); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	// Each pod's plugins are stored at its index, so that the outputs are returned in the order of the pods.
+	plugins := make([]*fluentdPlugins, len(pods.Items))
+	sem := make(chan struct{}, fluentdMonitorConcurrency)
+	var wg sync.WaitGroup
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, pod *corev1.Pod) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			p, err := m.plugins(ctx, pod)
+			if err != nil {
+				log.V(2).Info("Failed to read the fluentd monitor", "pod", pod.Name, "error", err.Error())
+				return
+			}
+			plugins[i] = p
+		}(i, pod)
+	}
+	wg.Wait()
+
+	var outputs []fluentdOutput
+	for i, p := range plugins {
+		if p == nil {
+			continue
+		}
+		for _, plugin := range p.Plugins {
+			if plugin.PluginCategory == "output" {
+				outputs = append(outputs, fluentdOutput{node: pods.Items[i].Spec.NodeName, fluentdPlugin: plugin})
+			}
+		}
+	}
+	return outputs, nil
+}
+
+// bufferOverflows returns the outputs whose buffers are full.
+func bufferOverflows(outputs []fluentdOutput) []operatorv1.FluentdBufferOverflow {
+	var overflows []operatorv1.FluentdBufferOverflow
+	for _, o := range outputs {
+		if o.BufferAvailableSpaceRatio == nil || *o.BufferAvailableSpaceRatio > 0 {
+			continue
+		}
+		overflow := operatorv1.FluentdBufferOverflow{Node: o.node, Output: o.PluginID}
+		if o.BufferTotalQueuedSize != nil {
+			overflow.QueuedBytes = *o.BufferTotalQueuedSize
+		}
+		overflows = append(overflows, overflow)
+	}
+	return overflows
+}
+
+// elasticsearchDeliveryFailures describes the Elasticsearch outputs that are retrying to write logs, because their
+// last write failed.
+func elasticsearchDeliveryFailures(outputs []fluentdOutput) []string {
+	var failures []string
+	for _, o := range outputs {
+		if !strings.HasPrefix(o.Type, "elasticsearch") || o.Retry.Start == "" {
+			continue
+		}
+		failures = append(failures, fmt.Sprintf("%s on node %s has been retrying since %s (%d retries)", o.PluginID, o.node, o.Retry.Start, o.Retry.Steps))
+	}
+	return failures
+}
+
+func (m *fluentdMonitor) plugins(ctx context.Context, pod *corev1.Pod) (*fluentdPlugins, error) {
+	req, err := http.NewRequest(http.MethodGet, m.endpoint(pod), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := m.http.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	plugins := &fluentdPlugins{}
+	if err := json.NewDecoder(resp.Body).Decode(plugins); err != nil {
+		return nil, err
+	}
+	return plugins, nil
+}
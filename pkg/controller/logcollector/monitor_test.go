@@ -0,0 +1,158 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logcollector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	operatorv1 "github.com/tigera/operator/pkg/apis/operator/v1"
+	"github.com/tigera/operator/pkg/render"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Fluentd monitor tests", func() {
+	var m *fluentdMonitor
+	var server *httptest.Server
+	var responses map[string]string
+	var delay time.Duration
+
+	BeforeEach(func() {
+		responses = map[string]string{}
+		delay = 0
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-time.After(delay):
+			case <-r.Context().Done():
+				return
+			}
+			body, ok := responses[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte(body))
+		}))
+
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).ShouldNot(HaveOccurred())
+		m = newFluentdMonitor(fake.NewFakeClientWithScheme(scheme))
+		m.endpoint = func(pod *corev1.Pod) string {
+			return server.URL + "/" + pod.Name
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	createPod := func(name, node string, phase corev1.PodPhase) {
+		Expect(m.client.Create(context.Background(), &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: render.LogCollectorNamespace,
+				Labels:    map[string]string{"k8s-app": render.FluentdNodeName},
+			},
+			Spec:   corev1.PodSpec{NodeName: node},
+			Status: corev1.PodStatus{Phase: phase, PodIP: "10.0.0.1"},
+		})).ShouldNot(HaveOccurred())
+	}
+
+	It("should report the full output buffers of the running pods", func() {
+		createPod("fluentd-a", "node-a", corev1.PodRunning)
+		createPod("fluentd-b", "node-b", corev1.PodRunning)
+		createPod("fluentd-c", "node-c", corev1.PodPending)
+		responses["/fluentd-a"] = `{"plugins": [
+			{"plugin_id": "in_tail_flows", "plugin_category": "input"},
+			{"plugin_id": "out_es_flows", "plugin_category": "output", "buffer_total_queued_size": 536870912, "buffer_available_buffer_space_ratios": 0.0},
+			{"plugin_id": "out_s3_flows", "plugin_category": "output", "buffer_total_queued_size": 1024, "buffer_available_buffer_space_ratios": 99.9}
+		]}`
+		responses["/fluentd-c"] = `{"plugins": [
+			{"plugin_id": "out_es_flows", "plugin_category": "output", "buffer_total_queued_size": 536870912, "buffer_available_buffer_space_ratios": 0.0}
+		]}`
+
+		outputs, err := m.outputs(context.Background())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(bufferOverflows(outputs)).To(ConsistOf(operatorv1.FluentdBufferOverflow{
+			Node:        "node-a",
+			Output:      "out_es_flows",
+			QueuedBytes: 536870912,
+		}))
+	})
+
+	It("should report no overflows when the buffers have space", func() {
+		createPod("fluentd-a", "node-a", corev1.PodRunning)
+		responses["/fluentd-a"] = `{"plugins": [
+			{"plugin_id": "out_es_flows", "plugin_category": "output", "buffer_total_queued_size": 0, "buffer_available_buffer_space_ratios": 100.0}
+		]}`
+
+		outputs, err := m.outputs(context.Background())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(bufferOverflows(outputs)).To(BeEmpty())
+	})
+
+	It("should report the Elasticsearch outputs that are retrying", func() {
+		createPod("fluentd-a", "node-a", corev1.PodRunning)
+		createPod("fluentd-b", "node-b", corev1.PodRunning)
+		responses["/fluentd-a"] = `{"plugins": [
+			{"plugin_id": "out_es_flows", "plugin_category": "output", "type": "elasticsearch", "retry_count": 4,
+			 "retry": {"start": "2020-06-01 10:00:00 +0000", "steps": 3, "next_time": "2020-06-01 10:00:08 +0000"}},
+			{"plugin_id": "out_s3_flows", "plugin_category": "output", "type": "s3", "retry_count": 1,
+			 "retry": {"start": "2020-06-01 10:00:00 +0000", "steps": 0, "next_time": "2020-06-01 10:00:01 +0000"}}
+		]}`
+		responses["/fluentd-b"] = `{"plugins": [
+			{"plugin_id": "out_es_flows", "plugin_category": "output", "type": "elasticsearch", "retry_count": 2, "retry": {}}
+		]}`
+
+		outputs, err := m.outputs(context.Background())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(elasticsearchDeliveryFailures(outputs)).To(ConsistOf(
+			"out_es_flows on node node-a has been retrying since 2020-06-01 10:00:00 +0000 (3 retries)",
+		))
+	})
+
+	It("should read the monitors of the pods concurrently within the timeout", func() {
+		for _, name := range []string{"fluentd-a", "fluentd-b", "fluentd-c", "fluentd-d"} {
+			createPod(name, "node-"+name, corev1.PodRunning)
+			responses["/"+name] = `{"plugins": [{"plugin_id": "out_es_flows", "plugin_category": "output"}]}`
+		}
+		delay = 300 * time.Millisecond
+		m.timeout = time.Second
+
+		start := time.Now()
+		outputs, err := m.outputs(context.Background())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(outputs).To(HaveLen(4))
+		Expect(time.Since(start)).To(BeNumerically("<", 4*delay))
+
+		By("skipping the pods that don't respond before the timeout")
+		delay = 2 * time.Second
+		m.timeout = 100 * time.Millisecond
+		start = time.Now()
+		outputs, err = m.outputs(context.Background())
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(outputs).To(BeEmpty())
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+	})
+})
@@ -0,0 +1,156 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logcollector
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	operatorv1 "github.com/tigera/operator/pkg/apis/operator/v1"
+	"github.com/tigera/operator/pkg/render"
+)
+
+// validateCustomResource validates that the given custom resource is correct. This
+// should be called before rendering objects.
+func validateCustomResource(instance *operatorv1.LogCollector) error {
+	if flowLogs := instance.Spec.FlowLogs; flowLogs != nil {
+		if flowLogs.FlushInterval < 0 {
+			return fmt.Errorf("FlowLogs config has negative FlushInterval %d", flowLogs.FlushInterval)
+		}
+		if flowLogs.PerNodeLimit != nil && *flowLogs.PerNodeLimit < 0 {
+			return fmt.Errorf("FlowLogs config has negative PerNodeLimit %d", *flowLogs.PerNodeLimit)
+		}
+	}
+
+	if componentLogs := instance.Spec.ComponentLogs; componentLogs != nil {
+		if instance.Spec.Collector == operatorv1.LogCollectorTypeFluentBit {
+			return fmt.Errorf("Fluent Bit collector does not collect component logs")
+		}
+		if path := componentLogs.KubeAPIServerAuditLogPath; path != "" {
+			if !filepath.IsAbs(path) || strings.HasSuffix(path, "/") {
+				return fmt.Errorf("ComponentLogs config has invalid KubeAPIServerAuditLogPath %q: must be the absolute path of a file", path)
+			}
+		}
+	}
+
+	stores := instance.Spec.AdditionalStores
+	if stores == nil {
+		return nil
+	}
+
+	if stores.Syslog != nil {
+		if err := validateSyslogEndpoint(stores.Syslog.Endpoint); err != nil {
+			return fmt.Errorf("Syslog config has invalid Endpoint: %s", err)
+		}
+	}
+	if stores.Splunk != nil {
+		proto, _, _, err := render.ParseEndpoint(stores.Splunk.Endpoint)
+		if err != nil {
+			return fmt.Errorf("Splunk config has invalid Endpoint: %s", err)
+		}
+		if proto != "http" && proto != "https" {
+			return fmt.Errorf("Splunk config has invalid Endpoint: protocol must be http or https")
+		}
+	}
+	if stores.Kafka != nil {
+		if len(stores.Kafka.Brokers) == 0 {
+			return fmt.Errorf("Kafka config has no Brokers")
+		}
+		for _, broker := range stores.Kafka.Brokers {
+			if _, _, err := render.ParseHostPort(broker); err != nil {
+				return fmt.Errorf("Kafka config has invalid Broker %q: %s", broker, err)
+			}
+		}
+		if stores.Kafka.Topic == "" {
+			return fmt.Errorf("Kafka config has no Topic")
+		}
+	}
+	if stores.HTTP != nil {
+		u, err := url.Parse(stores.HTTP.Endpoint)
+		if err != nil {
+			return fmt.Errorf("HTTP config has invalid Endpoint: %s", err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("HTTP config has invalid Endpoint: must be an http or https URL")
+		}
+	}
+	if instance.Spec.Collector == operatorv1.LogCollectorTypeFluentBit {
+		if err := validateFluentBitStores(stores); err != nil {
+			return fmt.Errorf("Fluent Bit collector %s", err)
+		}
+	}
+
+	return nil
+}
+
+// validateFluentBitStores checks that only the log stores, log types and features supported by the Fluent Bit
+// collector are used.
+func validateFluentBitStores(stores *operatorv1.AdditionalLogStoreSpec) error {
+	switch {
+	case stores.Splunk != nil:
+		return fmt.Errorf("does not support the Splunk store")
+	case stores.Kafka != nil:
+		return fmt.Errorf("does not support the Kafka store")
+	case stores.HTTP != nil:
+		return fmt.Errorf("does not support the HTTP store")
+	}
+
+	type store struct {
+		name     string
+		logTypes []operatorv1.StoreLogType
+		filter   string
+	}
+	var configured []store
+	if stores.S3 != nil {
+		configured = append(configured, store{"S3", stores.S3.LogTypes, stores.S3.Filter})
+	}
+	if stores.Syslog != nil {
+		configured = append(configured, store{"Syslog", stores.Syslog.LogTypes, stores.Syslog.Filter})
+	}
+	for _, s := range configured {
+		if s.filter != "" {
+			return fmt.Errorf("does not support the Filter of the %s store", s.name)
+		}
+		for _, t := range s.logTypes {
+			if t != operatorv1.StoreLogTypeFlows && t != operatorv1.StoreLogTypeDNS {
+				return fmt.Errorf("only collects Flows and DNS logs, the %s store has LogType %s", s.name, t)
+			}
+		}
+	}
+	return nil
+}
+
+// validateSyslogEndpoint checks that a syslog endpoint has a supported protocol, a host and a port.
+func validateSyslogEndpoint(endpoint string) error {
+	proto, host, port, err := render.ParseEndpoint(endpoint)
+	if err != nil {
+		return err
+	}
+	switch proto {
+	case "tcp", "udp", "tls":
+	default:
+		return fmt.Errorf("protocol %q is not one of tcp, udp or tls", proto)
+	}
+	if host == "" {
+		return fmt.Errorf("host is missing")
+	}
+	if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+		return fmt.Errorf("port %q is not a number between 1 and 65535", port)
+	}
+	return nil
+}
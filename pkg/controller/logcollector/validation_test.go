@@ -0,0 +1,122 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logcollector
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	operatorv1 "github.com/tigera/operator/pkg/apis/operator/v1"
+)
+
+var _ = Describe("LogCollector validation tests", func() {
+	DescribeTable("syslog endpoints",
+		func(endpoint string, valid bool) {
+			instance := &operatorv1.LogCollector{
+				Spec: operatorv1.LogCollectorSpec{
+					AdditionalStores: &operatorv1.AdditionalLogStoreSpec{
+						Syslog: &operatorv1.SyslogStoreSpec{Endpoint: endpoint},
+					},
+				},
+			}
+			err := validateCustomResource(instance)
+			if valid {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(HavePrefix("Syslog config has invalid Endpoint: "))
+			}
+		},
+		Entry("tcp", "tcp://1.2.3.4:601", true),
+		Entry("udp", "udp://syslog.example.com:514", true),
+		Entry("tls", "tls://1.2.3.4:6514", true),
+		Entry("unsupported protocol", "http://1.2.3.4:601", false),
+		Entry("missing port", "tcp://1.2.3.4", false),
+		Entry("non-numeric port", "tcp://1.2.3.4:syslog", false),
+		Entry("port out of range", "tcp://1.2.3.4:70000", false),
+		Entry("missing host", "tcp://:601", false),
+		Entry("not a URL", "1.2.3.4:601", false),
+	)
+
+	It("should reject an HTTP store without a URL", func() {
+		instance := &operatorv1.LogCollector{
+			Spec: operatorv1.LogCollectorSpec{
+				AdditionalStores: &operatorv1.AdditionalLogStoreSpec{
+					HTTP: &operatorv1.HTTPStoreSpec{Endpoint: "logs.example.com"},
+				},
+			},
+		}
+		Expect(validateCustomResource(instance)).To(HaveOccurred())
+	})
+
+	DescribeTable("Fluent Bit stores",
+		func(stores *operatorv1.AdditionalLogStoreSpec, valid bool) {
+			instance := &operatorv1.LogCollector{
+				Spec: operatorv1.LogCollectorSpec{
+					Collector:        operatorv1.LogCollectorTypeFluentBit,
+					AdditionalStores: stores,
+				},
+			}
+			err := validateCustomResource(instance)
+			if valid {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(HavePrefix("Fluent Bit collector "))
+			}
+		},
+		Entry("S3 and syslog", &operatorv1.AdditionalLogStoreSpec{
+			S3:     &operatorv1.S3StoreSpec{BucketName: "logs"},
+			Syslog: &operatorv1.SyslogStoreSpec{Endpoint: "tcp://1.2.3.4:601"},
+		}, true),
+		Entry("flow and DNS logs", &operatorv1.AdditionalLogStoreSpec{
+			S3: &operatorv1.S3StoreSpec{LogTypes: []operatorv1.StoreLogType{operatorv1.StoreLogTypeFlows, operatorv1.StoreLogTypeDNS}},
+		}, true),
+		Entry("audit logs", &operatorv1.AdditionalLogStoreSpec{
+			S3: &operatorv1.S3StoreSpec{LogTypes: []operatorv1.StoreLogType{operatorv1.StoreLogTypeEEAudit}},
+		}, false),
+		Entry("a filter", &operatorv1.AdditionalLogStoreSpec{
+			Syslog: &operatorv1.SyslogStoreSpec{Endpoint: "tcp://1.2.3.4:601", Filter: "<filter **>\n</filter>"},
+		}, false),
+		Entry("Splunk", &operatorv1.AdditionalLogStoreSpec{
+			Splunk: &operatorv1.SplunkStoreSpec{Endpoint: "https://1.2.3.4:8088"},
+		}, false),
+	)
+	DescribeTable("component logs",
+		func(collector operatorv1.LogCollectorType, componentLogs *operatorv1.ComponentLogsSpec, valid bool) {
+			instance := &operatorv1.LogCollector{
+				Spec: operatorv1.LogCollectorSpec{
+					Collector:     collector,
+					ComponentLogs: componentLogs,
+				},
+			}
+			err := validateCustomResource(instance)
+			if valid {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(HaveOccurred())
+			}
+		},
+		Entry("container logs", operatorv1.LogCollectorTypeFluentd, &operatorv1.ComponentLogsSpec{Containers: true}, true),
+		Entry("audit log file", operatorv1.LogCollectorTypeFluentd,
+			&operatorv1.ComponentLogsSpec{KubeAPIServerAuditLogPath: "/var/log/kubernetes/audit.log"}, true),
+		Entry("relative audit log path", operatorv1.LogCollectorTypeFluentd,
+			&operatorv1.ComponentLogsSpec{KubeAPIServerAuditLogPath: "kubernetes/audit.log"}, false),
+		Entry("audit log directory", operatorv1.LogCollectorTypeFluentd,
+			&operatorv1.ComponentLogsSpec{KubeAPIServerAuditLogPath: "/var/log/kubernetes/"}, false),
+		Entry("Fluent Bit", operatorv1.LogCollectorTypeFluentBit, &operatorv1.ComponentLogsSpec{Containers: true}, false),
+	)
+})
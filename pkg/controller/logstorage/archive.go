@@ -0,0 +1,268 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	operatorv1 "github.com/tigera/operator/pkg/apis/operator/v1"
+	"github.com/tigera/operator/pkg/elasticsearch"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// archiveSnapshotPrefix precedes the name of an index in the name of the snapshot it is archived into.
+	archiveSnapshotPrefix = "archive-"
+
+	// Keys of the metadata of an archive snapshot. The start and end of the logs in the archived index are in
+	// milliseconds since the epoch.
+	archiveMetadataLogType = "log_type"
+	archiveMetadataStart   = "start_time"
+	archiveMetadataEnd     = "end_time"
+)
+
+// archiveLogTypes maps the log types that can be archived to the name of their logType.
+var archiveLogTypes = map[operatorv1.ArchiveLogType]string{
+	operatorv1.ArchiveLogTypeFlows: "flows",
+	operatorv1.ArchiveLogTypeDNS:   "dns",
+}
+
+var defaultArchiveLogTypes = []operatorv1.ArchiveLogType{operatorv1.ArchiveLogTypeFlows, operatorv1.ArchiveLogTypeDNS}
+
+// restoredIndexSettings are the settings of an archived index that are not restored, so the restored index is not
+// managed by index lifecycle management and is not archived again.
+var restoredIndexSettings = []string{"index.lifecycle.name", "index.lifecycle.rollover_alias"}
+
+// archivedLogTypes returns the names of the log types that are archived.
+func archivedLogTypes(ls *operatorv1.LogStorage) map[string]bool {
+	archived := map[string]bool{}
+	if ls.Spec.Snapshots == nil || ls.Spec.Snapshots.Archive == nil {
+		return archived
+	}
+	for _, t := range ls.Spec.Snapshots.Archive.LogTypes {
+		archived[archiveLogTypes[t]] = true
+	}
+	return archived
+}
+
+// archivedIndex is an index archived, or being archived, into a snapshot.
+type archivedIndex struct {
+	snapshot string
+	index    string
+	state    string
+	reason   string
+	start    time.Time
+	end      time.Time
+}
+
+func newArchivedIndex(s elasticsearch.SnapshotInfo) archivedIndex {
+	return archivedIndex{
+		snapshot: s.Snapshot,
+		index:    s.Snapshot[len(archiveSnapshotPrefix):],
+		state:    s.State,
+		reason:   s.Reason,
+		start:    metadataTime(s.Metadata, archiveMetadataStart),
+		end:      metadataTime(s.Metadata, archiveMetadataEnd),
+	}
+}
+
+// metadataTime returns the time held in milliseconds since the epoch under key, which is decoded from JSON as a
+// float64.
+func metadataTime(metadata map[string]interface{}, key string) time.Time {
+	millis, _ := metadata[key].(float64)
+	return time.Unix(0, int64(millis)*int64(time.Millisecond))
+}
+
+func days(n int32) time.Duration {
+	return time.Duration(n) * 24 * time.Hour
+}
+
+// reconcileArchive deletes the archived indices whose retention period has passed and archives the indices of the
+// archived log types of the given clusters whose retention period has passed. An index is deleted once its snapshot has completed. Only one
+// snapshot is taken at a time, as Elasticsearch can't take or delete snapshots concurrently. It returns the status
+// of the archive.
+func reconcileArchive(ctx context.Context, esClient *elasticsearch.Client, ls *operatorv1.LogStorage, clusterNames []string, now time.Time) (*operatorv1.ArchiveStatus, error) {
+	archive := ls.Spec.Snapshots.Archive
+	snapshots, err := esClient.Snapshots(ctx, snapshotRepositoryName, archiveSnapshotPrefix+"*")
+	if err != nil {
+		return nil, err
+	}
+
+	status := &operatorv1.ArchiveStatus{}
+	// A failure is reported until an index is archived successfully.
+	if ls.Status.Archive != nil {
+		status.Error = ls.Status.Archive.Error
+	}
+
+	archives := map[string]archivedIndex{}
+	for _, s := range snapshots {
+		a := newArchivedIndex(s)
+		archives[a.index] = a
+		if a.state == elasticsearch.SnapshotInProgress {
+			status.InProgress = a.index
+		}
+	}
+
+	names := make([]string, 0, len(archives))
+	for name := range archives {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		a := archives[name]
+		if a.state != elasticsearch.SnapshotSuccess {
+			continue
+		}
+		if archive.RetentionDays != nil && now.Sub(a.end) > days(*archive.RetentionDays) && status.InProgress == "" {
+			err := esClient.DeleteSnapshot(ctx, snapshotRepositoryName, a.snapshot)
+			if err == nil {
+				delete(archives, name)
+				continue
+			}
+			if !elasticsearch.IsConcurrentSnapshot(err) {
+				return nil, fmt.Errorf("failed to delete archived index %s: %v", a.index, err)
+			}
+		}
+
+		status.Indices++
+		if status.OldestLog == nil || a.start.Before(status.OldestLog.Time) {
+			status.OldestLog = &metav1.Time{Time: a.start}
+		}
+		if status.NewestLog == nil || a.end.After(status.NewestLog.Time) {
+			status.NewestLog = &metav1.Time{Time: a.end}
+		}
+	}
+
+	archived := archivedLogTypes(ls)
+	for _, l := range logTypes(ls.Spec.Retention) {
+		if !archived[l.name] {
+			continue
+		}
+		for _, prefix := range l.indexPrefixes {
+			indices := map[string]elasticsearch.IndexLifecycle{}
+			for _, clusterName := range clusterNames {
+				clusterIndices, err := esClient.ExplainLifecycle(ctx, rolloverAlias(prefix, clusterName)+"*")
+				if err != nil {
+					return nil, err
+				}
+				for name, index := range clusterIndices {
+					indices[name] = index
+				}
+			}
+			names := make([]string, 0, len(indices))
+			for name := range indices {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			for _, name := range names {
+				index := indices[name]
+				// Indices are archived once they have left the hot phase. Rolled over indices leave it when they are
+				// rolled over, which sets their lifecycle date. Dated indices leave it once the rollover age of the log
+				// type has passed since they were created, which is their lifecycle date, and were written to until
+				// then. Restored indices are not managed.
+				dated := index.Policy == l.datedPolicyName()
+				if !index.Managed || (index.Policy != l.policyName() && !dated) || index.Phase == "hot" || index.Phase == "new" || index.LifecycleDateMillis == 0 {
+					continue
+				}
+				end := time.Unix(0, index.LifecycleDateMillis*int64(time.Millisecond))
+				if dated {
+					written, err := parseTimeValue(l.rolloverAge)
+					if err != nil {
+						return nil, err
+					}
+					end = end.Add(written)
+				}
+				if now.Sub(end) < days(l.retentionDays) {
+					continue
+				}
+
+				a, ok := archives[name]
+				switch {
+				case !ok:
+					if status.InProgress != "" {
+						continue
+					}
+					start, err := esClient.IndexCreationDate(ctx, name)
+					if err != nil {
+						return nil, err
+					}
+					err = esClient.CreateSnapshot(ctx, snapshotRepositoryName, archiveSnapshotPrefix+name, elasticsearch.Snapshot{
+						Indices: name,
+						Metadata: map[string]interface{}{
+							archiveMetadataLogType: l.name,
+							archiveMetadataStart:   start,
+							archiveMetadataEnd:     end.UnixNano() / int64(time.Millisecond),
+						},
+					})
+					if elasticsearch.IsConcurrentSnapshot(err) {
+						// A scheduled snapshot is being taken. The index is archived once it has completed.
+						continue
+					} else if err != nil {
+						return nil, fmt.Errorf("failed to archive index %s: %v", name, err)
+					}
+					status.InProgress = name
+				case a.state == elasticsearch.SnapshotSuccess:
+					if err := esClient.DeleteIndex(ctx, name); err != nil {
+						return nil, fmt.Errorf("failed to delete archived index %s: %v", name, err)
+					}
+					status.Error = ""
+				case a.state != elasticsearch.SnapshotInProgress:
+					status.Error = fmt.Sprintf("snapshot %s of index %s ended in state %s", a.snapshot, name, a.state)
+					if a.reason != "" {
+						status.Error += ": " + a.reason
+					}
+					// The snapshot is deleted so the index is archived again.
+					if status.InProgress == "" {
+						if err := esClient.DeleteSnapshot(ctx, snapshotRepositoryName, a.snapshot); err != nil && !elasticsearch.IsConcurrentSnapshot(err) {
+							return nil, err
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return status, nil
+}
+
+// archivedSnapshots returns the sorted names of the snapshots holding the archived logs of the log type and cluster
+// that overlap the period of time of the restore.
+func archivedSnapshots(ctx context.Context, esClient *elasticsearch.Client, ls *operatorv1.LogStorage, clusterName string, restore *operatorv1.ArchiveRestore) ([]string, error) {
+	var names []string
+	for _, l := range logTypes(ls.Spec.Retention) {
+		if l.name != archiveLogTypes[restore.LogType] {
+			continue
+		}
+		for _, prefix := range l.indexPrefixes {
+			snapshots, err := esClient.Snapshots(ctx, snapshotRepositoryName, archiveSnapshotPrefix+rolloverAlias(prefix, clusterName)+"*")
+			if err != nil {
+				return nil, err
+			}
+			for _, s := range snapshots {
+				a := newArchivedIndex(s)
+				if a.state == elasticsearch.SnapshotSuccess && !a.start.After(restore.To.Time) && !a.end.Before(restore.From.Time) {
+					names = append(names, a.snapshot)
+				}
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,255 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	operatorv1 "github.com/tigera/operator/pkg/apis/operator/v1"
+	"github.com/tigera/operator/pkg/elasticsearch"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Archive tests", func() {
+	var ls *operatorv1.LogStorage
+	var server *httptest.Server
+	var esClient *elasticsearch.Client
+	var requests []string
+	var snapshots, explain, health string
+	var now time.Time
+
+	BeforeEach(func() {
+		retentionDays := int32(30)
+		ls = &operatorv1.LogStorage{
+			Spec: operatorv1.LogStorageSpec{
+				Snapshots: &operatorv1.Snapshots{
+					Repository: operatorv1.SnapshotRepository{
+						S3: &operatorv1.S3SnapshotRepository{Bucket: "snapshots"},
+					},
+					Archive: &operatorv1.SnapshotArchive{LogTypes: []operatorv1.ArchiveLogType{operatorv1.ArchiveLogTypeFlows}, RetentionDays: &retentionDays},
+				},
+			},
+		}
+		fillDefaults(ls)
+		// The flows index was rolled over on 2020-01-01, so its retention period of 8 days has passed.
+		now = time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC)
+
+		requests = nil
+		snapshots = `{"snapshots": []}`
+		explain = `{"indices": {}}`
+		health = `{"status": "green", "indices": {}}`
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			switch {
+			case r.Method == http.MethodGet && r.URL.Path == "/_snapshot/tigera-secure-snapshots/archive-*":
+				_, _ = w.Write([]byte(snapshots))
+			case r.Method == http.MethodGet && r.URL.Path == "/_snapshot/tigera-secure-snapshots/archive-tigera_secure_ee_flows.cluster.*":
+				_, _ = w.Write([]byte(snapshots))
+			case r.URL.Path == "/tigera_secure_ee_flows.cluster.*/_ilm/explain":
+				_, _ = w.Write([]byte(explain))
+			case strings.HasSuffix(r.URL.Path, "/_settings/index.creation_date"):
+				index := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/"), "/_settings/index.creation_date")
+				_, _ = w.Write([]byte(`{"` + index + `": {"settings": {"index": {"creation_date": "1577750400000"}}}}`))
+			case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/_snapshot/tigera-secure-snapshots/archive-tigera_secure_ee_flows.cluster.ilm-"):
+				snapshot := strings.TrimPrefix(r.URL.Path, "/_snapshot/tigera-secure-snapshots/")
+				_, _ = w.Write([]byte(`{"snapshots": [{"snapshot": "` + snapshot + `", "state": "SUCCESS", "indices": ["` +
+					strings.TrimPrefix(snapshot, archiveSnapshotPrefix) + `"]}]}`))
+			case r.URL.Path == "/_cluster/health":
+				_, _ = w.Write([]byte(health))
+			default:
+				_, _ = w.Write([]byte(`{}`))
+			}
+		}))
+
+		var err error
+		esClient, err = elasticsearch.NewClient(server.URL, "elastic", "elasticpw", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("archives the indices of archived log types only", func() {
+		Expect(archivedLogTypes(ls)).To(Equal(map[string]bool{"flows": true}))
+
+		ls.Spec.Snapshots.Archive.LogTypes = nil
+		fillDefaults(ls)
+		Expect(archivedLogTypes(ls)).To(Equal(map[string]bool{"flows": true, "dns": true}))
+	})
+
+	It("rejects an archive retention of less than a day", func() {
+		Expect(validateSnapshots(ls)).NotTo(HaveOccurred())
+		*ls.Spec.Snapshots.Archive.RetentionDays = 0
+		Expect(validateSnapshots(ls)).To(HaveOccurred())
+	})
+
+	It("takes a snapshot of a rolled over index once its retention period has passed", func() {
+		explain = `{"indices": {
+			"tigera_secure_ee_flows.cluster.ilm-000001": {"managed": true, "policy": "tigera_secure_ee_flows_policy", "phase": "warm", "lifecycle_date_millis": 1577836800000},
+			"tigera_secure_ee_flows.cluster.ilm-000002": {"managed": true, "policy": "tigera_secure_ee_flows_policy", "phase": "hot", "lifecycle_date_millis": 1577836800000},
+			"tigera_secure_ee_flows.cluster.restored": {"managed": false}
+		}}`
+
+		status, err := reconcileArchive(context.Background(), esClient, ls, []string{"cluster"}, now)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(status.InProgress).To(Equal("tigera_secure_ee_flows.cluster.ilm-000001"))
+		Expect(requests).To(ContainElement("PUT /_snapshot/tigera-secure-snapshots/archive-tigera_secure_ee_flows.cluster.ilm-000001"))
+		Expect(requests).NotTo(ContainElement("PUT /_snapshot/tigera-secure-snapshots/archive-tigera_secure_ee_flows.cluster.ilm-000002"))
+		Expect(requests).NotTo(ContainElement(ContainSubstring("DELETE")))
+
+		By("not archiving the index before its retention period has passed")
+		requests = nil
+		_, err = reconcileArchive(context.Background(), esClient, ls, []string{"cluster"}, now.Add(-3*24*time.Hour))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(requests).NotTo(ContainElement(ContainSubstring("PUT")))
+	})
+
+	It("takes a snapshot of a dated index once its retention period has passed since it was last written to", func() {
+		explain = `{"indices": {
+			"tigera_secure_ee_flows.cluster.20200101": {"managed": true, "policy": "tigera_secure_ee_flows_dated_policy", "phase": "warm", "lifecycle_date_millis": 1577750400000}
+		}}`
+
+		status, err := reconcileArchive(context.Background(), esClient, ls, []string{"cluster"}, now)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(status.InProgress).To(Equal("tigera_secure_ee_flows.cluster.20200101"))
+		Expect(requests).To(ContainElement("PUT /_snapshot/tigera-secure-snapshots/archive-tigera_secure_ee_flows.cluster.20200101"))
+
+		By("not archiving the index before its retention period has passed since the end of its rollover age")
+		requests = nil
+		_, err = reconcileArchive(context.Background(), esClient, ls, []string{"cluster"}, now.Add(-2*24*time.Hour))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(requests).NotTo(ContainElement(ContainSubstring("PUT")))
+	})
+
+	It("deletes an index once it has been archived and reports the archived logs", func() {
+		explain = `{"indices": {
+			"tigera_secure_ee_flows.cluster.ilm-000001": {"managed": true, "policy": "tigera_secure_ee_flows_policy", "phase": "warm", "lifecycle_date_millis": 1577836800000}
+		}}`
+		snapshots = `{"snapshots": [{"snapshot": "archive-tigera_secure_ee_flows.cluster.ilm-000001", "state": "SUCCESS",
+			"metadata": {"log_type": "flows", "start_time": 1577750400000, "end_time": 1577836800000}}]}`
+		ls.Status.Archive = &operatorv1.ArchiveStatus{Error: "snapshot failed"}
+
+		status, err := reconcileArchive(context.Background(), esClient, ls, []string{"cluster"}, now)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(requests).To(ContainElement("DELETE /tigera_secure_ee_flows.cluster.ilm-000001"))
+		Expect(status.Indices).To(Equal(int32(1)))
+		Expect(status.OldestLog.Time.UTC()).To(Equal(time.Date(2019, 12, 31, 0, 0, 0, 0, time.UTC)))
+		Expect(status.NewestLog.Time.UTC()).To(Equal(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)))
+		Expect(status.Error).To(BeEmpty())
+	})
+
+	It("deletes archived logs once the archive retention period has passed", func() {
+		snapshots = `{"snapshots": [{"snapshot": "archive-tigera_secure_ee_flows.cluster.ilm-000001", "state": "SUCCESS",
+			"metadata": {"log_type": "flows", "start_time": 1577750400000, "end_time": 1577836800000}}]}`
+
+		status, err := reconcileArchive(context.Background(), esClient, ls, []string{"cluster"}, now.Add(30*24*time.Hour))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(requests).To(ContainElement("DELETE /_snapshot/tigera-secure-snapshots/archive-tigera_secure_ee_flows.cluster.ilm-000001"))
+		Expect(status.Indices).To(BeZero())
+	})
+
+	It("reports a failed snapshot and deletes it so the index is archived again", func() {
+		explain = `{"indices": {
+			"tigera_secure_ee_flows.cluster.ilm-000001": {"managed": true, "policy": "tigera_secure_ee_flows_policy", "phase": "warm", "lifecycle_date_millis": 1577836800000}
+		}}`
+		snapshots = `{"snapshots": [{"snapshot": "archive-tigera_secure_ee_flows.cluster.ilm-000001", "state": "FAILED", "reason": "access denied"}]}`
+
+		status, err := reconcileArchive(context.Background(), esClient, ls, []string{"cluster"}, now)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(status.Error).To(Equal("snapshot archive-tigera_secure_ee_flows.cluster.ilm-000001 of index tigera_secure_ee_flows.cluster.ilm-000001 ended in state FAILED: access denied"))
+		Expect(requests).To(ContainElement("DELETE /_snapshot/tigera-secure-snapshots/archive-tigera_secure_ee_flows.cluster.ilm-000001"))
+		Expect(requests).NotTo(ContainElement("DELETE /tigera_secure_ee_flows.cluster.ilm-000001"))
+	})
+
+	It("restores the archived snapshots that overlap the period of time one after the other", func() {
+		snapshots = `{"snapshots": [
+			{"snapshot": "archive-tigera_secure_ee_flows.cluster.ilm-000002", "state": "SUCCESS", "metadata": {"start_time": 1577836800000, "end_time": 1577923200000}},
+			{"snapshot": "archive-tigera_secure_ee_flows.cluster.ilm-000001", "state": "SUCCESS", "metadata": {"start_time": 1577750400000, "end_time": 1577836800000}},
+			{"snapshot": "archive-tigera_secure_ee_flows.cluster.ilm-000003", "state": "SUCCESS", "metadata": {"start_time": 1577923200000, "end_time": 1578009600000}}
+		]}`
+
+		scheme := runtime.NewScheme()
+		Expect(operatorv1.SchemeBuilder.AddToScheme(scheme)).ShouldNot(HaveOccurred())
+		cli := fake.NewFakeClientWithScheme(scheme)
+		r := &ReconcileLogStorage{client: cli}
+		ctx := context.Background()
+		Expect(cli.Create(ctx, &operatorv1.LogStorageRestore{
+			ObjectMeta: metav1.ObjectMeta{Name: "investigation"},
+			Spec: operatorv1.LogStorageRestoreSpec{
+				Archive: &operatorv1.ArchiveRestore{
+					LogType: operatorv1.ArchiveLogTypeFlows,
+					From:    metav1.NewTime(time.Date(2019, 12, 31, 12, 0, 0, 0, time.UTC)),
+					To:      metav1.NewTime(time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)),
+				},
+			},
+		})).ShouldNot(HaveOccurred())
+
+		restoring, failed, err := r.reconcileRestores(ctx, esClient, ls, "cluster")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(failed).To(BeEmpty())
+		Expect(restoring).To(Equal([]string{"restore investigation from snapshot archive-tigera_secure_ee_flows.cluster.ilm-000001"}))
+		Expect(requests).To(ContainElement("POST /_snapshot/tigera-secure-snapshots/archive-tigera_secure_ee_flows.cluster.ilm-000001/_restore"))
+
+		restore := &operatorv1.LogStorageRestore{}
+		Expect(cli.Get(ctx, types.NamespacedName{Name: "investigation"}, restore)).ShouldNot(HaveOccurred())
+		Expect(restore.Status.PendingSnapshots).To(Equal([]string{
+			"archive-tigera_secure_ee_flows.cluster.ilm-000001",
+			"archive-tigera_secure_ee_flows.cluster.ilm-000002",
+		}))
+
+		By("waiting for the indices of the first snapshot to be restored")
+		_, _, err = r.reconcileRestores(ctx, esClient, ls, "cluster")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(requests).NotTo(ContainElement("POST /_snapshot/tigera-secure-snapshots/archive-tigera_secure_ee_flows.cluster.ilm-000002/_restore"))
+
+		By("restoring the next snapshot once the first has been restored")
+		health = `{"status": "green", "indices": {"tigera_secure_ee_flows.cluster.ilm-000001": {"status": "green"}}}`
+		_, _, err = r.reconcileRestores(ctx, esClient, ls, "cluster")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(requests).To(ContainElement("POST /_snapshot/tigera-secure-snapshots/archive-tigera_secure_ee_flows.cluster.ilm-000002/_restore"))
+
+		By("completing the restore once the last snapshot has been restored")
+		health = `{"status": "green", "indices": {
+			"tigera_secure_ee_flows.cluster.ilm-000001": {"status": "green"},
+			"tigera_secure_ee_flows.cluster.ilm-000002": {"status": "yellow"}
+		}}`
+		_, _, err = r.reconcileRestores(ctx, esClient, ls, "cluster")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(cli.Get(ctx, types.NamespacedName{Name: "investigation"}, restore)).ShouldNot(HaveOccurred())
+		Expect(restore.Status.State).To(Equal(operatorv1.LogStorageRestoreStateCompleted))
+		Expect(restore.Status.PendingSnapshots).To(BeEmpty())
+	})
+
+	It("fails a restore that sets both a snapshot and an archive", func() {
+		restore := &operatorv1.LogStorageRestore{Spec: operatorv1.LogStorageRestoreSpec{
+			Snapshot: "snapshot-1",
+			Archive:  &operatorv1.ArchiveRestore{LogType: operatorv1.ArchiveLogTypeFlows},
+		}}
+		status := startRestore(context.Background(), esClient, ls, "cluster", restore)
+		Expect(status.State).To(Equal(operatorv1.LogStorageRestoreStateFailed))
+	})
+})
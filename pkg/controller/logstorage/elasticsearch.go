@@ -0,0 +1,114 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"fmt"
+
+	operatorv1 "github.com/tigera/operator/pkg/apis/operator/v1"
+	"github.com/tigera/operator/pkg/elasticsearch"
+	"github.com/tigera/operator/pkg/render"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// elasticsearchClient returns a client for the Elasticsearch cluster of ls, authenticated as a user that can manage
+// the cluster.
+func (r *ReconcileLogStorage) elasticsearchClient(ctx context.Context, ls *operatorv1.LogStorage) (*elasticsearch.Client, error) {
+	if ls.Spec.External != nil {
+		esClient, _, err := r.externalElasticsearchClient(ctx, ls.Spec.External)
+		return esClient, err
+	}
+
+	ca, err := r.secretValue(ctx, render.ElasticsearchPublicCertSecret, render.ElasticsearchNamespace, corev1.TLSCertKey)
+	if err != nil {
+		return nil, err
+	}
+	password, err := r.secretValue(ctx, render.ElasticsearchAdminUserSecret, render.ElasticsearchNamespace, render.ElasticsearchAdminUser)
+	if err != nil {
+		return nil, err
+	}
+
+	return elasticsearch.NewClient(r.esEndpoint, render.ElasticsearchAdminUser, string(password), ca)
+}
+
+// externalElasticsearchClient returns a client for the external Elasticsearch cluster, authenticated with the admin
+// credentials, and the CA bundle of the cluster.
+func (r *ReconcileLogStorage) externalElasticsearchClient(ctx context.Context, ext *operatorv1.ExternalElasticsearch) (*elasticsearch.Client, []byte, error) {
+	ca, err := r.secretValue(ctx, ext.CASecretName, render.OperatorNamespace(), corev1.TLSCertKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	username, err := r.secretValue(ctx, ext.AdminSecretName, render.OperatorNamespace(), "username")
+	if err != nil {
+		return nil, nil, err
+	}
+	password, err := r.secretValue(ctx, ext.AdminSecretName, render.OperatorNamespace(), "password")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	esClient, err := elasticsearch.NewClient(ext.Endpoint, string(username), string(password), ca)
+	return esClient, ca, err
+}
+
+// secureSettingsSecret returns the secret that ECK adds to the keystore of the Elasticsearch nodes, holding the
+// credentials of the snapshot repository and the client secret of the OpenID Connect provider, or nil if neither is
+// needed.
+func (r *ReconcileLogStorage) secureSettingsSecret(ctx context.Context, ls *operatorv1.LogStorage) (*corev1.Secret, error) {
+	if !render.ElasticsearchHasSecureSettings(ls) {
+		return nil, nil
+	}
+
+	data := map[string][]byte{}
+	if ls.Spec.Snapshots != nil {
+		settings, err := r.snapshotSecureSettings(ctx, ls.Spec.Snapshots.Repository)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range settings {
+			data[key] = value
+		}
+	}
+	if render.KibanaSSORealm(ls) == render.KibanaOIDCRealm {
+		oidc := ls.Spec.Kibana.Auth.OIDC
+		value, err := r.secretValue(ctx, oidc.ClientSecretName, render.OperatorNamespace(), "clientSecret")
+		if err != nil {
+			return nil, err
+		}
+		data[fmt.Sprintf("xpack.security.authc.realms.oidc.%s.rp.client_secret", render.KibanaOIDCRealm)] = value
+	}
+
+	return &corev1.Secret{
+		TypeMeta:   metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchSecureSettingsSecret, Namespace: render.ElasticsearchNamespace},
+		Data:       data,
+	}, nil
+}
+
+// secretValue returns the non empty value of key in the secret.
+func (r *ReconcileLogStorage) secretValue(ctx context.Context, name, namespace, key string) ([]byte, error) {
+	secret := &corev1.Secret{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, secret); err != nil {
+		return nil, err
+	}
+	if len(secret.Data[key]) == 0 {
+		return nil, fmt.Errorf("secret %s/%s does not contain %s", namespace, name, key)
+	}
+	return secret.Data[key], nil
+}
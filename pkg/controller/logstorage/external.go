@@ -0,0 +1,68 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	operatorv1 "github.com/tigera/operator/pkg/apis/operator/v1"
+	"github.com/tigera/operator/pkg/render"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// validateExternalElasticsearch returns an error if the external Elasticsearch configuration is not usable.
+func validateExternalElasticsearch(ext *operatorv1.ExternalElasticsearch) error {
+	scheme, _, _, err := render.ParseEndpoint(ext.Endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid external Elasticsearch endpoint %q: %v", ext.Endpoint, err)
+	}
+	if scheme != "https" {
+		return fmt.Errorf("external Elasticsearch endpoint %q must use https", ext.Endpoint)
+	}
+	if ext.CASecretName == "" {
+		return fmt.Errorf("external Elasticsearch caSecretName must be set")
+	}
+	if ext.AdminSecretName == "" {
+		return fmt.Errorf("external Elasticsearch adminSecretName must be set")
+	}
+	return nil
+}
+
+// reconcileExternalElasticsearch creates the users and roles for each component in the external Elasticsearch
+// cluster, with read access to the indices of the given managed clusters. It returns the secrets to render: the CA
+// bundle of the cluster, copied into the secret that components mount to verify Elasticsearch, and the credentials of
+// each user.
+func (r *ReconcileLogStorage) reconcileExternalElasticsearch(ctx context.Context, ext *operatorv1.ExternalElasticsearch, clusterName string, managedClusters []string, rotation time.Duration) ([]*corev1.Secret, error) {
+	esClient, ca, err := r.externalElasticsearchClient(ctx, ext)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := []*corev1.Secret{{
+		TypeMeta:   metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchPublicCertSecret, Namespace: render.OperatorNamespace()},
+		Data:       map[string][]byte{corev1.TLSCertKey: ca},
+	}}
+
+	userSecrets, err := r.reconcileUsers(ctx, esClient, clusterName, managedClusters, rotation)
+	if err != nil {
+		return nil, err
+	}
+	return append(secrets, userSecrets...), nil
+}
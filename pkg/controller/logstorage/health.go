@@ -0,0 +1,158 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	operatorv1 "github.com/tigera/operator/pkg/apis/operator/v1"
+	"github.com/tigera/operator/pkg/elasticsearch"
+)
+
+// healthPollInterval is how often the health of the Elasticsearch cluster is polled.
+const healthPollInterval = time.Minute
+
+// byteUnits are the multipliers of the units of byte sizes in Elasticsearch settings, longest suffix first.
+var byteUnits = []struct {
+	suffix string
+	bytes  float64
+}{
+	{"kb", 1 << 10},
+	{"mb", 1 << 20},
+	{"gb", 1 << 30},
+	{"tb", 1 << 40},
+	{"pb", 1 << 50},
+	{"b", 1},
+}
+
+// watermarkExceeded returns true if the disk usage of the node exceeds the watermark. A watermark is either the
+// percentage or ratio of the disk that may be used, or the disk space that must remain free. Watermarks that can't be
+// parsed are never exceeded.
+func watermarkExceeded(watermark string, disk elasticsearch.NodeDisk) bool {
+	watermark = strings.ToLower(strings.TrimSpace(watermark))
+	if watermark == "" || disk.Total == 0 {
+		return false
+	}
+	used := float64(disk.Used) / float64(disk.Total)
+
+	if strings.HasSuffix(watermark, "%") {
+		percent, err := strconv.ParseFloat(strings.TrimSuffix(watermark, "%"), 64)
+		return err == nil && used*100 > percent
+	}
+	if ratio, err := strconv.ParseFloat(watermark, 64); err == nil {
+		return ratio <= 1 && used > ratio
+	}
+	for _, unit := range byteUnits {
+		if strings.HasSuffix(watermark, unit.suffix) {
+			free, err := strconv.ParseFloat(strings.TrimSuffix(watermark, unit.suffix), 64)
+			return err == nil && float64(disk.Available) < free*unit.bytes
+		}
+	}
+	return false
+}
+
+// diskWatermark returns the highest watermark the disk usage of the node exceeds.
+func diskWatermark(disk elasticsearch.NodeDisk, watermarks *elasticsearch.DiskWatermarks) operatorv1.DiskWatermark {
+	switch {
+	case watermarkExceeded(watermarks.FloodStage, disk):
+		return operatorv1.DiskWatermarkFloodStage
+	case watermarkExceeded(watermarks.High, disk):
+		return operatorv1.DiskWatermarkHigh
+	case watermarkExceeded(watermarks.Low, disk):
+		return operatorv1.DiskWatermarkLow
+	}
+	return operatorv1.DiskWatermarkNone
+}
+
+// watermarkLevels orders the disk watermarks from lowest to highest.
+var watermarkLevels = map[operatorv1.DiskWatermark]int{
+	operatorv1.DiskWatermarkNone:       0,
+	operatorv1.DiskWatermarkLow:        1,
+	operatorv1.DiskWatermarkHigh:       2,
+	operatorv1.DiskWatermarkFloodStage: 3,
+}
+
+// elasticsearchHealth polls the health of the Elasticsearch cluster and the disk usage of its data nodes.
+func elasticsearchHealth(ctx context.Context, esClient *elasticsearch.Client) (*operatorv1.ElasticsearchHealth, error) {
+	clusterHealth, err := esClient.ClusterHealth(ctx)
+	if err != nil {
+		return nil, err
+	}
+	disks, err := esClient.NodeDiskUsage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	watermarks, err := esClient.DiskWatermarks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	health := &operatorv1.ElasticsearchHealth{
+		Status:             operatorv1.ElasticsearchHealthStatus(clusterHealth.Status),
+		UnassignedShards:   clusterHealth.UnassignedShards,
+		InitializingShards: clusterHealth.InitializingShards,
+		RelocatingShards:   clusterHealth.RelocatingShards,
+		Watermark:          operatorv1.DiskWatermarkNone,
+	}
+	for _, disk := range disks {
+		node := operatorv1.NodeDiskUsage{
+			Name:           disk.Node,
+			DiskUsedBytes:  disk.Used,
+			DiskTotalBytes: disk.Total,
+			DiskPercent:    disk.Percent,
+			Watermark:      diskWatermark(disk, watermarks),
+		}
+		if watermarkLevels[node.Watermark] > watermarkLevels[health.Watermark] {
+			health.Watermark = node.Watermark
+		}
+		health.Nodes = append(health.Nodes, node)
+	}
+	return health, nil
+}
+
+// healthConditions returns why the Elasticsearch cluster is degraded and why it is recovering according to its
+// health. A yellow cluster is recovering while shards are being initialized or relocated, otherwise replicas can't be
+// allocated and it is degraded.
+func healthConditions(health *operatorv1.ElasticsearchHealth) ([]string, []string) {
+	if health == nil {
+		return nil, nil
+	}
+
+	var degraded, recovering []string
+	switch health.Status {
+	case operatorv1.ElasticsearchHealthRed:
+		degraded = append(degraded, fmt.Sprintf("Elasticsearch cluster health is red, %d shards are unassigned", health.UnassignedShards))
+	case operatorv1.ElasticsearchHealthYellow:
+		if moving := health.InitializingShards + health.RelocatingShards; moving > 0 {
+			recovering = append(recovering, fmt.Sprintf("Elasticsearch cluster health is yellow, %d shards are being initialized or relocated", moving))
+		} else {
+			degraded = append(degraded, fmt.Sprintf("Elasticsearch cluster health is yellow, %d replica shards are unassigned", health.UnassignedShards))
+		}
+	}
+
+	for _, node := range health.Nodes {
+		switch node.Watermark {
+		case operatorv1.DiskWatermarkFloodStage:
+			degraded = append(degraded, fmt.Sprintf("Node %s has used %d%% of its disk and exceeded the flood stage watermark, its indices are read only", node.Name, node.DiskPercent))
+		case operatorv1.DiskWatermarkHigh:
+			degraded = append(degraded, fmt.Sprintf("Node %s has used %d%% of its disk and exceeded the high watermark, shards are moved off it", node.Name, node.DiskPercent))
+		}
+	}
+	return degraded, recovering
+}
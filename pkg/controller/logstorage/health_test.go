@@ -0,0 +1,82 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	operatorv1 "github.com/tigera/operator/pkg/apis/operator/v1"
+	"github.com/tigera/operator/pkg/elasticsearch"
+)
+
+var _ = Describe("Elasticsearch health tests", func() {
+	disk := elasticsearch.NodeDisk{Node: "es-0", Used: 88 << 30, Available: 12 << 30, Total: 100 << 30, Percent: 88}
+
+	It("should compare the disk usage with percentage, ratio and free space watermarks", func() {
+		Expect(watermarkExceeded("85%", disk)).To(BeTrue())
+		Expect(watermarkExceeded("90%", disk)).To(BeFalse())
+		Expect(watermarkExceeded("0.85", disk)).To(BeTrue())
+		Expect(watermarkExceeded("0.9", disk)).To(BeFalse())
+		Expect(watermarkExceeded("20gb", disk)).To(BeTrue())
+		Expect(watermarkExceeded("10gb", disk)).To(BeFalse())
+		Expect(watermarkExceeded("500mb", disk)).To(BeFalse())
+		Expect(watermarkExceeded("", disk)).To(BeFalse())
+		Expect(watermarkExceeded("lots", disk)).To(BeFalse())
+	})
+
+	It("should return the highest watermark exceeded", func() {
+		watermarks := &elasticsearch.DiskWatermarks{Low: "85%", High: "90%", FloodStage: "95%"}
+		Expect(diskWatermark(disk, watermarks)).To(Equal(operatorv1.DiskWatermarkLow))
+
+		watermarks.High = "80%"
+		Expect(diskWatermark(disk, watermarks)).To(Equal(operatorv1.DiskWatermarkHigh))
+
+		watermarks.FloodStage = "15gb"
+		Expect(diskWatermark(disk, watermarks)).To(Equal(operatorv1.DiskWatermarkFloodStage))
+	})
+
+	It("should report a yellow cluster as recovering while shards are moved", func() {
+		health := &operatorv1.ElasticsearchHealth{Status: operatorv1.ElasticsearchHealthYellow, UnassignedShards: 2, InitializingShards: 2}
+		degraded, recovering := healthConditions(health)
+		Expect(degraded).To(BeEmpty())
+		Expect(recovering).To(HaveLen(1))
+
+		health.InitializingShards = 0
+		degraded, recovering = healthConditions(health)
+		Expect(degraded).To(HaveLen(1))
+		Expect(recovering).To(BeEmpty())
+	})
+
+	It("should report the nodes above the high and flood stage watermarks", func() {
+		health := &operatorv1.ElasticsearchHealth{
+			Status: operatorv1.ElasticsearchHealthGreen,
+			Nodes: []operatorv1.NodeDiskUsage{
+				{Name: "es-0", DiskPercent: 86, Watermark: operatorv1.DiskWatermarkLow},
+				{Name: "es-1", DiskPercent: 91, Watermark: operatorv1.DiskWatermarkHigh},
+				{Name: "es-2", DiskPercent: 96, Watermark: operatorv1.DiskWatermarkFloodStage},
+			},
+		}
+		degraded, recovering := healthConditions(health)
+		Expect(degraded).To(HaveLen(2))
+		Expect(degraded[0]).To(ContainSubstring("es-1"))
+		Expect(degraded[1]).To(ContainSubstring("es-2"))
+		Expect(recovering).To(BeEmpty())
+
+		degraded, recovering = healthConditions(nil)
+		Expect(degraded).To(BeEmpty())
+		Expect(recovering).To(BeEmpty())
+	})
+})
@@ -0,0 +1,319 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	operatorv1 "github.com/tigera/operator/pkg/apis/operator/v1"
+	"github.com/tigera/operator/pkg/controller/utils"
+	"github.com/tigera/operator/pkg/elasticsearch"
+	"github.com/tigera/operator/pkg/render"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+// rolloverIndexPrefix follows the rollover alias in the names of the indices created by rolling over the alias. Other
+// indices matching the alias, such as the date suffixed indices written by fluentd and the other components that don't
+// write to the alias, are dated indices, which are not rolled over.
+const rolloverIndexPrefix = "ilm-"
+
+// logType is a type of log stored in Elasticsearch. Each log type has its own index lifecycle management policy,
+// which rolls over the indices of the log type by size or age and deletes them once the retention period has passed.
+type logType struct {
+	// name is also the render.ElasticsearchIndex that holds the replicas and shards of the indices of the log type.
+	name string
+	// indexPrefixes are the names of the indices of the log type, without the cluster name.
+	indexPrefixes []string
+	retentionDays int32
+	rolloverSize  string
+	rolloverAge   string
+	// archived log types are archived by the operator, instead of deleted by their policy, once the retention period
+	// has passed.
+	archived bool
+}
+
+func logTypes(retention *operatorv1.Retention) []logType {
+	return []logType{
+		{
+			name:          "flows",
+			indexPrefixes: []string{"tigera_secure_ee_flows"},
+			retentionDays: *retention.Flows,
+			rolloverSize:  "50gb",
+			rolloverAge:   "1d",
+		},
+		{
+			name:          "dns",
+			indexPrefixes: []string{"tigera_secure_ee_dns"},
+			retentionDays: *retention.DNSLogs,
+			rolloverSize:  "50gb",
+			rolloverAge:   "1d",
+		},
+		{
+			name:          "audit",
+			indexPrefixes: []string{"tigera_secure_ee_audit_ee", "tigera_secure_ee_audit_kube"},
+			retentionDays: *retention.AuditReports,
+			rolloverSize:  "50gb",
+			rolloverAge:   "1d",
+		},
+		{
+			name:          "snapshots",
+			indexPrefixes: []string{"tigera_secure_ee_snapshots"},
+			retentionDays: *retention.Snapshots,
+			rolloverSize:  "10gb",
+			rolloverAge:   "30d",
+		},
+		{
+			name:          "compliance_reports",
+			indexPrefixes: []string{"tigera_secure_ee_compliance_reports"},
+			retentionDays: *retention.ComplianceReports,
+			rolloverSize:  "10gb",
+			rolloverAge:   "30d",
+		},
+		{
+			name:          "events",
+			indexPrefixes: []string{"tigera_secure_ee_events"},
+			retentionDays: *retention.IntrusionDetectionEvents,
+			rolloverSize:  "10gb",
+			rolloverAge:   "30d",
+		},
+		{
+			name:          "component_logs",
+			indexPrefixes: []string{"tigera_secure_ee_component_logs"},
+			retentionDays: *retention.ComponentLogs,
+			rolloverSize:  "50gb",
+			rolloverAge:   "1d",
+		},
+	}
+}
+
+func (l logType) policyName() string {
+	return fmt.Sprintf("tigera_secure_ee_%s_policy", l.name)
+}
+
+// datedPolicyName returns the name of the policy of the dated indices of the log type.
+func (l logType) datedPolicyName() string {
+	return fmt.Sprintf("tigera_secure_ee_%s_dated_policy", l.name)
+}
+
+// rolloverAlias returns the alias that components write the logs of the given index prefix to.
+func rolloverAlias(indexPrefix, clusterName string) string {
+	return fmt.Sprintf("%s.%s.", indexPrefix, clusterName)
+}
+
+// dataTiers returns whether the cluster has data nodes tagged with their tier and whether any of them are in the
+// warm tier.
+func dataTiers(ls *operatorv1.LogStorage) (tiered, warm bool) {
+	if ls.Spec.External != nil || ls.Spec.Nodes == nil {
+		return false, false
+	}
+	for _, ns := range ls.Spec.Nodes.NodeSets {
+		if !ns.HasRole(operatorv1.ElasticsearchNodeRoleData) {
+			continue
+		}
+		tiered = true
+		if ns.DataTier == operatorv1.ElasticsearchDataTierWarm {
+			warm = true
+		}
+	}
+	return tiered, warm
+}
+
+// ilmPolicy returns the index lifecycle management policy of the log type. Indices stay in the hot phase while they
+// are written to, move to the warm phase, on the warm data nodes if there are any, once they are rolled over and are
+// deleted once the retention period has passed since they were rolled over, unless the log type is archived.
+func ilmPolicy(l logType, warmTier bool) elasticsearch.ILMPolicy {
+	warmActions := map[string]interface{}{
+		"readonly":     map[string]interface{}{},
+		"set_priority": map[string]interface{}{"priority": 50},
+	}
+	if warmTier {
+		warmActions["allocate"] = map[string]interface{}{
+			"require": map[string]interface{}{"data": strings.ToLower(string(operatorv1.ElasticsearchDataTierWarm))},
+		}
+	}
+
+	policy := elasticsearch.ILMPolicy{
+		Phases: map[string]elasticsearch.ILMPhase{
+			"hot": {
+				Actions: map[string]interface{}{
+					"rollover":     map[string]interface{}{"max_size": l.rolloverSize, "max_age": l.rolloverAge},
+					"set_priority": map[string]interface{}{"priority": 100},
+				},
+			},
+			"warm": {
+				MinAge:  "0ms",
+				Actions: warmActions,
+			},
+		},
+	}
+	if !l.archived {
+		policy.Phases["delete"] = elasticsearch.ILMPhase{
+			MinAge:  fmt.Sprintf("%dd", l.retentionDays),
+			Actions: map[string]interface{}{"delete": map[string]interface{}{}},
+		}
+	}
+	return policy
+}
+
+// datedILMPolicy returns the index lifecycle management policy of the dated indices of the log type, which are not
+// rolled over. They are written to until the components writing them move on to the next index, which happens
+// within the rollover age of the log type, so they only move to the warm phase once that age has passed since they
+// were created and are not made read-only. They are deleted once the retention period has passed since they were
+// created, unless the log type is archived.
+func datedILMPolicy(l logType, warmTier bool) elasticsearch.ILMPolicy {
+	policy := ilmPolicy(l, warmTier)
+	delete(policy.Phases["hot"].Actions, "rollover")
+	warm := policy.Phases["warm"]
+	warm.MinAge = l.rolloverAge
+	delete(warm.Actions, "readonly")
+	policy.Phases["warm"] = warm
+	return policy
+}
+
+// indexTemplate returns the template that applies the policy of the dated indices of the log type to new indices with
+// the index prefix, of all the clusters writing to the Elasticsearch cluster.
+func indexTemplate(l logType, indexPrefix string, clusterConfig *render.ElasticsearchClusterConfig, tiered bool) elasticsearch.IndexTemplate {
+	indexSettings := clusterConfig.IndexSettings(render.ElasticsearchIndex(l.name))
+	settings := map[string]interface{}{
+		"number_of_shards":     indexSettings.Shards,
+		"number_of_replicas":   indexSettings.Replicas,
+		"index.lifecycle.name": l.datedPolicyName(),
+	}
+	if tiered {
+		settings["index.routing.allocation.require.data"] = strings.ToLower(string(operatorv1.ElasticsearchDataTierHot))
+	}
+
+	return elasticsearch.IndexTemplate{
+		IndexPatterns: []string{indexPrefix + ".*"},
+		Order:         1,
+		Settings:      settings,
+	}
+}
+
+// rolloverTemplateName returns the name of the template of the indices of the rollover alias of the given cluster.
+func rolloverTemplateName(indexPrefix, clusterName string) string {
+	return fmt.Sprintf("%s_rollover.%s", indexPrefix, clusterName)
+}
+
+// rolloverIndexTemplate returns the template that applies the policy of the log type and the rollover alias of the
+// given cluster to the indices of the alias. It overrides the template of the dated indices, which also matches them.
+func rolloverIndexTemplate(l logType, indexPrefix, clusterName string) elasticsearch.IndexTemplate {
+	alias := rolloverAlias(indexPrefix, clusterName)
+	return elasticsearch.IndexTemplate{
+		IndexPatterns: []string{alias + rolloverIndexPrefix + "*"},
+		Order:         2,
+		Settings: map[string]interface{}{
+			"index.lifecycle.name":           l.policyName(),
+			"index.lifecycle.rollover_alias": alias,
+		},
+	}
+}
+
+// reconcileIndexLifecycle creates or updates the index lifecycle management policies and index templates of each log
+// type. The rollover aliases of the indices that Fluent Bit writes to, and their first index, are only created for
+// the given clusters, whose logs are collected by Fluent Bit. The dated indices that fluentd and the other components
+// write to instead have a policy of their own. It returns the indices whose lifecycle management failed.
+func reconcileIndexLifecycle(ctx context.Context, esClient *elasticsearch.Client, ls *operatorv1.LogStorage, clusterConfig *render.ElasticsearchClusterConfig, rolloverClusters []string) ([]operatorv1.IndexLifecycleError, error) {
+	tiered, warm := dataTiers(ls)
+	archived := archivedLogTypes(ls)
+	rollover := map[string]bool{}
+	for _, prefix := range render.FluentBitIndices() {
+		rollover[prefix] = true
+	}
+
+	var ilmErrors []operatorv1.IndexLifecycleError
+	for _, l := range logTypes(ls.Spec.Retention) {
+		l.archived = archived[l.name]
+		if err := esClient.PutILMPolicy(ctx, l.policyName(), ilmPolicy(l, warm)); err != nil {
+			return nil, fmt.Errorf("failed to create index lifecycle policy %s: %v", l.policyName(), err)
+		}
+		if err := esClient.PutILMPolicy(ctx, l.datedPolicyName(), datedILMPolicy(l, warm)); err != nil {
+			return nil, fmt.Errorf("failed to create index lifecycle policy %s: %v", l.datedPolicyName(), err)
+		}
+
+		for _, prefix := range l.indexPrefixes {
+			template := indexTemplate(l, prefix, clusterConfig, tiered)
+			if err := esClient.PutIndexTemplate(ctx, prefix, template); err != nil {
+				return nil, fmt.Errorf("failed to create index template %s: %v", prefix, err)
+			}
+
+			if rollover[prefix] {
+				for _, clusterName := range rolloverClusters {
+					if err := reconcileRolloverAlias(ctx, esClient, l, prefix, clusterName); err != nil {
+						return nil, err
+					}
+				}
+			}
+
+			indices, err := esClient.ExplainLifecycle(ctx, prefix+".*")
+			if err != nil {
+				return nil, err
+			}
+			for name, index := range indices {
+				if index.Step != elasticsearch.ILMErrorStep {
+					continue
+				}
+				ilmError := operatorv1.IndexLifecycleError{Index: name, Policy: index.Policy, FailedStep: index.FailedStep}
+				if index.StepInfo != nil {
+					ilmError.Reason = index.StepInfo.Reason
+				}
+				ilmErrors = append(ilmErrors, ilmError)
+			}
+		}
+	}
+
+	sort.Slice(ilmErrors, func(i, j int) bool { return ilmErrors[i].Index < ilmErrors[j].Index })
+	return ilmErrors, nil
+}
+
+// reconcileRolloverAlias creates or updates the template of the indices of the rollover alias of the given cluster and
+// creates the first index of the alias if it does not exist.
+func reconcileRolloverAlias(ctx context.Context, esClient *elasticsearch.Client, l logType, indexPrefix, clusterName string) error {
+	name := rolloverTemplateName(indexPrefix, clusterName)
+	if err := esClient.PutIndexTemplate(ctx, name, rolloverIndexTemplate(l, indexPrefix, clusterName)); err != nil {
+		return fmt.Errorf("failed to create index template %s: %v", name, err)
+	}
+
+	alias := rolloverAlias(indexPrefix, clusterName)
+	exists, err := esClient.AliasExists(ctx, alias)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		if err := esClient.CreateWriteIndex(ctx, alias+rolloverIndexPrefix+"000001", alias); err != nil {
+			return fmt.Errorf("failed to create the write index of %s: %v", alias, err)
+		}
+	}
+	return nil
+}
+
+// rolloverClusters returns the clusters whose logs are collected by Fluent Bit, which writes to the rollover aliases
+// of its indices: this cluster if it is selected by the LogCollector, and the given managed clusters. Fluentd writes
+// to dated indices instead.
+func (r *ReconcileLogStorage) rolloverClusters(ctx context.Context, clusterName string, fluentBitManagedClusters []string) ([]string, error) {
+	lc := &operatorv1.LogCollector{}
+	if err := r.client.Get(ctx, utils.DefaultTSEEInstanceKey, lc); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, err
+		}
+	} else if lc.Spec.Collector == operatorv1.LogCollectorTypeFluentBit {
+		return append([]string{clusterName}, fluentBitManagedClusters...), nil
+	}
+	return fluentBitManagedClusters, nil
+}
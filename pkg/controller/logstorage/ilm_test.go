@@ -0,0 +1,189 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	operatorv1 "github.com/tigera/operator/pkg/apis/operator/v1"
+	"github.com/tigera/operator/pkg/elasticsearch"
+	"github.com/tigera/operator/pkg/render"
+)
+
+var _ = Describe("Index lifecycle management tests", func() {
+	var ls *operatorv1.LogStorage
+	var clusterConfig *render.ElasticsearchClusterConfig
+
+	BeforeEach(func() {
+		ls = &operatorv1.LogStorage{
+			Spec: operatorv1.LogStorageSpec{
+				Nodes: &operatorv1.Nodes{Count: 1},
+			},
+		}
+		fillDefaults(ls)
+		clusterConfig = render.NewElasticsearchClusterConfig("cluster", 1, 5, "")
+	})
+
+	It("deletes indices once the retention period has passed", func() {
+		policy := ilmPolicy(logTypes(ls.Spec.Retention)[0], false)
+		Expect(policy.Phases["hot"].Actions["rollover"]).To(Equal(map[string]interface{}{"max_size": "50gb", "max_age": "1d"}))
+		Expect(policy.Phases["warm"].Actions).NotTo(HaveKey("allocate"))
+		Expect(policy.Phases["delete"].MinAge).To(Equal("8d"))
+	})
+
+	It("does not delete the indices of archived log types", func() {
+		flows := logTypes(ls.Spec.Retention)[0]
+		flows.archived = true
+		Expect(ilmPolicy(flows, false).Phases).NotTo(HaveKey("delete"))
+	})
+
+	It("moves rolled over indices to the warm data nodes", func() {
+		ls.Spec.Nodes = &operatorv1.Nodes{
+			NodeSets: []operatorv1.NodeSet{
+				{Name: "master", Count: 1, Roles: []operatorv1.ElasticsearchNodeRole{operatorv1.ElasticsearchNodeRoleMaster}},
+				{Name: "hot", Count: 1, Roles: []operatorv1.ElasticsearchNodeRole{operatorv1.ElasticsearchNodeRoleData}},
+				{Name: "warm", Count: 1, Roles: []operatorv1.ElasticsearchNodeRole{operatorv1.ElasticsearchNodeRoleData}, DataTier: operatorv1.ElasticsearchDataTierWarm},
+			},
+		}
+		tiered, warm := dataTiers(ls)
+		Expect(tiered).To(BeTrue())
+		Expect(warm).To(BeTrue())
+
+		policy := ilmPolicy(logTypes(ls.Spec.Retention)[0], warm)
+		Expect(policy.Phases["warm"].Actions["allocate"]).To(Equal(map[string]interface{}{
+			"require": map[string]interface{}{"data": "warm"},
+		}))
+
+		template := indexTemplate(logTypes(ls.Spec.Retention)[0], "tigera_secure_ee_flows", clusterConfig, tiered)
+		Expect(template.IndexPatterns).To(Equal([]string{"tigera_secure_ee_flows.*"}))
+		Expect(template.Settings).To(HaveKeyWithValue("index.routing.allocation.require.data", "hot"))
+	})
+
+	It("only sets the rollover alias on the indices of the alias", func() {
+		flows := logTypes(ls.Spec.Retention)[0]
+
+		template := indexTemplate(flows, "tigera_secure_ee_flows", clusterConfig, false)
+		Expect(template.Settings).To(HaveKeyWithValue("index.lifecycle.name", "tigera_secure_ee_flows_dated_policy"))
+		Expect(template.Settings).NotTo(HaveKey("index.lifecycle.rollover_alias"))
+
+		rollover := rolloverIndexTemplate(flows, "tigera_secure_ee_flows", "cluster")
+		Expect(rollover.IndexPatterns).To(Equal([]string{"tigera_secure_ee_flows.cluster.ilm-*"}))
+		Expect(rollover.Order).To(BeNumerically(">", template.Order))
+		Expect(rollover.Settings).To(HaveKeyWithValue("index.lifecycle.name", "tigera_secure_ee_flows_policy"))
+		Expect(rollover.Settings).To(HaveKeyWithValue("index.lifecycle.rollover_alias", "tigera_secure_ee_flows.cluster."))
+	})
+
+	It("does not roll over dated indices or make them read-only while they are written to", func() {
+		policy := datedILMPolicy(logTypes(ls.Spec.Retention)[0], false)
+		Expect(policy.Phases["hot"].Actions).NotTo(HaveKey("rollover"))
+		Expect(policy.Phases["warm"].MinAge).To(Equal("1d"))
+		Expect(policy.Phases["warm"].Actions).NotTo(HaveKey("readonly"))
+		Expect(policy.Phases["delete"].MinAge).To(Equal("8d"))
+
+		Expect(ilmPolicy(logTypes(ls.Spec.Retention)[0], false).Phases["hot"].Actions).To(HaveKey("rollover"))
+	})
+
+	Context("Reconciling", func() {
+		var server *httptest.Server
+		var requests []string
+		var failedIndices string
+
+		BeforeEach(func() {
+			requests = nil
+			failedIndices = `{}`
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.Method+" "+r.URL.Path)
+				switch {
+				case r.Method == http.MethodHead:
+					w.WriteHeader(http.StatusNotFound)
+				case r.URL.Path == "/tigera_secure_ee_flows.*/_ilm/explain":
+					_, _ = w.Write([]byte(`{"indices": ` + failedIndices + `}`))
+				case strings.HasSuffix(r.URL.Path, "/_ilm/explain"):
+					_, _ = w.Write([]byte(`{"indices": {}}`))
+				default:
+					_, _ = w.Write([]byte(`{}`))
+				}
+			}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("creates the policies and the templates of the dated indices of all clusters", func() {
+			esClient, err := elasticsearch.NewClient(server.URL, "elastic", "elasticpw", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			ilmErrors, err := reconcileIndexLifecycle(context.Background(), esClient, ls, clusterConfig, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ilmErrors).To(BeEmpty())
+
+			Expect(requests).To(ContainElement("PUT /_ilm/policy/tigera_secure_ee_audit_policy"))
+			Expect(requests).To(ContainElement("PUT /_ilm/policy/tigera_secure_ee_audit_dated_policy"))
+			Expect(requests).To(ContainElement("PUT /_template/tigera_secure_ee_audit_kube"))
+			Expect(requests).To(ContainElement("PUT /_ilm/policy/tigera_secure_ee_component_logs_policy"))
+			Expect(requests).To(ContainElement("GET /tigera_secure_ee_flows.*/_ilm/explain"))
+
+			// Fluentd writes to dated indices, so no rollover aliases are created.
+			for _, req := range requests {
+				Expect(req).NotTo(ContainSubstring("ilm-000001"))
+				Expect(req).NotTo(ContainSubstring("_rollover"))
+			}
+		})
+
+		It("creates the rollover aliases that Fluent Bit writes to for the given clusters", func() {
+			esClient, err := elasticsearch.NewClient(server.URL, "elastic", "elasticpw", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			_, err = reconcileIndexLifecycle(context.Background(), esClient, ls, clusterConfig, []string{"cluster"})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(requests).To(ContainElement("PUT /_template/tigera_secure_ee_flows_rollover.cluster"))
+			Expect(requests).To(ContainElement("PUT /tigera_secure_ee_flows.cluster.ilm-000001"))
+			Expect(requests).To(ContainElement("PUT /_template/tigera_secure_ee_dns_rollover.cluster"))
+			Expect(requests).To(ContainElement("PUT /tigera_secure_ee_dns.cluster.ilm-000001"))
+			Expect(requests).NotTo(ContainElement("PUT /tigera_secure_ee_audit_kube.cluster.ilm-000001"))
+			Expect(requests).NotTo(ContainElement("PUT /tigera_secure_ee_events.cluster.ilm-000001"))
+		})
+
+		It("returns the indices whose lifecycle management failed", func() {
+			failedIndices = `{"tigera_secure_ee_flows.cluster.ilm-000002": {
+				"index": "tigera_secure_ee_flows.cluster.ilm-000002",
+				"managed": true,
+				"policy": "tigera_secure_ee_flows_policy",
+				"step": "ERROR",
+				"failed_step": "check-rollover-ready",
+				"step_info": {"type": "illegal_argument_exception", "reason": "rollover alias is missing"}
+			}}`
+			esClient, err := elasticsearch.NewClient(server.URL, "elastic", "elasticpw", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			ilmErrors, err := reconcileIndexLifecycle(context.Background(), esClient, ls, clusterConfig, nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(ilmErrors).To(Equal([]operatorv1.IndexLifecycleError{{
+				Index:      "tigera_secure_ee_flows.cluster.ilm-000002",
+				Policy:     "tigera_secure_ee_flows_policy",
+				FailedStep: "check-rollover-ready",
+				Reason:     "rollover alias is missing",
+			}}))
+		})
+	})
+})
@@ -0,0 +1,212 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	operatorv1 "github.com/tigera/operator/pkg/apis/operator/v1"
+	"github.com/tigera/operator/pkg/controller/utils"
+	"github.com/tigera/operator/pkg/elasticsearch"
+	"github.com/tigera/operator/pkg/kibana"
+	"github.com/tigera/operator/pkg/render"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// kibanaSSORoleMapping is the Elasticsearch role mapping that grants roles to users who log in to Kibana with single
+// sign-on.
+const kibanaSSORoleMapping = "tigera-kibana-sso"
+
+var defaultKibanaSSORoles = []string{"kibana_user", "tigera-ee-manager"}
+
+// kibanaClient returns a client for the Kibana installed with the Elasticsearch cluster, authenticated as the
+// Elasticsearch admin user.
+func (r *ReconcileLogStorage) kibanaClient(ctx context.Context) (*kibana.Client, error) {
+	ca, err := r.secretValue(ctx, render.KibanaPublicCertSecret, render.KibanaNamespace, corev1.TLSCertKey)
+	if err != nil {
+		return nil, err
+	}
+	password, err := r.secretValue(ctx, render.ElasticsearchAdminUserSecret, render.ElasticsearchNamespace, render.ElasticsearchAdminUser)
+	if err != nil {
+		return nil, err
+	}
+
+	return kibana.NewClient(r.kbEndpoint, render.ElasticsearchAdminUser, string(password), ca)
+}
+
+// reconcileKibanaSSO grants roles to the users of the realm that provides single sign-on to Kibana, and removes the
+// role mapping when single sign-on isn't used.
+func reconcileKibanaSSO(ctx context.Context, esClient *elasticsearch.Client, ls *operatorv1.LogStorage) error {
+	realm := render.KibanaSSORealm(ls)
+	if realm == "" {
+		return esClient.DeleteRoleMapping(ctx, kibanaSSORoleMapping)
+	}
+
+	roles := ls.Spec.Kibana.Auth.Roles
+	if len(roles) == 0 {
+		roles = defaultKibanaSSORoles
+	}
+	return esClient.PutRoleMapping(ctx, kibanaSSORoleMapping, elasticsearch.RoleMapping{
+		Enabled: true,
+		Roles:   roles,
+		Rules:   map[string]interface{}{"field": map[string]interface{}{"realm.name": realm}},
+	})
+}
+
+// getManager returns the Manager, or nil if it doesn't exist.
+func (r *ReconcileLogStorage) getManager(ctx context.Context) (*operatorv1.Manager, error) {
+	manager := &operatorv1.Manager{}
+	if err := r.client.Get(ctx, utils.DefaultTSEEInstanceKey, manager); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return manager, nil
+}
+
+// managerOIDC returns the auth of the Manager if users log in to it with OpenID Connect, otherwise nil.
+func managerOIDC(manager *operatorv1.Manager) *operatorv1.Auth {
+	if manager == nil || manager.Spec.Auth == nil || manager.Spec.Auth.Type != operatorv1.AuthTypeOIDC {
+		return nil
+	}
+	return manager.Spec.Auth
+}
+
+// fillKibanaOIDCDefaults sets the authority and client ID of the OpenID Connect provider of Kibana to those of the
+// Manager when they are not set, so that users log in to both with the same provider.
+func fillKibanaOIDCDefaults(kibana *operatorv1.Kibana, manager *operatorv1.Manager) {
+	managerAuth := managerOIDC(manager)
+	if kibana == nil || kibana.Auth == nil || kibana.Auth.OIDC == nil || managerAuth == nil {
+		return
+	}
+	if kibana.Auth.OIDC.Authority == "" {
+		kibana.Auth.OIDC.Authority = managerAuth.Authority
+	}
+	if kibana.Auth.OIDC.ClientID == "" {
+		kibana.Auth.OIDC.ClientID = managerAuth.ClientID
+	}
+}
+
+// importSavedObjects creates the spaces listed in the Kibana spec, then imports the saved objects in each ConfigMap
+// listed for the default space and each space whose contents have changed since they were last imported, or whose
+// last import failed. It returns the status of the import of each ConfigMap.
+func (r *ReconcileLogStorage) importSavedObjects(ctx context.Context, kbClient *kibana.Client, ls *operatorv1.LogStorage) ([]operatorv1.SavedObjectsImport, error) {
+	previous := map[string]operatorv1.SavedObjectsImport{}
+	for _, imp := range ls.Status.SavedObjects {
+		previous[imp.Space+"/"+imp.ConfigMapName] = imp
+	}
+
+	imports, err := r.importConfigMaps(ctx, kbClient, "", ls.Spec.Kibana.SavedObjectsConfigMaps, previous)
+	if err != nil {
+		return nil, err
+	}
+	for _, space := range ls.Spec.Kibana.Spaces {
+		if err := kbClient.PutSpace(ctx, kibana.Space{
+			ID:               space.ID,
+			Name:             space.Name,
+			Description:      space.Description,
+			DisabledFeatures: space.DisabledFeatures,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to create Kibana space %s: %v", space.ID, err)
+		}
+
+		spaceImports, err := r.importConfigMaps(ctx, kbClient, space.ID, space.SavedObjectsConfigMaps, previous)
+		if err != nil {
+			return nil, err
+		}
+		imports = append(imports, spaceImports...)
+	}
+	return imports, nil
+}
+
+// importConfigMaps imports the saved objects in the ConfigMaps into the space, skipping the ConfigMaps whose previous
+// import succeeded and whose contents haven't changed since.
+func (r *ReconcileLogStorage) importConfigMaps(ctx context.Context, kbClient *kibana.Client, space string, names []string, previous map[string]operatorv1.SavedObjectsImport) ([]operatorv1.SavedObjectsImport, error) {
+	var imports []operatorv1.SavedObjectsImport
+	for _, name := range names {
+		cm := &corev1.ConfigMap{}
+		if err := r.client.Get(ctx, types.NamespacedName{Name: name, Namespace: render.OperatorNamespace()}, cm); err != nil {
+			if !errors.IsNotFound(err) {
+				return nil, err
+			}
+			imports = append(imports, operatorv1.SavedObjectsImport{
+				ConfigMapName: name,
+				Space:         space,
+				Error:         fmt.Sprintf("ConfigMap %s not found in the %s namespace", name, render.OperatorNamespace()),
+			})
+			continue
+		}
+
+		hash := render.AnnotationHash(cm.Data)
+		if imp, ok := previous[space+"/"+name]; ok && imp.Hash == hash && imp.Error == "" {
+			imports = append(imports, imp)
+			continue
+		}
+
+		imp := operatorv1.SavedObjectsImport{ConfigMapName: name, Space: space, Hash: hash}
+		if err := importConfigMap(ctx, kbClient, space, cm); err != nil {
+			imp.Error = err.Error()
+		}
+		imports = append(imports, imp)
+	}
+	return imports, nil
+}
+
+// importConfigMap imports the saved objects in each value of the ConfigMap into the space.
+func importConfigMap(ctx context.Context, kbClient *kibana.Client, space string, cm *corev1.ConfigMap) error {
+	var keys []string
+	for key := range cm.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		result, err := kbClient.ImportSavedObjects(ctx, space, []byte(cm.Data[key]))
+		if err != nil {
+			return fmt.Errorf("failed to import %s: %v", key, err)
+		}
+		if len(result.Errors) != 0 {
+			var failed []string
+			for _, e := range result.Errors {
+				failed = append(failed, fmt.Sprintf("%s %s (%s)", e.Type, e.ID, e.Error.Type))
+			}
+			return fmt.Errorf("failed to import %s: %s", key, strings.Join(failed, ", "))
+		}
+	}
+	return nil
+}
+
+// savedObjectsFailures returns a message for each ConfigMap whose saved objects could not be imported.
+func savedObjectsFailures(imports []operatorv1.SavedObjectsImport) []string {
+	var failures []string
+	for _, imp := range imports {
+		if imp.Error == "" {
+			continue
+		}
+		if imp.Space != "" {
+			failures = append(failures, fmt.Sprintf("saved objects in ConfigMap %s for space %s: %s", imp.ConfigMapName, imp.Space, imp.Error))
+		} else {
+			failures = append(failures, fmt.Sprintf("saved objects in ConfigMap %s: %s", imp.ConfigMapName, imp.Error))
+		}
+	}
+	return failures
+}
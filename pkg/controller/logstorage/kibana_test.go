@@ -0,0 +1,148 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	operatorv1 "github.com/tigera/operator/pkg/apis/operator/v1"
+	"github.com/tigera/operator/pkg/elasticsearch"
+	"github.com/tigera/operator/pkg/kibana"
+	"github.com/tigera/operator/pkg/render"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Kibana tests", func() {
+	var cli client.Client
+	var r *ReconcileLogStorage
+	var ls *operatorv1.LogStorage
+	var server *httptest.Server
+	var requests []string
+	var response string
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).ShouldNot(HaveOccurred())
+		cli = fake.NewFakeClientWithScheme(scheme)
+		r = &ReconcileLogStorage{client: cli}
+
+		ls = &operatorv1.LogStorage{
+			Spec: operatorv1.LogStorageSpec{
+				Kibana: &operatorv1.Kibana{SavedObjectsConfigMaps: []string{"dashboards"}},
+			},
+		}
+
+		requests = nil
+		response = `{"success": true, "successCount": 1}`
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			_, _ = w.Write([]byte(response))
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	createConfigMap := func(data map[string]string) {
+		Expect(cli.Create(context.Background(), &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "dashboards", Namespace: render.OperatorNamespace()},
+			Data:       data,
+		})).ShouldNot(HaveOccurred())
+	}
+
+	It("imports the saved objects of a ConfigMap once", func() {
+		createConfigMap(map[string]string{"flows.ndjson": `{"type": "dashboard", "id": "flows"}`})
+		kbClient, err := kibana.NewClient(server.URL, "elastic", "elasticpw", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		imports, err := r.importSavedObjects(context.Background(), kbClient, ls)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(imports).To(HaveLen(1))
+		Expect(imports[0].Hash).NotTo(BeEmpty())
+		Expect(imports[0].Error).To(BeEmpty())
+		Expect(requests).To(Equal([]string{"POST /api/saved_objects/_import"}))
+
+		By("not importing the ConfigMap again until it changes")
+		ls.Status.SavedObjects = imports
+		imports, err = r.importSavedObjects(context.Background(), kbClient, ls)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(imports).To(Equal(ls.Status.SavedObjects))
+		Expect(requests).To(HaveLen(1))
+	})
+
+	It("creates the spaces and imports their saved objects separately from the default space", func() {
+		createConfigMap(map[string]string{"flows.ndjson": `{"type": "dashboard", "id": "flows"}`})
+		ls.Spec.Kibana.Spaces = []operatorv1.KibanaSpace{{ID: "security", Name: "Security", SavedObjectsConfigMaps: []string{"dashboards"}}}
+		kbClient, err := kibana.NewClient(server.URL, "elastic", "elasticpw", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		imports, err := r.importSavedObjects(context.Background(), kbClient, ls)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(imports).To(HaveLen(2))
+		Expect(imports[0].Space).To(BeEmpty())
+		Expect(imports[1].Space).To(Equal("security"))
+		Expect(requests).To(Equal([]string{
+			"POST /api/saved_objects/_import",
+			"PUT /api/spaces/space/security",
+			"POST /s/security/api/saved_objects/_import",
+		}))
+
+		By("not importing the ConfigMaps again until they change")
+		ls.Status.SavedObjects = imports
+		imports, err = r.importSavedObjects(context.Background(), kbClient, ls)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(imports).To(Equal(ls.Status.SavedObjects))
+		Expect(requests).To(HaveLen(4))
+		Expect(requests[3]).To(Equal("PUT /api/spaces/space/security"))
+	})
+
+	It("reports the objects that could not be imported and missing ConfigMaps", func() {
+		createConfigMap(map[string]string{"flows.ndjson": `{"type": "visualization", "id": "flows"}`})
+		ls.Spec.Kibana.SavedObjectsConfigMaps = append(ls.Spec.Kibana.SavedObjectsConfigMaps, "missing")
+		response = `{"success": false, "errors": [{"id": "flows", "type": "visualization", "error": {"type": "missing_references"}}]}`
+		kbClient, err := kibana.NewClient(server.URL, "elastic", "elasticpw", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		imports, err := r.importSavedObjects(context.Background(), kbClient, ls)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(imports).To(HaveLen(2))
+		Expect(imports[0].Error).To(Equal("failed to import flows.ndjson: visualization flows (missing_references)"))
+		Expect(imports[1].Error).To(ContainSubstring("not found"))
+		Expect(savedObjectsFailures(imports)).To(HaveLen(2))
+	})
+
+	It("maps the users of the single sign-on realm to the Kibana roles", func() {
+		esClient, err := elasticsearch.NewClient(server.URL, "elastic", "elasticpw", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		Expect(reconcileKibanaSSO(context.Background(), esClient, ls)).ShouldNot(HaveOccurred())
+		Expect(requests).To(Equal([]string{"DELETE /_security/role_mapping/tigera-kibana-sso"}))
+
+		ls.Spec.Kibana.Auth = &operatorv1.KibanaAuth{Type: operatorv1.KibanaAuthTypeSAML}
+		Expect(reconcileKibanaSSO(context.Background(), esClient, ls)).ShouldNot(HaveOccurred())
+		Expect(requests[1]).To(Equal("PUT /_security/role_mapping/tigera-kibana-sso"))
+	})
+})
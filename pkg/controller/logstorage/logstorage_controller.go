@@ -20,6 +20,8 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/types"
 
@@ -84,11 +86,13 @@ func newReconciler(cli client.Client, schema *runtime.Scheme, statusMgr status.S
 	}
 
 	c := &ReconcileLogStorage{
-		client:   cli,
-		scheme:   schema,
-		status:   statusMgr,
-		provider: provider,
-		localDNS: localDNS,
+		client:     cli,
+		scheme:     schema,
+		status:     statusMgr,
+		provider:   provider,
+		localDNS:   localDNS,
+		esEndpoint: render.ElasticsearchHTTPSEndpoint,
+		kbEndpoint: fmt.Sprintf("%s/%s", render.KibanaHTTPSEndpoint, render.KibanaBasePath),
 	}
 
 	c.status.Run()
@@ -138,6 +142,24 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// Watch for restores of snapshots taken of the Elasticsearch cluster of the LogStorage
+	err = c.Watch(&source.Kind{Type: &operatorv1.LogStorageRestore{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return fmt.Errorf("log-storage-controller failed to watch LogStorageRestore resource: %v", err)
+	}
+
+	// Watch for the LogCollector, the rollover aliases that Fluent Bit writes to are created when it is selected
+	err = c.Watch(&source.Kind{Type: &operatorv1.LogCollector{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return fmt.Errorf("log-storage-controller failed to watch LogCollector resource: %v", err)
+	}
+
+	// Watch for the Manager, Kibana logs users in with the same OpenID Connect provider
+	err = c.Watch(&source.Kind{Type: &operatorv1.Manager{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return fmt.Errorf("log-storage-controller failed to watch Manager resource: %v", err)
+	}
+
 	if err = utils.AddNetworkWatch(c); err != nil {
 		return fmt.Errorf("log-storage-controller failed to watch Network resource: %v", err)
 	}
@@ -221,6 +243,10 @@ type ReconcileLogStorage struct {
 	status   status.StatusManager
 	provider operatorv1.Provider
 	localDNS string
+	// esEndpoint is the URL of the Elasticsearch cluster managed by the operator.
+	esEndpoint string
+	// kbEndpoint is the URL, including the base path, of the Kibana installed by the operator.
+	kbEndpoint string
 }
 
 func GetLogStorage(ctx context.Context, cli client.Client) (*operatorv1.LogStorage, error) {
@@ -256,6 +282,18 @@ func fillDefaults(opr *operatorv1.LogStorage) {
 		var crr int32 = 365
 		opr.Spec.Retention.ComplianceReports = &crr
 	}
+	if opr.Spec.Retention.DNSLogs == nil {
+		var dr int32 = 8
+		opr.Spec.Retention.DNSLogs = &dr
+	}
+	if opr.Spec.Retention.IntrusionDetectionEvents == nil {
+		var er int32 = 365
+		opr.Spec.Retention.IntrusionDetectionEvents = &er
+	}
+	if opr.Spec.Retention.ComponentLogs == nil {
+		var clr int32 = 8
+		opr.Spec.Retention.ComponentLogs = &clr
+	}
 
 	if opr.Spec.Indices == nil {
 		opr.Spec.Indices = &operatorv1.Indices{}
@@ -265,6 +303,57 @@ func fillDefaults(opr *operatorv1.LogStorage) {
 		var replicas int32 = render.DefaultElasticsearchReplicas
 		opr.Spec.Indices.Replicas = &replicas
 	}
+
+	if opr.Spec.Indices.Shards == nil {
+		var shards int32 = defaultElasticsearchShards
+		opr.Spec.Indices.Shards = &shards
+	}
+
+	if opr.Spec.Snapshots != nil {
+		fillSnapshotDefaults(opr.Spec.Snapshots)
+	}
+
+	if opr.Spec.PasswordRotationDays == nil {
+		var days int32 = defaultPasswordRotationDays
+		opr.Spec.PasswordRotationDays = &days
+	}
+}
+
+// indexSettings returns the replicas and shards set for each type of index.
+func indexSettings(indices *operatorv1.Indices) map[render.ElasticsearchIndex]*operatorv1.IndexSettings {
+	return map[render.ElasticsearchIndex]*operatorv1.IndexSettings{
+		render.FlowsIndex:             indices.Flows,
+		render.DNSIndex:               indices.DNSLogs,
+		render.AuditIndex:             indices.AuditLogs,
+		render.SnapshotsIndex:         indices.Snapshots,
+		render.ComplianceReportsIndex: indices.ComplianceReports,
+		render.BenchmarkResultsIndex:  indices.BenchmarkResults,
+		render.EventsIndex:            indices.IntrusionDetectionEvents,
+		render.ComponentLogsIndex:     indices.ComponentLogs,
+	}
+}
+
+// newElasticsearchClusterConfig returns the configuration of the Elasticsearch cluster of ls, with the replicas and
+// shards of each type of index.
+func newElasticsearchClusterConfig(ls *operatorv1.LogStorage, endpoint string) *render.ElasticsearchClusterConfig {
+	config := render.NewElasticsearchClusterConfig(render.DefaultElasticsearchClusterName, ls.Replicas(), ls.Shards(), endpoint)
+	if ls.KibanaDisabled() {
+		config.DisableKibana()
+	}
+	for index, settings := range indexSettings(ls.Spec.Indices) {
+		if settings == nil {
+			continue
+		}
+		replicas, shards := ls.Replicas(), ls.Shards()
+		if settings.Replicas != nil {
+			replicas = int(*settings.Replicas)
+		}
+		if settings.Shards != nil {
+			shards = int(*settings.Shards)
+		}
+		config.SetIndexSettings(index, replicas, shards)
+	}
+	return config
 }
 
 // Reconcile reads that state of the cluster for a LogStorage object and makes changes based on the state read
@@ -332,53 +421,123 @@ func (r *ReconcileLogStorage) Reconcile(request reconcile.Request) (reconcile.Re
 		return reconcile.Result{}, err
 	}
 
-	var elasticsearchSecrets, kibanaSecrets, curatorSecrets []*corev1.Secret
+	// The logs of the clusters managed by a management cluster are written to its Elasticsearch cluster, into indices
+	// with the name of the managed cluster as suffix.
+	managedClusters, fluentBitManagedClusters, err := managedClusterNames(ctx, r.client, installationCR)
+	if err != nil {
+		log.Error(err, "failed to retrieve the managed clusters")
+		r.status.SetDegraded("Failed to retrieve the managed clusters", err.Error())
+		return reconcile.Result{}, err
+	}
+
+	var elasticsearchSecrets, kibanaSecrets []*corev1.Secret
 	var clusterConfig *render.ElasticsearchClusterConfig
 	createWebhookSecret := false
 
 	if installationCR.Spec.ClusterManagementType != operatorv1.ClusterManagementTypeManaged {
-		clusterConfig = render.NewElasticsearchClusterConfig(render.DefaultElasticsearchClusterName, ls.Replicas(), defaultElasticsearchShards)
-		if err := r.client.Get(ctx, client.ObjectKey{Name: render.ElasticsearchStorageClass}, &storagev1.StorageClass{}); err != nil {
-			if errors.IsNotFound(err) {
-				err := fmt.Errorf("couldn't find storage class %s, this must be provided", render.ElasticsearchStorageClass)
-				log.Error(err, err.Error())
-				r.status.SetDegraded("Failed to get storage class", err.Error())
-				return reconcile.Result{}, nil
-			}
-
+		if err := validateSnapshots(ls); err != nil {
 			log.Error(err, err.Error())
-			r.status.SetDegraded("Failed to get storage class", err.Error())
+			r.status.SetDegraded("Invalid snapshot configuration", err.Error())
 			return reconcile.Result{}, nil
 		}
 
-		if elasticsearchSecrets, err = r.elasticsearchSecrets(ctx); err != nil {
+		if err := validateIndices(ls.Spec.Indices); err != nil {
 			log.Error(err, err.Error())
-			r.status.SetDegraded("Failed to create elasticsearch secrets", err.Error())
-			return reconcile.Result{}, err
+			r.status.SetDegraded("Invalid index settings", err.Error())
+			return reconcile.Result{}, nil
 		}
 
-		if kibanaSecrets, err = r.kibanaSecrets(ctx); err != nil {
+		if ls.Spec.PasswordRotationDays != nil && *ls.Spec.PasswordRotationDays < 0 {
+			err := fmt.Errorf("passwordRotationDays must not be negative")
 			log.Error(err, err.Error())
-			r.status.SetDegraded("Failed to create kibana secrets", err.Error())
-			return reconcile.Result{}, err
+			r.status.SetDegraded("Invalid password rotation", err.Error())
+			return reconcile.Result{}, nil
 		}
 
-		// The ECK operator requires that we provide it with a secret so it can add certificate information in for its webhooks.
-		// If it's created we don't want to overwrite it as we'll lose the certificate information the ECK operator relies on.
-		if err := r.client.Get(ctx, types.NamespacedName{Name: render.ECKWebhookSecretName, Namespace: render.ECKOperatorNamespace}, &corev1.Secret{}); err != nil {
-			if errors.IsNotFound(err) {
-				createWebhookSecret = true
-			} else {
+		if ls.Spec.External != nil {
+			if err := validateExternalElasticsearch(ls.Spec.External); err != nil {
 				log.Error(err, err.Error())
-				r.status.SetDegraded("Failed to read Elasticsearch webhook secret", err.Error())
+				r.status.SetDegraded("Invalid external Elasticsearch configuration", err.Error())
+				return reconcile.Result{}, nil
+			}
+
+			clusterConfig = newElasticsearchClusterConfig(ls, ls.Spec.External.Endpoint)
+			if elasticsearchSecrets, err = r.reconcileExternalElasticsearch(ctx, ls.Spec.External, clusterConfig.ClusterName(), managedClusters, passwordRotation(ls)); err != nil {
+				log.Error(err, err.Error())
+				r.status.SetDegraded("Failed to configure the external Elasticsearch cluster", err.Error())
 				return reconcile.Result{}, err
 			}
-		}
+		} else {
+			if err := validateNodeSets(ls.Spec.Nodes); err != nil {
+				log.Error(err, err.Error())
+				r.status.SetDegraded("Invalid Elasticsearch node sets", err.Error())
+				return reconcile.Result{}, nil
+			}
 
-		curatorSecrets, err = utils.ElasticsearchSecrets(context.Background(), []string{render.ElasticsearchCuratorUserSecret}, r.client)
-		if err != nil && !errors.IsNotFound(err) {
-			r.status.SetDegraded("Failed to get curator credentials", err.Error())
-			return reconcile.Result{}, err
+			manager, err := r.getManager(ctx)
+			if err != nil {
+				log.Error(err, err.Error())
+				r.status.SetDegraded("An error occurred while querying Manager", err.Error())
+				return reconcile.Result{}, err
+			}
+			fillKibanaOIDCDefaults(ls.Spec.Kibana, manager)
+			if err := validateKibana(ls.Spec.Kibana, manager); err != nil {
+				log.Error(err, err.Error())
+				r.status.SetDegraded("Invalid Kibana configuration", err.Error())
+				return reconcile.Result{}, nil
+			}
+
+			clusterConfig = newElasticsearchClusterConfig(ls, "")
+			for _, storageClass := range storageClassNames(ls.Spec.Nodes) {
+				if err := r.client.Get(ctx, client.ObjectKey{Name: storageClass}, &storagev1.StorageClass{}); err != nil {
+					if errors.IsNotFound(err) {
+						err := fmt.Errorf("couldn't find storage class %s, this must be provided", storageClass)
+						log.Error(err, err.Error())
+						r.status.SetDegraded("Failed to get storage class", err.Error())
+						return reconcile.Result{}, nil
+					}
+
+					log.Error(err, err.Error())
+					r.status.SetDegraded("Failed to get storage class", err.Error())
+					return reconcile.Result{}, nil
+				}
+			}
+
+			if elasticsearchSecrets, err = r.elasticsearchSecrets(ctx); err != nil {
+				log.Error(err, err.Error())
+				r.status.SetDegraded("Failed to create elasticsearch secrets", err.Error())
+				return reconcile.Result{}, err
+			}
+
+			secureSettings, err := r.secureSettingsSecret(ctx, ls)
+			if err != nil {
+				log.Error(err, err.Error())
+				r.status.SetDegraded("Failed to read the Elasticsearch secure settings", err.Error())
+				return reconcile.Result{}, err
+			}
+			if secureSettings != nil {
+				elasticsearchSecrets = append(elasticsearchSecrets, secureSettings)
+			}
+
+			if !ls.KibanaDisabled() {
+				if kibanaSecrets, err = r.kibanaSecrets(ctx); err != nil {
+					log.Error(err, err.Error())
+					r.status.SetDegraded("Failed to create kibana secrets", err.Error())
+					return reconcile.Result{}, err
+				}
+			}
+
+			// The ECK operator requires that we provide it with a secret so it can add certificate information in for its webhooks.
+			// If it's created we don't want to overwrite it as we'll lose the certificate information the ECK operator relies on.
+			if err := r.client.Get(ctx, types.NamespacedName{Name: render.ECKWebhookSecretName, Namespace: render.ECKOperatorNamespace}, &corev1.Secret{}); err != nil {
+				if errors.IsNotFound(err) {
+					createWebhookSecret = true
+				} else {
+					log.Error(err, err.Error())
+					r.status.SetDegraded("Failed to read Elasticsearch webhook secret", err.Error())
+					return reconcile.Result{}, err
+				}
+			}
 		}
 	}
 
@@ -396,6 +555,37 @@ func (r *ReconcileLogStorage) Reconcile(request reconcile.Request) (reconcile.Re
 		return reconcile.Result{}, err
 	}
 
+	// Nodes are only removed from the Elasticsearch cluster once their shards have been moved to the remaining nodes.
+	scaling := &scalingPlan{}
+	if installationCR.Spec.ClusterManagementType != operatorv1.ClusterManagementTypeManaged && ls.DeletionTimestamp == nil &&
+		ls.Spec.External == nil && elasticsearch != nil {
+		if scaling, err = r.reconcileScaling(ctx, ls, elasticsearch, clusterConfig); err != nil {
+			log.Error(err, err.Error())
+			r.status.SetDegraded("Failed to scale Elasticsearch", err.Error())
+			return reconcile.Result{}, err
+		}
+	}
+
+	// The users of the internal cluster are created once it is operational, as they are created through its security
+	// API. Until then the components wait for their user secrets.
+	if installationCR.Spec.ClusterManagementType != operatorv1.ClusterManagementTypeManaged && ls.DeletionTimestamp == nil &&
+		ls.Spec.External == nil && elasticsearch != nil && elasticsearch.Status.Phase == esalpha1.ElasticsearchOperationalPhase {
+		esClient, err := r.elasticsearchClient(ctx, ls)
+		if err != nil {
+			log.Error(err, err.Error())
+			r.status.SetDegraded("Failed to connect to Elasticsearch", err.Error())
+			return reconcile.Result{}, err
+		}
+
+		userSecrets, err := r.reconcileUsers(ctx, esClient, clusterConfig.ClusterName(), managedClusters, passwordRotation(ls))
+		if err != nil {
+			log.Error(err, err.Error())
+			r.status.SetDegraded("Failed to create the Elasticsearch users", err.Error())
+			return reconcile.Result{}, err
+		}
+		elasticsearchSecrets = append(elasticsearchSecrets, userSecrets...)
+	}
+
 	// If this is a Managed cluster ls must be nil to get to this point (unless the DeletionTimestamp is set) so we must
 	// create the ComponentHandler from the installationCR
 	var hdler utils.ComponentHandler
@@ -411,12 +601,12 @@ func (r *ReconcileLogStorage) Reconcile(request reconcile.Request) (reconcile.Re
 		elasticsearch,
 		kibana,
 		clusterConfig,
+		scaling.heldNodeCounts,
 		elasticsearchSecrets,
 		kibanaSecrets,
 		createWebhookSecret,
 		pullSecrets,
 		r.provider,
-		curatorSecrets,
 		esService,
 		r.localDNS,
 	)
@@ -427,28 +617,158 @@ func (r *ReconcileLogStorage) Reconcile(request reconcile.Request) (reconcile.Re
 		return reconcile.Result{}, err
 	}
 
-	if installationCR.Spec.ClusterManagementType != operatorv1.ClusterManagementTypeManaged {
+	if installationCR.Spec.ClusterManagementType != operatorv1.ClusterManagementTypeManaged && ls.Spec.External == nil {
 		if elasticsearch == nil || elasticsearch.Status.Phase != esalpha1.ElasticsearchOperationalPhase {
 			r.status.SetDegraded("Waiting for Elasticsearch cluster to be operational", "")
 			return reconcile.Result{}, nil
 		}
 
-		if kibana == nil || kibana.Status.AssociationStatus != cmneckalpha1.AssociationEstablished {
+		if !ls.KibanaDisabled() && (kibana == nil || kibana.Status.AssociationStatus != cmneckalpha1.AssociationEstablished) {
 			r.status.SetDegraded("Waiting for Kibana cluster to be operational", "")
 			return reconcile.Result{}, nil
 		}
+	}
 
-		if len(curatorSecrets) == 0 {
-			log.Info("waiting for curator secrets to become available")
-			r.status.SetDegraded("Waiting for curator secrets to become available", "")
-			return reconcile.Result{}, nil
+	var ilmErrors []operatorv1.IndexLifecycleError
+	var health *operatorv1.ElasticsearchHealth
+	var savedObjects []operatorv1.SavedObjectsImport
+	var archive *operatorv1.ArchiveStatus
+	var failures, restoring []string
+	if installationCR.Spec.ClusterManagementType != operatorv1.ClusterManagementTypeManaged && ls.DeletionTimestamp == nil {
+		esClient, err := r.elasticsearchClient(ctx, ls)
+		if err != nil {
+			log.Error(err, err.Error())
+			r.status.SetDegraded("Failed to connect to Elasticsearch", err.Error())
+			return reconcile.Result{}, err
+		}
+
+		if ls.Spec.External == nil && len(scaling.heldNodeCounts) == 0 {
+			if err := clearNodeExclusion(ctx, esClient); err != nil {
+				log.Error(err, err.Error())
+				r.status.SetDegraded("Failed to scale Elasticsearch", err.Error())
+				return reconcile.Result{}, err
+			}
+		}
+
+		if ls.Spec.External == nil {
+			if err := reconcileKibanaSSO(ctx, esClient, ls); err != nil {
+				log.Error(err, err.Error())
+				r.status.SetDegraded("Failed to configure Kibana single sign-on", err.Error())
+				return reconcile.Result{}, err
+			}
+
+			if ls.Spec.Kibana != nil && !ls.Spec.Kibana.Disabled && (len(ls.Spec.Kibana.SavedObjectsConfigMaps) != 0 || len(ls.Spec.Kibana.Spaces) != 0) {
+				kbClient, err := r.kibanaClient(ctx)
+				if err != nil {
+					log.Error(err, err.Error())
+					r.status.SetDegraded("Failed to connect to Kibana", err.Error())
+					return reconcile.Result{}, err
+				}
+				if savedObjects, err = r.importSavedObjects(ctx, kbClient, ls); err != nil {
+					log.Error(err, err.Error())
+					r.status.SetDegraded("Failed to import Kibana saved objects", err.Error())
+					return reconcile.Result{}, err
+				}
+			}
+		}
+
+		rolloverClusters, err := r.rolloverClusters(ctx, clusterConfig.ClusterName(), fluentBitManagedClusters)
+		if err != nil {
+			log.Error(err, err.Error())
+			r.status.SetDegraded("An error occurred while querying LogCollector", err.Error())
+			return reconcile.Result{}, err
+		}
+
+		if ilmErrors, err = reconcileIndexLifecycle(ctx, esClient, ls, clusterConfig, rolloverClusters); err != nil {
+			log.Error(err, err.Error())
+			r.status.SetDegraded("Failed to configure index lifecycle management", err.Error())
+			return reconcile.Result{}, err
 		}
+
+		if ls.Spec.External == nil {
+			deleted, err := enforceStorageLimits(ctx, esClient)
+			for _, index := range deleted {
+				log.Info("Deleted index to free disk space", "index", index)
+			}
+			if err != nil {
+				log.Error(err, err.Error())
+				r.status.SetDegraded("Failed to enforce the storage limits", err.Error())
+				return reconcile.Result{}, err
+			}
+		}
+
+		if health, err = elasticsearchHealth(ctx, esClient); err != nil {
+			log.Error(err, err.Error())
+			r.status.SetDegraded("Failed to read the Elasticsearch cluster health", err.Error())
+			return reconcile.Result{}, err
+		}
+
+		if ls.Spec.Snapshots != nil {
+			failure, err := reconcileSnapshots(ctx, esClient, ls.Spec.Snapshots, time.Now())
+			if err != nil {
+				log.Error(err, err.Error())
+				r.status.SetDegraded("Failed to configure snapshots", err.Error())
+				return reconcile.Result{}, err
+			}
+			if failure != "" {
+				failures = append(failures, failure)
+			}
+
+			if ls.Spec.Snapshots.Archive != nil {
+				if archive, err = reconcileArchive(ctx, esClient, ls, append([]string{clusterConfig.ClusterName()}, managedClusters...), time.Now()); err != nil {
+					log.Error(err, err.Error())
+					r.status.SetDegraded("Failed to archive logs", err.Error())
+					return reconcile.Result{}, err
+				}
+			}
+		}
+
+		var failedRestores []string
+		if restoring, failedRestores, err = r.reconcileRestores(ctx, esClient, ls, clusterConfig.ClusterName()); err != nil {
+			log.Error(err, err.Error())
+			r.status.SetDegraded("Failed to restore snapshot", err.Error())
+			return reconcile.Result{}, err
+		}
+		failures = append(failures, failedRestores...)
 	}
 
-	r.status.ClearDegraded()
+	unhealthy, recovering := healthConditions(health)
+
+	var degradedReasons, degradedMessages []string
+	if len(scaling.failures) != 0 {
+		degradedReasons = append(degradedReasons, "Elasticsearch scaling blocked")
+		degradedMessages = append(degradedMessages, scaling.failures...)
+	}
+	if len(unhealthy) != 0 {
+		degradedReasons = append(degradedReasons, "Elasticsearch cluster unhealthy")
+		degradedMessages = append(degradedMessages, unhealthy...)
+	}
+	if failed := savedObjectsFailures(savedObjects); len(failed) != 0 {
+		degradedReasons = append(degradedReasons, "Kibana saved objects import failed")
+		degradedMessages = append(degradedMessages, failed...)
+	}
+	if archive != nil && archive.Error != "" {
+		degradedReasons = append(degradedReasons, "Log archive failed")
+		degradedMessages = append(degradedMessages, archive.Error)
+	}
+	// A failed restore is reported until its LogStorageRestore is deleted.
+	if len(failures) != 0 {
+		degradedReasons = append(degradedReasons, "Snapshot or restore failed")
+		degradedMessages = append(degradedMessages, failures...)
+	}
+	if len(degradedReasons) != 0 {
+		r.status.SetDegraded(strings.Join(degradedReasons, ", "), strings.Join(degradedMessages, "; "))
+	} else {
+		r.status.ClearDegraded()
+	}
 
 	if ls != nil {
 		ls.Status.State = operatorv1.LogStorageStatusReady
+		ls.Status.IndexLifecycleErrors = ilmErrors
+		ls.Status.NodeSets = scaling.nodeSets
+		ls.Status.Health = health
+		ls.Status.SavedObjects = savedObjects
+		ls.Status.Archive = archive
 		if err := r.client.Status().Update(ctx, ls); err != nil {
 			reqLogger.Error(err, fmt.Sprintf("Error updating the log-storage status %s", operatorv1.LogStorageStatusReady))
 			r.status.SetDegraded(fmt.Sprintf("Error updating the log-storage status %s", operatorv1.LogStorageStatusReady), err.Error())
@@ -456,6 +776,31 @@ func (r *ReconcileLogStorage) Reconcile(request reconcile.Request) (reconcile.Re
 		}
 	}
 
+	var progressingReasons, progress []string
+	requeueAfter := scalingPollInterval
+	if len(scaling.progress) != 0 {
+		progressingReasons = append(progressingReasons, "Scaling Elasticsearch")
+		progress = append(progress, scaling.progress...)
+	}
+	if len(recovering) != 0 {
+		progressingReasons = append(progressingReasons, "Elasticsearch cluster recovering")
+		progress = append(progress, recovering...)
+	}
+	if len(restoring) != 0 {
+		progressingReasons = append(progressingReasons, "Restoring snapshot")
+		progress = append(progress, restoring...)
+		requeueAfter = restorePollInterval
+	}
+	if len(progressingReasons) != 0 {
+		r.status.SetProgressing(strings.Join(progressingReasons, ", "), strings.Join(progress, "; "))
+		return reconcile.Result{RequeueAfter: requeueAfter}, nil
+	}
+	r.status.ClearProgressing()
+
+	// The health of the cluster is polled as it changes without any of the watched resources changing.
+	if health != nil {
+		return reconcile.Result{RequeueAfter: healthPollInterval}, nil
+	}
 	return reconcile.Result{}, nil
 }
 
@@ -16,7 +16,11 @@ package logstorage_test
 
 import (
 	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -55,11 +59,8 @@ var (
 	eckOperatorObjKey = client.ObjectKey{Name: render.ECKOperatorName, Namespace: render.ECKOperatorNamespace}
 	esObjKey          = client.ObjectKey{Name: render.ElasticsearchName, Namespace: render.ElasticsearchNamespace}
 	kbObjKey          = client.ObjectKey{Name: render.KibanaName, Namespace: render.KibanaNamespace}
-	curatorObjKey     = types.NamespacedName{Namespace: render.ElasticsearchNamespace, Name: render.EsCuratorName}
 
-	esPublicCertObjMeta     = metav1.ObjectMeta{Name: render.ElasticsearchPublicCertSecret, Namespace: render.ElasticsearchNamespace}
-	kbPublicCertObjMeta     = metav1.ObjectMeta{Name: render.KibanaPublicCertSecret, Namespace: render.KibanaNamespace}
-	curatorUsrSecretObjMeta = metav1.ObjectMeta{Name: render.ElasticsearchCuratorUserSecret, Namespace: render.OperatorNamespace()}
+	kbPublicCertObjMeta = metav1.ObjectMeta{Name: render.KibanaPublicCertSecret, Namespace: render.KibanaNamespace}
 )
 
 var _ = Describe("LogStorage controller", func() {
@@ -107,6 +108,7 @@ var _ = Describe("LogStorage controller", func() {
 						mockStatus.On("AddDeployments", mock.Anything).Return()
 						mockStatus.On("AddStatefulSets", mock.Anything).Return()
 						mockStatus.On("AddCronJobs", mock.Anything)
+						mockStatus.On("ClearProgressing")
 						mockStatus.On("OnCRNotFound").Return()
 						mockStatus.On("ClearDegraded")
 					})
@@ -173,6 +175,7 @@ var _ = Describe("LogStorage controller", func() {
 						mockStatus.On("AddDeployments", mock.Anything).Return()
 						mockStatus.On("AddStatefulSets", mock.Anything).Return()
 						mockStatus.On("AddCronJobs", mock.Anything)
+						mockStatus.On("ClearProgressing")
 						mockStatus.On("ClearDegraded", mock.Anything).Return()
 
 						r, err := logstorage.NewReconcilerWithShims(cli, scheme, mockStatus, operatorv1.ProviderNone, "")
@@ -219,6 +222,9 @@ var _ = Describe("LogStorage controller", func() {
 		Context("Unmanaged cluster", func() {
 			Context("successful LogStorage Reconcile", func() {
 				var mockStatus *status.MockStatus
+				var server *httptest.Server
+				var paths []string
+				var responses map[string]string
 
 				BeforeEach(func() {
 					ctx := context.Background()
@@ -241,8 +247,18 @@ var _ = Describe("LogStorage controller", func() {
 					mockStatus.On("AddDeployments", mock.Anything)
 					mockStatus.On("AddStatefulSets", mock.Anything)
 					mockStatus.On("AddCronJobs", mock.Anything)
+					mockStatus.On("ClearProgressing")
 					mockStatus.On("OnCRFound").Return()
+
+					paths = nil
+					responses = map[string]string{}
+					server = newElasticsearchServer(cli, &paths, responses)
+				})
+
+				AfterEach(func() {
+					server.Close()
 				})
+
 				It("test LogStorage reconciles successfully", func() {
 					ctx := context.Background()
 					Expect(cli.Create(ctx, &storagev1.StorageClass{
@@ -264,6 +280,7 @@ var _ = Describe("LogStorage controller", func() {
 
 					r, err := logstorage.NewReconcilerWithShims(cli, scheme, mockStatus, operatorv1.ProviderNone, "")
 					Expect(err).ShouldNot(HaveOccurred())
+					r.SetElasticsearchEndpoint(server.URL)
 
 					mockStatus.On("SetDegraded", "Waiting for Elasticsearch cluster to be operational", "").Return()
 					result, err := r.Reconcile(reconcile.Request{})
@@ -290,30 +307,335 @@ var _ = Describe("LogStorage controller", func() {
 					kb.Status.AssociationStatus = cmneckalpha1.AssociationEstablished
 					Expect(cli.Update(ctx, kb)).ShouldNot(HaveOccurred())
 
-					Expect(cli.Create(ctx, &corev1.Secret{ObjectMeta: esPublicCertObjMeta})).ShouldNot(HaveOccurred())
 					Expect(cli.Create(ctx, &corev1.Secret{ObjectMeta: kbPublicCertObjMeta})).ShouldNot(HaveOccurred())
 
-					mockStatus.On("SetDegraded", "Waiting for curator secrets to become available", "").Return()
+					mockStatus.On("ClearDegraded")
 					result, err = r.Reconcile(reconcile.Request{})
 					Expect(err).ShouldNot(HaveOccurred())
-					// Expect to be waiting for curator secret
-					Expect(result).Should(Equal(reconcile.Result{}))
-					Expect(cli.Create(ctx, &corev1.Secret{ObjectMeta: curatorUsrSecretObjMeta})).ShouldNot(HaveOccurred())
+					Expect(result).Should(Equal(reconcile.Result{RequeueAfter: logstorage.HealthPollInterval}))
+
+					By("confirming the index lifecycle policies and templates are created")
+					Expect(paths).To(ContainElement("/_ilm/policy/tigera_secure_ee_flows_policy"))
+					Expect(paths).To(ContainElement("/_template/tigera_secure_ee_flows"))
+					Expect(paths).To(ContainElement("/_alias/tigera_secure_ee_flows.cluster."))
+
+					By("confirming the users of each component are created")
+					Expect(paths).To(ContainElement("/_security/user/tigera-fluentd"))
+					secret := &corev1.Secret{}
+					Expect(cli.Get(ctx, types.NamespacedName{Name: render.ElasticsearchLogCollectorUserSecret, Namespace: render.OperatorNamespace()}, secret)).ShouldNot(HaveOccurred())
+					Expect(secret.Data["password"]).NotTo(BeEmpty())
+
+					mockStatus.AssertExpectations(GinkgoT())
+				})
+
+				It("configures snapshots and reports the progress of a restore", func() {
+					ctx := context.Background()
+					Expect(cli.Create(ctx, &storagev1.StorageClass{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: render.ElasticsearchStorageClass,
+						},
+					})).ShouldNot(HaveOccurred())
+
+					Expect(cli.Create(ctx, &operatorv1.LogStorage{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "tigera-secure",
+						},
+						Spec: operatorv1.LogStorageSpec{
+							Nodes: &operatorv1.Nodes{
+								Count: int64(1),
+							},
+							Snapshots: &operatorv1.Snapshots{
+								Repository: operatorv1.SnapshotRepository{
+									S3: &operatorv1.S3SnapshotRepository{Bucket: "snapshots", Endpoint: "http://minio.minio.svc:9000"},
+								},
+							},
+						},
+					})).ShouldNot(HaveOccurred())
+
+					Expect(cli.Create(ctx, &operatorv1.LogStorageRestore{
+						ObjectMeta: metav1.ObjectMeta{Name: "restore"},
+						Spec:       operatorv1.LogStorageRestoreSpec{Snapshot: "snapshot-1"},
+					})).ShouldNot(HaveOccurred())
+
+					r, err := logstorage.NewReconcilerWithShims(cli, scheme, mockStatus, operatorv1.ProviderNone, "")
+					Expect(err).ShouldNot(HaveOccurred())
+					r.SetElasticsearchEndpoint(server.URL)
+
+					mockStatus.On("SetDegraded", "Waiting for Elasticsearch cluster to be operational", "").Return()
+					_, err = r.Reconcile(reconcile.Request{})
+					Expect(err).ShouldNot(HaveOccurred())
+
+					es := &esv1alpha1.Elasticsearch{}
+					Expect(cli.Get(ctx, esObjKey, es)).ShouldNot(HaveOccurred())
+					es.Status.Phase = esv1alpha1.ElasticsearchOperationalPhase
+					Expect(cli.Update(ctx, es)).ShouldNot(HaveOccurred())
+
+					kb := &kbv1alpha1.Kibana{}
+					Expect(cli.Get(ctx, kbObjKey, kb)).ShouldNot(HaveOccurred())
+					kb.Status.AssociationStatus = cmneckalpha1.AssociationEstablished
+					Expect(cli.Update(ctx, kb)).ShouldNot(HaveOccurred())
+
+					Expect(cli.Create(ctx, &corev1.Secret{ObjectMeta: kbPublicCertObjMeta})).ShouldNot(HaveOccurred())
+
+					responses["/"] = `{"version": {"number": "7.5.2"}}`
+					responses["/_snapshot/tigera-secure-snapshots/snapshot-1"] = `{"snapshots": [{"snapshot": "snapshot-1", "state": "SUCCESS", "indices": ["restored_flows"]}]}`
+					responses["/_cluster/health"] = `{"status": "green", "indices": {}}`
 
 					mockStatus.On("ClearDegraded")
+					mockStatus.On("SetProgressing", "Restoring snapshot", mock.Anything).Return()
+					result, err := r.Reconcile(reconcile.Request{})
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(result.RequeueAfter).NotTo(BeZero())
+
+					Expect(paths).To(ContainElement("/_snapshot/tigera-secure-snapshots"))
+					Expect(paths).To(ContainElement("/_slm/policy/tigera-secure-snapshots"))
+					Expect(paths).To(ContainElement("/_snapshot/tigera-secure-snapshots/snapshot-1/_restore"))
+
+					restore := &operatorv1.LogStorageRestore{}
+					Expect(cli.Get(ctx, types.NamespacedName{Name: "restore"}, restore)).ShouldNot(HaveOccurred())
+					Expect(restore.Status.State).To(Equal(operatorv1.LogStorageRestoreStateInProgress))
+
+					_, err = r.Reconcile(reconcile.Request{})
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(cli.Get(ctx, types.NamespacedName{Name: "restore"}, restore)).ShouldNot(HaveOccurred())
+					Expect(restore.Status.Message).To(Equal("Restoring 1 indices: restored_flows"))
+
+					By("waiting for the primary shards of the restored indices to be allocated")
+					responses["/_cluster/health"] = `{"status": "red", "unassigned_shards": 1, "indices": {"restored_flows": {"status": "red"}}}`
+					mockStatus.On("SetDegraded", "Elasticsearch cluster unhealthy", mock.Anything).Return()
+					_, err = r.Reconcile(reconcile.Request{})
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(cli.Get(ctx, types.NamespacedName{Name: "restore"}, restore)).ShouldNot(HaveOccurred())
+					Expect(restore.Status.State).To(Equal(operatorv1.LogStorageRestoreStateInProgress))
+
+					By("completing the restore once the restored indices are no longer red")
+					responses["/_cluster/health"] = `{"status": "green", "indices": {"restored_flows": {"status": "green"}}}`
 					result, err = r.Reconcile(reconcile.Request{})
 					Expect(err).ShouldNot(HaveOccurred())
-					Expect(result).Should(Equal(reconcile.Result{}))
+					Expect(result).Should(Equal(reconcile.Result{RequeueAfter: logstorage.HealthPollInterval}))
+					Expect(cli.Get(ctx, types.NamespacedName{Name: "restore"}, restore)).ShouldNot(HaveOccurred())
+					Expect(restore.Status.State).To(Equal(operatorv1.LogStorageRestoreStateCompleted))
+
+					mockStatus.AssertExpectations(GinkgoT())
+				})
+
+				It("reports the health and disk usage of the Elasticsearch cluster", func() {
+					ctx := context.Background()
+					Expect(cli.Create(ctx, &storagev1.StorageClass{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: render.ElasticsearchStorageClass,
+						},
+					})).ShouldNot(HaveOccurred())
+					Expect(cli.Create(ctx, &operatorv1.LogStorage{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "tigera-secure",
+						},
+						Spec: operatorv1.LogStorageSpec{
+							Nodes: &operatorv1.Nodes{
+								Count: int64(1),
+							},
+						},
+					})).ShouldNot(HaveOccurred())
+
+					r, err := logstorage.NewReconcilerWithShims(cli, scheme, mockStatus, operatorv1.ProviderNone, "")
+					Expect(err).ShouldNot(HaveOccurred())
+					r.SetElasticsearchEndpoint(server.URL)
+
+					mockStatus.On("SetDegraded", "Waiting for Elasticsearch cluster to be operational", "").Return()
+					_, err = r.Reconcile(reconcile.Request{})
+					Expect(err).ShouldNot(HaveOccurred())
+
+					es := &esv1alpha1.Elasticsearch{}
+					Expect(cli.Get(ctx, esObjKey, es)).ShouldNot(HaveOccurred())
+					es.Status.Phase = esv1alpha1.ElasticsearchOperationalPhase
+					Expect(cli.Update(ctx, es)).ShouldNot(HaveOccurred())
+
+					kb := &kbv1alpha1.Kibana{}
+					Expect(cli.Get(ctx, kbObjKey, kb)).ShouldNot(HaveOccurred())
+					kb.Status.AssociationStatus = cmneckalpha1.AssociationEstablished
+					Expect(cli.Update(ctx, kb)).ShouldNot(HaveOccurred())
 
-					By("confirming curator job is created")
-					Expect(cli.Get(ctx, curatorObjKey, &batchv1beta.CronJob{})).ShouldNot(HaveOccurred())
+					Expect(cli.Create(ctx, &corev1.Secret{ObjectMeta: kbPublicCertObjMeta})).ShouldNot(HaveOccurred())
+
+					responses["/_cluster/health"] = `{"status": "red", "number_of_nodes": 1, "number_of_data_nodes": 1, "unassigned_shards": 3}`
+					responses["/_cat/allocation"] = `[
+						{"node": "tigera-secure-es-0", "disk.used": "96", "disk.avail": "4", "disk.total": "100", "disk.percent": "96"},
+						{"node": "UNASSIGNED", "disk.used": null, "disk.avail": null, "disk.total": null, "disk.percent": null}
+					]`
+					responses["/_cluster/settings"] = `{"persistent": {}, "transient": {}, "defaults": {
+						"cluster.routing.allocation.disk.watermark.low": "85%",
+						"cluster.routing.allocation.disk.watermark.high": "90%",
+						"cluster.routing.allocation.disk.watermark.flood_stage": "95%"
+					}}`
+
+					mockStatus.On("SetDegraded", "Elasticsearch cluster unhealthy", mock.Anything).Return()
+					result, err := r.Reconcile(reconcile.Request{})
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(result).Should(Equal(reconcile.Result{RequeueAfter: logstorage.HealthPollInterval}))
+
+					ls := &operatorv1.LogStorage{}
+					Expect(cli.Get(ctx, types.NamespacedName{Name: "tigera-secure"}, ls)).ShouldNot(HaveOccurred())
+					Expect(ls.Status.Health).NotTo(BeNil())
+					Expect(ls.Status.Health.Status).To(Equal(operatorv1.ElasticsearchHealthRed))
+					Expect(ls.Status.Health.UnassignedShards).To(Equal(int32(3)))
+					Expect(ls.Status.Health.Watermark).To(Equal(operatorv1.DiskWatermarkFloodStage))
+					Expect(ls.Status.Health.Nodes).To(Equal([]operatorv1.NodeDiskUsage{{
+						Name:           "tigera-secure-es-0",
+						DiskUsedBytes:  96,
+						DiskTotalBytes: 100,
+						DiskPercent:    96,
+						Watermark:      operatorv1.DiskWatermarkFloodStage,
+					}}))
+
+					By("clearing the degraded condition once the cluster is healthy")
+					responses["/_cluster/health"] = `{"status": "green"}`
+					responses["/_cat/allocation"] = `[{"node": "tigera-secure-es-0", "disk.used": "50", "disk.avail": "50", "disk.total": "100", "disk.percent": "50"}]`
+					mockStatus.On("ClearDegraded")
+					_, err = r.Reconcile(reconcile.Request{})
+					Expect(err).ShouldNot(HaveOccurred())
+
+					Expect(cli.Get(ctx, types.NamespacedName{Name: "tigera-secure"}, ls)).ShouldNot(HaveOccurred())
+					Expect(ls.Status.Health.Status).To(Equal(operatorv1.ElasticsearchHealthGreen))
+					Expect(ls.Status.Health.Watermark).To(Equal(operatorv1.DiskWatermarkNone))
 
 					mockStatus.AssertExpectations(GinkgoT())
 				})
 			})
 
+			Context("external Elasticsearch cluster", func() {
+				var mockStatus *status.MockStatus
+				var server *httptest.Server
+				var paths []string
+
+				BeforeEach(func() {
+					ctx := context.Background()
+					Expect(cli.Create(ctx, &operatorv1.Installation{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "default",
+						},
+						Status: operatorv1.InstallationStatus{
+							Variant: operatorv1.TigeraSecureEnterprise,
+						},
+						Spec: operatorv1.InstallationSpec{
+							Variant:               operatorv1.TigeraSecureEnterprise,
+							ClusterManagementType: operatorv1.ClusterManagementTypeStandalone,
+						},
+					})).ShouldNot(HaveOccurred())
+
+					paths = nil
+					server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						if u, p, _ := r.BasicAuth(); u != "admin" || p != "adminpw" {
+							w.WriteHeader(http.StatusUnauthorized)
+							return
+						}
+						paths = append(paths, r.URL.Path)
+						if strings.HasPrefix(r.URL.Path, "/_cat/") {
+							_, _ = w.Write([]byte(`[]`))
+							return
+						}
+						_, _ = w.Write([]byte(`{}`))
+					}))
+
+					Expect(cli.Create(ctx, &corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{Name: "es-ca", Namespace: render.OperatorNamespace()},
+						Data: map[string][]byte{
+							"tls.crt": pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}),
+						},
+					})).ShouldNot(HaveOccurred())
+					Expect(cli.Create(ctx, &corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{Name: "es-admin", Namespace: render.OperatorNamespace()},
+						Data:       map[string][]byte{"username": []byte("admin"), "password": []byte("adminpw")},
+					})).ShouldNot(HaveOccurred())
+					Expect(cli.Create(ctx, &operatorv1.LogStorage{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "tigera-secure",
+						},
+						Spec: operatorv1.LogStorageSpec{
+							External: &operatorv1.ExternalElasticsearch{
+								Endpoint:        server.URL,
+								CASecretName:    "es-ca",
+								AdminSecretName: "es-admin",
+							},
+						},
+					})).ShouldNot(HaveOccurred())
+
+					mockStatus = &status.MockStatus{}
+					mockStatus.On("Run").Return()
+					mockStatus.On("AddDaemonsets", mock.Anything)
+					mockStatus.On("AddDeployments", mock.Anything)
+					mockStatus.On("AddStatefulSets", mock.Anything)
+					mockStatus.On("AddCronJobs", mock.Anything)
+					mockStatus.On("ClearProgressing")
+					mockStatus.On("OnCRFound").Return()
+				})
+
+				AfterEach(func() {
+					server.Close()
+				})
+
+				It("creates the users for each component without installing ECK or Kibana", func() {
+					ctx := context.Background()
+					r, err := logstorage.NewReconcilerWithShims(cli, scheme, mockStatus, operatorv1.ProviderNone, "")
+					Expect(err).ShouldNot(HaveOccurred())
+
+					mockStatus.On("ClearDegraded")
+					result, err := r.Reconcile(reconcile.Request{})
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(result).Should(Equal(reconcile.Result{RequeueAfter: logstorage.HealthPollInterval}))
+
+					Expect(paths).To(ContainElement("/_security/role/tigera-fluentd"))
+					Expect(paths).To(ContainElement("/_security/user/tigera-fluentd"))
+
+					userSecret := &corev1.Secret{}
+					Expect(cli.Get(ctx, types.NamespacedName{Name: render.ElasticsearchLogCollectorUserSecret, Namespace: render.OperatorNamespace()}, userSecret)).ShouldNot(HaveOccurred())
+					Expect(string(userSecret.Data["username"])).To(Equal("tigera-fluentd"))
+					password := userSecret.Data["password"]
+					Expect(password).NotTo(BeEmpty())
+
+					caSecret := &corev1.Secret{}
+					Expect(cli.Get(ctx, types.NamespacedName{Name: render.ElasticsearchPublicCertSecret, Namespace: render.OperatorNamespace()}, caSecret)).ShouldNot(HaveOccurred())
+					Expect(caSecret.Data["tls.crt"]).NotTo(BeEmpty())
+
+					cm := &corev1.ConfigMap{}
+					Expect(cli.Get(ctx, types.NamespacedName{Name: render.ElasticsearchConfigMapName, Namespace: render.OperatorNamespace()}, cm)).ShouldNot(HaveOccurred())
+					Expect(cm.Data["endpoint"]).To(Equal(server.URL))
+
+					Expect(paths).To(ContainElement("/_ilm/policy/tigera_secure_ee_dns_policy"))
+					Expect(errors.IsNotFound(cli.Get(ctx, eckOperatorObjKey, &appsv1.StatefulSet{}))).To(BeTrue())
+					Expect(errors.IsNotFound(cli.Get(ctx, esObjKey, &esv1alpha1.Elasticsearch{}))).To(BeTrue())
+					Expect(errors.IsNotFound(cli.Get(ctx, kbObjKey, &kbv1alpha1.Kibana{}))).To(BeTrue())
+
+					By("keeping the passwords of existing users")
+					_, err = r.Reconcile(reconcile.Request{})
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(cli.Get(ctx, types.NamespacedName{Name: render.ElasticsearchLogCollectorUserSecret, Namespace: render.OperatorNamespace()}, userSecret)).ShouldNot(HaveOccurred())
+					Expect(userSecret.Data["password"]).To(Equal(password))
+
+					mockStatus.AssertExpectations(GinkgoT())
+				})
+
+				It("degrades if the endpoint is not https", func() {
+					ctx := context.Background()
+					ls := &operatorv1.LogStorage{}
+					Expect(cli.Get(ctx, types.NamespacedName{Name: "tigera-secure"}, ls)).ShouldNot(HaveOccurred())
+					ls.Spec.External.Endpoint = "http://es.example.com:9200"
+					Expect(cli.Update(ctx, ls)).ShouldNot(HaveOccurred())
+
+					r, err := logstorage.NewReconcilerWithShims(cli, scheme, mockStatus, operatorv1.ProviderNone, "")
+					Expect(err).ShouldNot(HaveOccurred())
+
+					mockStatus.On("SetDegraded", "Invalid external Elasticsearch configuration", mock.Anything).Return()
+					_, err = r.Reconcile(reconcile.Request{})
+					Expect(err).ShouldNot(HaveOccurred())
+					Expect(paths).To(BeEmpty())
+
+					mockStatus.AssertCalled(GinkgoT(), "SetDegraded", "Invalid external Elasticsearch configuration", mock.Anything)
+				})
+			})
+
 			Context("LogStorage CR deleted", func() {
 				var mockStatus *status.MockStatus
+				var server *httptest.Server
 
 				BeforeEach(func() {
 					ctx := context.Background()
@@ -332,6 +654,7 @@ var _ = Describe("LogStorage controller", func() {
 					})).ShouldNot(HaveOccurred())
 
 					setUpLogStorageComponents(cli)
+					server = newElasticsearchServer(cli, nil, nil)
 
 					mockStatus = &status.MockStatus{}
 					mockStatus.On("Run").Return()
@@ -339,18 +662,24 @@ var _ = Describe("LogStorage controller", func() {
 					mockStatus.On("AddDeployments", mock.Anything)
 					mockStatus.On("AddStatefulSets", mock.Anything)
 					mockStatus.On("AddCronJobs", mock.Anything)
+					mockStatus.On("ClearProgressing")
 					mockStatus.On("ClearDegraded", mock.Anything)
 					mockStatus.On("OnCRFound").Return()
 				})
 
+				AfterEach(func() {
+					server.Close()
+				})
+
 				It("deletes Elasticsearch and Kibana then removes the finalizers on the LogStorage CR", func() {
 					r, err := logstorage.NewReconcilerWithShims(cli, scheme, mockStatus, operatorv1.ProviderNone, "")
 					Expect(err).ShouldNot(HaveOccurred())
+					r.SetElasticsearchEndpoint(server.URL)
 
 					By("making sure LogStorage has successfully reconciled")
 					result, err := r.Reconcile(reconcile.Request{})
 					Expect(err).ShouldNot(HaveOccurred())
-					Expect(result).Should(Equal(reconcile.Result{}))
+					Expect(result).Should(Equal(reconcile.Result{RequeueAfter: logstorage.HealthPollInterval}))
 
 					ls := &operatorv1.LogStorage{}
 					Expect(cli.Get(context.Background(), utils.DefaultTSEEInstanceKey, ls)).ShouldNot(HaveOccurred())
@@ -433,7 +762,8 @@ func setUpLogStorageComponents(cli client.Client) {
 		},
 		&esv1alpha1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchName, Namespace: render.ElasticsearchNamespace}},
 		&kbv1alpha1.Kibana{ObjectMeta: metav1.ObjectMeta{Name: render.KibanaName, Namespace: render.KibanaNamespace}},
-		render.NewElasticsearchClusterConfig("cluster", 1, 1),
+		render.NewElasticsearchClusterConfig("cluster", 1, 1, ""),
+		nil,
 		[]*corev1.Secret{
 			{ObjectMeta: metav1.ObjectMeta{Name: render.TigeraElasticsearchCertSecret, Namespace: render.OperatorNamespace()}},
 			{ObjectMeta: metav1.ObjectMeta{Name: render.TigeraElasticsearchCertSecret, Namespace: render.ElasticsearchNamespace}},
@@ -448,10 +778,6 @@ func setUpLogStorageComponents(cli client.Client) {
 		[]*corev1.Secret{
 			{ObjectMeta: metav1.ObjectMeta{Name: "tigera-pull-secret"}},
 		}, operatorv1.ProviderNone,
-		[]*corev1.Secret{
-			{ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchCuratorUserSecret, Namespace: render.OperatorNamespace()}},
-			{ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchPublicCertSecret, Namespace: render.OperatorNamespace()}},
-		},
 		nil, "cluster.local",
 	)
 
@@ -473,10 +799,41 @@ func setUpLogStorageComponents(cli client.Client) {
 
 		Expect(cli.Create(ctx, obj)).ShouldNot(HaveOccurred())
 	}
+}
+
+// newElasticsearchServer starts a server that stands in for the Elasticsearch cluster created by ECK and creates the
+// secrets the reconciler reads to connect to it as the elastic user. The path of each request is appended to paths.
+func newElasticsearchServer(cli client.Client, paths *[]string, responses map[string]string) *httptest.Server {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if u, p, _ := r.BasicAuth(); u != render.ElasticsearchAdminUser || p != "elasticpw" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if paths != nil {
+			*paths = append(*paths, r.URL.Path)
+		}
+		if resp, ok := responses[r.URL.Path]; ok {
+			_, _ = w.Write([]byte(resp))
+			return
+		}
+		if strings.HasPrefix(r.URL.Path, "/_cat/") {
+			_, _ = w.Write([]byte(`[]`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+
+	ctx := context.Background()
+	Expect(cli.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchPublicCertSecret, Namespace: render.ElasticsearchNamespace},
+		Data: map[string][]byte{
+			"tls.crt": pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}),
+		},
+	})).ShouldNot(HaveOccurred())
+	Expect(cli.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchAdminUserSecret, Namespace: render.ElasticsearchNamespace},
+		Data:       map[string][]byte{render.ElasticsearchAdminUser: []byte("elasticpw")},
+	})).ShouldNot(HaveOccurred())
 
-	Expect(
-		cli.Create(ctx, &corev1.Secret{
-			ObjectMeta: curatorUsrSecretObjMeta,
-		}),
-	).ShouldNot(HaveOccurred())
+	return server
 }
@@ -0,0 +1,53 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"sort"
+
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
+	operatorv1 "github.com/tigera/operator/pkg/apis/operator/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// logCollectorAnnotation selects the log collector of a managed cluster on its ManagedCluster resource, as the
+// management cluster can't read the LogCollector of the managed cluster. The rollover aliases that Fluent Bit writes to
+// are created for the managed clusters annotated with FluentBit.
+const logCollectorAnnotation = "operator.tigera.io/log-collector"
+
+// managedClusterNames returns the sorted names of the clusters managed by a management cluster, which are the suffixes
+// of the indices their logs are written to, and the names of those whose logs are collected by Fluent Bit. Other
+// clusters don't manage any clusters.
+func managedClusterNames(ctx context.Context, cli client.Client, installation *operatorv1.Installation) ([]string, []string, error) {
+	if installation.Spec.ClusterManagementType != operatorv1.ClusterManagementTypeManagement {
+		return nil, nil, nil
+	}
+
+	clusters := &v3.ManagedClusterList{}
+	if err := cli.List(ctx, clusters); err != nil {
+		return nil, nil, err
+	}
+	sort.Slice(clusters.Items, func(i, j int) bool { return clusters.Items[i].Name < clusters.Items[j].Name })
+
+	var names, fluentBit []string
+	for _, c := range clusters.Items {
+		names = append(names, c.Name)
+		if operatorv1.LogCollectorType(c.Annotations[logCollectorAnnotation]) == operatorv1.LogCollectorTypeFluentBit {
+			fluentBit = append(fluentBit, c.Name)
+		}
+	}
+	return names, fluentBit, nil
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
+	"github.com/tigera/operator/pkg/apis"
+	operatorv1 "github.com/tigera/operator/pkg/apis/operator/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Managed cluster tests", func() {
+	var cli client.Client
+	var r *ReconcileLogStorage
+	var installation *operatorv1.Installation
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(apis.AddToScheme(scheme)).ShouldNot(HaveOccurred())
+		cli = fake.NewFakeClientWithScheme(scheme)
+		r = &ReconcileLogStorage{client: cli}
+		installation = &operatorv1.Installation{
+			Spec: operatorv1.InstallationSpec{ClusterManagementType: operatorv1.ClusterManagementTypeManagement},
+		}
+
+		ctx := context.Background()
+		Expect(cli.Create(ctx, &v3.ManagedCluster{ObjectMeta: metav1.ObjectMeta{Name: "managed-2"}})).ShouldNot(HaveOccurred())
+		Expect(cli.Create(ctx, &v3.ManagedCluster{ObjectMeta: metav1.ObjectMeta{
+			Name:        "managed-1",
+			Annotations: map[string]string{logCollectorAnnotation: "FluentBit"},
+		}})).ShouldNot(HaveOccurred())
+	})
+
+	It("returns the managed clusters of a management cluster", func() {
+		names, fluentBit, err := managedClusterNames(context.Background(), cli, installation)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(names).To(Equal([]string{"managed-1", "managed-2"}))
+		Expect(fluentBit).To(Equal([]string{"managed-1"}))
+	})
+
+	It("does not return managed clusters for other clusters", func() {
+		installation.Spec.ClusterManagementType = operatorv1.ClusterManagementTypeStandalone
+		names, fluentBit, err := managedClusterNames(context.Background(), cli, installation)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(names).To(BeEmpty())
+		Expect(fluentBit).To(BeEmpty())
+	})
+
+	It("creates rollover aliases for the managed clusters and this cluster when they use Fluent Bit", func() {
+		clusters, err := r.rolloverClusters(context.Background(), "cluster", []string{"managed-1"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(clusters).To(Equal([]string{"managed-1"}))
+
+		Expect(cli.Create(context.Background(), &operatorv1.LogCollector{
+			ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"},
+			Spec:       operatorv1.LogCollectorSpec{Collector: operatorv1.LogCollectorTypeFluentBit},
+		})).ShouldNot(HaveOccurred())
+		clusters, err = r.rolloverClusters(context.Background(), "cluster", []string{"managed-1"})
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(clusters).To(Equal([]string{"cluster", "managed-1"}))
+	})
+})
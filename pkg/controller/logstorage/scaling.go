@@ -0,0 +1,299 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	esalpha1 "github.com/elastic/cloud-on-k8s/operators/pkg/apis/elasticsearch/v1alpha1"
+	operatorv1 "github.com/tigera/operator/pkg/apis/operator/v1"
+	"github.com/tigera/operator/pkg/elasticsearch"
+	"github.com/tigera/operator/pkg/render"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// scalingPollInterval is how often the progress of moving shards off nodes and expanding volumes is checked.
+	scalingPollInterval = 30 * time.Second
+
+	// elasticsearchDataVolume is the name of the volume claim template of the Elasticsearch nodes. ECK names the
+	// claim of each node <template name>-<pod name>.
+	elasticsearchDataVolume = "elasticsearch-data"
+)
+
+// scalingPlan is the result of reconciling the number of nodes and the storage of the Elasticsearch node sets.
+type scalingPlan struct {
+	// heldNodeCounts is the number of nodes kept in each node set whose departing nodes can't be removed yet.
+	heldNodeCounts map[string]int32
+	nodeSets       []operatorv1.NodeSetStatus
+	failures       []string
+	progress       []string
+}
+
+// departingNodes returns the names of the nodes of each node set of the Elasticsearch cluster that are removed by
+// changing its node sets to desired. ECK removes the nodes with the highest ordinals first.
+func departingNodes(desired []operatorv1.NodeSet, es *esalpha1.Elasticsearch) map[string][]string {
+	counts := map[string]int64{}
+	for _, nodeSet := range desired {
+		counts[nodeSet.Name] = nodeSet.Count
+	}
+
+	departing := map[string][]string{}
+	for _, node := range es.Spec.Nodes {
+		for i := counts[node.Name]; i < int64(node.NodeCount); i++ {
+			departing[node.Name] = append(departing[node.Name], fmt.Sprintf("%s-%d", render.ElasticsearchStatefulSetName(node.Name), i))
+		}
+	}
+	return departing
+}
+
+// isDataNodeSpec returns true if the nodes of the node spec of the Elasticsearch cluster hold data.
+func isDataNodeSpec(node esalpha1.NodeSpec) bool {
+	return node.Config == nil || node.Config.Data["node.data"] != "false"
+}
+
+// dataNodeCount returns the number of data nodes in the node sets.
+func dataNodeCount(nodeSets []operatorv1.NodeSet) int64 {
+	var count int64
+	for _, nodeSet := range nodeSets {
+		if nodeSet.HasRole(operatorv1.ElasticsearchNodeRoleData) {
+			count += nodeSet.Count
+		}
+	}
+	return count
+}
+
+// maxReplicas returns the highest number of replicas of any type of index.
+func maxReplicas(clusterConfig *render.ElasticsearchClusterConfig) int {
+	replicas := clusterConfig.Replicas()
+	for _, index := range render.ElasticsearchIndices {
+		if r := clusterConfig.IndexSettings(index).Replicas; r > replicas {
+			replicas = r
+		}
+	}
+	return replicas
+}
+
+// reconcileScaling moves the shards off the Elasticsearch nodes that are removed from the cluster and expands the
+// volumes of the nodes whose storage size has been increased. The nodes of a node set are only removed once they no
+// longer hold any shards, and not at all if too few data nodes would remain to hold the replicas of every index.
+func (r *ReconcileLogStorage) reconcileScaling(ctx context.Context, ls *operatorv1.LogStorage, es *esalpha1.Elasticsearch, clusterConfig *render.ElasticsearchClusterConfig) (*scalingPlan, error) {
+	plan := &scalingPlan{heldNodeCounts: map[string]int32{}}
+	desired := render.ElasticsearchNodeSets(ls)
+
+	var names []string
+	statuses := map[string]*operatorv1.NodeSetStatus{}
+	for _, nodeSet := range desired {
+		names = append(names, nodeSet.Name)
+		statuses[nodeSet.Name] = &operatorv1.NodeSetStatus{
+			Name:         nodeSet.Name,
+			Count:        nodeSet.Count,
+			DesiredCount: nodeSet.Count,
+			Phase:        operatorv1.NodeSetReady,
+		}
+	}
+
+	current := map[string]esalpha1.NodeSpec{}
+	for _, node := range es.Spec.Nodes {
+		current[node.Name] = node
+		if _, ok := statuses[node.Name]; !ok {
+			names = append(names, node.Name)
+			statuses[node.Name] = &operatorv1.NodeSetStatus{Name: node.Name, Phase: operatorv1.NodeSetReady}
+		}
+	}
+
+	hold := func(name string, phase operatorv1.NodeSetPhase, message string) {
+		plan.heldNodeCounts[name] = current[name].NodeCount
+		statuses[name].Count = int64(current[name].NodeCount)
+		statuses[name].Phase = phase
+		statuses[name].Message = message
+	}
+
+	departing := departingNodes(desired, es)
+	var draining []string
+	for name := range departing {
+		draining = append(draining, name)
+	}
+	sort.Strings(draining)
+
+	if len(draining) > 0 {
+		removesData := false
+		for _, name := range draining {
+			removesData = removesData || isDataNodeSpec(current[name])
+		}
+		dataNodes, replicas := dataNodeCount(desired), maxReplicas(clusterConfig)
+
+		switch {
+		case removesData && dataNodes <= int64(replicas):
+			message := fmt.Sprintf("Removing nodes would leave %d data nodes but %d are needed for %d replicas", dataNodes, replicas+1, replicas)
+			plan.failures = append(plan.failures, message)
+			for _, name := range draining {
+				hold(name, operatorv1.NodeSetBlocked, message)
+			}
+		case es.Status.Phase != esalpha1.ElasticsearchOperationalPhase:
+			for _, name := range draining {
+				hold(name, operatorv1.NodeSetDraining, "Waiting for the Elasticsearch cluster to be operational")
+			}
+		default:
+			esClient, err := r.elasticsearchClient(ctx, ls)
+			if err != nil {
+				return nil, err
+			}
+
+			var excluded []string
+			for _, name := range draining {
+				excluded = append(excluded, departing[name]...)
+			}
+			sort.Strings(excluded)
+			if err := esClient.ExcludeNodes(ctx, excluded); err != nil {
+				return nil, err
+			}
+
+			shards, err := esClient.NodeShards(ctx)
+			if err != nil {
+				return nil, err
+			}
+			for _, name := range draining {
+				nodes, remaining := departing[name], 0
+				for _, node := range nodes {
+					remaining += shards[node]
+				}
+				if remaining > 0 {
+					message := fmt.Sprintf("Moving %d shards off nodes %s", remaining, strings.Join(nodes, ", "))
+					hold(name, operatorv1.NodeSetDraining, message)
+					plan.progress = append(plan.progress, message)
+				}
+			}
+		}
+	}
+
+	// New node sets get volumes of the right size from their volume claim template.
+	for _, nodeSet := range desired {
+		if _, ok := current[nodeSet.Name]; !ok {
+			continue
+		}
+		phase, message, err := r.expandVolumes(ctx, nodeSet.Name, render.ElasticsearchStorageSize(nodeSet))
+		if err != nil {
+			return nil, err
+		}
+		switch phase {
+		case operatorv1.NodeSetBlocked:
+			plan.failures = append(plan.failures, message)
+		case operatorv1.NodeSetExpandingVolumes:
+			plan.progress = append(plan.progress, message)
+		default:
+			continue
+		}
+		// Draining or blocked removal of nodes is reported before the expansion of their volumes.
+		if statuses[nodeSet.Name].Phase == operatorv1.NodeSetReady {
+			statuses[nodeSet.Name].Phase = phase
+			statuses[nodeSet.Name].Message = message
+		}
+	}
+
+	sort.Strings(names)
+	for _, name := range names {
+		plan.nodeSets = append(plan.nodeSets, *statuses[name])
+	}
+	return plan, nil
+}
+
+// expandVolumes increases the requested storage of the data volumes of the nodes in the node set to size. It returns
+// NodeSetBlocked if the volumes can't be resized and NodeSetExpandingVolumes while they are being expanded, with a
+// message describing why.
+func (r *ReconcileLogStorage) expandVolumes(ctx context.Context, nodeSetName string, size resource.Quantity) (operatorv1.NodeSetPhase, string, error) {
+	claims := &corev1.PersistentVolumeClaimList{}
+	if err := r.client.List(ctx, claims, client.InNamespace(render.ElasticsearchNamespace)); err != nil {
+		return "", "", err
+	}
+
+	prefix := fmt.Sprintf("%s-%s-", elasticsearchDataVolume, render.ElasticsearchStatefulSetName(nodeSetName))
+	var expanding []string
+	for i := range claims.Items {
+		claim := &claims.Items[i]
+		// The names of the claims of a node set whose name starts with this one's only differ after the prefix.
+		if !strings.HasPrefix(claim.Name, prefix) {
+			continue
+		}
+		if _, err := strconv.Atoi(strings.TrimPrefix(claim.Name, prefix)); err != nil {
+			continue
+		}
+
+		requested := claim.Spec.Resources.Requests[corev1.ResourceStorage]
+		switch requested.Cmp(size) {
+		case 1:
+			return operatorv1.NodeSetBlocked, fmt.Sprintf("The volume %s can't be shrunk from %s to %s", claim.Name, requested.String(), size.String()), nil
+		case -1:
+			if claim.Spec.StorageClassName == nil {
+				return operatorv1.NodeSetBlocked, fmt.Sprintf("The volume %s has no storage class and can't be expanded", claim.Name), nil
+			}
+			storageClass := &storagev1.StorageClass{}
+			if err := r.client.Get(ctx, client.ObjectKey{Name: *claim.Spec.StorageClassName}, storageClass); err != nil {
+				return "", "", err
+			}
+			if storageClass.AllowVolumeExpansion == nil || !*storageClass.AllowVolumeExpansion {
+				return operatorv1.NodeSetBlocked, fmt.Sprintf("The storage class %s of volume %s does not allow volume expansion", storageClass.Name, claim.Name), nil
+			}
+
+			claim.Spec.Resources.Requests[corev1.ResourceStorage] = size
+			if err := r.client.Update(ctx, claim); err != nil {
+				return "", "", err
+			}
+			requested = size
+		}
+
+		// The capacity of a claim is only known once it is bound.
+		capacity, ok := claim.Status.Capacity[corev1.ResourceStorage]
+		if claim.Status.Phase == corev1.ClaimBound && ok && capacity.Cmp(requested) < 0 {
+			expanding = append(expanding, claim.Name)
+		}
+	}
+
+	if len(expanding) > 0 {
+		return operatorv1.NodeSetExpandingVolumes, fmt.Sprintf("Expanding volumes %s to %s", strings.Join(expanding, ", "), size.String()), nil
+	}
+	return "", "", nil
+}
+
+// clearNodeExclusion stops excluding nodes from shard allocation once none of the excluded nodes are in the cluster
+// any more, so that new nodes with the names of removed ones are used.
+func clearNodeExclusion(ctx context.Context, esClient *elasticsearch.Client) error {
+	excluded, err := esClient.ExcludedNodes(ctx)
+	if err != nil || len(excluded) == 0 {
+		return err
+	}
+
+	nodes, err := esClient.NodeNames(ctx)
+	if err != nil {
+		return err
+	}
+	for _, name := range excluded {
+		for _, node := range nodes {
+			if name == node {
+				return nil
+			}
+		}
+	}
+	return esClient.ExcludeNodes(ctx, nil)
+}
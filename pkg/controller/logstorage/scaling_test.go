@@ -0,0 +1,175 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	cmneckalpha1 "github.com/elastic/cloud-on-k8s/operators/pkg/apis/common/v1alpha1"
+	esalpha1 "github.com/elastic/cloud-on-k8s/operators/pkg/apis/elasticsearch/v1alpha1"
+	operatorv1 "github.com/tigera/operator/pkg/apis/operator/v1"
+	"github.com/tigera/operator/pkg/render"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Elasticsearch scaling tests", func() {
+	var cli client.Client
+	var r *ReconcileLogStorage
+	var ls *operatorv1.LogStorage
+	var es *esalpha1.Elasticsearch
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).ShouldNot(HaveOccurred())
+		Expect(storagev1.AddToScheme(scheme)).ShouldNot(HaveOccurred())
+		cli = fake.NewFakeClientWithScheme(scheme)
+		r = &ReconcileLogStorage{client: cli}
+
+		ls = &operatorv1.LogStorage{
+			Spec: operatorv1.LogStorageSpec{
+				Nodes: &operatorv1.Nodes{
+					NodeSets: []operatorv1.NodeSet{
+						{Name: "masters", Count: 3, Roles: []operatorv1.ElasticsearchNodeRole{operatorv1.ElasticsearchNodeRoleMaster}},
+						{Name: "hot", Count: 2, Roles: []operatorv1.ElasticsearchNodeRole{operatorv1.ElasticsearchNodeRoleData}},
+					},
+				},
+			},
+		}
+		fillDefaults(ls)
+
+		es = &esalpha1.Elasticsearch{
+			Spec: esalpha1.ElasticsearchSpec{
+				Nodes: []esalpha1.NodeSpec{
+					{Name: "masters", NodeCount: 3, Config: &cmneckalpha1.Config{Data: map[string]interface{}{"node.data": "false"}}},
+					{Name: "hot", NodeCount: 4, Config: &cmneckalpha1.Config{Data: map[string]interface{}{"node.data": "true"}}},
+				},
+			},
+		}
+	})
+
+	It("should return the nodes removed from each node set", func() {
+		es.Spec.Nodes = append(es.Spec.Nodes, esalpha1.NodeSpec{Name: "warm", NodeCount: 1})
+
+		Expect(departingNodes(render.ElasticsearchNodeSets(ls), es)).To(Equal(map[string][]string{
+			"hot":  {"tigera-secure-es-hot-2", "tigera-secure-es-hot-3"},
+			"warm": {"tigera-secure-es-warm-0"},
+		}))
+	})
+
+	It("should use the highest number of replicas of any index", func() {
+		config := render.NewElasticsearchClusterConfig("cluster", 1, 5, "")
+		Expect(maxReplicas(config)).To(Equal(1))
+
+		config.SetIndexSettings(render.AuditIndex, 2, 5)
+		Expect(maxReplicas(config)).To(Equal(2))
+	})
+
+	It("should refuse to remove data nodes needed for the replicas", func() {
+		plan, err := r.reconcileScaling(context.Background(), ls, es, render.NewElasticsearchClusterConfig("cluster", 2, 5, ""))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(plan.heldNodeCounts).To(Equal(map[string]int32{"hot": 4}))
+		Expect(plan.failures).To(HaveLen(1))
+		Expect(plan.nodeSets).To(ConsistOf(
+			operatorv1.NodeSetStatus{Name: "hot", Count: 4, DesiredCount: 2, Phase: operatorv1.NodeSetBlocked, Message: plan.failures[0]},
+			operatorv1.NodeSetStatus{Name: "masters", Count: 3, DesiredCount: 3, Phase: operatorv1.NodeSetReady},
+		))
+	})
+
+	It("should keep the nodes until the cluster is operational", func() {
+		plan, err := r.reconcileScaling(context.Background(), ls, es, render.NewElasticsearchClusterConfig("cluster", 1, 5, ""))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(plan.heldNodeCounts).To(Equal(map[string]int32{"hot": 4}))
+		Expect(plan.failures).To(BeEmpty())
+		Expect(plan.nodeSets[0].Phase).To(Equal(operatorv1.NodeSetDraining))
+	})
+
+	Context("Volume expansion", func() {
+		newClaim := func(name, size string) *corev1.PersistentVolumeClaim {
+			storageClass := render.ElasticsearchStorageClass
+			return &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: render.ElasticsearchNamespace},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					StorageClassName: &storageClass,
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)},
+					},
+				},
+				Status: corev1.PersistentVolumeClaimStatus{
+					Phase:    corev1.ClaimBound,
+					Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)},
+				},
+			}
+		}
+
+		BeforeEach(func() {
+			ctx := context.Background()
+			Expect(cli.Create(ctx, newClaim("elasticsearch-data-tigera-secure-es-hot-0", "10Gi"))).ShouldNot(HaveOccurred())
+			Expect(cli.Create(ctx, newClaim("elasticsearch-data-tigera-secure-es-hot-warm-0", "10Gi"))).ShouldNot(HaveOccurred())
+		})
+
+		It("should expand the volumes if the storage class allows it", func() {
+			ctx := context.Background()
+			allow := true
+			Expect(cli.Create(ctx, &storagev1.StorageClass{
+				ObjectMeta:           metav1.ObjectMeta{Name: render.ElasticsearchStorageClass},
+				AllowVolumeExpansion: &allow,
+			})).ShouldNot(HaveOccurred())
+
+			phase, message, err := r.expandVolumes(ctx, "hot", resource.MustParse("20Gi"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(phase).To(Equal(operatorv1.NodeSetExpandingVolumes))
+			Expect(message).To(ContainSubstring("elasticsearch-data-tigera-secure-es-hot-0"))
+
+			claim := &corev1.PersistentVolumeClaim{}
+			Expect(cli.Get(ctx, client.ObjectKey{Name: "elasticsearch-data-tigera-secure-es-hot-0", Namespace: render.ElasticsearchNamespace}, claim)).ShouldNot(HaveOccurred())
+			storage := claim.Spec.Resources.Requests[corev1.ResourceStorage]
+			Expect(storage.Cmp(resource.MustParse("20Gi"))).To(BeZero())
+
+			By("leaving the volumes of other node sets alone")
+			Expect(cli.Get(ctx, client.ObjectKey{Name: "elasticsearch-data-tigera-secure-es-hot-warm-0", Namespace: render.ElasticsearchNamespace}, claim)).ShouldNot(HaveOccurred())
+			storage = claim.Spec.Resources.Requests[corev1.ResourceStorage]
+			Expect(storage.Cmp(resource.MustParse("10Gi"))).To(BeZero())
+		})
+
+		It("should refuse to expand the volumes if the storage class does not allow it", func() {
+			ctx := context.Background()
+			Expect(cli.Create(ctx, &storagev1.StorageClass{
+				ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchStorageClass},
+			})).ShouldNot(HaveOccurred())
+
+			phase, _, err := r.expandVolumes(ctx, "hot", resource.MustParse("20Gi"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(phase).To(Equal(operatorv1.NodeSetBlocked))
+		})
+
+		It("should refuse to shrink the volumes", func() {
+			phase, _, err := r.expandVolumes(context.Background(), "hot", resource.MustParse("5Gi"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(phase).To(Equal(operatorv1.NodeSetBlocked))
+		})
+	})
+})
@@ -32,3 +32,11 @@ func NewReconcilerWithShims(
 
 	return newReconciler(cli, schema, status, resolvConfPath, provider)
 }
+
+// SetElasticsearchEndpoint overrides the endpoint the reconciler uses to reach the internal Elasticsearch cluster.
+func (r *ReconcileLogStorage) SetElasticsearchEndpoint(endpoint string) {
+	r.esEndpoint = endpoint
+}
+
+// HealthPollInterval is how often the reconciler polls the health of the Elasticsearch cluster.
+const HealthPollInterval = healthPollInterval
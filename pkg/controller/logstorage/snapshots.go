@@ -0,0 +1,564 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	operatorv1 "github.com/tigera/operator/pkg/apis/operator/v1"
+	"github.com/tigera/operator/pkg/elasticsearch"
+	"github.com/tigera/operator/pkg/render"
+)
+
+const (
+	// snapshotRepositoryName is the name of the snapshot repository registered in Elasticsearch, which is also the
+	// name of the snapshot lifecycle management policy that takes snapshots into it.
+	snapshotRepositoryName = "tigera-secure-snapshots"
+	// snapshotName is the name of the snapshots taken by the policy. Elasticsearch adds a unique suffix to each.
+	snapshotName = "<tigera-secure-snapshot-{now/d}>"
+	// scheduledSnapshotPrefix precedes the names of the scheduled snapshots, both those taken by the policy and those
+	// taken by the operator when Elasticsearch does not support snapshot lifecycle management.
+	scheduledSnapshotPrefix = "tigera-secure-snapshot-"
+
+	defaultSnapshotSchedule = "0 30 1 * * ?"
+	defaultSnapshotIndices  = "tigera_secure_ee_*"
+
+	// restorePollInterval is how often the progress of a restore is checked.
+	restorePollInterval = 10 * time.Second
+)
+
+func fillSnapshotDefaults(snapshots *operatorv1.Snapshots) {
+	if snapshots.Schedule == "" {
+		snapshots.Schedule = defaultSnapshotSchedule
+	}
+	if len(snapshots.Indices) == 0 {
+		snapshots.Indices = []string{defaultSnapshotIndices}
+	}
+	if snapshots.Archive != nil && len(snapshots.Archive.LogTypes) == 0 {
+		snapshots.Archive.LogTypes = defaultArchiveLogTypes
+	}
+}
+
+// validateSnapshots returns an error if the snapshot configuration of ls is not usable.
+func validateSnapshots(ls *operatorv1.LogStorage) error {
+	snapshots := ls.Spec.Snapshots
+	if snapshots == nil {
+		return nil
+	}
+
+	repo := snapshots.Repository
+	var repoTypes int
+	for _, set := range []bool{repo.S3 != nil, repo.GCS != nil, repo.Azure != nil, repo.FileSystem != nil} {
+		if set {
+			repoTypes++
+		}
+	}
+	if repoTypes != 1 {
+		return fmt.Errorf("exactly one of s3, gcs, azure or fileSystem must be set in the snapshot repository")
+	}
+
+	switch {
+	case repo.S3 != nil:
+		if repo.S3.Bucket == "" {
+			return fmt.Errorf("the bucket of the s3 snapshot repository must be set")
+		}
+		if repo.S3.Endpoint != "" {
+			u, err := url.Parse(repo.S3.Endpoint)
+			if err != nil {
+				return fmt.Errorf("invalid s3 snapshot repository endpoint %q: %v", repo.S3.Endpoint, err)
+			}
+			if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("s3 snapshot repository endpoint %q must be an http or https URL", repo.S3.Endpoint)
+			}
+		}
+	case repo.GCS != nil:
+		if repo.GCS.Bucket == "" {
+			return fmt.Errorf("the bucket of the gcs snapshot repository must be set")
+		}
+	case repo.Azure != nil:
+		if repo.Azure.Container == "" {
+			return fmt.Errorf("the container of the azure snapshot repository must be set")
+		}
+	case repo.FileSystem != nil:
+		if ls.Spec.External != nil {
+			return fmt.Errorf("a fileSystem snapshot repository cannot be used with an external Elasticsearch cluster")
+		}
+		if repo.FileSystem.PersistentVolumeClaimName == "" {
+			return fmt.Errorf("the persistentVolumeClaimName of the fileSystem snapshot repository must be set")
+		}
+	}
+
+	if repo.PluginURL != "" {
+		if repo.FileSystem != nil {
+			return fmt.Errorf("a pluginURL cannot be set for a fileSystem snapshot repository, it doesn't need a plugin")
+		}
+		u, err := url.Parse(repo.PluginURL)
+		if err != nil {
+			return fmt.Errorf("invalid snapshot repository pluginURL %q: %v", repo.PluginURL, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "file" {
+			return fmt.Errorf("snapshot repository pluginURL %q must be an http, https or file URL", repo.PluginURL)
+		}
+	}
+
+	if r := snapshots.Retention; r != nil && r.MinCount != nil && r.MaxCount != nil && *r.MinCount > *r.MaxCount {
+		return fmt.Errorf("snapshot retention minCount %d is greater than maxCount %d", *r.MinCount, *r.MaxCount)
+	}
+
+	if a := snapshots.Archive; a != nil && a.RetentionDays != nil && *a.RetentionDays < 1 {
+		return fmt.Errorf("archive retentionDays must be at least 1, got %d", *a.RetentionDays)
+	}
+
+	return nil
+}
+
+// snapshotSecureSettings returns the keystore settings of the Elasticsearch nodes holding the credentials of the
+// snapshot repository, if it has any.
+func (r *ReconcileLogStorage) snapshotSecureSettings(ctx context.Context, repo operatorv1.SnapshotRepository) (map[string][]byte, error) {
+	var secretName, prefix string
+	var keys []string
+	switch {
+	case repo.S3 != nil:
+		secretName, prefix = repo.S3.CredentialsSecretName, "s3"
+		keys = []string{"access_key", "secret_key"}
+	case repo.GCS != nil:
+		secretName, prefix = repo.GCS.CredentialsSecretName, "gcs"
+		keys = []string{"credentials_file"}
+	case repo.Azure != nil:
+		secretName, prefix = repo.Azure.CredentialsSecretName, "azure"
+		keys = []string{"account", "key"}
+	}
+	if secretName == "" {
+		return nil, nil
+	}
+
+	data := map[string][]byte{}
+	for _, key := range keys {
+		value, err := r.secretValue(ctx, secretName, render.OperatorNamespace(), key)
+		if err != nil {
+			return nil, err
+		}
+		data[fmt.Sprintf("%s.client.default.%s", prefix, key)] = value
+	}
+	return data, nil
+}
+
+// snapshotRepository returns the Elasticsearch snapshot repository for the repository of the LogStorage.
+func snapshotRepository(repo operatorv1.SnapshotRepository) elasticsearch.SnapshotRepository {
+	switch {
+	case repo.S3 != nil:
+		return elasticsearch.SnapshotRepository{Type: "s3", Settings: map[string]string{"bucket": repo.S3.Bucket, "base_path": repo.S3.BasePath}}
+	case repo.GCS != nil:
+		return elasticsearch.SnapshotRepository{Type: "gcs", Settings: map[string]string{"bucket": repo.GCS.Bucket, "base_path": repo.GCS.BasePath}}
+	case repo.Azure != nil:
+		return elasticsearch.SnapshotRepository{Type: "azure", Settings: map[string]string{"container": repo.Azure.Container, "base_path": repo.Azure.BasePath}}
+	}
+	return elasticsearch.SnapshotRepository{Type: "fs", Settings: map[string]string{"location": render.ElasticsearchSnapshotPath}}
+}
+
+// slmPolicy returns the snapshot lifecycle management policy that takes snapshots into the repository.
+func slmPolicy(snapshots *operatorv1.Snapshots) elasticsearch.SLMPolicy {
+	policy := elasticsearch.SLMPolicy{
+		Schedule:   snapshots.Schedule,
+		Name:       snapshotName,
+		Repository: snapshotRepositoryName,
+		Config: map[string]interface{}{
+			"indices":              snapshots.Indices,
+			"include_global_state": false,
+		},
+	}
+	if r := snapshots.Retention; r != nil {
+		policy.Retention = &elasticsearch.SLMRetention{ExpireAfter: r.ExpireAfter, MinCount: r.MinCount, MaxCount: r.MaxCount}
+	}
+	return policy
+}
+
+// versionAtLeast returns whether the Elasticsearch version is at least major.minor.
+func versionAtLeast(version string, major, minor int) bool {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return false
+	}
+	vMajor, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return false
+	}
+	vMinor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return false
+	}
+	return vMajor > major || (vMajor == major && vMinor >= minor)
+}
+
+// reconcileSnapshots registers the snapshot repository and creates or updates the snapshot lifecycle management
+// policy. Elasticsearch versions that do not support snapshot lifecycle management have their snapshots taken and
+// deleted by the operator instead. It returns a message describing why snapshots are not being taken, if the last
+// snapshot failed or the schedule can't be followed.
+func reconcileSnapshots(ctx context.Context, esClient *elasticsearch.Client, snapshots *operatorv1.Snapshots, now time.Time) (string, error) {
+	if err := esClient.PutSnapshotRepository(ctx, snapshotRepositoryName, snapshotRepository(snapshots.Repository)); err != nil {
+		return "", fmt.Errorf("failed to register snapshot repository: %v", err)
+	}
+
+	version, err := esClient.Version(ctx)
+	if err != nil {
+		return "", err
+	}
+	if !versionAtLeast(version, 7, 5) {
+		return takeScheduledSnapshots(ctx, esClient, snapshots, version, now)
+	}
+
+	if err := esClient.PutSLMPolicy(ctx, snapshotRepositoryName, slmPolicy(snapshots)); err != nil {
+		return "", fmt.Errorf("failed to create snapshot lifecycle policy: %v", err)
+	}
+
+	status, err := esClient.GetSLMPolicyStatus(ctx, snapshotRepositoryName)
+	if err != nil {
+		return "", err
+	}
+	if f := status.LastFailure; f != nil && (status.LastSuccess == nil || status.LastSuccess.Time < f.Time) {
+		return fmt.Sprintf("snapshot %s failed: %s", f.SnapshotName, f.Details), nil
+	}
+
+	return "", nil
+}
+
+// snapshotTimeOfDay returns the time of day of a schedule that takes one snapshot every day at a fixed time, such as
+// the default schedule. It returns false for any other schedule.
+func snapshotTimeOfDay(schedule string) (time.Duration, bool) {
+	fields := strings.Fields(schedule)
+	if len(fields) != 6 && (len(fields) != 7 || fields[6] != "*") {
+		return 0, false
+	}
+	for _, field := range fields[3:6] {
+		if field != "*" && field != "?" {
+			return 0, false
+		}
+	}
+
+	var timeOfDay time.Duration
+	for i, unit := range []struct {
+		duration time.Duration
+		max      int
+	}{{time.Second, 59}, {time.Minute, 59}, {time.Hour, 23}} {
+		n, err := strconv.Atoi(fields[i])
+		if err != nil || n < 0 || n > unit.max {
+			return 0, false
+		}
+		timeOfDay += time.Duration(n) * unit.duration
+	}
+	return timeOfDay, true
+}
+
+// parseTimeValue parses an Elasticsearch time value in days, hours, minutes or seconds, such as 30d.
+func parseTimeValue(value string) (time.Duration, error) {
+	for _, unit := range []struct {
+		suffix   string
+		duration time.Duration
+	}{{"d", 24 * time.Hour}, {"h", time.Hour}, {"m", time.Minute}, {"s", time.Second}} {
+		if !strings.HasSuffix(value, unit.suffix) {
+			continue
+		}
+		if n, err := strconv.Atoi(strings.TrimSuffix(value, unit.suffix)); err == nil && n >= 0 {
+			return time.Duration(n) * unit.duration, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid time value %q, expected a number of days, hours, minutes or seconds such as 30d", value)
+}
+
+// takeScheduledSnapshots takes the snapshots of an Elasticsearch cluster that does not support snapshot lifecycle
+// management. Only daily schedules are supported: a snapshot named after the day is taken once the time of day of the
+// schedule has passed, in UTC, unless it has already been taken. Successful snapshots are then deleted according to
+// the retention, never the snapshot of the current day. Nothing is taken or deleted while a snapshot is in progress.
+func takeScheduledSnapshots(ctx context.Context, esClient *elasticsearch.Client, snapshots *operatorv1.Snapshots, version string, now time.Time) (string, error) {
+	timeOfDay, ok := snapshotTimeOfDay(snapshots.Schedule)
+	if !ok {
+		return fmt.Sprintf("snapshot schedule %q is not supported on Elasticsearch %s, which requires a schedule that runs once a day such as %q",
+			snapshots.Schedule, version, defaultSnapshotSchedule), nil
+	}
+	var expireAfter time.Duration
+	if r := snapshots.Retention; r != nil && r.ExpireAfter != "" {
+		var err error
+		if expireAfter, err = parseTimeValue(r.ExpireAfter); err != nil {
+			return fmt.Sprintf("snapshot retention expireAfter is not supported on Elasticsearch %s: %v", version, err), nil
+		}
+	}
+
+	now = now.UTC()
+	scheduled := now.Truncate(24 * time.Hour).Add(timeOfDay)
+	if scheduled.After(now) {
+		scheduled = scheduled.Add(-24 * time.Hour)
+	}
+	name := scheduledSnapshotPrefix + scheduled.Format("2006.01.02")
+
+	existing, err := esClient.Snapshots(ctx, snapshotRepositoryName, scheduledSnapshotPrefix+"*")
+	if err != nil {
+		return "", err
+	}
+	// Newest first.
+	sort.Slice(existing, func(i, j int) bool { return existing[i].StartTimeMillis > existing[j].StartTimeMillis })
+
+	// The last snapshot that completed is reported until a later snapshot succeeds.
+	var failure string
+	for _, s := range existing {
+		if s.State != elasticsearch.SnapshotInProgress {
+			if s.State != elasticsearch.SnapshotSuccess {
+				failure = fmt.Sprintf("snapshot %s failed: %s", s.Snapshot, s.Reason)
+			}
+			break
+		}
+	}
+	var taken bool
+	for _, s := range existing {
+		if s.State == elasticsearch.SnapshotInProgress {
+			return failure, nil
+		}
+		taken = taken || s.Snapshot == name
+	}
+
+	if !taken {
+		err := esClient.CreateSnapshot(ctx, snapshotRepositoryName, name, elasticsearch.Snapshot{
+			Indices: strings.Join(snapshots.Indices, ","),
+		})
+		if err != nil && !elasticsearch.IsConcurrentSnapshot(err) {
+			return "", fmt.Errorf("failed to take snapshot %s: %v", name, err)
+		}
+		// If an index is being archived, the snapshot is taken once the archive snapshot has completed.
+		return failure, nil
+	}
+
+	r := snapshots.Retention
+	if r == nil {
+		return failure, nil
+	}
+	var kept int32
+	for _, s := range existing {
+		if s.State != elasticsearch.SnapshotSuccess {
+			continue
+		}
+		kept++
+		if s.Snapshot == name || (r.MinCount != nil && kept <= *r.MinCount) {
+			continue
+		}
+		started := time.Unix(0, s.StartTimeMillis*int64(time.Millisecond))
+		if (r.MaxCount != nil && kept > *r.MaxCount) || (expireAfter != 0 && now.Sub(started) > expireAfter) {
+			err := esClient.DeleteSnapshot(ctx, snapshotRepositoryName, s.Snapshot)
+			if elasticsearch.IsConcurrentSnapshot(err) {
+				break
+			} else if err != nil {
+				return "", fmt.Errorf("failed to delete snapshot %s: %v", s.Snapshot, err)
+			}
+			kept--
+		}
+	}
+
+	return failure, nil
+}
+
+// reconcileRestores starts the restore of each new LogStorageRestore and updates the status of the restores in
+// progress. The snapshots of an archive restore are restored one after the other. It returns messages describing the
+// restores in progress and the failed restores.
+func (r *ReconcileLogStorage) reconcileRestores(ctx context.Context, esClient *elasticsearch.Client, ls *operatorv1.LogStorage, clusterName string) ([]string, []string, error) {
+	restores := &operatorv1.LogStorageRestoreList{}
+	if err := r.client.List(ctx, restores); err != nil {
+		return nil, nil, err
+	}
+
+	var inProgress, failed []string
+	for i := range restores.Items {
+		restore := &restores.Items[i]
+		status := restore.Status
+
+		switch status.State {
+		case "":
+			status = startRestore(ctx, esClient, ls, clusterName, restore)
+		case operatorv1.LogStorageRestoreStateInProgress:
+			indices, err := restoringIndices(ctx, esClient, restore.Spec, status)
+			if err != nil {
+				return nil, nil, err
+			}
+			switch {
+			case len(indices) != 0:
+				status.Message = fmt.Sprintf("Restoring %d indices: %s", len(indices), strings.Join(indices, ", "))
+			case len(status.PendingSnapshots) > 1:
+				status = restoreArchivedSnapshots(ctx, esClient, status.PendingSnapshots[1:])
+			default:
+				status = operatorv1.LogStorageRestoreStatus{State: operatorv1.LogStorageRestoreStateCompleted}
+			}
+		}
+
+		if !reflect.DeepEqual(status, restore.Status) {
+			restore.Status = status
+			if err := r.client.Status().Update(ctx, restore); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		switch status.State {
+		case operatorv1.LogStorageRestoreStateInProgress:
+			inProgress = append(inProgress, fmt.Sprintf("restore %s from snapshot %s", restore.Name, restoringSnapshot(restore.Spec, status)))
+		case operatorv1.LogStorageRestoreStateFailed:
+			failed = append(failed, fmt.Sprintf("restore %s failed: %s", restore.Name, status.Message))
+		}
+	}
+
+	return inProgress, failed, nil
+}
+
+// restoringSnapshot returns the snapshot being restored.
+func restoringSnapshot(spec operatorv1.LogStorageRestoreSpec, status operatorv1.LogStorageRestoreStatus) string {
+	if len(status.PendingSnapshots) != 0 {
+		return status.PendingSnapshots[0]
+	}
+	return spec.Snapshot
+}
+
+// restoringIndices returns the sorted names of the indices of the snapshot being restored that have not been restored
+// yet. An index has been restored once it exists and all of its primary shards have been allocated, that is once its
+// health is no longer red. The snapshot of an archive restore is restored whole and without renaming its indices.
+func restoringIndices(ctx context.Context, esClient *elasticsearch.Client, spec operatorv1.LogStorageRestoreSpec, status operatorv1.LogStorageRestoreStatus) ([]string, error) {
+	snapshot := restoringSnapshot(spec, status)
+	snapshots, err := esClient.Snapshots(ctx, snapshotRepositoryName, snapshot)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) != 1 {
+		return nil, fmt.Errorf("snapshot %s not found in repository %s", snapshot, snapshotRepositoryName)
+	}
+
+	var patterns []string
+	var rename *regexp.Regexp
+	if spec.Archive == nil {
+		patterns = spec.Indices
+		if spec.RenamePattern != "" {
+			if rename, err = regexp.Compile(spec.RenamePattern); err != nil {
+				return nil, fmt.Errorf("invalid renamePattern %q: %v", spec.RenamePattern, err)
+			}
+		}
+	}
+
+	health, err := esClient.IndicesHealth(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var indices []string
+	for _, index := range snapshots[0].Indices {
+		if !matchesIndexPatterns(index, patterns) {
+			continue
+		}
+		if rename != nil {
+			index = rename.ReplaceAllString(index, spec.RenameReplacement)
+		}
+		if h, ok := health[index]; !ok || operatorv1.ElasticsearchHealthStatus(h) == operatorv1.ElasticsearchHealthRed {
+			indices = append(indices, index)
+		}
+	}
+	sort.Strings(indices)
+	return indices, nil
+}
+
+// matchesIndexPatterns returns whether the index is selected by the names or wildcard patterns of a restore, where a
+// pattern starting with - excludes the indices it matches. All indices are selected if there are no patterns.
+func matchesIndexPatterns(index string, patterns []string) bool {
+	var included, includes bool
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, "-") {
+			if ok, _ := path.Match(pattern[1:], index); ok {
+				return false
+			}
+			continue
+		}
+		includes = true
+		if ok, _ := path.Match(pattern, index); ok {
+			included = true
+		}
+	}
+	return included || !includes
+}
+
+// startRestore starts restoring the indices of the restore and returns its new status.
+func startRestore(ctx context.Context, esClient *elasticsearch.Client, ls *operatorv1.LogStorage, clusterName string, restore *operatorv1.LogStorageRestore) operatorv1.LogStorageRestoreStatus {
+	if ls.Spec.Snapshots == nil {
+		return operatorv1.LogStorageRestoreStatus{
+			State:   operatorv1.LogStorageRestoreStateFailed,
+			Message: "no snapshot repository is configured on the LogStorage",
+		}
+	}
+	if (restore.Spec.Snapshot == "") == (restore.Spec.Archive == nil) {
+		return operatorv1.LogStorageRestoreStatus{
+			State:   operatorv1.LogStorageRestoreStateFailed,
+			Message: "exactly one of snapshot or archive must be set",
+		}
+	}
+
+	if restore.Spec.RenamePattern != "" {
+		if _, err := regexp.Compile(restore.Spec.RenamePattern); err != nil {
+			return operatorv1.LogStorageRestoreStatus{
+				State:   operatorv1.LogStorageRestoreStateFailed,
+				Message: fmt.Sprintf("invalid renamePattern %q: %v", restore.Spec.RenamePattern, err),
+			}
+		}
+	}
+
+	if archive := restore.Spec.Archive; archive != nil {
+		snapshots, err := archivedSnapshots(ctx, esClient, ls, clusterName, archive)
+		if err != nil {
+			return operatorv1.LogStorageRestoreStatus{State: operatorv1.LogStorageRestoreStateFailed, Message: err.Error()}
+		}
+		if len(snapshots) == 0 {
+			return operatorv1.LogStorageRestoreStatus{
+				State: operatorv1.LogStorageRestoreStateFailed,
+				Message: fmt.Sprintf("no archived %s logs between %s and %s", archive.LogType,
+					archive.From.Format(time.RFC3339), archive.To.Format(time.RFC3339)),
+			}
+		}
+		return restoreArchivedSnapshots(ctx, esClient, snapshots)
+	}
+
+	err := esClient.RestoreSnapshot(ctx, snapshotRepositoryName, restore.Spec.Snapshot, elasticsearch.SnapshotRestore{
+		Indices:           strings.Join(restore.Spec.Indices, ","),
+		RenamePattern:     restore.Spec.RenamePattern,
+		RenameReplacement: restore.Spec.RenameReplacement,
+	})
+	if err != nil {
+		return operatorv1.LogStorageRestoreStatus{State: operatorv1.LogStorageRestoreStateFailed, Message: err.Error()}
+	}
+	return operatorv1.LogStorageRestoreStatus{State: operatorv1.LogStorageRestoreStateInProgress, Message: "Restore started"}
+}
+
+// restoreArchivedSnapshots starts restoring the first of the archived snapshots and returns the new status of the
+// restore.
+func restoreArchivedSnapshots(ctx context.Context, esClient *elasticsearch.Client, snapshots []string) operatorv1.LogStorageRestoreStatus {
+	err := esClient.RestoreSnapshot(ctx, snapshotRepositoryName, snapshots[0], elasticsearch.SnapshotRestore{
+		IgnoreIndexSettings: restoredIndexSettings,
+	})
+	if err != nil {
+		return operatorv1.LogStorageRestoreStatus{State: operatorv1.LogStorageRestoreStateFailed, Message: err.Error()}
+	}
+	return operatorv1.LogStorageRestoreStatus{
+		State:            operatorv1.LogStorageRestoreStateInProgress,
+		Message:          fmt.Sprintf("Restore of archived snapshot %s started, %d more to restore", snapshots[0], len(snapshots)-1),
+		PendingSnapshots: snapshots,
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	operatorv1 "github.com/tigera/operator/pkg/apis/operator/v1"
+	"github.com/tigera/operator/pkg/elasticsearch"
+)
+
+var _ = Describe("Snapshot tests", func() {
+	var ls *operatorv1.LogStorage
+
+	BeforeEach(func() {
+		ls = &operatorv1.LogStorage{
+			Spec: operatorv1.LogStorageSpec{
+				Snapshots: &operatorv1.Snapshots{
+					Repository: operatorv1.SnapshotRepository{
+						S3: &operatorv1.S3SnapshotRepository{Bucket: "snapshots", Endpoint: "http://minio.minio.svc:9000"},
+					},
+				},
+			},
+		}
+		fillDefaults(ls)
+	})
+
+	It("should default the schedule and indices", func() {
+		Expect(ls.Spec.Snapshots.Schedule).To(Equal(defaultSnapshotSchedule))
+		Expect(ls.Spec.Snapshots.Indices).To(Equal([]string{"tigera_secure_ee_*"}))
+		Expect(validateSnapshots(ls)).NotTo(HaveOccurred())
+	})
+
+	It("should require exactly one repository type", func() {
+		ls.Spec.Snapshots.Repository.GCS = &operatorv1.GCSSnapshotRepository{Bucket: "snapshots"}
+		Expect(validateSnapshots(ls)).To(HaveOccurred())
+
+		ls.Spec.Snapshots.Repository = operatorv1.SnapshotRepository{}
+		Expect(validateSnapshots(ls)).To(HaveOccurred())
+	})
+
+	It("should reject an S3 endpoint that is not an http or https URL", func() {
+		ls.Spec.Snapshots.Repository.S3.Endpoint = "minio.minio.svc:9000"
+		Expect(validateSnapshots(ls)).To(HaveOccurred())
+	})
+
+	It("should only accept an http, https or file plugin URL", func() {
+		ls.Spec.Snapshots.Repository.PluginURL = "file:///plugins/repository-s3-7.3.2.zip"
+		Expect(validateSnapshots(ls)).NotTo(HaveOccurred())
+
+		ls.Spec.Snapshots.Repository.PluginURL = "repository-s3"
+		Expect(validateSnapshots(ls)).To(HaveOccurred())
+	})
+
+	It("should reject a file system repository for an external cluster", func() {
+		ls.Spec.Snapshots.Repository = operatorv1.SnapshotRepository{
+			FileSystem: &operatorv1.FileSystemSnapshotRepository{PersistentVolumeClaimName: "snapshots"},
+		}
+		Expect(validateSnapshots(ls)).NotTo(HaveOccurred())
+
+		ls.Spec.External = &operatorv1.ExternalElasticsearch{}
+		Expect(validateSnapshots(ls)).To(HaveOccurred())
+	})
+
+	It("should compare Elasticsearch versions", func() {
+		Expect(versionAtLeast("7.5.0", 7, 5)).To(BeTrue())
+		Expect(versionAtLeast("7.10.2", 7, 5)).To(BeTrue())
+		Expect(versionAtLeast("8.0.0", 7, 5)).To(BeTrue())
+		Expect(versionAtLeast("7.3.2", 7, 5)).To(BeFalse())
+		Expect(versionAtLeast("", 7, 5)).To(BeFalse())
+	})
+
+	It("should only support daily schedules at a fixed time without snapshot lifecycle management", func() {
+		timeOfDay, ok := snapshotTimeOfDay(defaultSnapshotSchedule)
+		Expect(ok).To(BeTrue())
+		Expect(timeOfDay).To(Equal(time.Hour + 30*time.Minute))
+
+		_, ok = snapshotTimeOfDay("0 0/30 * * * ?")
+		Expect(ok).To(BeFalse())
+		_, ok = snapshotTimeOfDay("0 30 1 ? * MON")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should parse snapshot retention time values", func() {
+		Expect(parseTimeValue("30d")).To(Equal(30 * 24 * time.Hour))
+		Expect(parseTimeValue("12h")).To(Equal(12 * time.Hour))
+		_, err := parseTimeValue("10ms")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should select the indices of a restore", func() {
+		Expect(matchesIndexPatterns("tigera_secure_ee_flows.cluster.1", nil)).To(BeTrue())
+		Expect(matchesIndexPatterns("tigera_secure_ee_flows.cluster.1", []string{"tigera_secure_ee_flows*"})).To(BeTrue())
+		Expect(matchesIndexPatterns("tigera_secure_ee_dns.cluster.1", []string{"tigera_secure_ee_flows*"})).To(BeFalse())
+		Expect(matchesIndexPatterns("tigera_secure_ee_flows.cluster.1", []string{"tigera_secure_ee_*", "-tigera_secure_ee_flows*"})).To(BeFalse())
+	})
+
+	Context("Reconciling", func() {
+		var server *httptest.Server
+		var requests []string
+		var version, policyStatus, snapshots string
+		now := time.Date(2020, 5, 2, 12, 0, 0, 0, time.UTC)
+
+		BeforeEach(func() {
+			requests = nil
+			version = "7.5.2"
+			policyStatus = `{}`
+			snapshots = `{"snapshots": []}`
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.Method+" "+r.URL.Path)
+				switch {
+				case r.URL.Path == "/":
+					_, _ = w.Write([]byte(`{"version": {"number": "` + version + `"}}`))
+				case r.Method == http.MethodGet && r.URL.Path == "/_slm/policy/"+snapshotRepositoryName:
+					_, _ = w.Write([]byte(policyStatus))
+				case r.Method == http.MethodGet && r.URL.Path == "/_snapshot/"+snapshotRepositoryName+"/"+scheduledSnapshotPrefix+"*":
+					_, _ = w.Write([]byte(snapshots))
+				default:
+					_, _ = w.Write([]byte(`{}`))
+				}
+			}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("registers the repository and creates the snapshot lifecycle policy", func() {
+			esClient, err := elasticsearch.NewClient(server.URL, "elastic", "elasticpw", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			failure, err := reconcileSnapshots(context.Background(), esClient, ls.Spec.Snapshots, now)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(failure).To(BeEmpty())
+			Expect(requests).To(ContainElement("PUT /_snapshot/tigera-secure-snapshots"))
+			Expect(requests).To(ContainElement("PUT /_slm/policy/tigera-secure-snapshots"))
+		})
+
+		It("takes the scheduled snapshot if Elasticsearch does not support snapshot lifecycle management", func() {
+			version = "7.3.2"
+			esClient, err := elasticsearch.NewClient(server.URL, "elastic", "elasticpw", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			failure, err := reconcileSnapshots(context.Background(), esClient, ls.Spec.Snapshots, now)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(failure).To(BeEmpty())
+			Expect(requests).To(ContainElement("PUT /_snapshot/tigera-secure-snapshots"))
+			Expect(requests).To(ContainElement("PUT /_snapshot/tigera-secure-snapshots/tigera-secure-snapshot-2020.05.02"))
+			Expect(requests).NotTo(ContainElement("PUT /_slm/policy/tigera-secure-snapshots"))
+		})
+
+		It("deletes the expired snapshots if Elasticsearch does not support snapshot lifecycle management", func() {
+			version = "7.3.2"
+			ls.Spec.Snapshots.Retention = &operatorv1.SnapshotRetention{ExpireAfter: "7d"}
+			snapshots = `{"snapshots": [
+				{"snapshot": "tigera-secure-snapshot-2020.04.01", "state": "SUCCESS", "start_time_in_millis": 1585704600000},
+				{"snapshot": "tigera-secure-snapshot-2020.05.01", "state": "SUCCESS", "start_time_in_millis": 1588296600000},
+				{"snapshot": "tigera-secure-snapshot-2020.05.02", "state": "SUCCESS", "start_time_in_millis": 1588383000000}
+			]}`
+			esClient, err := elasticsearch.NewClient(server.URL, "elastic", "elasticpw", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			failure, err := reconcileSnapshots(context.Background(), esClient, ls.Spec.Snapshots, now)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(failure).To(BeEmpty())
+			Expect(requests).To(ContainElement("DELETE /_snapshot/tigera-secure-snapshots/tigera-secure-snapshot-2020.04.01"))
+			Expect(requests).NotTo(ContainElement("DELETE /_snapshot/tigera-secure-snapshots/tigera-secure-snapshot-2020.05.01"))
+			Expect(requests).NotTo(ContainElement("PUT /_snapshot/tigera-secure-snapshots/tigera-secure-snapshot-2020.05.02"))
+		})
+
+		It("reports the failed snapshot if Elasticsearch does not support snapshot lifecycle management", func() {
+			version = "7.3.2"
+			snapshots = `{"snapshots": [
+				{"snapshot": "tigera-secure-snapshot-2020.05.02", "state": "FAILED", "reason": "access denied", "start_time_in_millis": 1588383000000}
+			]}`
+			esClient, err := elasticsearch.NewClient(server.URL, "elastic", "elasticpw", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			failure, err := reconcileSnapshots(context.Background(), esClient, ls.Spec.Snapshots, now)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(failure).To(Equal("snapshot tigera-secure-snapshot-2020.05.02 failed: access denied"))
+		})
+
+		It("reports a schedule that can't be followed without snapshot lifecycle management", func() {
+			version = "7.3.2"
+			ls.Spec.Snapshots.Schedule = "0 0/30 * * * ?"
+			esClient, err := elasticsearch.NewClient(server.URL, "elastic", "elasticpw", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			failure, err := reconcileSnapshots(context.Background(), esClient, ls.Spec.Snapshots, now)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(failure).To(ContainSubstring("7.3.2"))
+			Expect(requests).NotTo(ContainElement("PUT /_snapshot/tigera-secure-snapshots/tigera-secure-snapshot-2020.05.02"))
+		})
+
+		It("reports a snapshot that failed after the last successful snapshot", func() {
+			policyStatus = `{"tigera-secure-snapshots": {
+				"last_success": {"snapshot_name": "tigera-secure-snapshot-2020.05.01-a", "time": 1588296600000},
+				"last_failure": {"snapshot_name": "tigera-secure-snapshot-2020.05.02-b", "time": 1588383000000, "details": "access denied"}
+			}}`
+			esClient, err := elasticsearch.NewClient(server.URL, "elastic", "elasticpw", nil)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			failure, err := reconcileSnapshots(context.Background(), esClient, ls.Spec.Snapshots, now)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(failure).To(Equal("snapshot tigera-secure-snapshot-2020.05.02-b failed: access denied"))
+		})
+	})
+})
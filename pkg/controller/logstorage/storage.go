@@ -0,0 +1,108 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/tigera/operator/pkg/elasticsearch"
+)
+
+const (
+	// As soon as the disk used by the Elasticsearch cluster exceeds maxTotalStoragePercent of its size, indices are
+	// removed starting with the oldest, whatever their retention period. These are the limits that the curator
+	// enforced before index lifecycle management policies replaced it.
+	maxTotalStoragePercent int64 = 80
+
+	// Flow and DNS log indices are removed, starting with the oldest, once together they use more than
+	// maxLogsStoragePercent of the size of the cluster. They often use the most disk space, so this allows the
+	// compliance and security indices to be retained longer.
+	maxLogsStoragePercent int64 = 70
+
+	// storageIndexPattern matches the indices removed to stay under the storage limits.
+	storageIndexPattern = "tigera_secure_ee_*"
+)
+
+// storageLogIndexPrefixes are the prefixes of the indices limited to maxLogsStoragePercent.
+var storageLogIndexPrefixes = []string{"tigera_secure_ee_flows.", "tigera_secure_ee_dns."}
+
+// enforceStorageLimits deletes the oldest indices while the flow and DNS logs use more than maxLogsStoragePercent of
+// the disk space of the data nodes or the cluster uses more than maxTotalStoragePercent. The indices that aliases
+// write to are never deleted. It returns the deleted indices. The retention periods of the index lifecycle management
+// policies usually keep the indices well under the limits, this guards against running out of disk space before they
+// have passed.
+func enforceStorageLimits(ctx context.Context, esClient *elasticsearch.Client) ([]string, error) {
+	disks, err := esClient.NodeDiskUsage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var used, total int64
+	for _, disk := range disks {
+		used += disk.Used
+		total += disk.Total
+	}
+	if total == 0 {
+		return nil, nil
+	}
+
+	indices, err := esClient.IndexSizes(ctx, storageIndexPattern)
+	if err != nil {
+		return nil, err
+	}
+	writeIndices, err := esClient.WriteIndices(ctx, storageIndexPattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var logsUsed int64
+	for _, index := range indices {
+		if isLogIndex(index.Index) {
+			logsUsed += index.Size
+		}
+	}
+
+	var deleted []string
+	for _, index := range indices {
+		if writeIndices[index.Index] {
+			continue
+		}
+		logIndex := isLogIndex(index.Index)
+		if used*100 <= total*maxTotalStoragePercent && !(logIndex && logsUsed*100 > total*maxLogsStoragePercent) {
+			continue
+		}
+
+		if err := esClient.DeleteIndex(ctx, index.Index); err != nil {
+			return deleted, fmt.Errorf("failed to delete index %s: %v", index.Index, err)
+		}
+		deleted = append(deleted, index.Index)
+		used -= index.Size
+		if logIndex {
+			logsUsed -= index.Size
+		}
+	}
+	return deleted, nil
+}
+
+// isLogIndex returns whether the index holds flow or DNS logs.
+func isLogIndex(index string) bool {
+	for _, prefix := range storageLogIndexPrefixes {
+		if strings.HasPrefix(index, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/tigera/operator/pkg/elasticsearch"
+)
+
+var _ = Describe("Elasticsearch storage limit tests", func() {
+	var server *httptest.Server
+	var esClient *elasticsearch.Client
+	var responses map[string]string
+	var deletes []string
+
+	BeforeEach(func() {
+		responses = map[string]string{
+			"/_cat/indices/tigera_secure_ee_*": `[
+				{"index": "tigera_secure_ee_flows.cluster.20200301", "store.size": "10", "creation.date": "1583020800000"},
+				{"index": "tigera_secure_ee_audit_kube.cluster.20200301", "store.size": "10", "creation.date": "1583020800001"},
+				{"index": "tigera_secure_ee_dns.cluster.20200302", "store.size": "10", "creation.date": "1583107200000"},
+				{"index": "tigera_secure_ee_flows.cluster.20200302", "store.size": "10", "creation.date": "1583107200001"},
+				{"index": "tigera_secure_ee_flows.cluster.ilm-000001", "store.size": "10", "creation.date": "1583193600000"}
+			]`,
+			"/_cat/aliases/tigera_secure_ee_*": `[
+				{"alias": "tigera_secure_ee_flows.cluster.", "index": "tigera_secure_ee_flows.cluster.ilm-000001", "is_write_index": "true"}
+			]`,
+		}
+		deletes = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodDelete {
+				deletes = append(deletes, r.URL.Path)
+			}
+			if resp, ok := responses[r.URL.Path]; ok {
+				_, _ = w.Write([]byte(resp))
+				return
+			}
+			_, _ = w.Write([]byte(`{}`))
+		}))
+
+		var err error
+		esClient, err = elasticsearch.NewClient(server.URL, "elastic", "elasticpw", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	allocation := func(used int) string {
+		return `[{"node": "es-0", "disk.used": "` + strconv.Itoa(used) + `", "disk.avail": "` + strconv.Itoa(100-used) + `", "disk.total": "100", "disk.percent": "` + strconv.Itoa(used) + `"}]`
+	}
+
+	It("does not delete indices while the disk usage is under the limits", func() {
+		responses["/_cat/allocation"] = allocation(60)
+
+		deleted, err := enforceStorageLimits(context.Background(), esClient)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(deleted).To(BeEmpty())
+		Expect(deletes).To(BeEmpty())
+	})
+
+	It("deletes the oldest indices once the cluster uses more than the total limit", func() {
+		responses["/_cat/allocation"] = allocation(95)
+
+		deleted, err := enforceStorageLimits(context.Background(), esClient)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(deleted).To(Equal([]string{"tigera_secure_ee_flows.cluster.20200301", "tigera_secure_ee_audit_kube.cluster.20200301"}))
+		Expect(deletes).To(Equal([]string{"/tigera_secure_ee_flows.cluster.20200301", "/tigera_secure_ee_audit_kube.cluster.20200301"}))
+	})
+
+	It("only deletes flow and DNS log indices once they use more than the logs limit", func() {
+		responses["/_cat/allocation"] = allocation(79)
+		responses["/_cat/indices/tigera_secure_ee_*"] = `[
+			{"index": "tigera_secure_ee_audit_kube.cluster.20200301", "store.size": "4", "creation.date": "1583020800000"},
+			{"index": "tigera_secure_ee_flows.cluster.20200301", "store.size": "30", "creation.date": "1583020800001"},
+			{"index": "tigera_secure_ee_dns.cluster.20200302", "store.size": "30", "creation.date": "1583107200000"},
+			{"index": "tigera_secure_ee_flows.cluster.ilm-000001", "store.size": "15", "creation.date": "1583193600000"}
+		]`
+
+		deleted, err := enforceStorageLimits(context.Background(), esClient)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(deleted).To(Equal([]string{"tigera_secure_ee_flows.cluster.20200301"}))
+	})
+
+	It("does not delete the write index of an alias", func() {
+		responses["/_cat/allocation"] = allocation(100)
+		responses["/_cat/indices/tigera_secure_ee_*"] = `[
+			{"index": "tigera_secure_ee_flows.cluster.ilm-000001", "store.size": "1", "creation.date": "1583020800000"},
+			{"index": "tigera_secure_ee_flows.cluster.20200301", "store.size": "1", "creation.date": "1583020800001"},
+			{"index": "tigera_secure_ee_dns.cluster.20200302", "store.size": "1", "creation.date": "1583107200000"}
+		]`
+
+		deleted, err := enforceStorageLimits(context.Background(), esClient)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(deleted).To(Equal([]string{"tigera_secure_ee_flows.cluster.20200301", "tigera_secure_ee_dns.cluster.20200302"}))
+	})
+})
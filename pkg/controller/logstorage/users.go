@@ -0,0 +1,308 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	operatorv1 "github.com/tigera/operator/pkg/apis/operator/v1"
+	"github.com/tigera/operator/pkg/controller/utils"
+	"github.com/tigera/operator/pkg/elasticsearch"
+	"github.com/tigera/operator/pkg/render"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// passwordCreatedAnnotation records when the password in an Elasticsearch user secret was created, so that it can
+	// be replaced once it is older than the rotation period.
+	passwordCreatedAnnotation = "operator.tigera.io/password-created"
+	// previousUserAnnotation records the user that a component used before the last rotation, which stays enabled
+	// until all the pods of the component have been restarted with the credentials of the new user.
+	previousUserAnnotation = "operator.tigera.io/previous-user"
+
+	// rotatedUserSuffix is appended to the name of the user of a component to get its second user. Passwords are
+	// rotated by alternating between the two users, so that the pods that have not restarted yet keep working.
+	rotatedUserSuffix = "-rotated"
+
+	defaultPasswordRotationDays = 90
+)
+
+// esUser is an Elasticsearch user, and the role granted to it, that the operator creates for a component. The
+// credentials are stored in the secret with the given name, which is used by the pods in the given namespace.
+type esUser struct {
+	secretName string
+	namespace  string
+	username   string
+	role       elasticsearch.Role
+}
+
+// usernames returns the two users that the component alternates between when its password is rotated.
+func (u esUser) usernames() [2]string {
+	return [2]string{u.username, u.username + rotatedUserSuffix}
+}
+
+// esUsers returns the users for each component that accesses Elasticsearch. The roles only grant write access to the
+// indices of the given cluster, and read access to the indices of the given cluster and the clusters it manages.
+func esUsers(clusterName string, managedClusters []string) []esUser {
+	own := []string{clusterName}
+	all := append(own, managedClusters...)
+	indices := func(clusters, privileges []string, names ...string) elasticsearch.IndexPrivileges {
+		var patterns []string
+		for _, n := range names {
+			for _, c := range clusters {
+				patterns = append(patterns, fmt.Sprintf("tigera_secure_ee_%s.%s*", n, c))
+			}
+		}
+		return elasticsearch.IndexPrivileges{Names: patterns, Privileges: privileges}
+	}
+	write := []string{"create_index", "write", "view_index_metadata"}
+	read := []string{"read", "view_index_metadata"}
+	allPrivileges := []string{"all"}
+
+	return []esUser{
+		{render.ElasticsearchLogCollectorUserSecret, render.LogCollectorNamespace, "tigera-fluentd", elasticsearch.Role{
+			Cluster: []string{"monitor", "manage_index_templates"},
+			Indices: []elasticsearch.IndexPrivileges{indices(own, write, "flows", "audit_*", "dns", "l7", "component_logs")},
+		}},
+		{render.ElasticsearchEksLogForwarderUserSecret, render.LogCollectorNamespace, "tigera-eks-log-forwarder", elasticsearch.Role{
+			Cluster: []string{"monitor", "manage_index_templates"},
+			Indices: []elasticsearch.IndexPrivileges{indices(own, write, "audit_kube")},
+		}},
+		{render.ElasticsearchAksLogForwarderUserSecret, render.LogCollectorNamespace, "tigera-aks-log-forwarder", elasticsearch.Role{
+			Cluster: []string{"monitor", "manage_index_templates"},
+			Indices: []elasticsearch.IndexPrivileges{indices(own, write, "audit_kube")},
+		}},
+		{render.ElasticsearchGkeLogForwarderUserSecret, render.LogCollectorNamespace, "tigera-gke-log-forwarder", elasticsearch.Role{
+			Cluster: []string{"monitor", "manage_index_templates"},
+			Indices: []elasticsearch.IndexPrivileges{indices(own, write, "audit_kube")},
+		}},
+		{render.ElasticsearchComplianceBenchmarkerUserSecret, render.ComplianceNamespace, "tigera-ee-compliance-benchmarker", elasticsearch.Role{
+			Cluster: []string{"monitor", "manage_index_templates"},
+			Indices: []elasticsearch.IndexPrivileges{indices(own, write, "benchmark_results")},
+		}},
+		{render.ElasticsearchComplianceControllerUserSecret, render.ComplianceNamespace, "tigera-ee-compliance-controller", elasticsearch.Role{
+			Cluster: []string{"monitor"},
+			Indices: []elasticsearch.IndexPrivileges{indices(all, read, "compliance_reports")},
+		}},
+		{render.ElasticsearchComplianceReporterUserSecret, render.ComplianceNamespace, "tigera-ee-compliance-reporter", elasticsearch.Role{
+			Cluster: []string{"monitor", "manage_index_templates"},
+			Indices: []elasticsearch.IndexPrivileges{
+				indices(all, read, "audit_*", "snapshots", "benchmark_results", "flows"),
+				indices(own, write, "compliance_reports"),
+			},
+		}},
+		{render.ElasticsearchComplianceSnapshotterUserSecret, render.ComplianceNamespace, "tigera-ee-compliance-snapshotter", elasticsearch.Role{
+			Cluster: []string{"monitor", "manage_index_templates"},
+			Indices: []elasticsearch.IndexPrivileges{indices(own, append(write, "read"), "snapshots")},
+		}},
+		{render.ElasticsearchComplianceServerUserSecret, render.ComplianceNamespace, "tigera-ee-compliance-server", elasticsearch.Role{
+			Cluster: []string{"monitor"},
+			Indices: []elasticsearch.IndexPrivileges{indices(all, read, "compliance_reports", "benchmark_results")},
+		}},
+		{render.ElasticsearchIntrusionDetectionUserSecret, render.IntrusionDetectionNamespace, "tigera-ee-intrusion-detection", elasticsearch.Role{
+			Cluster: []string{"monitor", "manage_index_templates"},
+			Indices: []elasticsearch.IndexPrivileges{
+				indices(all, read, "flows", "audit_*", "dns", "l7"),
+				indices(own, allPrivileges, "events"),
+				{
+					Names:      []string{fmt.Sprintf(".tigera.ipset.%s*", clusterName), fmt.Sprintf(".tigera.domainnameset.%s*", clusterName)},
+					Privileges: allPrivileges,
+				},
+			},
+		}},
+		{render.ElasticsearchIntrusionDetectionJobUserSecret, render.IntrusionDetectionNamespace, "tigera-ee-installer", elasticsearch.Role{
+			Cluster: []string{"monitor", "manage_index_templates"},
+			Indices: []elasticsearch.IndexPrivileges{indices(own, allPrivileges, "*")},
+		}},
+		{render.ElasticsearchManagerUserSecret, render.ManagerNamespace, "tigera-ee-manager", elasticsearch.Role{
+			Cluster: []string{"monitor"},
+			Indices: []elasticsearch.IndexPrivileges{indices(all, read, "*")},
+		}},
+	}
+}
+
+// reconcileUsers creates or updates the role and users of each component in Elasticsearch and returns the secrets
+// holding their credentials. Passwords older than rotation are replaced, unless rotation is 0. A rotation is staged,
+// so that the components keep access to Elasticsearch while they restart: the new password is set on the second user
+// of the component, the secret is switched to that user, and the previous user is only disabled once all the pods of
+// the component have been restarted with the new secret. The components are restarted by their controllers, which
+// annotate the pod templates with a hash of the secrets.
+func (r *ReconcileLogStorage) reconcileUsers(ctx context.Context, esClient *elasticsearch.Client, clusterName string, managedClusters []string, rotation time.Duration) ([]*corev1.Secret, error) {
+	now := time.Now()
+
+	var secrets []*corev1.Secret
+	for _, u := range esUsers(clusterName, managedClusters) {
+		creds, err := r.esUserCredentials(ctx, u, rotation, now)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := esClient.PutRole(ctx, u.username, u.role); err != nil {
+			return nil, fmt.Errorf("failed to create role %s: %v", u.username, err)
+		}
+		if err := esClient.PutUser(ctx, creds.username, elasticsearch.User{Password: creds.password, Roles: []string{u.username}, Enabled: true}); err != nil {
+			return nil, fmt.Errorf("failed to create user %s: %v", creds.username, err)
+		}
+
+		if creds.previous != "" {
+			restarted, err := r.componentUsesUser(ctx, u, creds.username, creds.created)
+			if err != nil {
+				return nil, err
+			}
+			if restarted {
+				if err := esClient.DisableUser(ctx, creds.previous); err != nil {
+					return nil, fmt.Errorf("failed to disable user %s: %v", creds.previous, err)
+				}
+				creds.previous = ""
+			}
+		}
+
+		annotations := map[string]string{passwordCreatedAnnotation: creds.created.UTC().Format(time.RFC3339)}
+		if creds.previous != "" {
+			annotations[previousUserAnnotation] = creds.previous
+		}
+		secrets = append(secrets, &corev1.Secret{
+			TypeMeta: metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        u.secretName,
+				Namespace:   render.OperatorNamespace(),
+				Labels:      map[string]string{tigeraElasticsearchUserSecretLabel: "true"},
+				Annotations: annotations,
+			},
+			Data: map[string][]byte{
+				"username": []byte(creds.username),
+				"password": []byte(creds.password),
+			},
+		})
+	}
+
+	return secrets, nil
+}
+
+// esUserCredentials are the credentials of the user that a component uses, and the user it used before the last
+// rotation if that user is still enabled.
+type esUserCredentials struct {
+	username string
+	password string
+	created  time.Time
+	previous string
+}
+
+// esUserCredentials returns the credentials in the existing user secret of the component. New credentials for the
+// other user of the component are returned if there aren't any or the password is older than rotation, unless the
+// previous rotation has not completed yet. A password without a creation time, such as one created before the
+// operator rotated passwords, is treated as created now.
+func (r *ReconcileLogStorage) esUserCredentials(ctx context.Context, u esUser, rotation time.Duration, now time.Time) (esUserCredentials, error) {
+	names := u.usernames()
+	creds := esUserCredentials{username: names[0]}
+
+	secret := &corev1.Secret{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: u.secretName, Namespace: render.OperatorNamespace()}, secret); err != nil {
+		if !errors.IsNotFound(err) {
+			return creds, err
+		}
+	} else if password := string(secret.Data["password"]); password != "" {
+		if string(secret.Data["username"]) == names[1] {
+			creds.username = names[1]
+		}
+		creds.password = password
+		creds.previous = secret.Annotations[previousUserAnnotation]
+		created, err := time.Parse(time.RFC3339, secret.Annotations[passwordCreatedAnnotation])
+		if err != nil {
+			created = now
+		}
+		creds.created = created
+		if rotation == 0 || now.Sub(created) < rotation || creds.previous != "" {
+			return creds, nil
+		}
+
+		// Switch to the other user, the current one stays enabled until the pods have restarted.
+		creds.previous = creds.username
+		if creds.username == names[0] {
+			creds.username = names[1]
+		} else {
+			creds.username = names[0]
+		}
+	}
+
+	password, err := utils.RandomPassword(16)
+	creds.password, creds.created = password, now
+	return creds, err
+}
+
+// componentUsesUser returns whether the component uses the given user: the copy of its user secret in the namespace
+// of the component has been switched to the user, and all the running pods that use the secret have been started since
+// the given time.
+func (r *ReconcileLogStorage) componentUsesUser(ctx context.Context, u esUser, username string, since time.Time) (bool, error) {
+	secret := &corev1.Secret{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: u.secretName, Namespace: u.namespace}, secret); err != nil {
+		if !errors.IsNotFound(err) {
+			return false, err
+		}
+	} else if string(secret.Data["username"]) != username {
+		return false, nil
+	}
+
+	pods := &corev1.PodList{}
+	if err := r.client.List(ctx, pods, client.InNamespace(u.namespace)); err != nil {
+		return false, err
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if pod.CreationTimestamp.Time.Before(since) && podUsesSecret(pod, u.secretName) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// podUsesSecret returns whether the containers of the pod read the secret with the given name.
+func podUsesSecret(pod corev1.Pod, name string) bool {
+	for _, v := range pod.Spec.Volumes {
+		if v.Secret != nil && v.Secret.SecretName == name {
+			return true
+		}
+	}
+	for _, c := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+		for _, e := range c.Env {
+			if e.ValueFrom != nil && e.ValueFrom.SecretKeyRef != nil && e.ValueFrom.SecretKeyRef.Name == name {
+				return true
+			}
+		}
+		for _, e := range c.EnvFrom {
+			if e.SecretRef != nil && e.SecretRef.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// passwordRotation returns how long the passwords of the Elasticsearch users are used before they are replaced.
+func passwordRotation(ls *operatorv1.LogStorage) time.Duration {
+	if ls.Spec.PasswordRotationDays == nil {
+		return defaultPasswordRotationDays * 24 * time.Hour
+	}
+	return time.Duration(*ls.Spec.PasswordRotationDays) * 24 * time.Hour
+}
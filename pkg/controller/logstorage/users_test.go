@@ -0,0 +1,253 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/tigera/operator/pkg/elasticsearch"
+	"github.com/tigera/operator/pkg/render"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Elasticsearch user tests", func() {
+	var cli client.Client
+	var r *ReconcileLogStorage
+	var server *httptest.Server
+	var requests []string
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).ShouldNot(HaveOccurred())
+		cli = fake.NewFakeClientWithScheme(scheme)
+		r = &ReconcileLogStorage{client: cli}
+
+		requests = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			_, _ = w.Write([]byte(`{}`))
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	userSecret := func(password string, created time.Time) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        render.ElasticsearchLogCollectorUserSecret,
+				Namespace:   render.OperatorNamespace(),
+				Annotations: map[string]string{passwordCreatedAnnotation: created.UTC().Format(time.RFC3339)},
+			},
+			Data: map[string][]byte{"username": []byte("tigera-fluentd"), "password": []byte(password)},
+		}
+	}
+
+	rotatingSecret := func(created time.Time) *corev1.Secret {
+		secret := userSecret("new", created)
+		secret.Annotations[previousUserAnnotation] = "tigera-fluentd"
+		secret.Data["username"] = []byte("tigera-fluentd-rotated")
+		return secret
+	}
+
+	fluentdPod := func(created time.Time) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "fluentd-node-abcde",
+				Namespace:         render.LogCollectorNamespace,
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name: "fluentd",
+					Env: []corev1.EnvVar{{
+						Name: "ELASTIC_PASSWORD",
+						ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: render.ElasticsearchLogCollectorUserSecret},
+							Key:                  "password",
+						}},
+					}},
+				}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+
+	fluentdSecret := func(secrets []*corev1.Secret) *corev1.Secret {
+		for _, s := range secrets {
+			if s.Name == render.ElasticsearchLogCollectorUserSecret {
+				return s
+			}
+		}
+		return nil
+	}
+
+	It("creates the role and user of each component", func() {
+		esClient, err := elasticsearch.NewClient(server.URL, "elastic", "elasticpw", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		secrets, err := r.reconcileUsers(context.Background(), esClient, "cluster", nil, 90*24*time.Hour)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(secrets).To(HaveLen(len(esUsers("cluster", nil))))
+		Expect(requests).To(ContainElement("PUT /_security/role/tigera-fluentd"))
+		Expect(requests).To(ContainElement("PUT /_security/user/tigera-fluentd"))
+
+		secret := fluentdSecret(secrets)
+		Expect(secret).NotTo(BeNil())
+		Expect(secret.Labels).To(HaveKeyWithValue(tigeraElasticsearchUserSecretLabel, "true"))
+		Expect(secret.Annotations).To(HaveKey(passwordCreatedAnnotation))
+		Expect(secret.Data["password"]).NotTo(BeEmpty())
+	})
+
+	It("keeps a password that is newer than the rotation period", func() {
+		created := time.Now().Add(-24 * time.Hour)
+		Expect(cli.Create(context.Background(), userSecret("current", created))).ShouldNot(HaveOccurred())
+		esClient, err := elasticsearch.NewClient(server.URL, "elastic", "elasticpw", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		secrets, err := r.reconcileUsers(context.Background(), esClient, "cluster", nil, 90*24*time.Hour)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		secret := fluentdSecret(secrets)
+		Expect(string(secret.Data["password"])).To(Equal("current"))
+		Expect(secret.Annotations[passwordCreatedAnnotation]).To(Equal(created.UTC().Format(time.RFC3339)))
+	})
+
+	It("replaces a password that is older than the rotation period", func() {
+		Expect(cli.Create(context.Background(), userSecret("expired", time.Now().Add(-91*24*time.Hour)))).ShouldNot(HaveOccurred())
+		esClient, err := elasticsearch.NewClient(server.URL, "elastic", "elasticpw", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		secrets, err := r.reconcileUsers(context.Background(), esClient, "cluster", nil, 90*24*time.Hour)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		secret := fluentdSecret(secrets)
+		Expect(string(secret.Data["password"])).NotTo(Equal("expired"))
+		Expect(string(secret.Data["password"])).NotTo(BeEmpty())
+	})
+
+	It("switches to the other user when the password is older than the rotation period", func() {
+		Expect(cli.Create(context.Background(), userSecret("expired", time.Now().Add(-91*24*time.Hour)))).ShouldNot(HaveOccurred())
+		Expect(cli.Create(context.Background(), fluentdPod(time.Now().Add(-time.Hour)))).ShouldNot(HaveOccurred())
+		esClient, err := elasticsearch.NewClient(server.URL, "elastic", "elasticpw", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		secrets, err := r.reconcileUsers(context.Background(), esClient, "cluster", nil, 90*24*time.Hour)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		// The previous user stays enabled, as the running pod still uses it.
+		secret := fluentdSecret(secrets)
+		Expect(string(secret.Data["username"])).To(Equal("tigera-fluentd-rotated"))
+		Expect(secret.Annotations).To(HaveKeyWithValue(previousUserAnnotation, "tigera-fluentd"))
+		Expect(requests).To(ContainElement("PUT /_security/user/tigera-fluentd-rotated"))
+		Expect(requests).NotTo(ContainElement("PUT /_security/user/tigera-fluentd"))
+		Expect(requests).NotTo(ContainElement("PUT /_security/user/tigera-fluentd/_disable"))
+	})
+
+	It("keeps the previous user until the pods have restarted", func() {
+		created := time.Now().Add(-time.Minute)
+		Expect(cli.Create(context.Background(), rotatingSecret(created))).ShouldNot(HaveOccurred())
+		Expect(cli.Create(context.Background(), fluentdPod(created.Add(-time.Hour)))).ShouldNot(HaveOccurred())
+		esClient, err := elasticsearch.NewClient(server.URL, "elastic", "elasticpw", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		secrets, err := r.reconcileUsers(context.Background(), esClient, "cluster", nil, 90*24*time.Hour)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		secret := fluentdSecret(secrets)
+		Expect(string(secret.Data["password"])).To(Equal("new"))
+		Expect(secret.Annotations).To(HaveKeyWithValue(previousUserAnnotation, "tigera-fluentd"))
+		Expect(requests).NotTo(ContainElement("PUT /_security/user/tigera-fluentd/_disable"))
+	})
+
+	It("disables the previous user once the pods have restarted", func() {
+		created := time.Now().Add(-time.Hour)
+		Expect(cli.Create(context.Background(), rotatingSecret(created))).ShouldNot(HaveOccurred())
+		copied := rotatingSecret(created)
+		copied.Namespace = render.LogCollectorNamespace
+		Expect(cli.Create(context.Background(), copied)).ShouldNot(HaveOccurred())
+		Expect(cli.Create(context.Background(), fluentdPod(created.Add(time.Minute)))).ShouldNot(HaveOccurred())
+		esClient, err := elasticsearch.NewClient(server.URL, "elastic", "elasticpw", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		secrets, err := r.reconcileUsers(context.Background(), esClient, "cluster", nil, 90*24*time.Hour)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		secret := fluentdSecret(secrets)
+		Expect(string(secret.Data["username"])).To(Equal("tigera-fluentd-rotated"))
+		Expect(string(secret.Data["password"])).To(Equal("new"))
+		Expect(secret.Annotations).NotTo(HaveKey(previousUserAnnotation))
+		Expect(requests).To(ContainElement("PUT /_security/user/tigera-fluentd/_disable"))
+	})
+
+	It("does not disable the previous user before the secret of the component has been updated", func() {
+		created := time.Now().Add(-time.Hour)
+		Expect(cli.Create(context.Background(), rotatingSecret(created))).ShouldNot(HaveOccurred())
+		copied := userSecret("expired", created.Add(-91*24*time.Hour))
+		copied.Namespace = render.LogCollectorNamespace
+		Expect(cli.Create(context.Background(), copied)).ShouldNot(HaveOccurred())
+		esClient, err := elasticsearch.NewClient(server.URL, "elastic", "elasticpw", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		secrets, err := r.reconcileUsers(context.Background(), esClient, "cluster", nil, 90*24*time.Hour)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(fluentdSecret(secrets).Annotations).To(HaveKeyWithValue(previousUserAnnotation, "tigera-fluentd"))
+		Expect(requests).NotTo(ContainElement("PUT /_security/user/tigera-fluentd/_disable"))
+	})
+
+	It("grants read access to the indices of the managed clusters in a management cluster", func() {
+		users := esUsers("cluster", []string{"managed-1", "managed-2"})
+		roles := map[string]elasticsearch.Role{}
+		for _, u := range users {
+			roles[u.username] = u.role
+		}
+
+		Expect(roles["tigera-ee-manager"].Indices).To(ConsistOf(elasticsearch.IndexPrivileges{
+			Names:      []string{"tigera_secure_ee_*.cluster*", "tigera_secure_ee_*.managed-1*", "tigera_secure_ee_*.managed-2*"},
+			Privileges: []string{"read", "view_index_metadata"},
+		}))
+		Expect(roles["tigera-ee-compliance-reporter"].Indices).To(ContainElement(elasticsearch.IndexPrivileges{
+			Names:      []string{"tigera_secure_ee_compliance_reports.cluster*"},
+			Privileges: []string{"create_index", "write", "view_index_metadata"},
+		}))
+		Expect(roles["tigera-ee-compliance-reporter"].Indices[0].Names).To(ContainElement("tigera_secure_ee_flows.managed-2*"))
+		Expect(roles["tigera-ee-intrusion-detection"].Indices[0].Names).To(ContainElement("tigera_secure_ee_audit_*.managed-1*"))
+
+		// Writes are only granted to the indices of the management cluster.
+		Expect(roles["tigera-fluentd"].Indices[0].Names).NotTo(ContainElement(ContainSubstring("managed")))
+	})
+
+	It("does not replace passwords when rotation is disabled", func() {
+		Expect(cli.Create(context.Background(), userSecret("expired", time.Now().Add(-365*24*time.Hour)))).ShouldNot(HaveOccurred())
+		esClient, err := elasticsearch.NewClient(server.URL, "elastic", "elasticpw", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		secrets, err := r.reconcileUsers(context.Background(), esClient, "cluster", nil, 0)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(string(fluentdSecret(secrets).Data["password"])).To(Equal("expired"))
+	})
+})
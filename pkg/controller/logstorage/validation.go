@@ -0,0 +1,229 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+
+	operatorv1 "github.com/tigera/operator/pkg/apis/operator/v1"
+	"github.com/tigera/operator/pkg/render"
+)
+
+// ECK uses the node set name in the names of the StatefulSet and pods of the node set.
+var nodeSetNameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+const maxNodeSetNameLength = 23
+
+// validateNodeSets returns an error if the node sets of the Elasticsearch cluster are not valid.
+func validateNodeSets(nodes *operatorv1.Nodes) error {
+	if nodes == nil || len(nodes.NodeSets) == 0 {
+		return nil
+	}
+	if nodes.Count != 0 || nodes.ResourceRequirements != nil {
+		return fmt.Errorf("nodes.count and nodes.resourceRequirements cannot be set with nodes.nodeSets")
+	}
+
+	names := map[string]bool{}
+	var masters, dataNodes int64
+	for _, ns := range nodes.NodeSets {
+		if len(ns.Name) > maxNodeSetNameLength || !nodeSetNameRegexp.MatchString(ns.Name) {
+			return fmt.Errorf("node set name %q must be at most %d lower case alphanumeric characters or '-'", ns.Name, maxNodeSetNameLength)
+		}
+		if names[ns.Name] {
+			return fmt.Errorf("node set name %q is used more than once", ns.Name)
+		}
+		names[ns.Name] = true
+
+		if ns.Count < 1 {
+			return fmt.Errorf("node set %q must have at least one node", ns.Name)
+		}
+
+		roles := map[operatorv1.ElasticsearchNodeRole]bool{}
+		for _, r := range ns.Roles {
+			switch r {
+			case operatorv1.ElasticsearchNodeRoleMaster, operatorv1.ElasticsearchNodeRoleData, operatorv1.ElasticsearchNodeRoleIngest:
+			default:
+				return fmt.Errorf("node set %q has invalid role %q", ns.Name, r)
+			}
+			if roles[r] {
+				return fmt.Errorf("node set %q has role %q more than once", ns.Name, r)
+			}
+			roles[r] = true
+		}
+
+		switch ns.DataTier {
+		case "", operatorv1.ElasticsearchDataTierHot, operatorv1.ElasticsearchDataTierWarm:
+		default:
+			return fmt.Errorf("node set %q has invalid data tier %q", ns.Name, ns.DataTier)
+		}
+		if ns.DataTier != "" && !ns.HasRole(operatorv1.ElasticsearchNodeRoleData) {
+			return fmt.Errorf("node set %q sets a data tier but does not have the %s role", ns.Name, operatorv1.ElasticsearchNodeRoleData)
+		}
+
+		if ns.HasRole(operatorv1.ElasticsearchNodeRoleMaster) {
+			masters += ns.Count
+		}
+		if ns.HasRole(operatorv1.ElasticsearchNodeRoleData) {
+			dataNodes += ns.Count
+		}
+	}
+
+	if masters == 0 {
+		return fmt.Errorf("at least one node must have the %s role", operatorv1.ElasticsearchNodeRoleMaster)
+	}
+	if masters%2 == 0 {
+		return fmt.Errorf("the number of nodes with the %s role must be odd, found %d", operatorv1.ElasticsearchNodeRoleMaster, masters)
+	}
+	if dataNodes == 0 {
+		return fmt.Errorf("at least one node must have the %s role", operatorv1.ElasticsearchNodeRoleData)
+	}
+
+	return nil
+}
+
+// validateIndices returns an error if the replicas or shards of any type of index are not valid.
+func validateIndices(indices *operatorv1.Indices) error {
+	validate := func(name string, replicas, shards *int32) error {
+		if replicas != nil && *replicas < 0 {
+			return fmt.Errorf("%sreplicas must not be negative", name)
+		}
+		if shards != nil && *shards < 1 {
+			return fmt.Errorf("%sshards must be at least 1", name)
+		}
+		return nil
+	}
+
+	if err := validate("indices.", indices.Replicas, indices.Shards); err != nil {
+		return err
+	}
+	for index, settings := range indexSettings(indices) {
+		if settings == nil {
+			continue
+		}
+		if err := validate(fmt.Sprintf("the %s index ", index), settings.Replicas, settings.Shards); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateKibana returns an error if the Kibana configuration is not valid. Users must log in to Kibana with the same
+// OpenID Connect provider as the Manager, if set.
+func validateKibana(kibana *operatorv1.Kibana, manager *operatorv1.Manager) error {
+	if kibana == nil || kibana.Disabled {
+		return nil
+	}
+	if kibana.Count < 0 {
+		return fmt.Errorf("kibana.count must not be negative")
+	}
+	if err := validateKibanaSpaces(kibana.Spaces); err != nil {
+		return err
+	}
+
+	auth := kibana.Auth
+	if auth == nil || auth.Type == "" || auth.Type == operatorv1.KibanaAuthTypeBasic {
+		return nil
+	}
+	if auth.BaseURL == "" {
+		return fmt.Errorf("kibana.auth.baseURL must be set for %s", auth.Type)
+	}
+	if u, err := url.Parse(auth.BaseURL); err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("kibana.auth.baseURL %q must be an https URL", auth.BaseURL)
+	}
+
+	switch auth.Type {
+	case operatorv1.KibanaAuthTypeOIDC:
+		oidc := auth.OIDC
+		if oidc == nil {
+			return fmt.Errorf("kibana.auth.oidc must be set for OIDC")
+		}
+		for _, field := range []struct{ name, value string }{
+			{"authority", oidc.Authority},
+			{"clientID", oidc.ClientID},
+			{"clientSecretName", oidc.ClientSecretName},
+			{"authorizationEndpoint", oidc.AuthorizationEndpoint},
+			{"tokenEndpoint", oidc.TokenEndpoint},
+			{"jwkSetURL", oidc.JWKSetURL},
+		} {
+			if field.value == "" {
+				return fmt.Errorf("kibana.auth.oidc.%s must be set", field.name)
+			}
+		}
+		if managerAuth := managerOIDC(manager); managerAuth != nil && oidc.Authority != managerAuth.Authority {
+			return fmt.Errorf("kibana.auth.oidc.authority %q must be the authority of the Manager %q", oidc.Authority, managerAuth.Authority)
+		}
+	case operatorv1.KibanaAuthTypeSAML:
+		saml := auth.SAML
+		if saml == nil {
+			return fmt.Errorf("kibana.auth.saml must be set for SAML")
+		}
+		if saml.IDPMetadataURL == "" || saml.IDPEntityID == "" {
+			return fmt.Errorf("kibana.auth.saml.idpMetadataURL and kibana.auth.saml.idpEntityID must be set")
+		}
+	default:
+		return fmt.Errorf("kibana.auth.type %q is not supported", auth.Type)
+	}
+	return nil
+}
+
+// kibanaSpaceID matches the characters Kibana allows in the ID of a space.
+var kibanaSpaceIDRegexp = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// validateKibanaSpaces returns an error if a space has an invalid ID, no name, or the ID of another space.
+func validateKibanaSpaces(spaces []operatorv1.KibanaSpace) error {
+	ids := map[string]bool{}
+	for _, space := range spaces {
+		if !kibanaSpaceIDRegexp.MatchString(space.ID) {
+			return fmt.Errorf("kibana.spaces id %q may only contain lowercase letters, numbers, underscores and hyphens", space.ID)
+		}
+		if space.ID == "default" {
+			return fmt.Errorf("kibana.spaces id %q is reserved for the default space", space.ID)
+		}
+		if ids[space.ID] {
+			return fmt.Errorf("kibana.spaces id %q is used by more than one space", space.ID)
+		}
+		ids[space.ID] = true
+		if space.Name == "" {
+			return fmt.Errorf("kibana.spaces name must be set for space %q", space.ID)
+		}
+	}
+	return nil
+}
+
+// storageClassNames returns the names of the storage classes used by the Elasticsearch nodes.
+func storageClassNames(nodes *operatorv1.Nodes) []string {
+	var classes []string
+	seen := map[string]bool{}
+	add := func(class string) {
+		if class == "" {
+			class = render.ElasticsearchStorageClass
+		}
+		if !seen[class] {
+			seen[class] = true
+			classes = append(classes, class)
+		}
+	}
+
+	if nodes == nil || len(nodes.NodeSets) == 0 {
+		add("")
+		return classes
+	}
+	for _, ns := range nodes.NodeSets {
+		add(ns.StorageClassName)
+	}
+	return classes
+}
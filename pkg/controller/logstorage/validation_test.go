@@ -0,0 +1,213 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logstorage
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	operatorv1 "github.com/tigera/operator/pkg/apis/operator/v1"
+	"github.com/tigera/operator/pkg/render"
+)
+
+var _ = Describe("LogStorage validation tests", func() {
+	var nodes *operatorv1.Nodes
+
+	BeforeEach(func() {
+		nodes = &operatorv1.Nodes{
+			NodeSets: []operatorv1.NodeSet{
+				{Name: "master", Count: 3, Roles: []operatorv1.ElasticsearchNodeRole{operatorv1.ElasticsearchNodeRoleMaster}},
+				{Name: "hot", Count: 2, Roles: []operatorv1.ElasticsearchNodeRole{operatorv1.ElasticsearchNodeRoleData, operatorv1.ElasticsearchNodeRoleIngest}},
+				{
+					Name:             "warm",
+					Count:            2,
+					Roles:            []operatorv1.ElasticsearchNodeRole{operatorv1.ElasticsearchNodeRoleData},
+					DataTier:         operatorv1.ElasticsearchDataTierWarm,
+					StorageClassName: "slow",
+				},
+			},
+		}
+	})
+
+	It("should allow dedicated master, hot and warm node sets", func() {
+		Expect(validateNodeSets(nodes)).NotTo(HaveOccurred())
+	})
+
+	It("should allow a single count of nodes without node sets", func() {
+		Expect(validateNodeSets(&operatorv1.Nodes{Count: 2})).NotTo(HaveOccurred())
+		Expect(validateNodeSets(nil)).NotTo(HaveOccurred())
+	})
+
+	It("should not allow count with node sets", func() {
+		nodes.Count = 3
+		Expect(validateNodeSets(nodes)).To(HaveOccurred())
+	})
+
+	It("should require a master-eligible node", func() {
+		nodes.NodeSets = nodes.NodeSets[1:]
+		Expect(validateNodeSets(nodes)).To(HaveOccurred())
+	})
+
+	It("should require an odd number of master-eligible nodes", func() {
+		nodes.NodeSets[0].Count = 2
+		Expect(validateNodeSets(nodes)).To(HaveOccurred())
+
+		// Node sets without roles are master-eligible.
+		nodes.NodeSets[0].Count = 3
+		nodes.NodeSets[1].Roles = nil
+		Expect(validateNodeSets(nodes)).To(HaveOccurred())
+	})
+
+	It("should require a data node", func() {
+		nodes.NodeSets = nodes.NodeSets[:1]
+		Expect(validateNodeSets(nodes)).To(HaveOccurred())
+	})
+
+	It("should not allow duplicate or invalid names", func() {
+		nodes.NodeSets[1].Name = "master"
+		Expect(validateNodeSets(nodes)).To(HaveOccurred())
+
+		nodes.NodeSets[1].Name = "Hot_Nodes"
+		Expect(validateNodeSets(nodes)).To(HaveOccurred())
+
+		nodes.NodeSets[1].Name = "a-node-set-name-that-is-too-long"
+		Expect(validateNodeSets(nodes)).To(HaveOccurred())
+	})
+
+	It("should not allow invalid or duplicate roles", func() {
+		nodes.NodeSets[1].Roles = append(nodes.NodeSets[1].Roles, "ML")
+		Expect(validateNodeSets(nodes)).To(HaveOccurred())
+
+		nodes.NodeSets[1].Roles = []operatorv1.ElasticsearchNodeRole{operatorv1.ElasticsearchNodeRoleData, operatorv1.ElasticsearchNodeRoleData}
+		Expect(validateNodeSets(nodes)).To(HaveOccurred())
+	})
+
+	It("should only allow a data tier for data nodes", func() {
+		nodes.NodeSets[0].DataTier = operatorv1.ElasticsearchDataTierHot
+		Expect(validateNodeSets(nodes)).To(HaveOccurred())
+	})
+
+	It("should return the storage classes used by the node sets", func() {
+		Expect(storageClassNames(nodes)).To(Equal([]string{render.ElasticsearchStorageClass, "slow"}))
+		Expect(storageClassNames(&operatorv1.Nodes{Count: 1})).To(Equal([]string{render.ElasticsearchStorageClass}))
+	})
+
+	Context("Index settings", func() {
+		var ls *operatorv1.LogStorage
+
+		BeforeEach(func() {
+			ls = &operatorv1.LogStorage{}
+			fillDefaults(ls)
+		})
+
+		It("should override the replicas and shards of an index type", func() {
+			var shards int32 = 10
+			ls.Spec.Indices.Flows = &operatorv1.IndexSettings{Shards: &shards}
+			Expect(validateIndices(ls.Spec.Indices)).NotTo(HaveOccurred())
+
+			config := newElasticsearchClusterConfig(ls, "")
+			Expect(config.IndexSettings(render.FlowsIndex)).To(Equal(render.IndexSettings{Replicas: render.DefaultElasticsearchReplicas, Shards: 10}))
+			Expect(config.IndexSettings(render.AuditIndex)).To(Equal(render.IndexSettings{Replicas: render.DefaultElasticsearchReplicas, Shards: defaultElasticsearchShards}))
+		})
+
+		It("should not allow an index type without shards", func() {
+			var shards int32
+			ls.Spec.Indices.DNSLogs = &operatorv1.IndexSettings{Shards: &shards}
+			Expect(validateIndices(ls.Spec.Indices)).To(HaveOccurred())
+		})
+
+		It("should not allow negative replicas", func() {
+			var replicas int32 = -1
+			ls.Spec.Indices.Replicas = &replicas
+			Expect(validateIndices(ls.Spec.Indices)).To(HaveOccurred())
+		})
+	})
+
+	Context("Kibana", func() {
+		var oidc *operatorv1.KibanaAuth
+
+		BeforeEach(func() {
+			oidc = &operatorv1.KibanaAuth{
+				Type:    operatorv1.KibanaAuthTypeOIDC,
+				BaseURL: "https://manager.example.com/tigera-kibana",
+				OIDC: &operatorv1.KibanaOIDC{
+					Authority:             "https://idp.example.com",
+					ClientID:              "kibana",
+					ClientSecretName:      "kibana-oidc",
+					AuthorizationEndpoint: "https://idp.example.com/authorize",
+					TokenEndpoint:         "https://idp.example.com/token",
+					JWKSetURL:             "https://idp.example.com/jwks",
+				},
+			}
+		})
+
+		It("should allow basic auth without further settings", func() {
+			Expect(validateKibana(nil, nil)).NotTo(HaveOccurred())
+			Expect(validateKibana(&operatorv1.Kibana{Auth: &operatorv1.KibanaAuth{Type: operatorv1.KibanaAuthTypeBasic}}, nil)).NotTo(HaveOccurred())
+		})
+
+		It("should allow a complete OIDC configuration", func() {
+			Expect(validateKibana(&operatorv1.Kibana{Auth: oidc}, nil)).NotTo(HaveOccurred())
+		})
+
+		It("should require the base URL and provider settings for single sign-on", func() {
+			oidc.OIDC.JWKSetURL = ""
+			Expect(validateKibana(&operatorv1.Kibana{Auth: oidc}, nil)).To(MatchError("kibana.auth.oidc.jwkSetURL must be set"))
+
+			oidc.BaseURL = "http://manager.example.com"
+			Expect(validateKibana(&operatorv1.Kibana{Auth: oidc}, nil)).To(HaveOccurred())
+
+			saml := &operatorv1.KibanaAuth{Type: operatorv1.KibanaAuthTypeSAML, BaseURL: "https://manager.example.com/tigera-kibana"}
+			Expect(validateKibana(&operatorv1.Kibana{Auth: saml}, nil)).To(HaveOccurred())
+		})
+
+		It("should default the OIDC provider to that of the Manager and require the same authority", func() {
+			manager := &operatorv1.Manager{Spec: operatorv1.ManagerSpec{Auth: &operatorv1.Auth{
+				Type:      operatorv1.AuthTypeOIDC,
+				Authority: "https://idp.example.com",
+				ClientID:  "manager",
+			}}}
+			oidc.OIDC.Authority, oidc.OIDC.ClientID = "", ""
+			kibana := &operatorv1.Kibana{Auth: oidc}
+			Expect(validateKibana(kibana, nil)).To(MatchError("kibana.auth.oidc.authority must be set"))
+
+			fillKibanaOIDCDefaults(kibana, manager)
+			Expect(oidc.OIDC.Authority).To(Equal("https://idp.example.com"))
+			Expect(oidc.OIDC.ClientID).To(Equal("manager"))
+			Expect(validateKibana(kibana, manager)).NotTo(HaveOccurred())
+
+			oidc.OIDC.Authority = "https://other.example.com"
+			Expect(validateKibana(kibana, manager)).To(HaveOccurred())
+		})
+
+		It("should require unique space IDs that Kibana accepts", func() {
+			kibana := &operatorv1.Kibana{Spaces: []operatorv1.KibanaSpace{{ID: "security", Name: "Security"}}}
+			Expect(validateKibana(kibana, nil)).NotTo(HaveOccurred())
+
+			kibana.Spaces = append(kibana.Spaces, operatorv1.KibanaSpace{ID: "security", Name: "Security"})
+			Expect(validateKibana(kibana, nil)).To(MatchError(`kibana.spaces id "security" is used by more than one space`))
+
+			kibana.Spaces[1] = operatorv1.KibanaSpace{ID: "Security", Name: "Security"}
+			Expect(validateKibana(kibana, nil)).To(HaveOccurred())
+
+			kibana.Spaces[1] = operatorv1.KibanaSpace{ID: "compliance"}
+			Expect(validateKibana(kibana, nil)).To(HaveOccurred())
+		})
+
+		It("should ignore the configuration of a disabled Kibana", func() {
+			Expect(validateKibana(&operatorv1.Kibana{Disabled: true, Count: -1}, nil)).NotTo(HaveOccurred())
+		})
+	})
+})
@@ -259,11 +259,16 @@ func (r *ReconcileManager) Reconcile(request reconcile.Request) (reconcile.Resul
 		return reconcile.Result{}, err
 	}
 
-	kibanaPublicCertSecret := &corev1.Secret{}
-	if err := r.client.Get(ctx, types.NamespacedName{Name: render.KibanaPublicCertSecret, Namespace: render.OperatorNamespace()}, kibanaPublicCertSecret); err != nil {
-		reqLogger.Error(err, "Failed to read Kibana public cert secret")
-		r.status.SetDegraded("Failed to read Kibana public cert secret", err.Error())
-		return reconcile.Result{}, err
+	// There is no Kibana when an external Elasticsearch cluster is used or Kibana is disabled.
+	var kibanaSecrets []*corev1.Secret
+	if esClusterConfig.Kibana() {
+		kibanaPublicCertSecret := &corev1.Secret{}
+		if err := r.client.Get(ctx, types.NamespacedName{Name: render.KibanaPublicCertSecret, Namespace: render.OperatorNamespace()}, kibanaPublicCertSecret); err != nil {
+			reqLogger.Error(err, "Failed to read Kibana public cert secret")
+			r.status.SetDegraded("Failed to read Kibana public cert secret", err.Error())
+			return reconcile.Result{}, err
+		}
+		kibanaSecrets = append(kibanaSecrets, kibanaPublicCertSecret)
 	}
 
 	complianceServerCertSecret, err := utils.ValidateCertPair(r.client,
@@ -315,13 +320,13 @@ func (r *ReconcileManager) Reconcile(request reconcile.Request) (reconcile.Resul
 	component, err := render.Manager(
 		instance,
 		esSecrets,
-		[]*corev1.Secret{kibanaPublicCertSecret},
+		kibanaSecrets,
 		complianceServerCertSecret,
 		esClusterConfig,
 		tlsSecret,
 		pullSecrets,
 		r.provider == operatorv1.ProviderOpenShift,
-		installation.Spec.Registry,
+		installation,
 		oidcConfig,
 		management,
 		tunnelSecret,
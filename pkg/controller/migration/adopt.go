@@ -0,0 +1,433 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operator "github.com/tigera/operator/pkg/apis/operator/v1"
+)
+
+const (
+	// Name of the ConfigMap the Calico manifests use to hold the CNI and MTU configuration.
+	calicoConfigMapName = "calico-config"
+
+	nodeContainerName    = "calico-node"
+	flexVolContainerName = "flexvol-driver"
+)
+
+// ignoredNodeEnv contains calico-node environment variables set by the manifests which the
+// operator renders itself. The value is the only setting that is known to be equivalent to
+// what the operator renders, or the empty string if any value is acceptable.
+var ignoredNodeEnv = map[string]string{
+	"DATASTORE_TYPE":                    "kubernetes",
+	"WAIT_FOR_DATASTORE":                "true",
+	"NODENAME":                          "",
+	"CLUSTER_TYPE":                      "",
+	"CALICO_DISABLE_FILE_LOGGING":       "true",
+	"FELIX_DEFAULTENDPOINTTOHOSTACTION": "ACCEPT",
+	"FELIX_HEALTHENABLED":               "true",
+	"FELIX_LOGSEVERITYSCREEN":           "info",
+	"FELIX_IPV6SUPPORT":                 "",
+	"FELIX_TYPHAK8SSERVICENAME":         "",
+	"CALICO_NETWORKING_BACKEND":         "bird",
+	"IP":                                "autodetect",
+	"IP6":                               "",
+}
+
+// AdoptionReport describes how the configuration of a manifest installation of Calico
+// was translated into an Installation.
+type AdoptionReport struct {
+	// Inferred lists the settings that were carried over into the Installation spec.
+	Inferred []string
+	// Unmapped lists the settings that have no equivalent in the Installation API.
+	Unmapped []string
+}
+
+// Compatible returns true if every setting of the existing installation could be mapped.
+func (r *AdoptionReport) Compatible() bool {
+	return len(r.Unmapped) == 0
+}
+
+func (r *AdoptionReport) inferred(format string, a ...interface{}) {
+	r.Inferred = append(r.Inferred, fmt.Sprintf(format, a...))
+}
+
+func (r *AdoptionReport) unmapped(format string, a ...interface{}) {
+	r.Unmapped = append(r.Unmapped, fmt.Sprintf(format, a...))
+}
+
+// AdoptExistingInstall reads the calico-node DaemonSet and calico-config ConfigMap of a
+// manifest installation in the kube-system namespace and returns the equivalent
+// Installation spec along with a report of what could and could not be mapped.
+func (m *CoreNamespaceMigration) AdoptExistingInstall() (*operator.InstallationSpec, *AdoptionReport, error) {
+	ds, err := m.client.AppsV1().DaemonSets(kubeSystem).Get(nodeDaemonSetName, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get daemonset %s in kube-system: %s", nodeDaemonSetName, err)
+	}
+
+	cm, err := m.client.CoreV1().ConfigMaps(kubeSystem).Get(calicoConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		if !apierrs.IsNotFound(err) {
+			return nil, nil, fmt.Errorf("failed to get configmap %s in kube-system: %s", calicoConfigMapName, err)
+		}
+		cm = nil
+	}
+
+	spec, report := ConvertManifestInstall(ds, cm)
+	return spec, report, nil
+}
+
+// ConvertManifestInstall translates the calico-node DaemonSet and calico-config ConfigMap of a
+// manifest installation into an Installation spec. Settings that cannot be represented by the
+// Installation API are listed in the Unmapped field of the returned report. The ConfigMap may be nil.
+func ConvertManifestInstall(ds *appsv1.DaemonSet, cm *v1.ConfigMap) (*operator.InstallationSpec, *AdoptionReport) {
+	report := &AdoptionReport{}
+	spec := &operator.InstallationSpec{}
+
+	var node *v1.Container
+	for i := range ds.Spec.Template.Spec.Containers {
+		if ds.Spec.Template.Spec.Containers[i].Name == nodeContainerName {
+			node = &ds.Spec.Template.Spec.Containers[i]
+		}
+	}
+	if node == nil {
+		report.unmapped("daemonset %s has no %s container", ds.Name, nodeContainerName)
+		return spec, report
+	}
+
+	cmData := map[string]string{}
+	if cm != nil {
+		cmData = cm.Data
+	}
+
+	env := map[string]string{}
+	for _, e := range node.Env {
+		value, ok := resolveEnvVar(e, cmData)
+		if !ok {
+			report.unmapped("env %s is set from an unsupported source", e.Name)
+			continue
+		}
+		env[e.Name] = value
+	}
+
+	// Policy-only installs have no Calico networking configuration to carry over.
+	if env["CALICO_NETWORKING_BACKEND"] == "none" {
+		delete(env, "CALICO_NETWORKING_BACKEND")
+		report.inferred("calico networking disabled")
+		checkRemainingEnv(env, report)
+		return spec, report
+	}
+
+	cn := &operator.CalicoNetworkSpec{}
+	spec.CalicoNetwork = cn
+
+	convertMTU(env, cmData, cn, report)
+	convertAutodetection(env, cn, report)
+	convertPools(env, cn, report)
+	convertCNIConfig(cmData, report)
+
+	flexVol := false
+	for _, c := range ds.Spec.Template.Spec.InitContainers {
+		if c.Name == flexVolContainerName {
+			flexVol = true
+		}
+	}
+	cn.FlexVolInitContainerEnabled = &flexVol
+	report.inferred("flexVolInitContainerEnabled=%t", flexVol)
+
+	checkRemainingEnv(env, report)
+	return spec, report
+}
+
+// resolveEnvVar returns the value of an environment variable, looking up values that
+// reference the calico-config ConfigMap. It returns false if the value comes from any other source.
+func resolveEnvVar(e v1.EnvVar, cmData map[string]string) (string, bool) {
+	if e.ValueFrom == nil {
+		return e.Value, true
+	}
+	if ref := e.ValueFrom.ConfigMapKeyRef; ref != nil && ref.Name == calicoConfigMapName {
+		return cmData[ref.Key], true
+	}
+	if e.ValueFrom.FieldRef != nil && e.Name == "NODENAME" {
+		return "", true
+	}
+	return "", false
+}
+
+// checkRemainingEnv reports every environment variable that has not been consumed by the
+// conversion and is not known to be equivalent to the operator rendered configuration.
+func checkRemainingEnv(env map[string]string, report *AdoptionReport) {
+	names := []string{}
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		expected, ok := ignoredNodeEnv[name]
+		if !ok {
+			report.unmapped("env %s=%s", name, env[name])
+			continue
+		}
+		if expected != "" && !strings.EqualFold(expected, env[name]) {
+			report.unmapped("env %s=%s (only %s is supported)", name, env[name], expected)
+		}
+	}
+}
+
+func convertMTU(env, cmData map[string]string, cn *operator.CalicoNetworkSpec, report *AdoptionReport) {
+	values := map[string]bool{}
+	for _, name := range []string{"FELIX_IPINIPMTU", "FELIX_VXLANMTU"} {
+		if v, ok := env[name]; ok {
+			values[v] = true
+			delete(env, name)
+		}
+	}
+	if v, ok := cmData["veth_mtu"]; ok {
+		values[v] = true
+	}
+
+	if len(values) == 0 {
+		return
+	}
+	if len(values) > 1 {
+		report.unmapped("differing MTU values for IPIP, VXLAN and veth interfaces")
+		return
+	}
+	for v := range values {
+		mtu, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			report.unmapped("invalid MTU %q", v)
+			return
+		}
+		mtu32 := int32(mtu)
+		cn.MTU = &mtu32
+		report.inferred("mtu=%d", mtu32)
+	}
+}
+
+func convertAutodetection(env map[string]string, cn *operator.CalicoNetworkSpec, report *AdoptionReport) {
+	if method, ok := env["IP_AUTODETECTION_METHOD"]; ok {
+		delete(env, "IP_AUTODETECTION_METHOD")
+		ad, err := autodetectionFromMethod(method)
+		if err != nil {
+			report.unmapped("env IP_AUTODETECTION_METHOD: %s", err)
+		} else {
+			cn.NodeAddressAutodetectionV4 = ad
+			report.inferred("nodeAddressAutodetectionV4 from %q", method)
+		}
+	}
+
+	if env["IP6"] == "autodetect" {
+		method := "first-found"
+		if m, ok := env["IP6_AUTODETECTION_METHOD"]; ok {
+			method = m
+		}
+		ad, err := autodetectionFromMethod(method)
+		if err != nil {
+			report.unmapped("env IP6_AUTODETECTION_METHOD: %s", err)
+		} else {
+			cn.NodeAddressAutodetectionV6 = ad
+			report.inferred("nodeAddressAutodetectionV6 from %q", method)
+		}
+	} else if m, ok := env["IP6_AUTODETECTION_METHOD"]; ok {
+		report.unmapped("env IP6_AUTODETECTION_METHOD=%s without IPv6 auto-detection enabled", m)
+	}
+	delete(env, "IP6_AUTODETECTION_METHOD")
+}
+
+// autodetectionFromMethod is the inverse of the render package's getAutodetectionMethod.
+func autodetectionFromMethod(method string) (*operator.NodeAddressAutodetection, error) {
+	switch {
+	case method == "first-found":
+		t := true
+		return &operator.NodeAddressAutodetection{FirstFound: &t}, nil
+	case strings.HasPrefix(method, "interface="):
+		return &operator.NodeAddressAutodetection{Interface: strings.TrimPrefix(method, "interface=")}, nil
+	case strings.HasPrefix(method, "skip-interface="):
+		return &operator.NodeAddressAutodetection{SkipInterface: strings.TrimPrefix(method, "skip-interface=")}, nil
+	case strings.HasPrefix(method, "can-reach="):
+		return &operator.NodeAddressAutodetection{CanReach: strings.TrimPrefix(method, "can-reach=")}, nil
+	}
+	return nil, fmt.Errorf("unsupported auto-detection method %q", method)
+}
+
+func convertPools(env map[string]string, cn *operator.CalicoNetworkSpec, report *AdoptionReport) {
+	if env["NO_DEFAULT_POOLS"] == "true" {
+		delete(env, "NO_DEFAULT_POOLS")
+		cn.IPPools = []operator.IPPool{}
+		report.inferred("no default IP pools")
+		for name, v := range env {
+			if strings.HasPrefix(name, "CALICO_IPV4POOL_") || strings.HasPrefix(name, "CALICO_IPV6POOL_") {
+				report.unmapped("env %s=%s with NO_DEFAULT_POOLS", name, v)
+				delete(env, name)
+			}
+		}
+		return
+	}
+	delete(env, "NO_DEFAULT_POOLS")
+
+	if pool := convertPool(env, "CALICO_IPV4POOL_", report); pool != nil {
+		cn.IPPools = append(cn.IPPools, *pool)
+	}
+	if pool := convertPool(env, "CALICO_IPV6POOL_", report); pool != nil {
+		cn.IPPools = append(cn.IPPools, *pool)
+	}
+}
+
+// convertPool builds an IPPool from the calico-node environment variables with the given
+// prefix. It returns nil if no CIDR is configured.
+func convertPool(env map[string]string, prefix string, report *AdoptionReport) *operator.IPPool {
+	pop := func(suffix string) (string, bool) {
+		v, ok := env[prefix+suffix]
+		delete(env, prefix+suffix)
+		return v, ok
+	}
+
+	cidr, ok := pop("CIDR")
+	ipip, ipipSet := pop("IPIP")
+	vxlan, vxlanSet := pop("VXLAN")
+	nat, natSet := pop("NAT_OUTGOING")
+	blockSize, blockSizeSet := pop("BLOCK_SIZE")
+	nodeSelector, nodeSelectorSet := pop("NODE_SELECTOR")
+
+	if !ok {
+		if ipipSet || vxlanSet || natSet || blockSizeSet || nodeSelectorSet {
+			report.unmapped("%s* settings without %sCIDR", prefix, prefix)
+		}
+		return nil
+	}
+	if _, _, err := net.ParseCIDR(cidr); err != nil {
+		report.unmapped("env %sCIDR: invalid CIDR %q", prefix, cidr)
+		return nil
+	}
+
+	pool := &operator.IPPool{CIDR: cidr}
+	report.inferred("IP pool %s", cidr)
+
+	if ipip == "Never" {
+		ipipSet = false
+	}
+	if vxlan == "Never" {
+		vxlanSet = false
+	}
+	switch {
+	case ipipSet && vxlanSet:
+		report.unmapped("both %sIPIP and %sVXLAN are enabled", prefix, prefix)
+	case ipipSet && ipip == "Always":
+		pool.Encapsulation = operator.EncapsulationIPIP
+	case ipipSet && ipip == "CrossSubnet":
+		pool.Encapsulation = operator.EncapsulationIPIPCrossSubnet
+	case vxlanSet && vxlan == "Always":
+		pool.Encapsulation = operator.EncapsulationVXLAN
+	case vxlanSet && vxlan == "CrossSubnet":
+		pool.Encapsulation = operator.EncapsulationVXLANCrossSubnet
+	case ipipSet:
+		report.unmapped("env %sIPIP=%s", prefix, ipip)
+	case vxlanSet:
+		report.unmapped("env %sVXLAN=%s", prefix, vxlan)
+	default:
+		pool.Encapsulation = operator.EncapsulationNone
+	}
+	if pool.Encapsulation != "" {
+		report.inferred("IP pool %s encapsulation=%s", cidr, pool.Encapsulation)
+	}
+
+	if natSet {
+		switch strings.ToLower(nat) {
+		case "true":
+			pool.NATOutgoing = operator.NATOutgoingEnabled
+		case "false":
+			pool.NATOutgoing = operator.NATOutgoingDisabled
+		default:
+			report.unmapped("env %sNAT_OUTGOING=%s", prefix, nat)
+		}
+		if pool.NATOutgoing != "" {
+			report.inferred("IP pool %s natOutgoing=%s", cidr, pool.NATOutgoing)
+		}
+	}
+
+	if blockSizeSet {
+		bs, err := strconv.ParseInt(blockSize, 10, 32)
+		if err != nil {
+			report.unmapped("env %sBLOCK_SIZE=%s", prefix, blockSize)
+		} else {
+			bs32 := int32(bs)
+			pool.BlockSize = &bs32
+			report.inferred("IP pool %s blockSize=%d", cidr, bs32)
+		}
+	}
+
+	if nodeSelectorSet {
+		pool.NodeSelector = nodeSelector
+		report.inferred("IP pool %s nodeSelector=%s", cidr, nodeSelector)
+	}
+
+	return pool
+}
+
+// cniNetworkConfig is the subset of the CNI network configuration list that is inspected
+// when adopting an existing installation.
+type cniNetworkConfig struct {
+	Plugins []struct {
+		Type string `json:"type"`
+		IPAM struct {
+			Type string `json:"type"`
+		} `json:"ipam"`
+	} `json:"plugins"`
+}
+
+// convertCNIConfig verifies that the CNI configuration of the existing installation matches the
+// configuration rendered by the operator: Calico IPAM plus the portmap plugin.
+func convertCNIConfig(cmData map[string]string, report *AdoptionReport) {
+	raw, ok := cmData["cni_network_config"]
+	if !ok {
+		report.unmapped("configmap %s has no cni_network_config", calicoConfigMapName)
+		return
+	}
+
+	// The manifests template the MTU into the CNI config when it is installed, substitute
+	// a number so that the config parses.
+	raw = strings.Replace(raw, "__CNI_MTU__", "0", -1)
+
+	conf := cniNetworkConfig{}
+	if err := json.Unmarshal([]byte(raw), &conf); err != nil {
+		report.unmapped("unable to parse cni_network_config: %s", err)
+		return
+	}
+
+	for _, p := range conf.Plugins {
+		switch p.Type {
+		case "calico":
+			if p.IPAM.Type != "calico-ipam" {
+				report.unmapped("CNI IPAM plugin %q", p.IPAM.Type)
+			}
+		case "portmap":
+		default:
+			report.unmapped("CNI plugin %q", p.Type)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operator "github.com/tigera/operator/pkg/apis/operator/v1"
+)
+
+const manifestCNIConfig = `{
+  "name": "k8s-pod-network",
+  "cniVersion": "0.3.1",
+  "plugins": [
+    {
+      "type": "calico",
+      "log_level": "info",
+      "datastore_type": "kubernetes",
+      "nodename": "__KUBERNETES_NODE_NAME__",
+      "mtu": __CNI_MTU__,
+      "ipam": {
+          "type": "calico-ipam"
+      },
+      "policy": {
+          "type": "k8s"
+      },
+      "kubernetes": {
+          "kubeconfig": "__KUBECONFIG_FILEPATH__"
+      }
+    },
+    {
+      "type": "portmap",
+      "snat": true,
+      "capabilities": {"portMappings": true}
+    }
+  ]
+}`
+
+var _ = Describe("Adopting a manifest installation", func() {
+	var ds *appsv1.DaemonSet
+	var cm *v1.ConfigMap
+
+	BeforeEach(func() {
+		cm = &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: calicoConfigMapName, Namespace: kubeSystem},
+			Data: map[string]string{
+				"typha_service_name": "none",
+				"calico_backend":     "bird",
+				"veth_mtu":           "1440",
+				"cni_network_config": manifestCNIConfig,
+			},
+		}
+		ds = &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: nodeDaemonSetName, Namespace: kubeSystem},
+			Spec: appsv1.DaemonSetSpec{
+				Template: v1.PodTemplateSpec{
+					Spec: v1.PodSpec{
+						InitContainers: []v1.Container{{Name: "install-cni"}, {Name: flexVolContainerName}},
+						Containers: []v1.Container{{
+							Name: nodeContainerName,
+							Env: []v1.EnvVar{
+								{Name: "DATASTORE_TYPE", Value: "kubernetes"},
+								{Name: "WAIT_FOR_DATASTORE", Value: "true"},
+								{Name: "NODENAME", ValueFrom: &v1.EnvVarSource{FieldRef: &v1.ObjectFieldSelector{FieldPath: "spec.nodeName"}}},
+								{Name: "CALICO_NETWORKING_BACKEND", ValueFrom: &v1.EnvVarSource{ConfigMapKeyRef: &v1.ConfigMapKeySelector{
+									LocalObjectReference: v1.LocalObjectReference{Name: calicoConfigMapName}, Key: "calico_backend"}}},
+								{Name: "CLUSTER_TYPE", Value: "k8s,bgp"},
+								{Name: "IP", Value: "autodetect"},
+								{Name: "CALICO_IPV4POOL_IPIP", Value: "CrossSubnet"},
+								{Name: "FELIX_IPINIPMTU", ValueFrom: &v1.EnvVarSource{ConfigMapKeyRef: &v1.ConfigMapKeySelector{
+									LocalObjectReference: v1.LocalObjectReference{Name: calicoConfigMapName}, Key: "veth_mtu"}}},
+								{Name: "CALICO_IPV4POOL_CIDR", Value: "10.244.0.0/16"},
+								{Name: "CALICO_IPV4POOL_BLOCK_SIZE", Value: "24"},
+								{Name: "IP_AUTODETECTION_METHOD", Value: "interface=eth.*"},
+								{Name: "CALICO_DISABLE_FILE_LOGGING", Value: "true"},
+								{Name: "FELIX_DEFAULTENDPOINTTOHOSTACTION", Value: "ACCEPT"},
+								{Name: "FELIX_IPV6SUPPORT", Value: "false"},
+								{Name: "FELIX_LOGSEVERITYSCREEN", Value: "info"},
+								{Name: "FELIX_HEALTHENABLED", Value: "true"},
+							},
+						}},
+					},
+				},
+			},
+		}
+	})
+
+	It("should convert a standard manifest installation", func() {
+		spec, report := ConvertManifestInstall(ds, cm)
+		Expect(report.Unmapped).To(BeEmpty())
+		Expect(report.Compatible()).To(BeTrue())
+		Expect(report.Inferred).NotTo(BeEmpty())
+
+		var mtu int32 = 1440
+		var blockSize int32 = 24
+		flexVol := true
+		Expect(spec.CalicoNetwork).To(Equal(&operator.CalicoNetworkSpec{
+			MTU: &mtu,
+			IPPools: []operator.IPPool{{
+				CIDR:          "10.244.0.0/16",
+				Encapsulation: operator.EncapsulationIPIPCrossSubnet,
+				BlockSize:     &blockSize,
+			}},
+			NodeAddressAutodetectionV4:  &operator.NodeAddressAutodetection{Interface: "eth.*"},
+			FlexVolInitContainerEnabled: &flexVol,
+		}))
+	})
+
+	It("should report environment variables it cannot map", func() {
+		c := &ds.Spec.Template.Spec.Containers[0]
+		c.Env = append(c.Env,
+			v1.EnvVar{Name: "FELIX_BPFENABLED", Value: "true"},
+			v1.EnvVar{Name: "FELIX_LOGSEVERITYSCREEN", Value: "debug"},
+		)
+		_, report := ConvertManifestInstall(ds, cm)
+		Expect(report.Compatible()).To(BeFalse())
+		Expect(report.Unmapped).To(ConsistOf(
+			"env FELIX_BPFENABLED=true",
+			"env FELIX_LOGSEVERITYSCREEN=debug (only info is supported)",
+		))
+	})
+
+	It("should refuse host-local IPAM and unknown CNI plugins", func() {
+		cm.Data["cni_network_config"] = `{"plugins": [
+			{"type": "calico", "ipam": {"type": "host-local"}},
+			{"type": "bandwidth"}
+		]}`
+		_, report := ConvertManifestInstall(ds, cm)
+		Expect(report.Unmapped).To(ConsistOf(`CNI IPAM plugin "host-local"`, `CNI plugin "bandwidth"`))
+	})
+
+	It("should refuse differing MTUs", func() {
+		c := &ds.Spec.Template.Spec.Containers[0]
+		c.Env = append(c.Env, v1.EnvVar{Name: "FELIX_VXLANMTU", Value: "1410"})
+		spec, report := ConvertManifestInstall(ds, cm)
+		Expect(report.Unmapped).To(ConsistOf("differing MTU values for IPIP, VXLAN and veth interfaces"))
+		Expect(spec.CalicoNetwork.MTU).To(BeNil())
+	})
+
+	It("should not create IP pools when default pools are disabled", func() {
+		c := &ds.Spec.Template.Spec.Containers[0]
+		c.Env = []v1.EnvVar{
+			{Name: "IP", Value: "autodetect"},
+			{Name: "NO_DEFAULT_POOLS", Value: "true"},
+		}
+		spec, report := ConvertManifestInstall(ds, cm)
+		Expect(report.Unmapped).To(BeEmpty())
+		Expect(spec.CalicoNetwork.IPPools).NotTo(BeNil())
+		Expect(spec.CalicoNetwork.IPPools).To(BeEmpty())
+	})
+})
@@ -61,6 +61,14 @@ func (m *MockStatus) ClearDegraded() {
 	m.Called()
 }
 
+func (m *MockStatus) SetProgressing(reason, msg string) {
+	m.Called(reason, msg)
+}
+
+func (m *MockStatus) ClearProgressing() {
+	m.Called()
+}
+
 func (m *MockStatus) IsAvailable() bool {
 	return m.Called().Bool(0)
 }
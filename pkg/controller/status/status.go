@@ -64,6 +64,8 @@ type StatusManager interface {
 	RemoveCronJobs(cjs ...types.NamespacedName)
 	SetDegraded(reason, msg string)
 	ClearDegraded()
+	SetProgressing(reason, msg string)
+	ClearProgressing()
 	IsAvailable() bool
 	IsProgressing() bool
 	IsDegraded() bool
@@ -84,6 +86,10 @@ type statusManager struct {
 	explicitDegradedMsg    string
 	explicitDegradedReason string
 
+	// Track progressing state as set by external controllers.
+	explicitProgressingMsg    string
+	explicitProgressingReason string
+
 	// Keep track of currently calculated status.
 	progressing []string
 	failing     []string
@@ -120,7 +126,7 @@ func (m *statusManager) Run() {
 			}
 
 			if m.IsProgressing() {
-				m.setProgressing("Not all pods are ready", m.progressingMessage())
+				m.setProgressing(m.progressingReason(), m.progressingMessage())
 			} else {
 				m.clearProgressing()
 			}
@@ -149,6 +155,7 @@ func (m *statusManager) OnCRFound() {
 // status manager will clear its state.
 func (m *statusManager) OnCRNotFound() {
 	m.ClearDegraded()
+	m.ClearProgressing()
 	m.clearAvailable()
 	m.clearProgressing()
 	m.lock.Lock()
@@ -252,6 +259,23 @@ func (m *statusManager) ClearDegraded() {
 	m.explicitDegradedMsg = ""
 }
 
+// SetProgressing sets progressing state with the provided reason and message, for changes that the controller is
+// making outside of the objects monitored by the status manager.
+func (m *statusManager) SetProgressing(reason, msg string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.explicitProgressingReason = reason
+	m.explicitProgressingMsg = msg
+}
+
+// ClearProgressing clears progressing state set with SetProgressing.
+func (m *statusManager) ClearProgressing() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.explicitProgressingReason = ""
+	m.explicitProgressingMsg = ""
+}
+
 // IsAvailable returns true if the component is available and false otherwise.
 func (m *statusManager) IsAvailable() bool {
 	m.lock.Lock()
@@ -273,7 +297,10 @@ func (m *statusManager) IsProgressing() bool {
 		// We haven't learned our state yet. Return false.
 		return false
 	}
-	return len(m.progressing) != 0 && len(m.failing) == 0
+	if len(m.failing) != 0 {
+		return false
+	}
+	return len(m.progressing) != 0 || m.explicitProgressingReason != ""
 }
 
 // IsDegraded returns true if the component is degraded and false otherwise.
@@ -569,7 +596,25 @@ func (m *statusManager) clearAvailable() {
 func (m *statusManager) progressingMessage() string {
 	m.lock.Lock()
 	defer m.lock.Unlock()
-	return strings.Join(m.progressing, "\n")
+	msgs := []string{}
+	if m.explicitProgressingMsg != "" {
+		msgs = append(msgs, m.explicitProgressingMsg)
+	}
+	msgs = append(msgs, m.progressing...)
+	return strings.Join(msgs, "\n")
+}
+
+func (m *statusManager) progressingReason() string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	reasons := []string{}
+	if m.explicitProgressingReason != "" {
+		reasons = append(reasons, m.explicitProgressingReason)
+	}
+	if len(m.progressing) != 0 {
+		reasons = append(reasons, "Not all pods are ready")
+	}
+	return strings.Join(reasons, "; ")
 }
 
 func (m *statusManager) degradedMessage() string {
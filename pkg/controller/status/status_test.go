@@ -113,6 +113,23 @@ var _ = Describe("Status reporting tests", func() {
 		Expect(sm.degradedMessage()).To(Equal("Controller set us degraded\nThis pod has died"))
 	})
 
+	It("should report progressing when set explicitly", func() {
+		sm.failing = []string{}
+		sm.progressing = []string{}
+		Expect(sm.IsProgressing()).To(BeFalse())
+
+		sm.SetProgressing("Restoring snapshot", "Restoring 2 indices")
+		Expect(sm.IsProgressing()).To(BeTrue())
+		Expect(sm.progressingReason()).To(Equal("Restoring snapshot"))
+		sm.progressing = []string{"Some progressing status"}
+		Expect(sm.progressingReason()).To(Equal("Restoring snapshot; Not all pods are ready"))
+		Expect(sm.progressingMessage()).To(Equal("Restoring 2 indices\nSome progressing status"))
+
+		sm.progressing = []string{}
+		sm.ClearProgressing()
+		Expect(sm.IsProgressing()).To(BeFalse())
+	})
+
 	It("should contain all the NamespacesNames for all the resources added by multiple calls to Set<Resources>", func() {
 		sm.AddStatefulSets([]types.NamespacedName{{Namespace: "NS1", Name: "SS1"}})
 		sm.AddStatefulSets([]types.NamespacedName{{Namespace: "NS1", Name: "SS2"}})
@@ -23,7 +23,9 @@ import (
 	"github.com/operator-framework/operator-sdk/pkg/restmapper"
 	"github.com/tigera/operator/pkg/apis"
 	"github.com/tigera/operator/pkg/controller"
+	"github.com/tigera/operator/pkg/controller/installation"
 	"github.com/tigera/operator/pkg/controller/utils"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
@@ -90,8 +92,22 @@ func Main() {
 	}
 	log.WithValues("required", startTSEE).Info("Checking if TSEE controllers are required")
 
+	// Check that this operator can upgrade the installed product. If it cannot, only the installation controller
+	// is started, which reports the refused upgrade, so that no other controller changes the product or its data.
+	// The manager's client cannot be used before the manager is started.
+	cli, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme()})
+	if err != nil {
+		log.Error(err, "")
+		os.Exit(1)
+	}
+	addToManager := controller.AddToManager
+	if err := installation.CheckUpgradePath(ctx, cli, provider); err != nil {
+		log.Error(err, "Unsupported upgrade, only the installation controller is started")
+		addToManager = installation.Add
+	}
+
 	// Setup all Controllers
-	if err := controller.AddToManager(mgr, provider, startTSEE); err != nil {
+	if err := addToManager(mgr, provider, startTSEE); err != nil {
 		log.Error(err, "")
 		os.Exit(1)
 	}
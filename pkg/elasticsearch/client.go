@@ -0,0 +1,777 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package elasticsearch contains a minimal client for the Elasticsearch REST API, used by the operator to configure
+// clusters it does not manage through ECK.
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTimeout = 30 * time.Second
+	// idleConnTimeout is how long an idle connection to Elasticsearch is kept open.
+	idleConnTimeout = 90 * time.Second
+)
+
+// transports holds the transport shared by the clients of each endpoint, so that the clients created on every
+// reconcile reuse the connections of the previous clients instead of each opening their own.
+var transports = struct {
+	sync.Mutex
+	byEndpoint map[string]sharedTransport
+}{byEndpoint: map[string]sharedTransport{}}
+
+// sharedTransport is the transport of an endpoint and the CA bundle it trusts.
+type sharedTransport struct {
+	caPEM     string
+	transport *http.Transport
+}
+
+// transport returns the transport of the endpoint. It is replaced, and the idle connections of the previous
+// transport closed, when the CA bundle of the endpoint changes.
+func transport(endpoint string, caPEM []byte, tlsConfig *tls.Config) *http.Transport {
+	transports.Lock()
+	defer transports.Unlock()
+
+	shared, ok := transports.byEndpoint[endpoint]
+	if ok && shared.caPEM == string(caPEM) {
+		return shared.transport
+	}
+	if ok {
+		shared.transport.CloseIdleConnections()
+	}
+
+	t := &http.Transport{TLSClientConfig: tlsConfig, IdleConnTimeout: idleConnTimeout}
+	transports.byEndpoint[endpoint] = sharedTransport{caPEM: string(caPEM), transport: t}
+	return t
+}
+
+// Client sends requests to an Elasticsearch cluster using basic authentication.
+type Client struct {
+	endpoint string
+	username string
+	password string
+	http     *http.Client
+}
+
+// Role is an Elasticsearch security role.
+type Role struct {
+	Cluster []string          `json:"cluster,omitempty"`
+	Indices []IndexPrivileges `json:"indices,omitempty"`
+}
+
+// IndexPrivileges grants privileges on the indices matching Names.
+type IndexPrivileges struct {
+	Names      []string `json:"names"`
+	Privileges []string `json:"privileges"`
+}
+
+// User is an Elasticsearch native realm user.
+type User struct {
+	Password string   `json:"password,omitempty"`
+	Roles    []string `json:"roles"`
+	Enabled  bool     `json:"enabled"`
+}
+
+// RoleMapping grants roles to the users of other realms, such as single sign-on realms, that match Rules.
+type RoleMapping struct {
+	Enabled bool                   `json:"enabled"`
+	Roles   []string               `json:"roles"`
+	Rules   map[string]interface{} `json:"rules"`
+}
+
+// ILMPolicy is an index lifecycle management policy.
+type ILMPolicy struct {
+	Phases map[string]ILMPhase `json:"phases"`
+}
+
+// ILMPhase is a phase of an index lifecycle management policy. An index enters the phase when it is at least MinAge
+// old, counted from its rollover if it was rolled over.
+type ILMPhase struct {
+	MinAge  string                 `json:"min_age,omitempty"`
+	Actions map[string]interface{} `json:"actions"`
+}
+
+// IndexTemplate defines the settings applied to new indices matching IndexPatterns.
+type IndexTemplate struct {
+	IndexPatterns []string               `json:"index_patterns"`
+	Order         int                    `json:"order"`
+	Settings      map[string]interface{} `json:"settings,omitempty"`
+}
+
+// IndexLifecycle is the index lifecycle management state of an index.
+type IndexLifecycle struct {
+	Index      string       `json:"index"`
+	Managed    bool         `json:"managed"`
+	Policy     string       `json:"policy,omitempty"`
+	Phase      string       `json:"phase,omitempty"`
+	Action     string       `json:"action,omitempty"`
+	Step       string       `json:"step,omitempty"`
+	FailedStep string       `json:"failed_step,omitempty"`
+	StepInfo   *ILMStepInfo `json:"step_info,omitempty"`
+	// LifecycleDateMillis is when the index was rolled over, or created if it has not been rolled over, in
+	// milliseconds since the epoch.
+	LifecycleDateMillis int64 `json:"lifecycle_date_millis,omitempty"`
+}
+
+// ILMStepInfo describes the state of the current step, including the cause of a failed step.
+type ILMStepInfo struct {
+	Type   string `json:"type,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// ILMErrorStep is the step of an index whose lifecycle management failed.
+const ILMErrorStep = "ERROR"
+
+// SnapshotRepository is a location that snapshots are stored in, such as a bucket or a shared file system.
+type SnapshotRepository struct {
+	Type     string            `json:"type"`
+	Settings map[string]string `json:"settings"`
+}
+
+// SLMPolicy is a snapshot lifecycle management policy. Snapshots of the indices in Config are taken on Schedule and
+// deleted according to Retention.
+type SLMPolicy struct {
+	Schedule   string                 `json:"schedule"`
+	Name       string                 `json:"name"`
+	Repository string                 `json:"repository"`
+	Config     map[string]interface{} `json:"config,omitempty"`
+	Retention  *SLMRetention          `json:"retention,omitempty"`
+}
+
+// SLMRetention defines when the snapshots taken by a snapshot lifecycle management policy are deleted.
+type SLMRetention struct {
+	ExpireAfter string `json:"expire_after,omitempty"`
+	MinCount    *int32 `json:"min_count,omitempty"`
+	MaxCount    *int32 `json:"max_count,omitempty"`
+}
+
+// SLMPolicyStatus holds the last successful and the last failed snapshot taken by a snapshot lifecycle management
+// policy.
+type SLMPolicyStatus struct {
+	LastSuccess *SLMInvocation `json:"last_success,omitempty"`
+	LastFailure *SLMInvocation `json:"last_failure,omitempty"`
+}
+
+// SLMInvocation is a snapshot taken by a snapshot lifecycle management policy. Time is in milliseconds since the
+// epoch.
+type SLMInvocation struct {
+	SnapshotName string `json:"snapshot_name"`
+	Time         int64  `json:"time"`
+	Details      string `json:"details,omitempty"`
+}
+
+// SnapshotRestore selects the indices restored from a snapshot, how they are renamed and which of their settings
+// are not restored.
+type SnapshotRestore struct {
+	Indices             string   `json:"indices,omitempty"`
+	IncludeGlobalState  bool     `json:"include_global_state"`
+	RenamePattern       string   `json:"rename_pattern,omitempty"`
+	RenameReplacement   string   `json:"rename_replacement,omitempty"`
+	IgnoreIndexSettings []string `json:"ignore_index_settings,omitempty"`
+}
+
+// Snapshot selects the indices included in a snapshot taken on demand. Metadata is stored with the snapshot.
+type Snapshot struct {
+	Indices            string                 `json:"indices"`
+	IncludeGlobalState bool                   `json:"include_global_state"`
+	Metadata           map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// SnapshotInfo describes a snapshot in a repository.
+type SnapshotInfo struct {
+	Snapshot string                 `json:"snapshot"`
+	State    string                 `json:"state"`
+	Reason   string                 `json:"reason,omitempty"`
+	Indices  []string               `json:"indices"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	// StartTimeMillis is when the snapshot was started, in milliseconds since the epoch.
+	StartTimeMillis int64 `json:"start_time_in_millis,omitempty"`
+}
+
+// States of a snapshot.
+const (
+	SnapshotInProgress = "IN_PROGRESS"
+	SnapshotSuccess    = "SUCCESS"
+)
+
+// ClusterHealth is the health of the cluster and the number of its shards in each state.
+type ClusterHealth struct {
+	Status             string `json:"status"`
+	NumberOfNodes      int32  `json:"number_of_nodes"`
+	NumberOfDataNodes  int32  `json:"number_of_data_nodes"`
+	UnassignedShards   int32  `json:"unassigned_shards"`
+	InitializingShards int32  `json:"initializing_shards"`
+	RelocatingShards   int32  `json:"relocating_shards"`
+}
+
+// NodeDisk is the disk usage of a data node, in bytes.
+type NodeDisk struct {
+	Node      string
+	Used      int64
+	Available int64
+	Total     int64
+	Percent   int32
+}
+
+// IndexSize is the disk space used by an index, including its replicas, in bytes.
+type IndexSize struct {
+	Index string
+	Size  int64
+	// CreationDate is when the index was created, in milliseconds since the epoch.
+	CreationDate int64
+}
+
+// DiskWatermarks are the disk usage thresholds of the disk based shard allocator. Each is either a percentage or ratio
+// of the disk used, such as "85%" or "0.85", or the disk space that must remain free, such as "500mb".
+type DiskWatermarks struct {
+	Low        string
+	High       string
+	FloodStage string
+}
+
+// Names of the cluster settings holding the disk watermarks.
+const (
+	DiskWatermarkLowSetting        = "cluster.routing.allocation.disk.watermark.low"
+	DiskWatermarkHighSetting       = "cluster.routing.allocation.disk.watermark.high"
+	DiskWatermarkFloodStageSetting = "cluster.routing.allocation.disk.watermark.flood_stage"
+)
+
+// AllocationExcludeNameSetting is the cluster setting holding the names of the nodes that shards are moved off and are
+// not allocated to.
+const AllocationExcludeNameSetting = "cluster.routing.allocation.exclude._name"
+
+// Error is returned when Elasticsearch responds with an unexpected status code.
+type Error struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Body       string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s %s returned %d: %s", e.Method, e.Path, e.StatusCode, e.Body)
+}
+
+// NewClient returns a client for the Elasticsearch cluster at endpoint. If caPEM is not empty, the server certificate
+// must be signed by one of the certificates in it, otherwise the system roots are used. The clients of an endpoint
+// share their connections.
+func NewClient(endpoint, username, password string, caPEM []byte) (*Client, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return nil, fmt.Errorf("unsupported scheme %q in Elasticsearch endpoint %s", u.Scheme, endpoint)
+	}
+
+	tlsConfig := &tls.Config{}
+	if len(caPEM) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no valid certificates found in the Elasticsearch CA bundle")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	endpoint = strings.TrimSuffix(endpoint, "/")
+	return &Client{
+		endpoint: endpoint,
+		username: username,
+		password: password,
+		http: &http.Client{
+			Timeout:   defaultTimeout,
+			Transport: transport(endpoint, caPEM, tlsConfig),
+		},
+	}, nil
+}
+
+// PutRole creates or updates the role with the given name.
+func (c *Client) PutRole(ctx context.Context, name string, role Role) error {
+	return c.Do(ctx, http.MethodPut, "/_security/role/"+url.PathEscape(name), role, nil)
+}
+
+// PutUser creates or updates the user with the given name.
+func (c *Client) PutUser(ctx context.Context, name string, user User) error {
+	return c.Do(ctx, http.MethodPut, "/_security/user/"+url.PathEscape(name), user, nil)
+}
+
+// DisableUser disables the user with the given name, if it exists, so that its credentials are no longer accepted.
+func (c *Client) DisableUser(ctx context.Context, name string) error {
+	err := c.Do(ctx, http.MethodPut, "/_security/user/"+url.PathEscape(name)+"/_disable", nil, nil)
+	if IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// PutRoleMapping creates or updates the role mapping with the given name.
+func (c *Client) PutRoleMapping(ctx context.Context, name string, mapping RoleMapping) error {
+	return c.Do(ctx, http.MethodPut, "/_security/role_mapping/"+url.PathEscape(name), mapping, nil)
+}
+
+// DeleteRoleMapping deletes the role mapping with the given name, if it exists.
+func (c *Client) DeleteRoleMapping(ctx context.Context, name string) error {
+	err := c.Do(ctx, http.MethodDelete, "/_security/role_mapping/"+url.PathEscape(name), nil, nil)
+	if IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// PutILMPolicy creates or updates the index lifecycle management policy with the given name.
+func (c *Client) PutILMPolicy(ctx context.Context, name string, policy ILMPolicy) error {
+	body := map[string]interface{}{"policy": policy}
+	return c.Do(ctx, http.MethodPut, "/_ilm/policy/"+url.PathEscape(name), body, nil)
+}
+
+// PutIndexTemplate creates or updates the index template with the given name.
+func (c *Client) PutIndexTemplate(ctx context.Context, name string, template IndexTemplate) error {
+	return c.Do(ctx, http.MethodPut, "/_template/"+url.PathEscape(name), template, nil)
+}
+
+// AliasExists returns true if the index alias exists.
+func (c *Client) AliasExists(ctx context.Context, alias string) (bool, error) {
+	err := c.Do(ctx, http.MethodHead, "/_alias/"+url.PathEscape(alias), nil, nil)
+	if IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// CreateWriteIndex creates the index with the given name as the write index of alias.
+func (c *Client) CreateWriteIndex(ctx context.Context, index, alias string) error {
+	body := map[string]interface{}{
+		"aliases": map[string]interface{}{
+			alias: map[string]interface{}{"is_write_index": true},
+		},
+	}
+	return c.Do(ctx, http.MethodPut, "/"+url.PathEscape(index), body, nil)
+}
+
+// ExplainLifecycle returns the index lifecycle management state of the indices matching pattern, by index name.
+func (c *Client) ExplainLifecycle(ctx context.Context, pattern string) (map[string]IndexLifecycle, error) {
+	var resp struct {
+		Indices map[string]IndexLifecycle `json:"indices"`
+	}
+	if err := c.Do(ctx, http.MethodGet, "/"+url.PathEscape(pattern)+"/_ilm/explain", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Indices, nil
+}
+
+// Version returns the version of Elasticsearch running on the cluster.
+func (c *Client) Version(ctx context.Context) (string, error) {
+	var resp struct {
+		Version struct {
+			Number string `json:"number"`
+		} `json:"version"`
+	}
+	if err := c.Do(ctx, http.MethodGet, "/", nil, &resp); err != nil {
+		return "", err
+	}
+	return resp.Version.Number, nil
+}
+
+// PutSnapshotRepository registers or updates the snapshot repository with the given name.
+func (c *Client) PutSnapshotRepository(ctx context.Context, name string, repository SnapshotRepository) error {
+	return c.Do(ctx, http.MethodPut, "/_snapshot/"+url.PathEscape(name), repository, nil)
+}
+
+// PutSLMPolicy creates or updates the snapshot lifecycle management policy with the given name.
+func (c *Client) PutSLMPolicy(ctx context.Context, name string, policy SLMPolicy) error {
+	return c.Do(ctx, http.MethodPut, "/_slm/policy/"+url.PathEscape(name), policy, nil)
+}
+
+// GetSLMPolicyStatus returns the last successful and failed snapshots of the snapshot lifecycle management policy.
+func (c *Client) GetSLMPolicyStatus(ctx context.Context, name string) (*SLMPolicyStatus, error) {
+	resp := map[string]*SLMPolicyStatus{}
+	if err := c.Do(ctx, http.MethodGet, "/_slm/policy/"+url.PathEscape(name), nil, &resp); err != nil {
+		return nil, err
+	}
+	if resp[name] == nil {
+		return &SLMPolicyStatus{}, nil
+	}
+	return resp[name], nil
+}
+
+// RestoreSnapshot starts restoring indices from the snapshot in the repository. It does not wait for the restore to
+// complete.
+func (c *Client) RestoreSnapshot(ctx context.Context, repository, snapshot string, restore SnapshotRestore) error {
+	path := fmt.Sprintf("/_snapshot/%s/%s/_restore", url.PathEscape(repository), url.PathEscape(snapshot))
+	return c.Do(ctx, http.MethodPost, path, restore, nil)
+}
+
+// CreateSnapshot starts taking a snapshot with the given name into the repository. It does not wait for the snapshot
+// to complete.
+func (c *Client) CreateSnapshot(ctx context.Context, repository, name string, snapshot Snapshot) error {
+	path := fmt.Sprintf("/_snapshot/%s/%s", url.PathEscape(repository), url.PathEscape(name))
+	return c.Do(ctx, http.MethodPut, path, snapshot, nil)
+}
+
+// Snapshots returns the snapshots in the repository whose names match pattern.
+func (c *Client) Snapshots(ctx context.Context, repository, pattern string) ([]SnapshotInfo, error) {
+	var resp struct {
+		Snapshots []SnapshotInfo `json:"snapshots"`
+	}
+	path := fmt.Sprintf("/_snapshot/%s/%s?ignore_unavailable=true", url.PathEscape(repository), url.PathEscape(pattern))
+	if err := c.Do(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Snapshots, nil
+}
+
+// DeleteSnapshot deletes the snapshot from the repository, if it exists.
+func (c *Client) DeleteSnapshot(ctx context.Context, repository, name string) error {
+	path := fmt.Sprintf("/_snapshot/%s/%s", url.PathEscape(repository), url.PathEscape(name))
+	err := c.Do(ctx, http.MethodDelete, path, nil, nil)
+	if IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// IndexCreationDate returns when the index was created, in milliseconds since the epoch.
+func (c *Client) IndexCreationDate(ctx context.Context, index string) (int64, error) {
+	resp := map[string]struct {
+		Settings struct {
+			Index struct {
+				CreationDate string `json:"creation_date"`
+			} `json:"index"`
+		} `json:"settings"`
+	}{}
+	if err := c.Do(ctx, http.MethodGet, "/"+url.PathEscape(index)+"/_settings/index.creation_date", nil, &resp); err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(resp[index].Settings.Index.CreationDate, 10, 64)
+}
+
+// DeleteIndex deletes the index, if it exists.
+func (c *Client) DeleteIndex(ctx context.Context, index string) error {
+	err := c.Do(ctx, http.MethodDelete, "/"+url.PathEscape(index), nil, nil)
+	if IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// ExcludedNodes returns the names of the nodes that shards are not allocated to.
+func (c *Client) ExcludedNodes(ctx context.Context) ([]string, error) {
+	var resp struct {
+		Persistent map[string]interface{} `json:"persistent"`
+	}
+	if err := c.Do(ctx, http.MethodGet, "/_cluster/settings?flat_settings=true", nil, &resp); err != nil {
+		return nil, err
+	}
+
+	value, _ := resp.Persistent[AllocationExcludeNameSetting].(string)
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// ExcludeNodes moves the shards off the nodes with the given names and stops shards from being allocated to them. The
+// nodes replace those excluded before, no nodes are excluded if names is empty.
+func (c *Client) ExcludeNodes(ctx context.Context, names []string) error {
+	var value interface{}
+	if len(names) > 0 {
+		value = strings.Join(names, ",")
+	}
+	settings := map[string]map[string]interface{}{
+		"persistent": {AllocationExcludeNameSetting: value},
+	}
+	return c.Do(ctx, http.MethodPut, "/_cluster/settings", settings, nil)
+}
+
+// NodeShards returns the number of shards allocated to each data node, by node name.
+func (c *Client) NodeShards(ctx context.Context) (map[string]int, error) {
+	// The cat APIs return numbers as strings.
+	var resp []struct {
+		Node   string `json:"node"`
+		Shards string `json:"shards"`
+	}
+	if err := c.Do(ctx, http.MethodGet, "/_cat/allocation?format=json&h=node,shards", nil, &resp); err != nil {
+		return nil, err
+	}
+
+	shards := map[string]int{}
+	for _, allocation := range resp {
+		// Shards that can't be allocated are reported for the UNASSIGNED node.
+		if allocation.Node == "UNASSIGNED" {
+			continue
+		}
+		n, err := strconv.Atoi(allocation.Shards)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number of shards %q for node %s", allocation.Shards, allocation.Node)
+		}
+		shards[allocation.Node] = n
+	}
+	return shards, nil
+}
+
+// NodeNames returns the names of the nodes in the cluster.
+func (c *Client) NodeNames(ctx context.Context) ([]string, error) {
+	var resp []struct {
+		Name string `json:"name"`
+	}
+	if err := c.Do(ctx, http.MethodGet, "/_cat/nodes?format=json&h=name", nil, &resp); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, node := range resp {
+		names = append(names, node.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// ClusterHealth returns the health of the cluster.
+func (c *Client) ClusterHealth(ctx context.Context) (*ClusterHealth, error) {
+	health := &ClusterHealth{}
+	if err := c.Do(ctx, http.MethodGet, "/_cluster/health", nil, health); err != nil {
+		return nil, err
+	}
+	return health, nil
+}
+
+// IndicesHealth returns the health of each index, which is green, yellow or red. An index is red until all of its
+// primary shards have been allocated, including the shards being restored from a snapshot.
+func (c *Client) IndicesHealth(ctx context.Context) (map[string]string, error) {
+	var resp struct {
+		Indices map[string]struct {
+			Status string `json:"status"`
+		} `json:"indices"`
+	}
+	if err := c.Do(ctx, http.MethodGet, "/_cluster/health?level=indices", nil, &resp); err != nil {
+		return nil, err
+	}
+
+	health := make(map[string]string, len(resp.Indices))
+	for index, h := range resp.Indices {
+		health[index] = h.Status
+	}
+	return health, nil
+}
+
+// NodeDiskUsage returns the disk usage of each data node, sorted by node name.
+func (c *Client) NodeDiskUsage(ctx context.Context) ([]NodeDisk, error) {
+	// The cat APIs return numbers as strings, and null for the UNASSIGNED node.
+	var resp []struct {
+		Node      string  `json:"node"`
+		Used      *string `json:"disk.used"`
+		Available *string `json:"disk.avail"`
+		Total     *string `json:"disk.total"`
+		Percent   *string `json:"disk.percent"`
+	}
+	if err := c.Do(ctx, http.MethodGet, "/_cat/allocation?format=json&bytes=b&h=node,disk.used,disk.avail,disk.total,disk.percent", nil, &resp); err != nil {
+		return nil, err
+	}
+
+	var disks []NodeDisk
+	for _, allocation := range resp {
+		if allocation.Node == "UNASSIGNED" || allocation.Total == nil {
+			continue
+		}
+		disk := NodeDisk{Node: allocation.Node}
+		var err error
+		if disk.Used, err = parseCatNumber(allocation.Used); err != nil {
+			return nil, fmt.Errorf("invalid disk usage for node %s: %s", allocation.Node, err)
+		}
+		if disk.Available, err = parseCatNumber(allocation.Available); err != nil {
+			return nil, fmt.Errorf("invalid available disk for node %s: %s", allocation.Node, err)
+		}
+		if disk.Total, err = parseCatNumber(allocation.Total); err != nil {
+			return nil, fmt.Errorf("invalid disk size for node %s: %s", allocation.Node, err)
+		}
+		percent, err := parseCatNumber(allocation.Percent)
+		if err != nil {
+			return nil, fmt.Errorf("invalid disk percent for node %s: %s", allocation.Node, err)
+		}
+		disk.Percent = int32(percent)
+		disks = append(disks, disk)
+	}
+	sort.Slice(disks, func(i, j int) bool { return disks[i].Node < disks[j].Node })
+	return disks, nil
+}
+
+// IndexSizes returns the disk space used by the indices matching the pattern, sorted by creation date, oldest first.
+func (c *Client) IndexSizes(ctx context.Context, pattern string) ([]IndexSize, error) {
+	// The size of an index whose primary shards are not allocated is null.
+	var resp []struct {
+		Index        string  `json:"index"`
+		Size         *string `json:"store.size"`
+		CreationDate *string `json:"creation.date"`
+	}
+	path := "/_cat/indices/" + url.PathEscape(pattern) + "?format=json&bytes=b&h=index,store.size,creation.date"
+	if err := c.Do(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, err
+	}
+
+	var sizes []IndexSize
+	for _, index := range resp {
+		size := IndexSize{Index: index.Index}
+		var err error
+		if size.Size, err = parseCatNumber(index.Size); err != nil {
+			return nil, fmt.Errorf("invalid size for index %s: %s", index.Index, err)
+		}
+		if size.CreationDate, err = parseCatNumber(index.CreationDate); err != nil {
+			return nil, fmt.Errorf("invalid creation date for index %s: %s", index.Index, err)
+		}
+		sizes = append(sizes, size)
+	}
+	sort.Slice(sizes, func(i, j int) bool {
+		if sizes[i].CreationDate != sizes[j].CreationDate {
+			return sizes[i].CreationDate < sizes[j].CreationDate
+		}
+		return sizes[i].Index < sizes[j].Index
+	})
+	return sizes, nil
+}
+
+// WriteIndices returns the indices that the aliases matching the pattern write to. The only index of an alias is its
+// write index unless it is explicitly not one.
+func (c *Client) WriteIndices(ctx context.Context, pattern string) (map[string]bool, error) {
+	var resp []struct {
+		Alias        string `json:"alias"`
+		Index        string `json:"index"`
+		IsWriteIndex string `json:"is_write_index"`
+	}
+	path := "/_cat/aliases/" + url.PathEscape(pattern) + "?format=json&h=alias,index,is_write_index"
+	if err := c.Do(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int{}
+	for _, alias := range resp {
+		counts[alias.Alias]++
+	}
+	indices := map[string]bool{}
+	for _, alias := range resp {
+		if alias.IsWriteIndex == "true" || (alias.IsWriteIndex != "false" && counts[alias.Alias] == 1) {
+			indices[alias.Index] = true
+		}
+	}
+	return indices, nil
+}
+
+// parseCatNumber parses a number returned by a cat API, which is zero if value is nil.
+func parseCatNumber(value *string) (int64, error) {
+	if value == nil {
+		return 0, nil
+	}
+	return strconv.ParseInt(*value, 10, 64)
+}
+
+// DiskWatermarks returns the disk watermarks in effect, which are the transient, persistent or default settings, in
+// that order of precedence.
+func (c *Client) DiskWatermarks(ctx context.Context) (*DiskWatermarks, error) {
+	var resp struct {
+		Persistent map[string]interface{} `json:"persistent"`
+		Transient  map[string]interface{} `json:"transient"`
+		Defaults   map[string]interface{} `json:"defaults"`
+	}
+	if err := c.Do(ctx, http.MethodGet, "/_cluster/settings?include_defaults=true&flat_settings=true", nil, &resp); err != nil {
+		return nil, err
+	}
+
+	setting := func(name string) string {
+		for _, settings := range []map[string]interface{}{resp.Transient, resp.Persistent, resp.Defaults} {
+			if value, ok := settings[name].(string); ok && value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+	return &DiskWatermarks{
+		Low:        setting(DiskWatermarkLowSetting),
+		High:       setting(DiskWatermarkHighSetting),
+		FloodStage: setting(DiskWatermarkFloodStageSetting),
+	}, nil
+}
+
+// Do sends a request with body, if not nil, encoded as JSON and decodes the response into out, if not nil. A response
+// with a status code outside of the 2xx range is returned as an *Error.
+func (c *Client) Do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader *bytes.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(b)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequest(method, c.endpoint+path, reader)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.SetBasicAuth(c.username, c.password)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &Error{Method: method, Path: path, StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+
+	if out != nil {
+		return json.Unmarshal(respBody, out)
+	}
+	return nil
+}
+
+// IsNotFound returns true if err is an *Error for a 404 response.
+func IsNotFound(err error) bool {
+	e, ok := err.(*Error)
+	return ok && e.StatusCode == http.StatusNotFound
+}
+
+// IsConcurrentSnapshot returns true if err is an *Error for a snapshot that could not start because another snapshot
+// is being taken or deleted.
+func IsConcurrentSnapshot(err error) bool {
+	e, ok := err.(*Error)
+	return ok && strings.Contains(e.Body, "concurrent_snapshot_execution_exception")
+}
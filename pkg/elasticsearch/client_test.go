@@ -0,0 +1,440 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elasticsearch_test
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/tigera/operator/pkg/elasticsearch"
+)
+
+type request struct {
+	method   string
+	path     string
+	username string
+	password string
+	body     map[string]interface{}
+}
+
+var _ = Describe("Elasticsearch client", func() {
+	var server *httptest.Server
+	var requests []request
+	var status int
+	var caPEM []byte
+
+	BeforeEach(func() {
+		requests = nil
+		status = http.StatusOK
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			u, p, _ := r.BasicAuth()
+			req := request{method: r.Method, path: r.URL.Path, username: u, password: p}
+			b, _ := ioutil.ReadAll(r.Body)
+			if len(b) > 0 {
+				Expect(json.Unmarshal(b, &req.body)).NotTo(HaveOccurred())
+			}
+			requests = append(requests, req)
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{}`))
+		}))
+		caPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should create roles and users with basic auth", func() {
+		c, err := elasticsearch.NewClient(server.URL, "elastic", "secret", caPEM)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(c.PutRole(context.Background(), "fluentd", elasticsearch.Role{
+			Cluster: []string{"monitor"},
+			Indices: []elasticsearch.IndexPrivileges{{Names: []string{"flows.*"}, Privileges: []string{"create_index", "write"}}},
+		})).NotTo(HaveOccurred())
+		Expect(c.PutUser(context.Background(), "fluentd", elasticsearch.User{Password: "pw", Roles: []string{"fluentd"}})).NotTo(HaveOccurred())
+
+		Expect(requests).To(HaveLen(2))
+		Expect(requests[0].method).To(Equal(http.MethodPut))
+		Expect(requests[0].path).To(Equal("/_security/role/fluentd"))
+		Expect(requests[0].username).To(Equal("elastic"))
+		Expect(requests[0].password).To(Equal("secret"))
+		Expect(requests[0].body).To(HaveKeyWithValue("cluster", ConsistOf("monitor")))
+		Expect(requests[1].path).To(Equal("/_security/user/fluentd"))
+		Expect(requests[1].body).To(HaveKeyWithValue("password", "pw"))
+		Expect(requests[1].body).To(HaveKeyWithValue("roles", ConsistOf("fluentd")))
+	})
+
+	It("should disable users and ignore missing ones", func() {
+		c, err := elasticsearch.NewClient(server.URL, "elastic", "secret", caPEM)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(c.DisableUser(context.Background(), "fluentd")).NotTo(HaveOccurred())
+		Expect(requests[0].method).To(Equal(http.MethodPut))
+		Expect(requests[0].path).To(Equal("/_security/user/fluentd/_disable"))
+
+		status = http.StatusNotFound
+		Expect(c.DisableUser(context.Background(), "fluentd")).NotTo(HaveOccurred())
+	})
+
+	It("should put role mappings and ignore missing ones when deleting", func() {
+		c, err := elasticsearch.NewClient(server.URL, "elastic", "secret", caPEM)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(c.PutRoleMapping(context.Background(), "kibana-sso", elasticsearch.RoleMapping{
+			Enabled: true,
+			Roles:   []string{"kibana_user"},
+			Rules:   map[string]interface{}{"field": map[string]interface{}{"realm.name": "oidc1"}},
+		})).NotTo(HaveOccurred())
+		Expect(requests[0].path).To(Equal("/_security/role_mapping/kibana-sso"))
+		Expect(requests[0].body).To(HaveKeyWithValue("roles", ConsistOf("kibana_user")))
+
+		status = http.StatusNotFound
+		Expect(c.DeleteRoleMapping(context.Background(), "kibana-sso")).NotTo(HaveOccurred())
+		Expect(requests[1].method).To(Equal(http.MethodDelete))
+	})
+
+	It("should put ILM policies wrapped in a policy object", func() {
+		c, err := elasticsearch.NewClient(server.URL, "elastic", "secret", caPEM)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(c.PutILMPolicy(context.Background(), "flows", elasticsearch.ILMPolicy{
+			Phases: map[string]elasticsearch.ILMPhase{
+				"delete": {MinAge: "8d", Actions: map[string]interface{}{"delete": map[string]interface{}{}}},
+			},
+		})).NotTo(HaveOccurred())
+
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].path).To(Equal("/_ilm/policy/flows"))
+		Expect(requests[0].body).To(HaveKey("policy"))
+		policy := requests[0].body["policy"].(map[string]interface{})
+		Expect(policy["phases"]).To(HaveKeyWithValue("delete", HaveKeyWithValue("min_age", "8d")))
+	})
+
+	It("should report whether an alias exists", func() {
+		c, err := elasticsearch.NewClient(server.URL, "elastic", "secret", caPEM)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(c.AliasExists(context.Background(), "flows.")).To(BeTrue())
+		Expect(requests[0].method).To(Equal(http.MethodHead))
+
+		status = http.StatusNotFound
+		Expect(c.AliasExists(context.Background(), "flows.")).To(BeFalse())
+	})
+
+	It("should return an error for a non 2xx response", func() {
+		status = http.StatusNotFound
+		c, err := elasticsearch.NewClient(server.URL, "elastic", "secret", caPEM)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = c.PutUser(context.Background(), "fluentd", elasticsearch.User{})
+		Expect(err).To(HaveOccurred())
+		Expect(elasticsearch.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should fail to connect to a server not signed by the CA", func() {
+		c, err := elasticsearch.NewClient(server.URL, "elastic", "secret", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(c.PutUser(context.Background(), "fluentd", elasticsearch.User{})).To(HaveOccurred())
+		Expect(requests).To(BeEmpty())
+	})
+
+	It("should reject an invalid CA bundle", func() {
+		_, err := elasticsearch.NewClient(server.URL, "elastic", "secret", []byte("not a cert"))
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Elasticsearch client lifecycle explain", func() {
+	It("should decode the lifecycle state of each index", func() {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.URL.Path).To(Equal("/flows.*/_ilm/explain"))
+			_, _ = w.Write([]byte(`{"indices": {"flows.1": {"index": "flows.1", "managed": true, "policy": "flows", "step": "ERROR",
+				"failed_step": "check-rollover-ready", "step_info": {"type": "illegal_argument_exception", "reason": "alias not set"}}}}`))
+		}))
+		defer server.Close()
+		caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+		c, err := elasticsearch.NewClient(server.URL, "elastic", "secret", caPEM)
+		Expect(err).NotTo(HaveOccurred())
+
+		indices, err := c.ExplainLifecycle(context.Background(), "flows.*")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(indices).To(HaveKey("flows.1"))
+		Expect(indices["flows.1"].Step).To(Equal(elasticsearch.ILMErrorStep))
+		Expect(indices["flows.1"].FailedStep).To(Equal("check-rollover-ready"))
+		Expect(indices["flows.1"].StepInfo.Reason).To(Equal("alias not set"))
+	})
+
+	It("should reuse the connections of the previous clients of the endpoint", func() {
+		var conns int32
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"version": {"number": "7.3.2"}}`))
+		}))
+		server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+			if state == http.StateNew {
+				atomic.AddInt32(&conns, 1)
+			}
+		}
+		server.StartTLS()
+		defer server.Close()
+		caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+		for i := 0; i < 3; i++ {
+			c, err := elasticsearch.NewClient(server.URL, "elastic", "secret", caPEM)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = c.Version(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(atomic.LoadInt32(&conns)).To(Equal(int32(1)))
+	})
+})
+
+var _ = Describe("Elasticsearch client snapshots", func() {
+	var server *httptest.Server
+	var response string
+	var c *elasticsearch.Client
+
+	BeforeEach(func() {
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(response))
+		}))
+		caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+		var err error
+		c, err = elasticsearch.NewClient(server.URL, "elastic", "secret", caPEM)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should decode the last success and failure of an SLM policy", func() {
+		response = `{"nightly": {"version": 1, "policy": {}, "last_success": {"snapshot_name": "nightly-1", "time": 1000},
+			"last_failure": {"snapshot_name": "nightly-2", "time": 2000, "details": "repository missing"}}}`
+
+		status, err := c.GetSLMPolicyStatus(context.Background(), "nightly")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.LastSuccess.SnapshotName).To(Equal("nightly-1"))
+		Expect(status.LastFailure.Time).To(Equal(int64(2000)))
+		Expect(status.LastFailure.Details).To(Equal("repository missing"))
+	})
+
+	It("should decode the health of each index", func() {
+		response = `{"status": "red", "indices": {"restored": {"status": "red"}, "flows": {"status": "green"}}}`
+
+		health, err := c.IndicesHealth(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(health).To(Equal(map[string]string{"restored": "red", "flows": "green"}))
+	})
+
+	It("should decode the snapshots and their metadata", func() {
+		response = `{"snapshots": [{"snapshot": "archive-flows", "state": "SUCCESS", "indices": ["flows"],
+			"start_time_in_millis": 1000, "metadata": {"log_type": "flows", "end_time": 2000}}]}`
+
+		snapshots, err := c.Snapshots(context.Background(), "repo", "archive-*")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(snapshots).To(HaveLen(1))
+		Expect(snapshots[0].Snapshot).To(Equal("archive-flows"))
+		Expect(snapshots[0].State).To(Equal(elasticsearch.SnapshotSuccess))
+		Expect(snapshots[0].Indices).To(Equal([]string{"flows"}))
+		Expect(snapshots[0].StartTimeMillis).To(Equal(int64(1000)))
+		Expect(snapshots[0].Metadata).To(HaveKeyWithValue("end_time", float64(2000)))
+	})
+
+	It("should decode the creation date of an index", func() {
+		response = `{"flows": {"settings": {"index": {"creation_date": "1577836800000"}}}}`
+
+		created, err := c.IndexCreationDate(context.Background(), "flows")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created).To(Equal(int64(1577836800000)))
+	})
+
+	It("should recognize a snapshot that conflicts with a running snapshot", func() {
+		Expect(elasticsearch.IsConcurrentSnapshot(&elasticsearch.Error{StatusCode: 503,
+			Body: `{"error": {"type": "concurrent_snapshot_execution_exception"}}`})).To(BeTrue())
+		Expect(elasticsearch.IsConcurrentSnapshot(&elasticsearch.Error{StatusCode: 404})).To(BeFalse())
+	})
+})
+
+var _ = Describe("Elasticsearch client allocation", func() {
+	var server *httptest.Server
+	var response string
+	var body map[string]interface{}
+	var c *elasticsearch.Client
+
+	BeforeEach(func() {
+		body = nil
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, _ := ioutil.ReadAll(r.Body)
+			if len(b) > 0 {
+				Expect(json.Unmarshal(b, &body)).NotTo(HaveOccurred())
+			}
+			_, _ = w.Write([]byte(response))
+		}))
+		caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+		var err error
+		c, err = elasticsearch.NewClient(server.URL, "elastic", "secret", caPEM)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should exclude nodes and clear the exclusion", func() {
+		response = `{}`
+		Expect(c.ExcludeNodes(context.Background(), []string{"es-0", "es-1"})).NotTo(HaveOccurred())
+		Expect(body).To(Equal(map[string]interface{}{
+			"persistent": map[string]interface{}{elasticsearch.AllocationExcludeNameSetting: "es-0,es-1"},
+		}))
+
+		Expect(c.ExcludeNodes(context.Background(), nil)).NotTo(HaveOccurred())
+		Expect(body).To(Equal(map[string]interface{}{
+			"persistent": map[string]interface{}{elasticsearch.AllocationExcludeNameSetting: nil},
+		}))
+	})
+
+	It("should decode the excluded nodes", func() {
+		response = `{"persistent": {"cluster.routing.allocation.exclude._name": "es-0, es-1"}, "transient": {}}`
+		names, err := c.ExcludedNodes(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names).To(Equal([]string{"es-0", "es-1"}))
+
+		response = `{"persistent": {}, "transient": {}}`
+		names, err = c.ExcludedNodes(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names).To(BeEmpty())
+	})
+
+	It("should decode the shards of each node", func() {
+		response = `[{"node": "es-0", "shards": "12"}, {"node": "es-1", "shards": "0"}, {"node": "UNASSIGNED", "shards": "3"}]`
+		shards, err := c.NodeShards(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(shards).To(Equal(map[string]int{"es-0": 12, "es-1": 0}))
+	})
+})
+
+var _ = Describe("Elasticsearch client health", func() {
+	var server *httptest.Server
+	var responses map[string]string
+	var c *elasticsearch.Client
+
+	BeforeEach(func() {
+		responses = map[string]string{}
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(responses[r.URL.Path]))
+		}))
+		caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+		var err error
+		c, err = elasticsearch.NewClient(server.URL, "elastic", "secret", caPEM)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should decode the cluster health", func() {
+		responses["/_cluster/health"] = `{"cluster_name": "cluster", "status": "yellow", "number_of_nodes": 3,
+			"number_of_data_nodes": 2, "unassigned_shards": 4, "initializing_shards": 1, "relocating_shards": 2}`
+
+		health, err := c.ClusterHealth(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*health).To(Equal(elasticsearch.ClusterHealth{
+			Status:             "yellow",
+			NumberOfNodes:      3,
+			NumberOfDataNodes:  2,
+			UnassignedShards:   4,
+			InitializingShards: 1,
+			RelocatingShards:   2,
+		}))
+	})
+
+	It("should decode the disk usage of each data node", func() {
+		responses["/_cat/allocation"] = `[
+			{"node": "es-1", "disk.used": "60", "disk.avail": "40", "disk.total": "100", "disk.percent": "60"},
+			{"node": "UNASSIGNED", "disk.used": null, "disk.avail": null, "disk.total": null, "disk.percent": null},
+			{"node": "es-0", "disk.used": "10", "disk.avail": "90", "disk.total": "100", "disk.percent": "10"}
+		]`
+
+		disks, err := c.NodeDiskUsage(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(disks).To(Equal([]elasticsearch.NodeDisk{
+			{Node: "es-0", Used: 10, Available: 90, Total: 100, Percent: 10},
+			{Node: "es-1", Used: 60, Available: 40, Total: 100, Percent: 60},
+		}))
+	})
+
+	It("should return the sizes of the indices, oldest first", func() {
+		responses["/_cat/indices/tigera_secure_ee_*"] = `[
+			{"index": "tigera_secure_ee_flows.cluster.20200302", "store.size": "200", "creation.date": "1583107200000"},
+			{"index": "tigera_secure_ee_dns.cluster.20200301", "store.size": null, "creation.date": "1583020800000"},
+			{"index": "tigera_secure_ee_flows.cluster.20200301", "store.size": "100", "creation.date": "1583020800000"}
+		]`
+
+		sizes, err := c.IndexSizes(context.Background(), "tigera_secure_ee_*")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sizes).To(Equal([]elasticsearch.IndexSize{
+			{Index: "tigera_secure_ee_dns.cluster.20200301", Size: 0, CreationDate: 1583020800000},
+			{Index: "tigera_secure_ee_flows.cluster.20200301", Size: 100, CreationDate: 1583020800000},
+			{Index: "tigera_secure_ee_flows.cluster.20200302", Size: 200, CreationDate: 1583107200000},
+		}))
+	})
+
+	It("should return the write indices of the aliases", func() {
+		responses["/_cat/aliases/tigera_secure_ee_*"] = `[
+			{"alias": "tigera_secure_ee_flows.cluster.", "index": "tigera_secure_ee_flows.cluster.ilm-000001", "is_write_index": "false"},
+			{"alias": "tigera_secure_ee_flows.cluster.", "index": "tigera_secure_ee_flows.cluster.ilm-000002", "is_write_index": "true"},
+			{"alias": "tigera_secure_ee_dns.cluster.", "index": "tigera_secure_ee_dns.cluster.ilm-000001", "is_write_index": "-"}
+		]`
+
+		indices, err := c.WriteIndices(context.Background(), "tigera_secure_ee_*")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(indices).To(Equal(map[string]bool{
+			"tigera_secure_ee_flows.cluster.ilm-000002": true,
+			"tigera_secure_ee_dns.cluster.ilm-000001":   true,
+		}))
+	})
+
+	It("should prefer transient over persistent over default disk watermarks", func() {
+		responses["/_cluster/settings"] = `{
+			"transient": {"cluster.routing.allocation.disk.watermark.low": "80%"},
+			"persistent": {"cluster.routing.allocation.disk.watermark.low": "70%", "cluster.routing.allocation.disk.watermark.high": "50gb"},
+			"defaults": {
+				"cluster.routing.allocation.disk.watermark.low": "85%",
+				"cluster.routing.allocation.disk.watermark.high": "90%",
+				"cluster.routing.allocation.disk.watermark.flood_stage": "95%"
+			}
+		}`
+
+		watermarks, err := c.DiskWatermarks(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*watermarks).To(Equal(elasticsearch.DiskWatermarks{Low: "80%", High: "50gb", FloodStage: "95%"}))
+	})
+})
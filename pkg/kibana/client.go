@@ -0,0 +1,229 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package kibana contains a minimal client for the Kibana REST API, used by the operator to import saved objects into
+// the Kibana it installs.
+package kibana
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTimeout = 30 * time.Second
+	// idleConnTimeout is how long an idle connection to Kibana is kept open.
+	idleConnTimeout = 90 * time.Second
+)
+
+// transports holds the transport shared by the clients of each endpoint, so that the clients created on every
+// reconcile reuse the connections of the previous clients instead of each opening their own.
+var transports = struct {
+	sync.Mutex
+	byEndpoint map[string]sharedTransport
+}{byEndpoint: map[string]sharedTransport{}}
+
+// sharedTransport is the transport of an endpoint and the CA bundle it trusts.
+type sharedTransport struct {
+	caPEM     string
+	transport *http.Transport
+}
+
+// transport returns the transport of the endpoint. It is replaced, and the idle connections of the previous
+// transport closed, when the CA bundle of the endpoint changes.
+func transport(endpoint string, caPEM []byte, tlsConfig *tls.Config) *http.Transport {
+	transports.Lock()
+	defer transports.Unlock()
+
+	shared, ok := transports.byEndpoint[endpoint]
+	if ok && shared.caPEM == string(caPEM) {
+		return shared.transport
+	}
+	if ok {
+		shared.transport.CloseIdleConnections()
+	}
+
+	t := &http.Transport{TLSClientConfig: tlsConfig, IdleConnTimeout: idleConnTimeout}
+	transports.byEndpoint[endpoint] = sharedTransport{caPEM: string(caPEM), transport: t}
+	return t
+}
+
+// Client sends requests to Kibana using basic authentication.
+type Client struct {
+	endpoint string
+	username string
+	password string
+	http     *http.Client
+}
+
+// ImportResult is the result of importing saved objects.
+type ImportResult struct {
+	Success      bool          `json:"success"`
+	SuccessCount int           `json:"successCount"`
+	Errors       []ImportError `json:"errors,omitempty"`
+}
+
+// ImportError describes a saved object that could not be imported.
+type ImportError struct {
+	ID    string `json:"id"`
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	Error struct {
+		Type string `json:"type"`
+	} `json:"error"`
+}
+
+// Error is returned when Kibana responds with an unexpected status code.
+type Error struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Body       string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s %s returned %d: %s", e.Method, e.Path, e.StatusCode, e.Body)
+}
+
+// NewClient returns a client for Kibana at endpoint, including the base path Kibana is served under. If caPEM is not
+// empty, the server certificate must be signed by one of the certificates in it, otherwise the system roots are used.
+// The clients of an endpoint share their connections.
+func NewClient(endpoint, username, password string, caPEM []byte) (*Client, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return nil, fmt.Errorf("unsupported scheme %q in Kibana endpoint %s", u.Scheme, endpoint)
+	}
+
+	tlsConfig := &tls.Config{}
+	if len(caPEM) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no valid certificates found in the Kibana CA bundle")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	endpoint = strings.TrimSuffix(endpoint, "/")
+	return &Client{
+		endpoint: endpoint,
+		username: username,
+		password: password,
+		http: &http.Client{
+			Timeout:   defaultTimeout,
+			Transport: transport(endpoint, caPEM, tlsConfig),
+		},
+	}, nil
+}
+
+// ImportSavedObjects imports the saved objects in ndjson, the format of a saved objects export, into the space,
+// replacing existing objects with the same IDs. The objects are imported into the default space if space is empty.
+// Objects that could not be imported are listed in the errors of the result.
+func (c *Client) ImportSavedObjects(ctx context.Context, space string, ndjson []byte) (*ImportResult, error) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", "export.ndjson")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(ndjson); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	path := "/api/saved_objects/_import?overwrite=true"
+	if space != "" {
+		path = "/s/" + url.PathEscape(space) + path
+	}
+	respBody, err := c.do(ctx, http.MethodPost, path, writer.FormDataContentType(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{}
+	if err := json.Unmarshal(respBody, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Space is a Kibana space.
+type Space struct {
+	ID               string   `json:"id"`
+	Name             string   `json:"name"`
+	Description      string   `json:"description,omitempty"`
+	DisabledFeatures []string `json:"disabledFeatures"`
+}
+
+// PutSpace creates the space, or updates it if a space with the same ID exists.
+func (c *Client) PutSpace(ctx context.Context, space Space) error {
+	if space.DisabledFeatures == nil {
+		// Kibana requires the list, even when empty.
+		space.DisabledFeatures = []string{}
+	}
+	body, err := json.Marshal(space)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.do(ctx, http.MethodPut, "/api/spaces/space/"+url.PathEscape(space.ID), "application/json", bytes.NewReader(body))
+	if kerr, ok := err.(*Error); ok && kerr.StatusCode == http.StatusNotFound {
+		_, err = c.do(ctx, http.MethodPost, "/api/spaces/space", "application/json", bytes.NewReader(body))
+	}
+	return err
+}
+
+// do sends a request to Kibana and returns the body of the response. An *Error is returned for a non 2xx response.
+func (c *Client) do(ctx context.Context, method, path, contentType string, body io.Reader) ([]byte, error) {
+	req, err := http.NewRequest(method, c.endpoint+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.SetBasicAuth(c.username, c.password)
+	req.Header.Set("Content-Type", contentType)
+	// Kibana rejects requests that change state without this header.
+	req.Header.Set("kbn-xsrf", "true")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &Error{Method: method, Path: path, StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+	return respBody, nil
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kibana_test
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/tigera/operator/pkg/kibana"
+)
+
+var _ = Describe("Kibana client", func() {
+	var server *httptest.Server
+	var status int
+	var response string
+	var request *http.Request
+	var uploaded []byte
+	var caPEM []byte
+
+	BeforeEach(func() {
+		status = http.StatusOK
+		response = `{"success": true, "successCount": 2}`
+		request, uploaded = nil, nil
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			request = r
+			if file, _, err := r.FormFile("file"); err == nil {
+				uploaded, _ = ioutil.ReadAll(file)
+			}
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(response))
+		}))
+		caPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should upload the saved objects as a file with an XSRF header", func() {
+		c, err := kibana.NewClient(server.URL+"/tigera-kibana/", "elastic", "secret", caPEM)
+		Expect(err).NotTo(HaveOccurred())
+
+		result, err := c.ImportSavedObjects(context.Background(), "", []byte(`{"type": "dashboard", "id": "flows"}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Success).To(BeTrue())
+		Expect(result.SuccessCount).To(Equal(2))
+
+		Expect(request.Method).To(Equal(http.MethodPost))
+		Expect(request.URL.Path).To(Equal("/tigera-kibana/api/saved_objects/_import"))
+		Expect(request.URL.Query().Get("overwrite")).To(Equal("true"))
+		Expect(request.Header.Get("kbn-xsrf")).To(Equal("true"))
+		username, password, _ := request.BasicAuth()
+		Expect(username).To(Equal("elastic"))
+		Expect(password).To(Equal("secret"))
+		Expect(string(uploaded)).To(Equal(`{"type": "dashboard", "id": "flows"}`))
+	})
+
+	It("should import the saved objects into a space", func() {
+		c, err := kibana.NewClient(server.URL, "elastic", "secret", caPEM)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = c.ImportSavedObjects(context.Background(), "security", []byte(`{}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(request.URL.Path).To(Equal("/s/security/api/saved_objects/_import"))
+	})
+
+	It("should return the objects that could not be imported", func() {
+		response = `{"success": false, "successCount": 0, "errors": [
+			{"id": "flows", "type": "dashboard", "title": "Flows", "error": {"type": "missing_references"}}
+		]}`
+		c, err := kibana.NewClient(server.URL, "elastic", "secret", caPEM)
+		Expect(err).NotTo(HaveOccurred())
+
+		result, err := c.ImportSavedObjects(context.Background(), "", []byte(`{}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Success).To(BeFalse())
+		Expect(result.Errors).To(HaveLen(1))
+		Expect(result.Errors[0].ID).To(Equal("flows"))
+		Expect(result.Errors[0].Error.Type).To(Equal("missing_references"))
+	})
+
+	It("should return an error for a non 2xx response", func() {
+		status = http.StatusUnsupportedMediaType
+		c, err := kibana.NewClient(server.URL, "elastic", "secret", caPEM)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = c.ImportSavedObjects(context.Background(), "", []byte(`{}`))
+		Expect(err).To(HaveOccurred())
+		Expect(err.(*kibana.Error).StatusCode).To(Equal(http.StatusUnsupportedMediaType))
+	})
+
+	It("should reuse the connections of the previous clients of the endpoint", func() {
+		var conns int32
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"success": true, "successCount": 1}`))
+		}))
+		server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+			if state == http.StateNew {
+				atomic.AddInt32(&conns, 1)
+			}
+		}
+		server.StartTLS()
+		defer server.Close()
+		caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+		for i := 0; i < 3; i++ {
+			c, err := kibana.NewClient(server.URL, "elastic", "secret", caPEM)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = c.ImportSavedObjects(context.Background(), "", []byte(`{}`))
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(atomic.LoadInt32(&conns)).To(Equal(int32(1)))
+	})
+
+	It("should update a space and create it when it does not exist", func() {
+		var requests []string
+		var spaces []kibana.Space
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			Expect(r.Header.Get("kbn-xsrf")).To(Equal("true"))
+			var space kibana.Space
+			Expect(json.NewDecoder(r.Body).Decode(&space)).To(Succeed())
+			spaces = append(spaces, space)
+			if r.Method == http.MethodPut && len(requests) > 1 {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+
+		c, err := kibana.NewClient(server.URL, "elastic", "secret", nil)
+		Expect(err).NotTo(HaveOccurred())
+		space := kibana.Space{ID: "security", Name: "Security"}
+
+		Expect(c.PutSpace(context.Background(), space)).To(Succeed())
+		Expect(requests).To(Equal([]string{"PUT /api/spaces/space/security"}))
+
+		Expect(c.PutSpace(context.Background(), space)).To(Succeed())
+		Expect(requests).To(Equal([]string{
+			"PUT /api/spaces/space/security",
+			"PUT /api/spaces/space/security",
+			"POST /api/spaces/space",
+		}))
+		Expect(spaces[2]).To(Equal(kibana.Space{ID: "security", Name: "Security", DisabledFeatures: []string{}}))
+	})
+})
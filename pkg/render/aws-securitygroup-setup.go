@@ -15,6 +15,7 @@
 package render
 
 import (
+	operator "github.com/tigera/operator/pkg/apis/operator/v1"
 	"github.com/tigera/operator/pkg/components"
 
 	batchv1 "k8s.io/api/batch/v1"
@@ -24,13 +25,13 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
-func AWSSecurityGroupSetup(ps []corev1.LocalObjectReference, r string) (Component, error) {
-	return &awsSGSetupComponent{pullSecrets: ps, registry: r}, nil
+func AWSSecurityGroupSetup(ps []corev1.LocalObjectReference, installation *operator.Installation) (Component, error) {
+	return &awsSGSetupComponent{pullSecrets: ps, installation: installation}, nil
 }
 
 type awsSGSetupComponent struct {
-	pullSecrets []corev1.LocalObjectReference
-	registry    string
+	pullSecrets  []corev1.LocalObjectReference
+	installation *operator.Installation
 }
 
 func (c *awsSGSetupComponent) Objects() ([]runtime.Object, []runtime.Object) {
@@ -75,7 +76,7 @@ func (c *awsSGSetupComponent) setupJob() *batchv1.Job {
 					},
 					Containers: []corev1.Container{{
 						Name:  "aws-security-group-setup",
-						Image: components.GetOperatorInitReference(c.registry),
+						Image: components.GetOperatorInitReference(c.installation),
 						Env: []corev1.EnvVar{
 							{
 								Name:  "OPENSHIFT",
@@ -299,10 +299,10 @@ func (c *complianceComponent) complianceControllerDeployment() *appsv1.Deploymen
 			Containers: []corev1.Container{
 				ElasticsearchContainerDecorate(corev1.Container{
 					Name:          ComplianceControllerName,
-					Image:         components.GetReference(components.ComponentComplianceController, c.installation.Spec.Registry),
+					Image:         components.GetReference(components.ComponentComplianceController, c.installation),
 					Env:           envVars,
 					LivenessProbe: complianceLivenessProbe,
-				}, c.esClusterConfig.ClusterName(), ElasticsearchComplianceControllerUserSecret),
+				}, c.esClusterConfig, ElasticsearchComplianceControllerUserSecret),
 			},
 		}),
 	}, c.esClusterConfig, c.esSecrets).(*corev1.PodTemplateSpec)
@@ -406,7 +406,7 @@ func (c *complianceComponent) complianceReporterPodTemplate() *corev1.PodTemplat
 					ElasticsearchContainerDecorateIndexCreator(
 						ElasticsearchContainerDecorate(corev1.Container{
 							Name:          "reporter",
-							Image:         components.GetReference(components.ComponentComplianceReporter, c.installation.Spec.Registry),
+							Image:         components.GetReference(components.ComponentComplianceReporter, c.installation),
 							Env:           envVars,
 							LivenessProbe: complianceLivenessProbe,
 							SecurityContext: &corev1.SecurityContext{
@@ -415,7 +415,7 @@ func (c *complianceComponent) complianceReporterPodTemplate() *corev1.PodTemplat
 							VolumeMounts: []corev1.VolumeMount{
 								{MountPath: "/var/log/calico", Name: "var-log-calico"},
 							},
-						}, c.esClusterConfig.ClusterName(), ElasticsearchComplianceReporterUserSecret), c.esClusterConfig.Replicas(), c.esClusterConfig.Shards(),
+						}, c.esClusterConfig, ElasticsearchComplianceReporterUserSecret), c.esClusterConfig, ComplianceReportsIndex,
 					),
 				},
 				Volumes: []corev1.Volume{
@@ -551,7 +551,7 @@ func (c *complianceComponent) complianceServerDeployment() *appsv1.Deployment {
 			Containers: []corev1.Container{
 				ElasticsearchContainerDecorate(corev1.Container{
 					Name:  ComplianceServerName,
-					Image: components.GetReference(components.ComponentComplianceServer, c.installation.Spec.Registry),
+					Image: components.GetReference(components.ComponentComplianceServer, c.installation),
 					Env:   envVars,
 					LivenessProbe: &corev1.Probe{
 						Handler: corev1.Handler{
@@ -582,7 +582,7 @@ func (c *complianceComponent) complianceServerDeployment() *appsv1.Deployment {
 						MountPath: "/code/apiserver.local.config/certificates",
 						ReadOnly:  true,
 					}},
-				}, c.esClusterConfig.ClusterName(), ElasticsearchComplianceServerUserSecret),
+				}, c.esClusterConfig, ElasticsearchComplianceServerUserSecret),
 			},
 			Volumes: []corev1.Volume{{
 				Name: "cert",
@@ -708,10 +708,10 @@ func (c *complianceComponent) complianceSnapshotterDeployment() *appsv1.Deployme
 				ElasticsearchContainerDecorateIndexCreator(
 					ElasticsearchContainerDecorate(corev1.Container{
 						Name:          ComplianceSnapshotterName,
-						Image:         components.GetReference(components.ComponentComplianceSnapshotter, c.installation.Spec.Registry),
+						Image:         components.GetReference(components.ComponentComplianceSnapshotter, c.installation),
 						Env:           envVars,
 						LivenessProbe: complianceLivenessProbe,
-					}, c.esClusterConfig.ClusterName(), ElasticsearchComplianceSnapshotterUserSecret), c.esClusterConfig.Replicas(), c.esClusterConfig.Shards(),
+					}, c.esClusterConfig, ElasticsearchComplianceSnapshotterUserSecret), c.esClusterConfig, SnapshotsIndex,
 				),
 			},
 		}),
@@ -850,11 +850,11 @@ func (c *complianceComponent) complianceBenchmarkerDaemonSet() *appsv1.DaemonSet
 				ElasticsearchContainerDecorateIndexCreator(
 					ElasticsearchContainerDecorate(corev1.Container{
 						Name:          "compliance-benchmarker",
-						Image:         components.GetReference(components.ComponentComplianceBenchmarker, c.installation.Spec.Registry),
+						Image:         components.GetReference(components.ComponentComplianceBenchmarker, c.installation),
 						Env:           envVars,
 						VolumeMounts:  volMounts,
 						LivenessProbe: complianceLivenessProbe,
-					}, c.esClusterConfig.ClusterName(), ElasticsearchComplianceBenchmarkerUserSecret), c.esClusterConfig.Replicas(), c.esClusterConfig.Shards(),
+					}, c.esClusterConfig, ElasticsearchComplianceBenchmarkerUserSecret), c.esClusterConfig, BenchmarkResultsIndex,
 				),
 			},
 			Volumes: vols,
@@ -46,24 +46,49 @@ func ElasticsearchDecorateAnnotations(obj Annotatable, config *ElasticsearchClus
 	return obj
 }
 
-func ElasticsearchContainerDecorate(c corev1.Container, cluster, secret string) corev1.Container {
-	return ElasticsearchContainerDecorateVolumeMounts(ElasticsearchContainerDecorateENVVars(c, cluster, secret))
+func ElasticsearchContainerDecorate(c corev1.Container, config *ElasticsearchClusterConfig, secret string) corev1.Container {
+	return ElasticsearchContainerDecorateVolumeMounts(ElasticsearchContainerDecorateENVVars(c, config, secret))
 }
 
-func ElasticsearchContainerDecorateIndexCreator(c corev1.Container, replicas, shards int) corev1.Container {
-	envVars := []corev1.EnvVar{
-		{Name: "ELASTIC_REPLICAS", Value: strconv.Itoa(replicas)},
-		{Name: "ELASTIC_SHARDS", Value: strconv.Itoa(shards)},
-	}
-	c.Env = append(c.Env, envVars...)
+// ElasticsearchContainerDecorateIndexCreator adds the environment variables that set the number of replicas and shards
+// of the indices of the given type, which the container creates.
+func ElasticsearchContainerDecorateIndexCreator(c corev1.Container, config *ElasticsearchClusterConfig, index ElasticsearchIndex) corev1.Container {
+	settings := config.IndexSettings(index)
+	c.Env = setEnvVars(c.Env,
+		corev1.EnvVar{Name: "ELASTIC_REPLICAS", Value: strconv.Itoa(settings.Replicas)},
+		corev1.EnvVar{Name: "ELASTIC_SHARDS", Value: strconv.Itoa(settings.Shards)},
+	)
 
 	return c
 }
 
-func ElasticsearchContainerDecorateENVVars(c corev1.Container, cluster, esUserSecretName string) corev1.Container {
-	esScheme, esHost, esPort, _ := ParseEndpoint(ElasticsearchHTTPSEndpoint)
+// setEnvVars returns envs with each of vars added, replacing any environment variable with the same name, so that a
+// container decorated more than once does not get duplicate environment variables.
+func setEnvVars(envs []corev1.EnvVar, vars ...corev1.EnvVar) []corev1.EnvVar {
+	// Copy envs so the slice of the caller's container is not modified.
+	envs = append([]corev1.EnvVar(nil), envs...)
+	for _, v := range vars {
+		replaced := false
+		for i := range envs {
+			if envs[i].Name == v.Name {
+				envs[i] = v
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			envs = append(envs, v)
+		}
+	}
+	return envs
+}
+
+// ElasticsearchContainerDecorateENVVars adds the environment variables used to connect to the Elasticsearch cluster,
+// either the one managed by the operator or an external one, using the credentials in the given user secret.
+func ElasticsearchContainerDecorateENVVars(c corev1.Container, config *ElasticsearchClusterConfig, esUserSecretName string) corev1.Container {
+	esScheme, esHost, esPort, _ := ParseEndpoint(config.Endpoint())
 	envVars := []corev1.EnvVar{
-		{Name: "ELASTIC_INDEX_SUFFIX", Value: cluster},
+		{Name: "ELASTIC_INDEX_SUFFIX", Value: config.ClusterName()},
 		{Name: "ELASTIC_SCHEME", Value: esScheme},
 		{Name: "ELASTIC_HOST", Value: esHost},
 		{Name: "ELASTIC_PORT", Value: esPort},
@@ -86,7 +111,7 @@ func ElasticsearchContainerDecorateENVVars(c corev1.Container, cluster, esUserSe
 		{Name: "ES_CURATOR_BACKEND_CERT", Value: ElasticsearchDefaultCertPath},
 	}
 
-	c.Env = append(c.Env, envVars...)
+	c.Env = setEnvVars(c.Env, envVars...)
 	return c
 }
 
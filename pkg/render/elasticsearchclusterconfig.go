@@ -17,17 +17,48 @@ package render
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func NewElasticsearchClusterConfig(clusterName string, replicas int, shards int) *ElasticsearchClusterConfig {
+// ElasticsearchIndex is a type of index written to Elasticsearch. The number of replicas and shards can be set for each
+// type of index.
+type ElasticsearchIndex string
+
+const (
+	FlowsIndex             ElasticsearchIndex = "flows"
+	DNSIndex               ElasticsearchIndex = "dns"
+	AuditIndex             ElasticsearchIndex = "audit"
+	SnapshotsIndex         ElasticsearchIndex = "snapshots"
+	ComplianceReportsIndex ElasticsearchIndex = "compliance_reports"
+	BenchmarkResultsIndex  ElasticsearchIndex = "benchmark_results"
+	EventsIndex            ElasticsearchIndex = "events"
+	ComponentLogsIndex     ElasticsearchIndex = "component_logs"
+)
+
+// ElasticsearchIndices are all the types of index written to Elasticsearch.
+var ElasticsearchIndices = []ElasticsearchIndex{
+	FlowsIndex, DNSIndex, AuditIndex, SnapshotsIndex, ComplianceReportsIndex, BenchmarkResultsIndex, EventsIndex,
+	ComponentLogsIndex,
+}
+
+// IndexSettings are the number of replicas and shards of the indices of one type.
+type IndexSettings struct {
+	Replicas int
+	Shards   int
+}
+
+// NewElasticsearchClusterConfig returns the configuration of the Elasticsearch cluster used by all components. An
+// empty endpoint refers to the cluster managed by the operator.
+func NewElasticsearchClusterConfig(clusterName string, replicas int, shards int, endpoint string) *ElasticsearchClusterConfig {
 	return &ElasticsearchClusterConfig{
 		clusterName: clusterName,
 		replicas:    replicas,
 		shards:      shards,
+		endpoint:    endpoint,
 	}
 }
 
@@ -55,17 +86,54 @@ func NewElasticsearchClusterConfigFromConfigMap(configMap *corev1.ConfigMap) (*E
 		}
 	}
 
-	return &ElasticsearchClusterConfig{
+	config := &ElasticsearchClusterConfig{
 		clusterName: configMap.Data["clusterName"],
 		replicas:    replicas,
 		shards:      shards,
-	}, nil
+		endpoint:    configMap.Data["endpoint"],
+	}
+
+	if configMap.Data["kibanaDisabled"] != "" {
+		if config.kibanaDisabled, err = strconv.ParseBool(configMap.Data["kibanaDisabled"]); err != nil {
+			return nil, errors.Wrap(err, "'kibanaDisabled' must be a boolean")
+		}
+	}
+
+	// The settings of an index type are only in the ConfigMap if they differ from those of all indices.
+	for _, index := range ElasticsearchIndices {
+		replicasKey, shardsKey := indexConfigMapKey(index, "replicas"), indexConfigMapKey(index, "shards")
+		if configMap.Data[replicasKey] == "" && configMap.Data[shardsKey] == "" {
+			continue
+		}
+
+		indexReplicas, err := strconv.Atoi(configMap.Data[replicasKey])
+		if err != nil {
+			return nil, errors.Wrapf(err, "'%s' must be an integer", replicasKey)
+		}
+		indexShards, err := strconv.Atoi(configMap.Data[shardsKey])
+		if err != nil {
+			return nil, errors.Wrapf(err, "'%s' must be an integer", shardsKey)
+		}
+		config.SetIndexSettings(index, indexReplicas, indexShards)
+	}
+
+	return config, nil
+}
+
+// indexConfigMapKey returns the key of a setting of the index type in the ConfigMap, for example flows.replicas.
+func indexConfigMapKey(index ElasticsearchIndex, setting string) string {
+	return strings.Join([]string{string(index), setting}, ".")
 }
 
 type ElasticsearchClusterConfig struct {
 	clusterName string
 	replicas    int
 	shards      int
+	endpoint    string
+	// kibanaDisabled is true if Kibana is not installed with the Elasticsearch cluster managed by the operator.
+	kibanaDisabled bool
+	// indices holds the settings of the index types that differ from the replicas and shards of all indices.
+	indices map[ElasticsearchIndex]IndexSettings
 }
 
 func (c ElasticsearchClusterConfig) ClusterName() string {
@@ -80,12 +148,73 @@ func (c ElasticsearchClusterConfig) Shards() int {
 	return c.shards
 }
 
+// SetIndexSettings sets the number of replicas and shards of the indices of the given type.
+func (c *ElasticsearchClusterConfig) SetIndexSettings(index ElasticsearchIndex, replicas, shards int) {
+	if replicas == c.replicas && shards == c.shards {
+		delete(c.indices, index)
+		return
+	}
+	if c.indices == nil {
+		c.indices = map[ElasticsearchIndex]IndexSettings{}
+	}
+	c.indices[index] = IndexSettings{Replicas: replicas, Shards: shards}
+}
+
+// IndexSettings returns the number of replicas and shards of the indices of the given type.
+func (c ElasticsearchClusterConfig) IndexSettings(index ElasticsearchIndex) IndexSettings {
+	if settings, ok := c.indices[index]; ok {
+		return settings
+	}
+	return IndexSettings{Replicas: c.replicas, Shards: c.shards}
+}
+
+// Endpoint returns the URL of the Elasticsearch cluster.
+func (c ElasticsearchClusterConfig) Endpoint() string {
+	if c.endpoint == "" {
+		return ElasticsearchHTTPSEndpoint
+	}
+	return c.endpoint
+}
+
+// External returns true if the Elasticsearch cluster is not managed by the operator.
+func (c ElasticsearchClusterConfig) External() bool {
+	return c.endpoint != ""
+}
+
+// ForManagedCluster returns the configuration used by the components of a managed cluster with the given name. They
+// write to the Elasticsearch cluster of the management cluster, which is reached through the Guardian tunnel at the
+// endpoint of the cluster managed by the operator, and the name of the managed cluster is the suffix of their indices.
+func (c ElasticsearchClusterConfig) ForManagedCluster(clusterName string) *ElasticsearchClusterConfig {
+	config := c
+	config.clusterName = clusterName
+	config.endpoint = ""
+	if c.indices != nil {
+		// Copy the index settings, so that setting them on the copy does not change this configuration.
+		config.indices = map[ElasticsearchIndex]IndexSettings{}
+		for index, settings := range c.indices {
+			config.indices[index] = settings
+		}
+	}
+	return &config
+}
+
+// DisableKibana records that Kibana is not installed with the Elasticsearch cluster.
+func (c *ElasticsearchClusterConfig) DisableKibana() {
+	c.kibanaDisabled = true
+}
+
+// Kibana returns true if Kibana is installed with the Elasticsearch cluster. There is no Kibana when the Elasticsearch
+// cluster is not managed by the operator.
+func (c ElasticsearchClusterConfig) Kibana() bool {
+	return !c.External() && !c.kibanaDisabled
+}
+
 func (c ElasticsearchClusterConfig) Annotation() string {
 	return AnnotationHash(c)
 }
 
 func (c ElasticsearchClusterConfig) ConfigMap() *corev1.ConfigMap {
-	return &corev1.ConfigMap{
+	cm := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      ElasticsearchConfigMapName,
 			Namespace: OperatorNamespace(),
@@ -96,4 +225,15 @@ func (c ElasticsearchClusterConfig) ConfigMap() *corev1.ConfigMap {
 			"shards":      strconv.Itoa(c.shards),
 		},
 	}
+	if c.endpoint != "" {
+		cm.Data["endpoint"] = c.endpoint
+	}
+	if c.kibanaDisabled {
+		cm.Data["kibanaDisabled"] = "true"
+	}
+	for index, settings := range c.indices {
+		cm.Data[indexConfigMapKey(index, "replicas")] = strconv.Itoa(settings.Replicas)
+		cm.Data[indexConfigMapKey(index, "shards")] = strconv.Itoa(settings.Shards)
+	}
+	return cm
 }
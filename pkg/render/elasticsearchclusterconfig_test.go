@@ -0,0 +1,83 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/tigera/operator/pkg/render"
+)
+
+var _ = Describe("Elasticsearch cluster config tests", func() {
+	It("should read the settings of each index type from the ConfigMap", func() {
+		config := render.NewElasticsearchClusterConfig("cluster", 1, 5, "")
+		config.SetIndexSettings(render.FlowsIndex, 2, 10)
+		config.SetIndexSettings(render.AuditIndex, 1, 5)
+
+		cm := config.ConfigMap()
+		Expect(cm.Data).To(HaveKeyWithValue("flows.replicas", "2"))
+		Expect(cm.Data).To(HaveKeyWithValue("flows.shards", "10"))
+		Expect(cm.Data).NotTo(HaveKey("audit.replicas"))
+
+		read, err := render.NewElasticsearchClusterConfigFromConfigMap(cm)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(read).To(Equal(config))
+		Expect(read.IndexSettings(render.FlowsIndex)).To(Equal(render.IndexSettings{Replicas: 2, Shards: 10}))
+		Expect(read.IndexSettings(render.DNSIndex)).To(Equal(render.IndexSettings{Replicas: 1, Shards: 5}))
+	})
+
+	It("should use the replicas and shards of all indices for a ConfigMap without index settings", func() {
+		cm := render.NewElasticsearchClusterConfig("cluster", 1, 5, "").ConfigMap()
+
+		read, err := render.NewElasticsearchClusterConfigFromConfigMap(cm)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(read.IndexSettings(render.EventsIndex)).To(Equal(render.IndexSettings{Replicas: 1, Shards: 5}))
+	})
+
+	It("should write the logs of a managed cluster through the tunnel with its name as index suffix", func() {
+		config := render.NewElasticsearchClusterConfig("cluster", 1, 5, "https://external.example.com:9200")
+		config.SetIndexSettings(render.FlowsIndex, 2, 10)
+
+		managed := config.ForManagedCluster("managed-a")
+		Expect(managed.ClusterName()).To(Equal("managed-a"))
+		Expect(managed.Endpoint()).To(Equal(render.ElasticsearchHTTPSEndpoint))
+		Expect(managed.IndexSettings(render.FlowsIndex)).To(Equal(render.IndexSettings{Replicas: 2, Shards: 10}))
+
+		managed.SetIndexSettings(render.FlowsIndex, 1, 5)
+		Expect(config.IndexSettings(render.FlowsIndex)).To(Equal(render.IndexSettings{Replicas: 2, Shards: 10}))
+		Expect(config.ClusterName()).To(Equal("cluster"))
+	})
+
+	It("should reject index settings that are not integers", func() {
+		cm := render.NewElasticsearchClusterConfig("cluster", 1, 5, "").ConfigMap()
+		cm.Data["flows.replicas"] = "two"
+		cm.Data["flows.shards"] = "10"
+
+		_, err := render.NewElasticsearchClusterConfigFromConfigMap(cm)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should read whether Kibana is disabled from the ConfigMap", func() {
+		config := render.NewElasticsearchClusterConfig("cluster", 1, 5, "")
+		Expect(config.Kibana()).To(BeTrue())
+		config.DisableKibana()
+
+		read, err := render.NewElasticsearchClusterConfigFromConfigMap(config.ConfigMap())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(read.Kibana()).To(BeFalse())
+		Expect(render.NewElasticsearchClusterConfig("cluster", 1, 5, "https://es.example.com:9200").Kibana()).To(BeFalse())
+	})
+})
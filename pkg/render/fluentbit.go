@@ -0,0 +1,426 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	operatorv1 "github.com/tigera/operator/pkg/apis/operator/v1"
+	"github.com/tigera/operator/pkg/components"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	FluentBitNodeName          = "fluent-bit-node"
+	FluentBitConfigMapName     = "fluent-bit-config"
+	FluentBitConfigName        = "fluent-bit.conf"
+	FluentBitParsersName       = "parsers.conf"
+	FluentBitHTTPPort          = 2020
+	fluentBitConfigPath        = "/fluent-bit/etc"
+	fluentBitBufferMountPath   = "/fluent-bit/buffers"
+	fluentBitSyslogCredentials = "/fluent-bit/syslog"
+	fluentBitConfigAnnotation  = "hash.operator.tigera.io/fluent-bit-config"
+)
+
+// fluentBitParsers is the parsers file of Fluent Bit. The flow and DNS logs are written as a JSON object per line.
+const fluentBitParsers = `[PARSER]
+    Name   json
+    Format json
+`
+
+// fluentBitLog is a type of log collected by Fluent Bit.
+type fluentBitLog struct {
+	logType operatorv1.StoreLogType
+	// tag is the tag of the parsed logs. The raw lines are tagged "raw.<tag>".
+	tag   string
+	file  string
+	index string
+}
+
+// fluentBitLogs are the logs that Fluent Bit collects, which are the logs of the files that Felix writes.
+var fluentBitLogs = []fluentBitLog{
+	{operatorv1.StoreLogTypeFlows, "flows", "/var/log/calico/flowlogs/flows.log", "tigera_secure_ee_flows"},
+	{operatorv1.StoreLogTypeDNS, "dns", "/var/log/calico/dnslogs/dns.log", "tigera_secure_ee_dns"},
+}
+
+// FluentBitIndices returns the prefixes of the indices that Fluent Bit writes to. It writes to their rollover aliases,
+// which must be created before Fluent Bit starts writing.
+func FluentBitIndices() []string {
+	var indices []string
+	for _, l := range fluentBitLogs {
+		indices = append(indices, l.index)
+	}
+	return indices
+}
+
+// fluentBitSection is a section of the Fluent Bit configuration with its entries in order.
+type fluentBitSection struct {
+	name    string
+	entries [][2]string
+}
+
+func (s *fluentBitSection) add(key, value string) {
+	s.entries = append(s.entries, [2]string{key, value})
+}
+
+func (s fluentBitSection) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s]\n", s.name)
+	for _, e := range s.entries {
+		fmt.Fprintf(&b, "    %-24s %s\n", e[0], e[1])
+	}
+	return b.String()
+}
+
+// fluentBit returns whether Fluent Bit is the log collector instead of fluentd.
+func (c *fluentdComponent) fluentBit() bool {
+	return c.lc.Spec.Collector == operatorv1.LogCollectorTypeFluentBit
+}
+
+// fluentBitStoreLogs returns whether a log type is sent to the additional store with the given name. The stores
+// are limited to the log types that Fluent Bit collects.
+func (c *fluentdComponent) fluentBitStoreLogs(name string, logType operatorv1.StoreLogType) bool {
+	for _, store := range c.additionalStores() {
+		if store.name != name {
+			continue
+		}
+		for _, t := range store.logTypes {
+			if t == logType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fluentBitConfig generates the Fluent Bit configuration from the LogCollector. It sends the same logs to the same
+// outputs as the fluentd configuration that is generated from the env vars of the fluentd container.
+func (c *fluentdComponent) fluentBitConfig() string {
+	buffer := c.buffer()
+	var limit string
+	if buffer != nil {
+		q := resource.MustParse(fluentdDefaultBufferLimit)
+		if buffer.TotalLimitSize != nil {
+			q = *buffer.TotalLimitSize
+		}
+		limit = strconv.FormatInt(q.Value(), 10)
+	}
+
+	service := fluentBitSection{name: "SERVICE"}
+	service.add("Flush", strings.TrimSuffix(c.flushInterval(0), "s"))
+	service.add("Daemon", "Off")
+	service.add("Log_Level", "info")
+	service.add("Parsers_File", FluentBitParsersName)
+	service.add("HTTP_Server", "On")
+	service.add("HTTP_Listen", "0.0.0.0")
+	service.add("HTTP_Port", strconv.Itoa(FluentBitHTTPPort))
+	if buffer != nil {
+		seconds := func(value, def int32) string {
+			if value == 0 {
+				value = def
+			}
+			return strconv.Itoa(int(value))
+		}
+		service.add("storage.path", fluentBitBufferMountPath)
+		service.add("scheduler.base", seconds(buffer.RetryWait, fluentdDefaultRetryWait))
+		service.add("scheduler.cap", seconds(buffer.RetryMaxInterval, fluentdDefaultRetryMaxInterval))
+	}
+	sections := []fluentBitSection{service}
+
+	stores := c.lc.Spec.AdditionalStores
+	var s3 *operatorv1.S3StoreSpec
+	var syslog *operatorv1.SyslogStoreSpec
+	if stores != nil {
+		s3, syslog = stores.S3, stores.Syslog
+	}
+
+	for _, l := range fluentBitLogs {
+		toSyslog := syslog != nil && c.fluentBitStoreLogs("syslog", l.logType)
+
+		// The raw lines are kept for syslog, which sends them as the message, while the other outputs get the
+		// parsed logs.
+		input := fluentBitSection{name: "INPUT"}
+		input.add("Name", "tail")
+		input.add("Tag", "raw."+l.tag)
+		input.add("Path", l.file)
+		input.add("Key", "message")
+		input.add("DB", path.Join(path.Dir(l.file), "fluent-bit.db"))
+		if buffer != nil {
+			input.add("storage.type", "filesystem")
+		}
+		rewrite := fluentBitSection{name: "FILTER"}
+		rewrite.add("Name", "rewrite_tag")
+		rewrite.add("Match", "raw."+l.tag)
+		rewrite.add("Rule", fmt.Sprintf("$message .* %s %s", l.tag, strconv.FormatBool(toSyslog)))
+		parser := fluentBitSection{name: "FILTER"}
+		parser.add("Name", "parser")
+		parser.add("Match", l.tag)
+		parser.add("Key_Name", "message")
+		parser.add("Parser", "json")
+		sections = append(sections, input, rewrite, parser)
+		if toSyslog {
+			// The raw lines are copied by the rewrite before the hostname is added, so only syslog gets it.
+			hostname := fluentBitSection{name: "FILTER"}
+			hostname.add("Name", "record_modifier")
+			hostname.add("Match", "raw."+l.tag)
+			hostname.add("Record", "hostname ${SYSLOG_HOSTNAME}")
+			sections = append(sections, hostname)
+		}
+
+		sections = append(sections, c.fluentBitElasticsearchOutput(l, limit))
+		if s3 != nil && c.fluentBitStoreLogs("s3", l.logType) {
+			sections = append(sections, c.fluentBitS3Output(l, s3, limit))
+		}
+		if toSyslog {
+			sections = append(sections, c.fluentBitSyslogOutput(l, syslog, limit))
+		}
+	}
+
+	var b strings.Builder
+	for i, s := range sections {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(s.String())
+	}
+	return b.String()
+}
+
+func (c *fluentdComponent) fluentBitElasticsearchOutput(l fluentBitLog, limit string) fluentBitSection {
+	scheme, host, port, _ := ParseEndpoint(c.esClusterConfig.Endpoint())
+	out := fluentBitSection{name: "OUTPUT"}
+	out.add("Name", "es")
+	out.add("Match", l.tag)
+	out.add("Host", host)
+	out.add("Port", port)
+	out.add("HTTP_User", "${ELASTIC_USER}")
+	out.add("HTTP_Passwd", "${ELASTIC_PASSWORD}")
+	// Logs are written to the rollover alias of the index, which is created by the operator.
+	out.add("Index", l.index+".${ELASTIC_INDEX_SUFFIX}.")
+	out.add("Type", "_doc")
+	if scheme == "https" {
+		out.add("tls", "On")
+		out.add("tls.verify", "On")
+		out.add("tls.ca_file", ElasticsearchDefaultCertPath)
+	}
+	if limit != "" {
+		out.add("storage.total_limit_size", limit)
+	}
+	return out
+}
+
+func (c *fluentdComponent) fluentBitS3Output(l fluentBitLog, s3 *operatorv1.S3StoreSpec, limit string) fluentBitSection {
+	out := fluentBitSection{name: "OUTPUT"}
+	out.add("Name", "s3")
+	out.add("Match", l.tag)
+	out.add("bucket", s3.BucketName)
+	out.add("region", s3.Region)
+	out.add("s3_key_format", path.Join("/", s3.BucketPath, l.tag, "%Y/%m/%d/%H_%M_%S_$UUID.gz"))
+	out.add("compression", "gzip")
+	out.add("use_put_object", "On")
+	out.add("upload_timeout", c.flushInterval(s3.FlushInterval))
+	if limit != "" {
+		out.add("storage.total_limit_size", limit)
+	}
+	return out
+}
+
+func (c *fluentdComponent) fluentBitSyslogOutput(l fluentBitLog, syslog *operatorv1.SyslogStoreSpec, limit string) fluentBitSection {
+	// The endpoint has been validated by the controller.
+	proto, host, port, _ := ParseEndpoint(syslog.Endpoint)
+	format := syslog.Format
+	if format == "" {
+		format = operatorv1.SyslogFormatRFC3164
+	}
+
+	out := fluentBitSection{name: "OUTPUT"}
+	out.add("Name", "syslog")
+	out.add("Match", "raw."+l.tag)
+	out.add("Host", host)
+	out.add("Port", port)
+	out.add("Mode", proto)
+	out.add("Syslog_Format", strings.ToLower(string(format)))
+	out.add("Syslog_Hostname_Key", "hostname")
+	out.add("Syslog_Message_Key", "message")
+	if syslog.PacketSize != nil {
+		out.add("Syslog_MaxSize", strconv.Itoa(int(*syslog.PacketSize)))
+	}
+	if proto == "tls" {
+		out.add("tls", "On")
+		out.add("tls.verify", "On")
+		files := c.syslogCredentialFiles()
+		for _, f := range []struct{ key, file string }{
+			{"tls.ca_file", SyslogCAName},
+			{"tls.crt_file", SyslogCertName},
+			{"tls.key_file", SyslogKeyName},
+		} {
+			if files[f.file] {
+				out.add(f.key, fluentBitSyslogCredentials+"/"+f.file)
+			}
+		}
+	}
+	if limit != "" {
+		out.add("storage.total_limit_size", limit)
+	}
+	return out
+}
+
+func (c *fluentdComponent) fluentBitConfigMap() *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      FluentBitConfigMapName,
+			Namespace: LogCollectorNamespace,
+		},
+		Data: map[string]string{
+			FluentBitConfigName:  c.fluentBitConfig(),
+			FluentBitParsersName: fluentBitParsers,
+		},
+	}
+}
+
+// fluentBitDaemonset creates the daemonset of the Fluent Bit collector, which replaces the fluentd daemonset.
+func (c *fluentdComponent) fluentBitDaemonset() *appsv1.DaemonSet {
+	var terminationGracePeriod int64 = 0
+	maxUnavailable := intstr.FromInt(1)
+
+	annots := map[string]string{
+		fluentBitConfigAnnotation: AnnotationHash(c.fluentBitConfig()),
+	}
+	if c.s3Credential != nil {
+		annots[s3CredentialHashAnnotation] = AnnotationHash(c.s3Credential)
+	}
+	if c.syslogCredential != nil {
+		annots[syslogCredentialHashAnnotation] = AnnotationHash(c.syslogCredential)
+	}
+
+	volumes := append(c.volumes(), corev1.Volume{
+		Name: "fluent-bit-config",
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: FluentBitConfigMapName},
+			},
+		},
+	})
+
+	podTemplate := ElasticsearchDecorateAnnotations(&corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				"k8s-app": FluentBitNodeName,
+			},
+			Annotations: annots,
+		},
+		Spec: ElasticsearchPodSpecDecorate(corev1.PodSpec{
+			NodeSelector:                  map[string]string{},
+			Tolerations:                   c.tolerations(),
+			ImagePullSecrets:              getImagePullSecretReferenceList(c.pullSecrets),
+			TerminationGracePeriodSeconds: &terminationGracePeriod,
+			Containers:                    []corev1.Container{c.fluentBitContainer()},
+			Volumes:                       volumes,
+		}),
+	}, c.esClusterConfig, c.esSecrets).(*corev1.PodTemplateSpec)
+
+	ds := &appsv1.DaemonSet{
+		TypeMeta: metav1.TypeMeta{Kind: "DaemonSet", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      FluentBitNodeName,
+			Namespace: LogCollectorNamespace,
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"k8s-app": FluentBitNodeName}},
+			Template: *podTemplate,
+			UpdateStrategy: appsv1.DaemonSetUpdateStrategy{
+				RollingUpdate: &appsv1.RollingUpdateDaemonSet{
+					MaxUnavailable: &maxUnavailable,
+				},
+			},
+		},
+	}
+
+	setCriticalPod(&(ds.Spec.Template))
+	return ds
+}
+
+// fluentBitContainer creates the Fluent Bit container. The configuration refers to the Elasticsearch credentials
+// through env vars, and the AWS credentials are read from the env by the S3 output.
+func (c *fluentdComponent) fluentBitContainer() corev1.Container {
+	envs := []corev1.EnvVar{}
+	if c.lc.Spec.AdditionalStores != nil {
+		if c.lc.Spec.AdditionalStores.S3 != nil {
+			envs = append(envs,
+				corev1.EnvVar{Name: "AWS_ACCESS_KEY_ID", ValueFrom: envVarSourceFromSecret(S3FluentdSecretName, S3KeyIdName, false)},
+				corev1.EnvVar{Name: "AWS_SECRET_ACCESS_KEY", ValueFrom: envVarSourceFromSecret(S3FluentdSecretName, S3KeySecretName, false)},
+			)
+		}
+		if c.lc.Spec.AdditionalStores.Syslog != nil {
+			envs = append(envs, corev1.EnvVar{Name: "SYSLOG_HOSTNAME",
+				ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{
+						FieldPath: "spec.nodeName",
+					},
+				},
+			})
+		}
+	}
+
+	volumeMounts := []corev1.VolumeMount{
+		{MountPath: "/var/log/calico", Name: "var-log-calico"},
+		{MountPath: fluentBitConfigPath, Name: "fluent-bit-config", ReadOnly: true},
+		ElasticsearchDefaultVolumeMount(),
+	}
+	if len(c.syslogCredentialFiles()) != 0 {
+		volumeMounts = append(volumeMounts,
+			corev1.VolumeMount{Name: "syslog-credentials", MountPath: fluentBitSyslogCredentials, ReadOnly: true})
+	}
+	if c.buffer() != nil {
+		volumeMounts = append(volumeMounts,
+			corev1.VolumeMount{Name: "fluentd-buffers", MountPath: fluentBitBufferMountPath})
+	}
+
+	resources := corev1.ResourceRequirements{}
+	if c.lc.Spec.ResourceRequirements != nil {
+		resources = *c.lc.Spec.ResourceRequirements
+	}
+
+	probe := &corev1.Probe{
+		Handler: corev1.Handler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path: "/",
+				Port: intstr.FromInt(FluentBitHTTPPort),
+			},
+		},
+	}
+	isPrivileged := true
+
+	return ElasticsearchContainerDecorateENVVars(corev1.Container{
+		Name:            "fluent-bit",
+		Image:           components.GetReference(components.ComponentFluentBit, c.installation),
+		Env:             envs,
+		Resources:       resources,
+		Ports:           []corev1.ContainerPort{{Name: "http", ContainerPort: FluentBitHTTPPort}},
+		SecurityContext: &corev1.SecurityContext{Privileged: &isPrivileged},
+		VolumeMounts:    volumeMounts,
+		LivenessProbe:   probe,
+		ReadinessProbe:  probe,
+	}, c.esClusterConfig, ElasticsearchLogCollectorUserSecret)
+}
@@ -0,0 +1,219 @@
+// Copyright (c) 2020 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render_test
+
+import (
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	operatorv1 "github.com/tigera/operator/pkg/apis/operator/v1"
+	"github.com/tigera/operator/pkg/render"
+)
+
+// fluentBitOutputs returns the outputs of a Fluent Bit configuration as "<plugin> <log> <destination>".
+func fluentBitOutputs(config string) []string {
+	var outputs []string
+	for _, section := range strings.Split(config, "\n\n") {
+		lines := strings.Split(strings.TrimSpace(section), "\n")
+		if lines[0] != "[OUTPUT]" {
+			continue
+		}
+		entries := map[string]string{}
+		for _, line := range lines[1:] {
+			fields := strings.Fields(line)
+			entries[fields[0]] = strings.Join(fields[1:], " ")
+		}
+		log := strings.TrimPrefix(entries["Match"], "raw.")
+		switch entries["Name"] {
+		case "es":
+			outputs = append(outputs, fmt.Sprintf("es %s %s:%s", log, entries["Host"], entries["Port"]))
+		case "s3":
+			outputs = append(outputs, fmt.Sprintf("s3 %s %s/%s", log, entries["bucket"], entries["region"]))
+		case "syslog":
+			outputs = append(outputs, fmt.Sprintf("syslog %s %s://%s:%s %s",
+				log, entries["Mode"], entries["Host"], entries["Port"], entries["Syslog_Format"]))
+		default:
+			outputs = append(outputs, "unknown "+entries["Name"])
+		}
+	}
+	return outputs
+}
+
+// fluentdOutputs returns the outputs of flow and DNS logs configured by the env vars of the fluentd container, in
+// the format of fluentBitOutputs.
+func fluentdOutputs(container corev1.Container) []string {
+	envs := map[string]string{}
+	for _, env := range container.Env {
+		envs[env.Name] = env.Value
+	}
+	var outputs []string
+	for _, log := range []struct{ name, env string }{{"flows", "FLOW_LOG"}, {"dns", "DNS_LOG"}} {
+		outputs = append(outputs, fmt.Sprintf("es %s %s:%s", log.name, envs["ELASTIC_HOST"], envs["ELASTIC_PORT"]))
+		if envs["S3_"+log.env] == "true" {
+			outputs = append(outputs, fmt.Sprintf("s3 %s %s/%s", log.name, envs["S3_BUCKET_NAME"], envs["AWS_REGION"]))
+		}
+		if envs["SYSLOG_"+log.env] == "true" {
+			proto := envs["SYSLOG_PROTOCOL"]
+			if envs["SYSLOG_TLS"] == "true" {
+				proto = "tls"
+			}
+			outputs = append(outputs, fmt.Sprintf("syslog %s %s://%s:%s %s",
+				log.name, proto, envs["SYSLOG_HOST"], envs["SYSLOG_PORT"], envs["SYSLOG_FORMAT"]))
+		}
+	}
+	return outputs
+}
+
+var _ = Describe("Tigera Secure Fluent Bit rendering tests", func() {
+	var instance *operatorv1.LogCollector
+	var installation *operatorv1.Installation
+	var esConfigMap *render.ElasticsearchClusterConfig
+	BeforeEach(func() {
+		instance = &operatorv1.LogCollector{
+			Spec: operatorv1.LogCollectorSpec{Collector: operatorv1.LogCollectorTypeFluentBit},
+		}
+		installation = &operatorv1.Installation{
+			Spec: operatorv1.InstallationSpec{
+				KubernetesProvider: operatorv1.ProviderNone,
+			},
+		}
+		esConfigMap = render.NewElasticsearchClusterConfig("clusterTestName", 1, 1, "")
+	})
+
+	It("should render the Fluent Bit collector instead of fluentd", func() {
+		component := render.Fluentd(instance, nil, esConfigMap, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, installation)
+		resources, toDelete := component.Objects()
+		Expect(len(resources)).To(Equal(3))
+		ExpectResource(resources[0], "tigera-fluentd", "", "", "v1", "Namespace")
+		ExpectResource(resources[1], "fluent-bit-config", "tigera-fluentd", "", "v1", "ConfigMap")
+		ExpectResource(resources[2], "fluent-bit-node", "tigera-fluentd", "apps", "v1", "DaemonSet")
+		Expect(len(toDelete)).To(Equal(1))
+		ExpectResource(toDelete[0], "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet")
+
+		ds := resources[2].(*apps.DaemonSet)
+		Expect(ds.Spec.Template.Annotations).To(HaveKey("hash.operator.tigera.io/fluent-bit-config"))
+		container := ds.Spec.Template.Spec.Containers[0]
+		Expect(container.Image).To(ContainSubstring("tigera/fluent-bit"))
+		Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{
+			Name: "fluent-bit-config", MountPath: "/fluent-bit/etc", ReadOnly: true,
+		}))
+
+		config := resources[1].(*corev1.ConfigMap).Data["fluent-bit.conf"]
+		Expect(config).To(ContainSubstring("Path                     /var/log/calico/flowlogs/flows.log"))
+		Expect(config).To(ContainSubstring("Path                     /var/log/calico/dnslogs/dns.log"))
+		Expect(config).To(ContainSubstring("Index                    tigera_secure_ee_flows.${ELASTIC_INDEX_SUFFIX}."))
+		Expect(config).To(ContainSubstring("tls.ca_file              /etc/ssl/elastic/ca.pem"))
+	})
+
+	It("should delete the Fluent Bit collector when fluentd is used", func() {
+		instance.Spec.Collector = ""
+		component := render.Fluentd(instance, nil, esConfigMap, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, installation)
+		_, toDelete := component.Objects()
+		Expect(len(toDelete)).To(Equal(2))
+		ExpectResource(toDelete[0], "fluent-bit-config", "tigera-fluentd", "", "v1", "ConfigMap")
+		ExpectResource(toDelete[1], "fluent-bit-node", "tigera-fluentd", "apps", "v1", "DaemonSet")
+	})
+
+	It("should render the buffer settings", func() {
+		instance.Spec.Buffer = &operatorv1.FluentdBufferSpec{FlushInterval: 10, RetryWait: 2}
+		component := render.Fluentd(instance, nil, esConfigMap, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, installation)
+		resources, _ := component.Objects()
+		config := GetResource(resources, "fluent-bit-config", "tigera-fluentd", "", "v1", "ConfigMap").(*corev1.ConfigMap).Data["fluent-bit.conf"]
+		for _, expected := range []string{
+			"Flush                    10\n",
+			"storage.path             /fluent-bit/buffers\n",
+			"scheduler.base           2\n",
+			"scheduler.cap            60\n",
+			"storage.type             filesystem\n",
+			"storage.total_limit_size 536870912\n",
+		} {
+			Expect(config).To(ContainSubstring(expected))
+		}
+	})
+
+	DescribeTable("should send the same logs to the same outputs as fluentd",
+		func(stores *operatorv1.AdditionalLogStoreSpec, syslogC *render.SyslogCredential) {
+			var s3C *render.S3Credential
+			if stores != nil && stores.S3 != nil {
+				s3C = &render.S3Credential{KeyId: []byte("id"), KeySecret: []byte("secret")}
+			}
+			objects := func(collector operatorv1.LogCollectorType) []runtime.Object {
+				lc := &operatorv1.LogCollector{
+					Spec: operatorv1.LogCollectorSpec{Collector: collector, AdditionalStores: stores},
+				}
+				resources, _ := render.Fluentd(lc, nil, esConfigMap, s3C, syslogC, nil, nil, nil, nil, nil, nil, nil, nil, installation).Objects()
+				return resources
+			}
+
+			fluentd := GetResource(objects(operatorv1.LogCollectorTypeFluentd), "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*apps.DaemonSet)
+			fluentBit := GetResource(objects(operatorv1.LogCollectorTypeFluentBit), "fluent-bit-config", "tigera-fluentd", "", "v1", "ConfigMap").(*corev1.ConfigMap)
+
+			expected := fluentdOutputs(fluentd.Spec.Template.Spec.Containers[0])
+			Expect(fluentBitOutputs(fluentBit.Data["fluent-bit.conf"])).To(ConsistOf(expected))
+		},
+		Entry("Elasticsearch only", nil, nil),
+		Entry("S3", &operatorv1.AdditionalLogStoreSpec{
+			S3: &operatorv1.S3StoreSpec{Region: "us-west-1", BucketName: "logs", BucketPath: "cluster-a"},
+		}, nil),
+		Entry("syslog", &operatorv1.AdditionalLogStoreSpec{
+			Syslog: &operatorv1.SyslogStoreSpec{Endpoint: "udp://1.2.3.4:514", Format: operatorv1.SyslogFormatRFC5424},
+		}, nil),
+		Entry("TLS syslog", &operatorv1.AdditionalLogStoreSpec{
+			Syslog: &operatorv1.SyslogStoreSpec{Endpoint: "tls://syslog.example.com:6514"},
+		}, &render.SyslogCredential{CA: []byte("ca")}),
+		Entry("S3 and syslog with log types", &operatorv1.AdditionalLogStoreSpec{
+			S3: &operatorv1.S3StoreSpec{
+				Region:     "us-west-1",
+				BucketName: "logs",
+				LogTypes:   []operatorv1.StoreLogType{operatorv1.StoreLogTypeDNS},
+			},
+			Syslog: &operatorv1.SyslogStoreSpec{
+				Endpoint: "tcp://1.2.3.4:601",
+				LogTypes: []operatorv1.StoreLogType{operatorv1.StoreLogTypeFlows, operatorv1.StoreLogTypeDNS},
+			},
+		}, nil),
+	)
+
+	It("should render the TLS credentials of syslog", func() {
+		instance.Spec.AdditionalStores = &operatorv1.AdditionalLogStoreSpec{
+			Syslog: &operatorv1.SyslogStoreSpec{Endpoint: "tls://1.2.3.4:6514"},
+		}
+		syslogC := &render.SyslogCredential{CA: []byte("ca"), Cert: []byte("cert"), Key: []byte("key")}
+		component := render.Fluentd(instance, nil, esConfigMap, nil, syslogC, nil, nil, nil, nil, nil, nil, nil, nil, installation)
+		resources, _ := component.Objects()
+
+		config := GetResource(resources, "fluent-bit-config", "tigera-fluentd", "", "v1", "ConfigMap").(*corev1.ConfigMap).Data["fluent-bit.conf"]
+		for _, expected := range []string{
+			"tls.ca_file              /fluent-bit/syslog/ca.crt\n",
+			"tls.crt_file             /fluent-bit/syslog/tls.crt\n",
+			"tls.key_file             /fluent-bit/syslog/tls.key\n",
+			"Record                   hostname ${SYSLOG_HOSTNAME}\n",
+		} {
+			Expect(config).To(ContainSubstring(expected))
+		}
+
+		ds := GetResource(resources, "fluent-bit-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*apps.DaemonSet)
+		Expect(ds.Spec.Template.Spec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+			Name: "syslog-credentials", MountPath: "/fluent-bit/syslog", ReadOnly: true,
+		}))
+	})
+})
@@ -15,14 +15,19 @@
 package render
 
 import (
+	"encoding/json"
 	"fmt"
+	"path/filepath"
 	"strconv"
+	"strings"
 
 	operatorv1 "github.com/tigera/operator/pkg/apis/operator/v1"
+	"github.com/tigera/operator/pkg/common"
 	"github.com/tigera/operator/pkg/components"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -33,20 +38,66 @@ const (
 	FluentdFilterConfigMapName               = "fluentd-filters"
 	FluentdFilterFlowName                    = "flow"
 	FluentdFilterDNSName                     = "dns"
+	FluentdStoreFilterConfigMapName          = "fluentd-store-filters"
 	S3FluentdSecretName                      = "log-collector-s3-credentials"
 	S3KeyIdName                              = "key-id"
 	S3KeySecretName                          = "key-secret"
+	SyslogFluentdSecretName                  = "log-collector-syslog-credentials"
+	SyslogCAName                             = "ca.crt"
+	SyslogCertName                           = "tls.crt"
+	SyslogKeyName                            = "tls.key"
+	SplunkFluentdSecretName                  = "log-collector-splunk-credentials"
+	SplunkTokenName                          = "token"
+	KafkaFluentdSecretName                   = "log-collector-kafka-credentials"
+	KafkaCAName                              = "ca.crt"
+	KafkaCertName                            = "tls.crt"
+	KafkaKeyName                             = "tls.key"
+	KafkaUsernameName                        = "username"
+	KafkaPasswordName                        = "password"
+	HTTPFluentdSecretName                    = "log-collector-http-credentials"
+	HTTPUsernameName                         = "username"
+	HTTPPasswordName                         = "password"
+	HTTPTokenName                            = "token"
 	elasticsearchSecretsAnnotation           = "hash.operator.tigera.io/elasticsearch-secrets"
 	filterHashAnnotation                     = "hash.operator.tigera.io/fluentd-filters"
+	storeFilterHashAnnotation                = "hash.operator.tigera.io/fluentd-store-filters"
+	storeFiltersPath                         = "/etc/fluentd/store-filters"
 	s3CredentialHashAnnotation               = "hash.operator.tigera.io/s3-credentials"
+	syslogCredentialHashAnnotation           = "hash.operator.tigera.io/syslog-credentials"
+	splunkCredentialHashAnnotation           = "hash.operator.tigera.io/splunk-credentials"
+	kafkaCredentialHashAnnotation            = "hash.operator.tigera.io/kafka-credentials"
+	httpCredentialHashAnnotation             = "hash.operator.tigera.io/http-credentials"
+	syslogCredentialsPath                    = "/etc/fluentd/syslog"
+	kafkaCredentialsPath                     = "/etc/fluentd/kafka"
 	eksCloudwatchLogCredentialHashAnnotation = "hash.operator.tigera.io/eks-cloudwatch-log-credentials"
 	fluentdDefaultFlush                      = "5s"
+	fluentdDefaultBufferPath                 = "/var/lib/calico/fluentd-buffers"
+	fluentdDefaultBufferLimit                = "512Mi"
+	fluentdDefaultRetryWait                  = 1
+	fluentdDefaultRetryMaxInterval           = 60
+	fluentdDefaultRetryTimeout               = 259200
+	fluentdBufferMountPath                   = "/fluentd/buffers"
+	fluentdKubeAuditLogMountPath             = "/var/log/kube-apiserver-audit"
+	FluentdMonitorPort                       = 24220
+	FluentdNodeName                          = "fluentd-node"
 	ElasticsearchLogCollectorUserSecret      = "tigera-fluentd-elasticsearch-access"
 	ElasticsearchEksLogForwarderUserSecret   = "tigera-eks-log-forwarder-elasticsearch-access"
 	EksLogForwarderSecret                    = "tigera-eks-log-forwarder-secret"
 	EksLogForwarderAwsId                     = "aws-id"
 	EksLogForwarderAwsKey                    = "aws-key"
 	eksLogForwarderName                      = "eks-log-forwarder"
+	ElasticsearchAksLogForwarderUserSecret   = "tigera-aks-log-forwarder-elasticsearch-access"
+	AksLogForwarderSecret                    = "tigera-aks-log-forwarder-secret"
+	AksLogForwarderTenantId                  = "tenant-id"
+	AksLogForwarderClientId                  = "client-id"
+	AksLogForwarderClientSecret              = "client-secret"
+	aksLogForwarderName                      = "aks-log-forwarder"
+	azureMonitorLogCredentialHashAnnotation  = "hash.operator.tigera.io/azure-monitor-log-credentials"
+	ElasticsearchGkeLogForwarderUserSecret   = "tigera-gke-log-forwarder-elasticsearch-access"
+	GkeLogForwarderSecret                    = "tigera-gke-log-forwarder-secret"
+	GkeLogForwarderKey                       = "key.json"
+	gkeLogForwarderName                      = "gke-log-forwarder"
+	gcpLoggingLogCredentialHashAnnotation    = "hash.operator.tigera.io/gcp-logging-log-credentials"
 )
 
 type FluentdFilters struct {
@@ -59,26 +110,128 @@ type S3Credential struct {
 	KeySecret []byte
 }
 
+// SyslogCredential holds the TLS credentials of the syslog store. Credentials that are not used are empty.
+type SyslogCredential struct {
+	CA   []byte
+	Cert []byte
+	Key  []byte
+}
+
+type SplunkCredential struct {
+	Token []byte
+}
+
+// KafkaCredential holds the TLS and SASL credentials of the Kafka store. Credentials that are not used are empty.
+type KafkaCredential struct {
+	CA       []byte
+	Cert     []byte
+	Key      []byte
+	Username []byte
+	Password []byte
+}
+
+// HTTPCredential holds the credentials of the HTTP store, either a username and password or a token.
+type HTTPCredential struct {
+	Username []byte
+	Password []byte
+	Token    []byte
+}
+
+// storeLogTypeEnvs are the suffixes of the env vars that enable sending each type of log to an additional store.
+var storeLogTypeEnvs = []struct {
+	logType operatorv1.StoreLogType
+	env     string
+}{
+	{operatorv1.StoreLogTypeFlows, "FLOW_LOG"},
+	{operatorv1.StoreLogTypeDNS, "DNS_LOG"},
+	{operatorv1.StoreLogTypeEEAudit, "AUDIT_EE_LOG"},
+	{operatorv1.StoreLogTypeKubeAudit, "AUDIT_KUBE_LOG"},
+	{operatorv1.StoreLogTypeIDSEvents, "IDS_EVENT_LOG"},
+	{operatorv1.StoreLogTypeBGP, "BGP_LOG"},
+	{operatorv1.StoreLogTypeL7, "L7_LOG"},
+}
+
+var defaultStoreLogTypes = []operatorv1.StoreLogType{
+	operatorv1.StoreLogTypeFlows,
+	operatorv1.StoreLogTypeDNS,
+	operatorv1.StoreLogTypeEEAudit,
+	operatorv1.StoreLogTypeKubeAudit,
+}
+
+// Syslog has never received DNS logs, so they are left out of its defaults.
+var defaultSyslogLogTypes = []operatorv1.StoreLogType{
+	operatorv1.StoreLogTypeFlows,
+	operatorv1.StoreLogTypeEEAudit,
+	operatorv1.StoreLogTypeKubeAudit,
+}
+
+// containerLogHostPaths are the directories on each node that hold the container log files and the files they link to.
+var containerLogHostPaths = []struct{ name, path string }{
+	{"var-log-containers", "/var/log/containers"},
+	{"var-log-pods", "/var/log/pods"},
+	{"docker-containers", "/var/lib/docker/containers"},
+}
+
+// componentLogNamespaces returns the namespaces managed by the operator whose container logs are collected when
+// component logs are enabled.
+func componentLogNamespaces() []string {
+	return []string{
+		OperatorNamespace(),
+		common.CalicoNamespace,
+		APIServerNamespace,
+		ComplianceNamespace,
+		IntrusionDetectionNamespace,
+		LogCollectorNamespace,
+		ECKOperatorNamespace,
+		ElasticsearchNamespace,
+		KibanaNamespace,
+		ManagerNamespace,
+		GuardianNamespace,
+		TigeraPrometheusNamespace,
+	}
+}
+
+// additionalStore holds the settings that all additional log stores have in common.
+type additionalStore struct {
+	// name is the key of the store's filter in the store filters ConfigMap and, in upper case, the prefix of the
+	// store's env vars.
+	name     string
+	logTypes []operatorv1.StoreLogType
+	filter   string
+}
+
 func Fluentd(
 	lc *operatorv1.LogCollector,
 	esSecrets []*corev1.Secret,
 	esClusterConfig *ElasticsearchClusterConfig,
 	s3C *S3Credential,
+	syslogC *SyslogCredential,
+	splunkC *SplunkCredential,
+	kafkaC *KafkaCredential,
+	httpC *HTTPCredential,
 	f *FluentdFilters,
 	eksConfig *EksCloudwatchLogConfig,
+	azureConfig *AzureMonitorLogConfig,
+	gcpConfig *GcpLoggingLogConfig,
 
 	pullSecrets []*corev1.Secret,
 	installation *operatorv1.Installation,
 ) Component {
 	return &fluentdComponent{
-		lc:              lc,
-		esSecrets:       esSecrets,
-		esClusterConfig: esClusterConfig,
-		s3Credential:    s3C,
-		filters:         f,
-		eksConfig:       eksConfig,
-		pullSecrets:     pullSecrets,
-		installation:    installation,
+		lc:               lc,
+		esSecrets:        esSecrets,
+		esClusterConfig:  esClusterConfig,
+		s3Credential:     s3C,
+		syslogCredential: syslogC,
+		splunkCredential: splunkC,
+		kafkaCredential:  kafkaC,
+		httpCredential:   httpC,
+		filters:          f,
+		eksConfig:        eksConfig,
+		azureConfig:      azureConfig,
+		gcpConfig:        gcpConfig,
+		pullSecrets:      pullSecrets,
+		installation:     installation,
 	}
 }
 
@@ -89,17 +242,42 @@ type EksCloudwatchLogConfig struct {
 	GroupName     string
 	StreamPrefix  string
 	FetchInterval int32
+	// RoleARN is the IAM role assumed with web identity credentials, in which case there are no AWS keys.
+	RoleARN string
+}
+
+type AzureMonitorLogConfig struct {
+	TenantId      []byte
+	ClientId      []byte
+	ClientSecret  []byte
+	WorkspaceId   string
+	FetchInterval int32
+}
+
+type GcpLoggingLogConfig struct {
+	// Key is the JSON key of a GCP service account. It is empty when Workload Identity is used.
+	Key            []byte
+	ProjectId      string
+	ClusterName    string
+	FetchInterval  int32
+	ServiceAccount string
 }
 
 type fluentdComponent struct {
-	lc              *operatorv1.LogCollector
-	esSecrets       []*corev1.Secret
-	esClusterConfig *ElasticsearchClusterConfig
-	s3Credential    *S3Credential
-	filters         *FluentdFilters
-	eksConfig       *EksCloudwatchLogConfig
-	pullSecrets     []*corev1.Secret
-	installation    *operatorv1.Installation
+	lc               *operatorv1.LogCollector
+	esSecrets        []*corev1.Secret
+	esClusterConfig  *ElasticsearchClusterConfig
+	s3Credential     *S3Credential
+	syslogCredential *SyslogCredential
+	splunkCredential *SplunkCredential
+	kafkaCredential  *KafkaCredential
+	httpCredential   *HTTPCredential
+	filters          *FluentdFilters
+	eksConfig        *EksCloudwatchLogConfig
+	azureConfig      *AzureMonitorLogConfig
+	gcpConfig        *GcpLoggingLogConfig
+	pullSecrets      []*corev1.Secret
+	installation     *operatorv1.Installation
 }
 
 func (c *fluentdComponent) Objects() ([]runtime.Object, []runtime.Object) {
@@ -112,19 +290,67 @@ func (c *fluentdComponent) Objects() ([]runtime.Object, []runtime.Object) {
 	if c.s3Credential != nil {
 		objs = append(objs, c.s3CredentialSecret())
 	}
+	if c.syslogCredential != nil {
+		objs = append(objs, credentialSecret(SyslogFluentdSecretName, map[string][]byte{
+			SyslogCAName:   c.syslogCredential.CA,
+			SyslogCertName: c.syslogCredential.Cert,
+			SyslogKeyName:  c.syslogCredential.Key,
+		}))
+	}
+	if c.splunkCredential != nil {
+		objs = append(objs, credentialSecret(SplunkFluentdSecretName, map[string][]byte{
+			SplunkTokenName: c.splunkCredential.Token,
+		}))
+	}
+	if c.kafkaCredential != nil {
+		objs = append(objs, credentialSecret(KafkaFluentdSecretName, map[string][]byte{
+			KafkaCAName:       c.kafkaCredential.CA,
+			KafkaCertName:     c.kafkaCredential.Cert,
+			KafkaKeyName:      c.kafkaCredential.Key,
+			KafkaUsernameName: c.kafkaCredential.Username,
+			KafkaPasswordName: c.kafkaCredential.Password,
+		}))
+	}
+	if c.httpCredential != nil {
+		objs = append(objs, credentialSecret(HTTPFluentdSecretName, map[string][]byte{
+			HTTPUsernameName: c.httpCredential.Username,
+			HTTPPasswordName: c.httpCredential.Password,
+			HTTPTokenName:    c.httpCredential.Token,
+		}))
+	}
 	if c.filters != nil {
 		objs = append(objs, c.filtersConfigMap())
 	}
+	if filters := c.storeFilters(); len(filters) != 0 {
+		objs = append(objs, c.storeFiltersConfigMap(filters))
+	}
 	if c.eksConfig != nil {
-		objs = append(objs, c.eksLogForwarderServiceAccount(),
-			c.eksLogForwarderSecret(),
-			c.eksLogForwarderDeployment())
+		objs = append(objs, c.logForwarderObjects(c.eksLogForwarder())...)
+	}
+	if c.azureConfig != nil {
+		objs = append(objs, c.logForwarderObjects(c.aksLogForwarder())...)
+	}
+	if c.gcpConfig != nil {
+		objs = append(objs, c.logForwarderObjects(c.gkeLogForwarder())...)
 	}
 
 	objs = append(objs, secretsToRuntimeObjects(CopySecrets(LogCollectorNamespace, c.esSecrets...)...)...)
-	objs = append(objs, c.daemonset())
 
-	return objs, nil
+	// Only one of the collectors runs, the other is removed when the collector is changed.
+	var objsToDelete []runtime.Object
+	if c.fluentBit() {
+		objs = append(objs, c.fluentBitConfigMap(), c.fluentBitDaemonset())
+		objsToDelete = []runtime.Object{c.daemonset()}
+	} else {
+		objs = append(objs, c.daemonset())
+		objsToDelete = []runtime.Object{c.fluentBitConfigMap(), c.fluentBitDaemonset()}
+	}
+	if c.eksConfig != nil && c.eksConfig.RoleARN != "" {
+		// The AWS keys are no longer needed once the log forwarder assumes a role.
+		objsToDelete = append(objsToDelete, c.eksLogForwarderSecret())
+	}
+
+	return objs, objsToDelete
 }
 
 func (c *fluentdComponent) Ready() bool {
@@ -148,6 +374,25 @@ func (c *fluentdComponent) s3CredentialSecret() *corev1.Secret {
 	}
 }
 
+// credentialSecret returns the Secret in the log collector namespace holding the non-empty credentials of a log
+// store.
+func credentialSecret(name string, data map[string][]byte) *corev1.Secret {
+	secret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: LogCollectorNamespace,
+		},
+		Data: map[string][]byte{},
+	}
+	for key, value := range data {
+		if len(value) != 0 {
+			secret.Data[key] = value
+		}
+	}
+	return secret
+}
+
 func (c *fluentdComponent) filtersConfigMap() *corev1.ConfigMap {
 	if c.filters == nil {
 		return nil
@@ -165,6 +410,60 @@ func (c *fluentdComponent) filtersConfigMap() *corev1.ConfigMap {
 	}
 }
 
+// additionalStores returns the additional log stores that are configured, with default log types filled in.
+func (c *fluentdComponent) additionalStores() []additionalStore {
+	spec := c.lc.Spec.AdditionalStores
+	if spec == nil {
+		return nil
+	}
+	logTypes := func(logTypes, defaults []operatorv1.StoreLogType) []operatorv1.StoreLogType {
+		if len(logTypes) == 0 {
+			return defaults
+		}
+		return logTypes
+	}
+
+	var stores []additionalStore
+	if spec.S3 != nil {
+		stores = append(stores, additionalStore{"s3", logTypes(spec.S3.LogTypes, defaultStoreLogTypes), spec.S3.Filter})
+	}
+	if spec.Syslog != nil {
+		stores = append(stores, additionalStore{"syslog", logTypes(spec.Syslog.LogTypes, defaultSyslogLogTypes), spec.Syslog.Filter})
+	}
+	if spec.Splunk != nil {
+		stores = append(stores, additionalStore{"splunk", logTypes(spec.Splunk.LogTypes, defaultStoreLogTypes), spec.Splunk.Filter})
+	}
+	if spec.Kafka != nil {
+		stores = append(stores, additionalStore{"kafka", logTypes(spec.Kafka.LogTypes, defaultStoreLogTypes), spec.Kafka.Filter})
+	}
+	if spec.HTTP != nil {
+		stores = append(stores, additionalStore{"http", logTypes(spec.HTTP.LogTypes, defaultStoreLogTypes), spec.HTTP.Filter})
+	}
+	return stores
+}
+
+// storeFilters returns the filters of the additional log stores, keyed by store name.
+func (c *fluentdComponent) storeFilters() map[string]string {
+	filters := map[string]string{}
+	for _, store := range c.additionalStores() {
+		if store.filter != "" {
+			filters[store.name] = store.filter
+		}
+	}
+	return filters
+}
+
+func (c *fluentdComponent) storeFiltersConfigMap(filters map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      FluentdStoreFilterConfigMapName,
+			Namespace: LogCollectorNamespace,
+		},
+		Data: filters,
+	}
+}
+
 // managerDeployment creates a deployment for the Tigera Secure manager component.
 func (c *fluentdComponent) daemonset() *appsv1.DaemonSet {
 	var terminationGracePeriod int64 = 0
@@ -174,14 +473,29 @@ func (c *fluentdComponent) daemonset() *appsv1.DaemonSet {
 	if c.s3Credential != nil {
 		annots[s3CredentialHashAnnotation] = AnnotationHash(c.s3Credential)
 	}
+	if c.syslogCredential != nil {
+		annots[syslogCredentialHashAnnotation] = AnnotationHash(c.syslogCredential)
+	}
+	if c.splunkCredential != nil {
+		annots[splunkCredentialHashAnnotation] = AnnotationHash(c.splunkCredential)
+	}
+	if c.kafkaCredential != nil {
+		annots[kafkaCredentialHashAnnotation] = AnnotationHash(c.kafkaCredential)
+	}
+	if c.httpCredential != nil {
+		annots[httpCredentialHashAnnotation] = AnnotationHash(c.httpCredential)
+	}
 	if c.filters != nil {
 		annots[filterHashAnnotation] = AnnotationHash(c.filters)
 	}
+	if filters := c.storeFilters(); len(filters) != 0 {
+		annots[storeFilterHashAnnotation] = AnnotationHash(filters)
+	}
 
 	podTemplate := ElasticsearchDecorateAnnotations(&corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
 			Labels: map[string]string{
-				"k8s-app": "fluentd-node",
+				"k8s-app": FluentdNodeName,
 			},
 			Annotations: annots,
 		},
@@ -198,11 +512,11 @@ func (c *fluentdComponent) daemonset() *appsv1.DaemonSet {
 	ds := &appsv1.DaemonSet{
 		TypeMeta: metav1.TypeMeta{Kind: "DaemonSet", APIVersion: "apps/v1"},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "fluentd-node",
+			Name:      FluentdNodeName,
 			Namespace: LogCollectorNamespace,
 		},
 		Spec: appsv1.DaemonSetSpec{
-			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"k8s-app": "fluentd-node"}},
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"k8s-app": FluentdNodeName}},
 			Template: *podTemplate,
 			UpdateStrategy: appsv1.DaemonSetUpdateStrategy{
 				RollingUpdate: &appsv1.RollingUpdateDaemonSet{
@@ -252,29 +566,64 @@ func (c *fluentdComponent) container() corev1.Container {
 				})
 		}
 	}
+	if len(c.storeFilters()) != 0 {
+		volumeMounts = append(volumeMounts,
+			corev1.VolumeMount{Name: "fluentd-store-filters", MountPath: storeFiltersPath, ReadOnly: true})
+	}
+	if len(c.syslogCredentialFiles()) != 0 {
+		volumeMounts = append(volumeMounts,
+			corev1.VolumeMount{Name: "syslog-credentials", MountPath: syslogCredentialsPath, ReadOnly: true})
+	}
+	if len(c.kafkaCredentialFiles()) != 0 {
+		volumeMounts = append(volumeMounts,
+			corev1.VolumeMount{Name: "kafka-credentials", MountPath: kafkaCredentialsPath, ReadOnly: true})
+	}
+	if c.buffer() != nil {
+		volumeMounts = append(volumeMounts,
+			corev1.VolumeMount{Name: "fluentd-buffers", MountPath: fluentdBufferMountPath})
+	}
+	if c.collectContainerLogs() {
+		// The container log files are links, so the directories are mounted at their paths on the host.
+		for _, p := range containerLogHostPaths {
+			volumeMounts = append(volumeMounts,
+				corev1.VolumeMount{Name: p.name, MountPath: p.path, ReadOnly: true})
+		}
+	}
+	if c.kubeAuditLogPath() != "" {
+		volumeMounts = append(volumeMounts,
+			corev1.VolumeMount{Name: "kube-audit-log", MountPath: fluentdKubeAuditLogMountPath, ReadOnly: true})
+	}
+
+	resources := corev1.ResourceRequirements{}
+	if c.lc.Spec.ResourceRequirements != nil {
+		resources = *c.lc.Spec.ResourceRequirements
+	}
 
 	isPrivileged := true
 
 	return ElasticsearchContainerDecorateENVVars(corev1.Container{
 		Name:            "fluentd",
-		Image:           components.GetReference(components.ComponentFluentd, c.installation.Spec.Registry),
+		Image:           components.GetReference(components.ComponentFluentd, c.installation),
 		Env:             envs,
+		Resources:       resources,
+		Ports:           []corev1.ContainerPort{{Name: "monitor", ContainerPort: FluentdMonitorPort}},
 		SecurityContext: &corev1.SecurityContext{Privileged: &isPrivileged},
 		VolumeMounts:    volumeMounts,
 		LivenessProbe:   c.liveness(),
 		ReadinessProbe:  c.readiness(),
-	}, c.esClusterConfig.ClusterName(), ElasticsearchLogCollectorUserSecret)
+	}, c.esClusterConfig, ElasticsearchLogCollectorUserSecret)
 }
 
 func (c *fluentdComponent) envvars() []corev1.EnvVar {
 	envs := []corev1.EnvVar{
 		{Name: "FLUENT_UID", Value: "0"},
-		{Name: "ELASTIC_FLOWS_INDEX_SHARDS", Value: "5"},
-		{Name: "ELASTIC_DNS_INDEX_SHARDS", Value: "5"},
 		{Name: "FLOW_LOG_FILE", Value: "/var/log/calico/flowlogs/flows.log"},
 		{Name: "DNS_LOG_FILE", Value: "/var/log/calico/dnslogs/dns.log"},
 		{Name: "FLUENTD_ES_SECURE", Value: "true"},
+		{Name: "FLUENTD_MONITOR_PORT", Value: strconv.Itoa(FluentdMonitorPort)},
+		{Name: "ELASTIC_FLUSH_INTERVAL", Value: c.flushInterval(0)},
 	}
+	envs = append(envs, c.bufferEnvvars()...)
 
 	if c.lc.Spec.AdditionalStores != nil {
 		s3 := c.lc.Spec.AdditionalStores.S3
@@ -302,19 +651,38 @@ func (c *fluentdComponent) envvars() []corev1.EnvVar {
 				corev1.EnvVar{Name: "S3_BUCKET_NAME", Value: s3.BucketName},
 				corev1.EnvVar{Name: "AWS_REGION", Value: s3.Region},
 				corev1.EnvVar{Name: "S3_BUCKET_PATH", Value: s3.BucketPath},
-				corev1.EnvVar{Name: "S3_FLUSH_INTERVAL", Value: fluentdDefaultFlush},
+				corev1.EnvVar{Name: "S3_FLUSH_INTERVAL", Value: c.flushInterval(s3.FlushInterval)},
 			)
 		}
 		syslog := c.lc.Spec.AdditionalStores.Syslog
 		if syslog != nil {
+			// The endpoint has been validated by the controller.
 			proto, host, port, _ := ParseEndpoint(syslog.Endpoint)
+			format := syslog.Format
+			if format == "" {
+				format = operatorv1.SyslogFormatRFC3164
+			}
+			if proto == "tls" {
+				// TLS is carried over TCP.
+				proto = "tcp"
+				envs = append(envs, corev1.EnvVar{Name: "SYSLOG_TLS", Value: "true"})
+				files := c.syslogCredentialFiles()
+				for _, f := range []struct{ env, key string }{
+					{"SYSLOG_CA_FILE", SyslogCAName},
+					{"SYSLOG_CLIENT_CERT_FILE", SyslogCertName},
+					{"SYSLOG_CLIENT_KEY_FILE", SyslogKeyName},
+				} {
+					if files[f.key] {
+						envs = append(envs, corev1.EnvVar{Name: f.env, Value: syslogCredentialsPath + "/" + f.key})
+					}
+				}
+			}
 			envs = append(envs,
-				corev1.EnvVar{Name: "SYSLOG_FLOW_LOG", Value: "true"},
-				corev1.EnvVar{Name: "SYSLOG_AUDIT_LOG", Value: "true"},
 				corev1.EnvVar{Name: "SYSLOG_HOST", Value: host},
 				corev1.EnvVar{Name: "SYSLOG_PORT", Value: port},
 				corev1.EnvVar{Name: "SYSLOG_PROTOCOL", Value: proto},
-				corev1.EnvVar{Name: "SYSLOG_FLUSH_INTERVAL", Value: fluentdDefaultFlush},
+				corev1.EnvVar{Name: "SYSLOG_FORMAT", Value: strings.ToLower(string(format))},
+				corev1.EnvVar{Name: "SYSLOG_FLUSH_INTERVAL", Value: c.flushInterval(syslog.FlushInterval)},
 				corev1.EnvVar{Name: "SYSLOG_HOSTNAME",
 					ValueFrom: &corev1.EnvVarSource{
 						FieldRef: &corev1.ObjectFieldSelector{
@@ -332,6 +700,31 @@ func (c *fluentdComponent) envvars() []corev1.EnvVar {
 				)
 			}
 		}
+		if splunk := c.lc.Spec.AdditionalStores.Splunk; splunk != nil {
+			envs = append(envs, c.splunkEnvvars(splunk)...)
+		}
+		if kafka := c.lc.Spec.AdditionalStores.Kafka; kafka != nil {
+			envs = append(envs, c.kafkaEnvvars(kafka)...)
+		}
+		if http := c.lc.Spec.AdditionalStores.HTTP; http != nil {
+			envs = append(envs, c.httpEnvvars(http)...)
+		}
+	}
+
+	for _, store := range c.additionalStores() {
+		prefix := strings.ToUpper(store.name)
+		for _, t := range storeLogTypeEnvs {
+			enabled := false
+			for _, logType := range store.logTypes {
+				if logType == t.logType {
+					enabled = true
+				}
+			}
+			envs = append(envs, corev1.EnvVar{Name: prefix + "_" + t.env, Value: strconv.FormatBool(enabled)})
+		}
+		if store.filter != "" {
+			envs = append(envs, corev1.EnvVar{Name: prefix + "_FILTERS", Value: fmt.Sprintf("%s/%s", storeFiltersPath, store.name)})
+		}
 	}
 
 	if c.filters != nil {
@@ -345,19 +738,217 @@ func (c *fluentdComponent) envvars() []corev1.EnvVar {
 		}
 	}
 
-	envs = append(envs,
-		corev1.EnvVar{Name: "ELASTIC_FLOWS_INDEX_REPLICAS", Value: strconv.Itoa(c.esClusterConfig.Replicas())},
-		corev1.EnvVar{Name: "ELASTIC_DNS_INDEX_REPLICAS", Value: strconv.Itoa(c.esClusterConfig.Replicas())},
-		corev1.EnvVar{Name: "ELASTIC_AUDIT_INDEX_REPLICAS", Value: strconv.Itoa(c.esClusterConfig.Replicas())},
+	indices := []ElasticsearchIndex{FlowsIndex, DNSIndex, AuditIndex}
+	if c.collectContainerLogs() {
+		var files []string
+		for _, ns := range componentLogNamespaces() {
+			files = append(files, fmt.Sprintf("/var/log/containers/*_%s_*.log", ns))
+		}
+		envs = append(envs, corev1.EnvVar{Name: "CONTAINER_LOG_FILES", Value: strings.Join(files, ",")})
+		indices = append(indices, ComponentLogsIndex)
+	}
+	if path := c.kubeAuditLogPath(); path != "" {
+		envs = append(envs,
+			corev1.EnvVar{Name: "KUBE_AUDIT_LOG_FILE", Value: fluentdKubeAuditLogMountPath + "/" + filepath.Base(path)})
+	}
 
-		corev1.EnvVar{Name: "ELASTIC_FLOWS_INDEX_SHARDS", Value: strconv.Itoa(c.esClusterConfig.Shards())},
-		corev1.EnvVar{Name: "ELASTIC_DNS_INDEX_SHARDS", Value: strconv.Itoa(c.esClusterConfig.Shards())},
-		corev1.EnvVar{Name: "ELASTIC_AUDIT_INDEX_SHARDS", Value: strconv.Itoa(c.esClusterConfig.Shards())},
-	)
+	for _, index := range indices {
+		settings := c.esClusterConfig.IndexSettings(index)
+		prefix := fmt.Sprintf("ELASTIC_%s_INDEX", strings.ToUpper(string(index)))
+		envs = append(envs,
+			corev1.EnvVar{Name: prefix + "_REPLICAS", Value: strconv.Itoa(settings.Replicas)},
+			corev1.EnvVar{Name: prefix + "_SHARDS", Value: strconv.Itoa(settings.Shards)},
+		)
+	}
 
 	return envs
 }
 
+// collectContainerLogs returns whether the container logs of the namespaces managed by the operator are collected.
+func (c *fluentdComponent) collectContainerLogs() bool {
+	return c.lc.Spec.ComponentLogs != nil && c.lc.Spec.ComponentLogs.Containers
+}
+
+// kubeAuditLogPath returns the path of the kube-apiserver audit log on the host, or an empty string if it is not
+// collected.
+func (c *fluentdComponent) kubeAuditLogPath() string {
+	if c.lc.Spec.ComponentLogs == nil {
+		return ""
+	}
+	return c.lc.Spec.ComponentLogs.KubeAPIServerAuditLogPath
+}
+
+// flushInterval returns the flush interval of a log store, which defaults to the flush interval of the buffer
+// configuration.
+func (c *fluentdComponent) flushInterval(interval int32) string {
+	if buffer := c.buffer(); interval == 0 && buffer != nil {
+		interval = buffer.FlushInterval
+	}
+	if interval == 0 {
+		return fluentdDefaultFlush
+	}
+	return fmt.Sprintf("%ds", interval)
+}
+
+// buffer returns the file buffer settings, or nil if logs are buffered in memory. Managed clusters always buffer logs
+// in files, so that the logs sent to the management cluster are kept while the tunnel to it is down.
+func (c *fluentdComponent) buffer() *operatorv1.FluentdBufferSpec {
+	if c.lc.Spec.Buffer == nil && c.installation.Spec.ClusterManagementType == operatorv1.ClusterManagementTypeManaged {
+		return &operatorv1.FluentdBufferSpec{}
+	}
+	return c.lc.Spec.Buffer
+}
+
+// bufferEnvvars returns the env vars that configure the file buffers, if they are enabled. The settings apply to the
+// buffers of all log stores.
+func (c *fluentdComponent) bufferEnvvars() []corev1.EnvVar {
+	buffer := c.buffer()
+	if buffer == nil {
+		return nil
+	}
+
+	limit := resource.MustParse(fluentdDefaultBufferLimit)
+	if buffer.TotalLimitSize != nil {
+		limit = *buffer.TotalLimitSize
+	}
+	seconds := func(value, def int32) string {
+		if value == 0 {
+			value = def
+		}
+		return fmt.Sprintf("%ds", value)
+	}
+
+	return []corev1.EnvVar{
+		{Name: "FLUENTD_BUFFER_TYPE", Value: "file"},
+		{Name: "FLUENTD_BUFFER_PATH", Value: fluentdBufferMountPath},
+		{Name: "FLUENTD_BUFFER_TOTAL_LIMIT_SIZE", Value: strconv.FormatInt(limit.Value(), 10)},
+		{Name: "FLUENTD_RETRY_WAIT", Value: seconds(buffer.RetryWait, fluentdDefaultRetryWait)},
+		{Name: "FLUENTD_RETRY_MAX_INTERVAL", Value: seconds(buffer.RetryMaxInterval, fluentdDefaultRetryMaxInterval)},
+		{Name: "FLUENTD_RETRY_TIMEOUT", Value: seconds(buffer.RetryTimeout, fluentdDefaultRetryTimeout)},
+	}
+}
+
+func (c *fluentdComponent) splunkEnvvars(splunk *operatorv1.SplunkStoreSpec) []corev1.EnvVar {
+	proto, host, port, _ := ParseEndpoint(splunk.Endpoint)
+	envs := []corev1.EnvVar{
+		{Name: "SPLUNK_HEC_HOST", Value: host},
+		{Name: "SPLUNK_HEC_PORT", Value: port},
+		{Name: "SPLUNK_PROTOCOL", Value: proto},
+		{Name: "SPLUNK_FLUSH_INTERVAL", Value: c.flushInterval(splunk.FlushInterval)},
+		{Name: "SPLUNK_HEC_TOKEN", ValueFrom: envVarSourceFromSecret(SplunkFluentdSecretName, SplunkTokenName, false)},
+	}
+	if splunk.Index != "" {
+		envs = append(envs, corev1.EnvVar{Name: "SPLUNK_INDEX", Value: splunk.Index})
+	}
+	return envs
+}
+
+func (c *fluentdComponent) kafkaEnvvars(kafka *operatorv1.KafkaStoreSpec) []corev1.EnvVar {
+	envs := []corev1.EnvVar{
+		{Name: "KAFKA_BROKERS", Value: strings.Join(kafka.Brokers, ",")},
+		{Name: "KAFKA_TOPIC", Value: kafka.Topic},
+		{Name: "KAFKA_FLUSH_INTERVAL", Value: c.flushInterval(kafka.FlushInterval)},
+	}
+	if kafka.TLS {
+		envs = append(envs, corev1.EnvVar{Name: "KAFKA_SSL", Value: "true"})
+		files := c.kafkaCredentialFiles()
+		for _, f := range []struct{ env, key string }{
+			{"KAFKA_SSL_CA_CERT", KafkaCAName},
+			{"KAFKA_SSL_CLIENT_CERT", KafkaCertName},
+			{"KAFKA_SSL_CLIENT_CERT_KEY", KafkaKeyName},
+		} {
+			if files[f.key] {
+				envs = append(envs, corev1.EnvVar{Name: f.env, Value: kafkaCredentialsPath + "/" + f.key})
+			}
+		}
+	}
+	if kafka.SASLMechanism != "" {
+		envs = append(envs,
+			corev1.EnvVar{Name: "KAFKA_SASL_MECHANISM", Value: string(kafka.SASLMechanism)},
+			corev1.EnvVar{Name: "KAFKA_USERNAME", ValueFrom: envVarSourceFromSecret(KafkaFluentdSecretName, KafkaUsernameName, false)},
+			corev1.EnvVar{Name: "KAFKA_PASSWORD", ValueFrom: envVarSourceFromSecret(KafkaFluentdSecretName, KafkaPasswordName, false)},
+		)
+	}
+	return envs
+}
+
+// syslogCredentialFiles returns the keys of the syslog credentials Secret that are mounted as files, which are the
+// TLS credentials when the endpoint uses TLS.
+func (c *fluentdComponent) syslogCredentialFiles() map[string]bool {
+	if c.lc.Spec.AdditionalStores == nil || c.lc.Spec.AdditionalStores.Syslog == nil || c.syslogCredential == nil {
+		return map[string]bool{}
+	}
+	if proto, _, _, _ := ParseEndpoint(c.lc.Spec.AdditionalStores.Syslog.Endpoint); proto != "tls" {
+		return map[string]bool{}
+	}
+	return tlsCredentialFiles(c.syslogCredential.CA, c.syslogCredential.Cert, c.syslogCredential.Key)
+}
+
+// kafkaCredentialFiles returns the keys of the Kafka credentials Secret that are mounted as files, which are the
+// TLS credentials when TLS is enabled.
+func (c *fluentdComponent) kafkaCredentialFiles() map[string]bool {
+	if c.lc.Spec.AdditionalStores == nil || c.lc.Spec.AdditionalStores.Kafka == nil || !c.lc.Spec.AdditionalStores.Kafka.TLS || c.kafkaCredential == nil {
+		return map[string]bool{}
+	}
+	return tlsCredentialFiles(c.kafkaCredential.CA, c.kafkaCredential.Cert, c.kafkaCredential.Key)
+}
+
+// tlsCredentialFiles returns the keys of the TLS credentials that are present. A client certificate is only used
+// together with its key.
+func tlsCredentialFiles(ca, cert, key []byte) map[string]bool {
+	files := map[string]bool{}
+	if len(ca) != 0 {
+		files[corev1.ServiceAccountRootCAKey] = true
+	}
+	if len(cert) != 0 && len(key) != 0 {
+		files[corev1.TLSCertKey] = true
+		files[corev1.TLSPrivateKeyKey] = true
+	}
+	return files
+}
+
+// credentialVolume returns a volume with the files of a credentials Secret.
+func credentialVolume(name, secretName string, files map[string]bool) corev1.Volume {
+	var items []corev1.KeyToPath
+	for _, key := range []string{corev1.ServiceAccountRootCAKey, corev1.TLSCertKey, corev1.TLSPrivateKeyKey} {
+		if files[key] {
+			items = append(items, corev1.KeyToPath{Key: key, Path: key})
+		}
+	}
+	return corev1.Volume{
+		Name: name,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: secretName, Items: items},
+		},
+	}
+}
+
+func (c *fluentdComponent) httpEnvvars(http *operatorv1.HTTPStoreSpec) []corev1.EnvVar {
+	envs := []corev1.EnvVar{
+		{Name: "HTTP_ENDPOINT", Value: http.Endpoint},
+		{Name: "HTTP_FLUSH_INTERVAL", Value: c.flushInterval(http.FlushInterval)},
+	}
+	if len(http.Headers) != 0 {
+		// The keys of a map are sorted when it is encoded, so the value only changes with the headers.
+		headers, _ := json.Marshal(http.Headers)
+		envs = append(envs, corev1.EnvVar{Name: "HTTP_HEADERS", Value: string(headers)})
+	}
+	switch http.Auth {
+	case operatorv1.HTTPStoreAuthTypeBasic:
+		envs = append(envs,
+			corev1.EnvVar{Name: "HTTP_AUTH", Value: "basic"},
+			corev1.EnvVar{Name: "HTTP_USERNAME", ValueFrom: envVarSourceFromSecret(HTTPFluentdSecretName, HTTPUsernameName, false)},
+			corev1.EnvVar{Name: "HTTP_PASSWORD", ValueFrom: envVarSourceFromSecret(HTTPFluentdSecretName, HTTPPasswordName, false)},
+		)
+	case operatorv1.HTTPStoreAuthTypeBearer:
+		envs = append(envs,
+			corev1.EnvVar{Name: "HTTP_AUTH", Value: "bearer"},
+			corev1.EnvVar{Name: "HTTP_TOKEN", ValueFrom: envVarSourceFromSecret(HTTPFluentdSecretName, HTTPTokenName, false)},
+		)
+	}
+	return envs
+}
+
 func (c *fluentdComponent) liveness() *corev1.Probe {
 	return &corev1.Probe{
 		Handler: corev1.Handler{
@@ -405,15 +996,123 @@ func (c *fluentdComponent) volumes() []corev1.Volume {
 				},
 			})
 	}
+	if len(c.storeFilters()) != 0 {
+		volumes = append(volumes,
+			corev1.Volume{
+				Name: "fluentd-store-filters",
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: FluentdStoreFilterConfigMapName,
+						},
+					},
+				},
+			})
+	}
+	if buffer := c.buffer(); buffer != nil {
+		path := buffer.HostPath
+		if path == "" {
+			path = fluentdDefaultBufferPath
+		}
+		volumes = append(volumes,
+			corev1.Volume{
+				Name: "fluentd-buffers",
+				VolumeSource: corev1.VolumeSource{
+					HostPath: &corev1.HostPathVolumeSource{
+						Path: path,
+						Type: &dirOrCreate,
+					},
+				},
+			})
+	}
+	if c.collectContainerLogs() {
+		for _, p := range containerLogHostPaths {
+			volumes = append(volumes,
+				corev1.Volume{
+					Name: p.name,
+					VolumeSource: corev1.VolumeSource{
+						HostPath: &corev1.HostPathVolumeSource{Path: p.path},
+					},
+				})
+		}
+	}
+	if path := c.kubeAuditLogPath(); path != "" {
+		// The audit log is only written on the control plane nodes, so its directory is created on the other nodes.
+		volumes = append(volumes,
+			corev1.Volume{
+				Name: "kube-audit-log",
+				VolumeSource: corev1.VolumeSource{
+					HostPath: &corev1.HostPathVolumeSource{
+						Path: filepath.Dir(path),
+						Type: &dirOrCreate,
+					},
+				},
+			})
+	}
+	if files := c.syslogCredentialFiles(); len(files) != 0 {
+		volumes = append(volumes, credentialVolume("syslog-credentials", SyslogFluentdSecretName, files))
+	}
+	if files := c.kafkaCredentialFiles(); len(files) != 0 {
+		volumes = append(volumes, credentialVolume("kafka-credentials", KafkaFluentdSecretName, files))
+	}
 
 	return volumes
 }
 
-func (c *fluentdComponent) eksLogForwarderServiceAccount() *corev1.ServiceAccount {
-	return &corev1.ServiceAccount{
-		TypeMeta:   metav1.TypeMeta{Kind: "ServiceAccount", APIVersion: "v1"},
-		ObjectMeta: metav1.ObjectMeta{Name: eksLogForwarderName, Namespace: LogCollectorNamespace},
+// logForwarder is a Deployment that fetches the audit logs of a managed Kubernetes platform from its cloud provider
+// and writes them to Elasticsearch.
+type logForwarder struct {
+	name     string
+	platform string
+	// esUserSecret is the Secret with the credentials of the forwarder's Elasticsearch user.
+	esUserSecret string
+	// stateDir is where the forwarder keeps track of the logs that it has fetched.
+	stateDir string
+	// secret holds the cloud credentials of the forwarder, if it does not use workload identity.
+	secret                    *corev1.Secret
+	annotations               map[string]string
+	serviceAccountAnnotations map[string]string
+	envVars                   []corev1.EnvVar
+	volumes                   []corev1.Volume
+	volumeMounts              []corev1.VolumeMount
+}
+
+func (c *fluentdComponent) logForwarderObjects(f logForwarder) []runtime.Object {
+	objs := []runtime.Object{c.logForwarderServiceAccount(f)}
+	if f.secret != nil {
+		objs = append(objs, f.secret)
+	}
+	return append(objs, c.logForwarderDeployment(f))
+}
+
+func (c *fluentdComponent) eksLogForwarder() logForwarder {
+	f := logForwarder{
+		name:         eksLogForwarderName,
+		platform:     "eks",
+		esUserSecret: ElasticsearchEksLogForwarderUserSecret,
+		stateDir:     "/fluentd/cloudwatch-logs/",
+		annotations: map[string]string{
+			eksCloudwatchLogCredentialHashAnnotation: AnnotationHash(c.eksConfig),
+		},
+		envVars: []corev1.EnvVar{
+			// Cloudwatch config, credentials.
+			{Name: "EKS_CLOUDWATCH_LOG_GROUP", Value: c.eksConfig.GroupName},
+			{Name: "EKS_CLOUDWATCH_LOG_STREAM_PREFIX", Value: c.eksConfig.StreamPrefix},
+			{Name: "EKS_CLOUDWATCH_LOG_FETCH_INTERVAL", Value: fmt.Sprintf("%d", c.eksConfig.FetchInterval)},
+			{Name: "AWS_REGION", Value: c.eksConfig.AwsRegion},
+		},
+	}
+	if c.eksConfig.RoleARN != "" {
+		// The EKS pod identity webhook injects web identity credentials for the role into the pods.
+		f.serviceAccountAnnotations = map[string]string{"eks.amazonaws.com/role-arn": c.eksConfig.RoleARN}
+	} else {
+		f.secret = c.eksLogForwarderSecret()
+		f.envVars = append(f.envVars,
+			corev1.EnvVar{Name: "AWS_ACCESS_KEY_ID", ValueFrom: envVarSourceFromSecret(EksLogForwarderSecret, EksLogForwarderAwsId, false)},
+			corev1.EnvVar{Name: "AWS_SECRET_ACCESS_KEY", ValueFrom: envVarSourceFromSecret(EksLogForwarderSecret, EksLogForwarderAwsKey, false)},
+		)
 	}
+	return f
 }
 
 func (c *fluentdComponent) eksLogForwarderSecret() *corev1.Secret {
@@ -430,106 +1129,174 @@ func (c *fluentdComponent) eksLogForwarderSecret() *corev1.Secret {
 	}
 }
 
-func (c *fluentdComponent) eksLogForwarderDeployment() *appsv1.Deployment {
-	annots := map[string]string{
-		eksCloudwatchLogCredentialHashAnnotation: AnnotationHash(c.eksConfig),
+func (c *fluentdComponent) aksLogForwarder() logForwarder {
+	return logForwarder{
+		name:         aksLogForwarderName,
+		platform:     "aks",
+		esUserSecret: ElasticsearchAksLogForwarderUserSecret,
+		stateDir:     "/fluentd/azure-monitor-logs/",
+		secret: &corev1.Secret{
+			TypeMeta: metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      AksLogForwarderSecret,
+				Namespace: LogCollectorNamespace,
+			},
+			Data: map[string][]byte{
+				AksLogForwarderTenantId:     c.azureConfig.TenantId,
+				AksLogForwarderClientId:     c.azureConfig.ClientId,
+				AksLogForwarderClientSecret: c.azureConfig.ClientSecret,
+			},
+		},
+		annotations: map[string]string{
+			azureMonitorLogCredentialHashAnnotation: AnnotationHash(c.azureConfig),
+		},
+		envVars: []corev1.EnvVar{
+			{Name: "AZURE_MONITOR_WORKSPACE_ID", Value: c.azureConfig.WorkspaceId},
+			{Name: "AZURE_MONITOR_LOG_FETCH_INTERVAL", Value: fmt.Sprintf("%d", c.azureConfig.FetchInterval)},
+			{Name: "AZURE_TENANT_ID", ValueFrom: envVarSourceFromSecret(AksLogForwarderSecret, AksLogForwarderTenantId, false)},
+			{Name: "AZURE_CLIENT_ID", ValueFrom: envVarSourceFromSecret(AksLogForwarderSecret, AksLogForwarderClientId, false)},
+			{Name: "AZURE_CLIENT_SECRET", ValueFrom: envVarSourceFromSecret(AksLogForwarderSecret, AksLogForwarderClientSecret, false)},
+		},
 	}
+}
 
-	envVars := []corev1.EnvVar{
+func (c *fluentdComponent) gkeLogForwarder() logForwarder {
+	f := logForwarder{
+		name:         gkeLogForwarderName,
+		platform:     "gke",
+		esUserSecret: ElasticsearchGkeLogForwarderUserSecret,
+		stateDir:     "/fluentd/gcp-logging-logs/",
+		annotations: map[string]string{
+			gcpLoggingLogCredentialHashAnnotation: AnnotationHash(c.gcpConfig),
+		},
+		envVars: []corev1.EnvVar{
+			{Name: "GCP_PROJECT_ID", Value: c.gcpConfig.ProjectId},
+			{Name: "GKE_CLUSTER_NAME", Value: c.gcpConfig.ClusterName},
+			{Name: "GCP_LOGGING_LOG_FETCH_INTERVAL", Value: fmt.Sprintf("%d", c.gcpConfig.FetchInterval)},
+		},
+	}
+	if c.gcpConfig.ServiceAccount != "" {
+		// GKE Workload Identity gives the pods the credentials of the GCP service account.
+		f.serviceAccountAnnotations = map[string]string{"iam.gke.io/gcp-service-account": c.gcpConfig.ServiceAccount}
+	} else {
+		f.secret = &corev1.Secret{
+			TypeMeta: metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      GkeLogForwarderSecret,
+				Namespace: LogCollectorNamespace,
+			},
+			Data: map[string][]byte{
+				GkeLogForwarderKey: c.gcpConfig.Key,
+			},
+		}
+		f.envVars = append(f.envVars, corev1.EnvVar{Name: "GOOGLE_APPLICATION_CREDENTIALS", Value: "/etc/fluentd/gcp/" + GkeLogForwarderKey})
+		f.volumes = []corev1.Volume{{
+			Name: "gcp-credentials",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: GkeLogForwarderSecret},
+			},
+		}}
+		f.volumeMounts = []corev1.VolumeMount{{Name: "gcp-credentials", MountPath: "/etc/fluentd/gcp", ReadOnly: true}}
+	}
+	return f
+}
+
+func (c *fluentdComponent) logForwarderServiceAccount(f logForwarder) *corev1.ServiceAccount {
+	return &corev1.ServiceAccount{
+		TypeMeta: metav1.TypeMeta{Kind: "ServiceAccount", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        f.name,
+			Namespace:   LogCollectorNamespace,
+			Annotations: f.serviceAccountAnnotations,
+		},
+	}
+}
+
+func (c *fluentdComponent) logForwarderDeployment(f logForwarder) *appsv1.Deployment {
+	envVars := append([]corev1.EnvVar{
 		// Meta flags.
 		{Name: "LOG_LEVEL", Value: "info"},
 		{Name: "FLUENT_UID", Value: "0"},
-		// Use fluentd for EKS log forwarder.
+		// Use fluentd for the log forwarder.
 		{Name: "MANAGED_K8S", Value: "true"},
-		{Name: "K8S_PLATFORM", Value: "eks"},
+		{Name: "K8S_PLATFORM", Value: f.platform},
 		{Name: "FLUENTD_ES_SECURE", Value: "true"},
-		// Cloudwatch config, credentials.
-		{Name: "EKS_CLOUDWATCH_LOG_GROUP", Value: c.eksConfig.GroupName},
-		{Name: "EKS_CLOUDWATCH_LOG_STREAM_PREFIX", Value: c.eksConfig.StreamPrefix},
-		{Name: "EKS_CLOUDWATCH_LOG_FETCH_INTERVAL", Value: fmt.Sprintf("%d", c.eksConfig.FetchInterval)},
-		{Name: "AWS_REGION", Value: c.eksConfig.AwsRegion},
-		{Name: "AWS_ACCESS_KEY_ID", ValueFrom: envVarSourceFromSecret(EksLogForwarderSecret, EksLogForwarderAwsId, false)},
-		{Name: "AWS_SECRET_ACCESS_KEY", ValueFrom: envVarSourceFromSecret(EksLogForwarderSecret, EksLogForwarderAwsKey, false)},
-	}
+	}, f.envVars...)
 
-	var eksLogForwarderReplicas int32 = 1
+	volumeMounts := append([]corev1.VolumeMount{
+		ElasticsearchDefaultVolumeMount(),
+		{
+			Name:      "plugin-statefile-dir",
+			MountPath: f.stateDir,
+		},
+		{
+			Name:      "elastic-ca-cert-volume",
+			MountPath: "/etc/fluentd/elastic/",
+		},
+	}, f.volumeMounts...)
+
+	volumes := append([]corev1.Volume{
+		ElasticsearchDefaultVolume(),
+		{
+			Name: "plugin-statefile-dir",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: nil,
+			},
+		},
+	}, f.volumes...)
+
+	var replicas int32 = 1
 
 	return &appsv1.Deployment{
 		TypeMeta: metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      eksLogForwarderName,
+			Name:      f.name,
 			Namespace: LogCollectorNamespace,
 			Labels: map[string]string{
-				"k8s-app": eksLogForwarderName,
+				"k8s-app": f.name,
 			},
 		},
 		Spec: appsv1.DeploymentSpec{
-			Replicas: &eksLogForwarderReplicas,
+			Replicas: &replicas,
 			Strategy: appsv1.DeploymentStrategy{
 				Type: appsv1.RecreateDeploymentStrategyType,
 			},
 			Selector: &metav1.LabelSelector{
 				MatchLabels: map[string]string{
-					"k8s-app": eksLogForwarderName,
+					"k8s-app": f.name,
 				},
 			},
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      eksLogForwarderName,
+					Name:      f.name,
 					Namespace: LogCollectorNamespace,
 					Labels: map[string]string{
-						"k8s-app": eksLogForwarderName,
+						"k8s-app": f.name,
 					},
-					Annotations: annots,
+					Annotations: f.annotations,
 				},
 				Spec: corev1.PodSpec{
 					NodeSelector: map[string]string{
 						"beta.kubernetes.io/os": "linux",
 					},
-					ServiceAccountName: eksLogForwarderName,
+					ServiceAccountName: f.name,
 					ImagePullSecrets:   getImagePullSecretReferenceList(c.pullSecrets),
 					InitContainers: []corev1.Container{ElasticsearchContainerDecorateENVVars(corev1.Container{
-						Name:         eksLogForwarderName + "-startup",
-						Image:        components.GetReference(components.ComponentFluentd, c.installation.Spec.Registry),
-						Command:      []string{"/bin/eks-log-forwarder-startup"},
+						Name:         f.name + "-startup",
+						Image:        components.GetReference(components.ComponentFluentd, c.installation),
+						Command:      []string{"/bin/" + f.name + "-startup"},
 						Env:          envVars,
-						VolumeMounts: c.eksLogForwarderVolumeMounts(),
-					}, c.esClusterConfig.ClusterName(), ElasticsearchEksLogForwarderUserSecret)},
+						VolumeMounts: volumeMounts,
+					}, c.esClusterConfig, f.esUserSecret)},
 					Containers: []corev1.Container{ElasticsearchContainerDecorateENVVars(corev1.Container{
-						Name:         eksLogForwarderName,
-						Image:        components.GetReference(components.ComponentFluentd, c.installation.Spec.Registry),
+						Name:         f.name,
+						Image:        components.GetReference(components.ComponentFluentd, c.installation),
 						Env:          envVars,
-						VolumeMounts: c.eksLogForwarderVolumeMounts(),
-					}, c.esClusterConfig.ClusterName(), ElasticsearchEksLogForwarderUserSecret)},
-					Volumes: c.eksLogForwarderVolumes(),
+						VolumeMounts: volumeMounts,
+					}, c.esClusterConfig, f.esUserSecret)},
+					Volumes: volumes,
 				},
 			},
 		},
 	}
 }
-
-func (c *fluentdComponent) eksLogForwarderVolumeMounts() []corev1.VolumeMount {
-	return []corev1.VolumeMount{
-		ElasticsearchDefaultVolumeMount(),
-		{
-			Name:      "plugin-statefile-dir",
-			MountPath: "/fluentd/cloudwatch-logs/",
-		},
-		{
-			Name:      "elastic-ca-cert-volume",
-			MountPath: "/etc/fluentd/elastic/",
-		},
-	}
-}
-
-func (c *fluentdComponent) eksLogForwarderVolumes() []corev1.Volume {
-	return []corev1.Volume{
-		ElasticsearchDefaultVolume(),
-		{
-			Name: "plugin-statefile-dir",
-			VolumeSource: corev1.VolumeSource{
-				EmptyDir: nil,
-			},
-		},
-	}
-}
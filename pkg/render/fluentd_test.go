@@ -15,10 +15,13 @@
 package render_test
 
 import (
+	"strings"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 
 	operatorv1 "github.com/tigera/operator/pkg/apis/operator/v1"
 	"github.com/tigera/operator/pkg/render"
@@ -44,11 +47,11 @@ var _ = Describe("Tigera Secure Fluentd rendering tests", func() {
 		filters = nil
 		eksConfig = nil
 
-		esConfigMap = render.NewElasticsearchClusterConfig("clusterTestName", 1, 1)
+		esConfigMap = render.NewElasticsearchClusterConfig("clusterTestName", 1, 1, "")
 	})
 
 	It("should render all resources for a default configuration", func() {
-		component := render.Fluentd(instance, nil, esConfigMap, s3Creds, filters, eksConfig, nil, installation)
+		component := render.Fluentd(instance, nil, esConfigMap, s3Creds, nil, nil, nil, nil, filters, eksConfig, nil, nil, nil, installation)
 		resources, _ := component.Objects()
 		Expect(len(resources)).To(Equal(2))
 
@@ -83,7 +86,7 @@ var _ = Describe("Tigera Secure Fluentd rendering tests", func() {
 				BucketPath: "bucketpath",
 			},
 		}
-		component := render.Fluentd(instance, nil, esConfigMap, s3Creds, filters, eksConfig, nil, installation)
+		component := render.Fluentd(instance, nil, esConfigMap, s3Creds, nil, nil, nil, nil, filters, eksConfig, nil, nil, nil, installation)
 		resources, _ := component.Objects()
 		Expect(len(resources)).To(Equal(3))
 
@@ -149,7 +152,7 @@ var _ = Describe("Tigera Secure Fluentd rendering tests", func() {
 				PacketSize: &ps,
 			},
 		}
-		component := render.Fluentd(instance, nil, esConfigMap, s3Creds, filters, eksConfig, nil, installation)
+		component := render.Fluentd(instance, nil, esConfigMap, s3Creds, nil, nil, nil, nil, filters, eksConfig, nil, nil, nil, installation)
 		resources, _ := component.Objects()
 		Expect(len(resources)).To(Equal(2))
 
@@ -182,10 +185,13 @@ var _ = Describe("Tigera Secure Fluentd rendering tests", func() {
 			secretKey  string
 		}{
 			{"SYSLOG_FLOW_LOG", "true", "", ""},
-			{"SYSLOG_AUDIT_LOG", "true", "", ""},
+			{"SYSLOG_DNS_LOG", "false", "", ""},
+			{"SYSLOG_AUDIT_EE_LOG", "true", "", ""},
+			{"SYSLOG_AUDIT_KUBE_LOG", "true", "", ""},
 			{"SYSLOG_HOST", "1.2.3.4", "", ""},
 			{"SYSLOG_PORT", "80", "", ""},
 			{"SYSLOG_PROTOCOL", "tcp", "", ""},
+			{"SYSLOG_FORMAT", "rfc3164", "", ""},
 			{"SYSLOG_FLUSH_INTERVAL", "5s", "", ""},
 			{"SYSLOG_PACKET_SIZE", "180", "", ""},
 		}
@@ -213,11 +219,345 @@ var _ = Describe("Tigera Secure Fluentd rendering tests", func() {
 
 	})
 
+	It("should render with TLS Syslog configuration", func() {
+		instance.Spec.AdditionalStores = &operatorv1.AdditionalLogStoreSpec{
+			Syslog: &operatorv1.SyslogStoreSpec{
+				Endpoint: "tls://1.2.3.4:6514",
+				Format:   operatorv1.SyslogFormatRFC5424,
+			},
+		}
+		syslogCreds := &render.SyslogCredential{CA: []byte("ca"), Cert: []byte("cert"), Key: []byte("key")}
+		component := render.Fluentd(instance, nil, esConfigMap, s3Creds, syslogCreds, nil, nil, nil, filters, eksConfig, nil, nil, nil, installation)
+		resources, _ := component.Objects()
+		Expect(len(resources)).To(Equal(3))
+
+		ExpectResource(resources[1], "log-collector-syslog-credentials", "tigera-fluentd", "", "v1", "Secret")
+
+		ds := resources[2].(*apps.DaemonSet)
+		Expect(ds.Spec.Template.Annotations).To(HaveKey("hash.operator.tigera.io/syslog-credentials"))
+		envs := ds.Spec.Template.Spec.Containers[0].Env
+		for _, expected := range []corev1.EnvVar{
+			{Name: "SYSLOG_HOST", Value: "1.2.3.4"},
+			{Name: "SYSLOG_PORT", Value: "6514"},
+			{Name: "SYSLOG_PROTOCOL", Value: "tcp"},
+			{Name: "SYSLOG_TLS", Value: "true"},
+			{Name: "SYSLOG_FORMAT", Value: "rfc5424"},
+			{Name: "SYSLOG_CA_FILE", Value: "/etc/fluentd/syslog/ca.crt"},
+			{Name: "SYSLOG_CLIENT_CERT_FILE", Value: "/etc/fluentd/syslog/tls.crt"},
+			{Name: "SYSLOG_CLIENT_KEY_FILE", Value: "/etc/fluentd/syslog/tls.key"},
+		} {
+			Expect(envs).To(ContainElement(expected))
+		}
+		Expect(ds.Spec.Template.Spec.Volumes).To(ContainElement(corev1.Volume{
+			Name: "syslog-credentials",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: "log-collector-syslog-credentials",
+					Items: []corev1.KeyToPath{
+						{Key: "ca.crt", Path: "ca.crt"},
+						{Key: "tls.crt", Path: "tls.crt"},
+						{Key: "tls.key", Path: "tls.key"},
+					},
+				},
+			},
+		}))
+		Expect(ds.Spec.Template.Spec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+			Name: "syslog-credentials", MountPath: "/etc/fluentd/syslog", ReadOnly: true,
+		}))
+	})
+
+	It("should render with per-store log types and filters", func() {
+		instance.Spec.AdditionalStores = &operatorv1.AdditionalLogStoreSpec{
+			S3: &operatorv1.S3StoreSpec{
+				Region:     "anyplace",
+				BucketName: "thebucket",
+				BucketPath: "bucketpath",
+			},
+			Syslog: &operatorv1.SyslogStoreSpec{
+				Endpoint: "tcp://1.2.3.4:80",
+				LogTypes: []operatorv1.StoreLogType{operatorv1.StoreLogTypeFlows, operatorv1.StoreLogTypeIDSEvents},
+				Filter:   "<filter flows>\n  @type grep\n</filter>",
+			},
+		}
+		s3Creds = &render.S3Credential{KeyId: []byte("IdForTheKey"), KeySecret: []byte("SecretForTheKey")}
+		component := render.Fluentd(instance, nil, esConfigMap, s3Creds, nil, nil, nil, nil, filters, eksConfig, nil, nil, nil, installation)
+		resources, _ := component.Objects()
+		Expect(len(resources)).To(Equal(4))
+
+		ExpectResource(resources[2], "fluentd-store-filters", "tigera-fluentd", "", "v1", "ConfigMap")
+		cm := resources[2].(*corev1.ConfigMap)
+		Expect(cm.Data).To(Equal(map[string]string{"syslog": "<filter flows>\n  @type grep\n</filter>"}))
+
+		ds := resources[3].(*apps.DaemonSet)
+		Expect(ds.Spec.Template.Annotations).To(HaveKey("hash.operator.tigera.io/fluentd-store-filters"))
+		envs := ds.Spec.Template.Spec.Containers[0].Env
+		for _, expected := range []corev1.EnvVar{
+			{Name: "S3_FLOW_LOG", Value: "true"},
+			{Name: "S3_DNS_LOG", Value: "true"},
+			{Name: "S3_AUDIT_EE_LOG", Value: "true"},
+			{Name: "S3_AUDIT_KUBE_LOG", Value: "true"},
+			{Name: "S3_IDS_EVENT_LOG", Value: "false"},
+			{Name: "SYSLOG_FLOW_LOG", Value: "true"},
+			{Name: "SYSLOG_IDS_EVENT_LOG", Value: "true"},
+			{Name: "SYSLOG_AUDIT_EE_LOG", Value: "false"},
+			{Name: "SYSLOG_L7_LOG", Value: "false"},
+			{Name: "SYSLOG_FILTERS", Value: "/etc/fluentd/store-filters/syslog"},
+		} {
+			Expect(envs).To(ContainElement(expected))
+		}
+		for _, env := range envs {
+			Expect(env.Name).ToNot(Equal("S3_FILTERS"))
+		}
+		Expect(ds.Spec.Template.Spec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+			Name: "fluentd-store-filters", MountPath: "/etc/fluentd/store-filters", ReadOnly: true,
+		}))
+	})
+
+	It("should render with Splunk configuration", func() {
+		instance.Spec.AdditionalStores = &operatorv1.AdditionalLogStoreSpec{
+			Splunk: &operatorv1.SplunkStoreSpec{
+				Endpoint: "https://1.2.3.4:8088",
+				Index:    "calico",
+			},
+		}
+		splunkCreds := &render.SplunkCredential{Token: []byte("token")}
+		component := render.Fluentd(instance, nil, esConfigMap, s3Creds, nil, splunkCreds, nil, nil, filters, eksConfig, nil, nil, nil, installation)
+		resources, _ := component.Objects()
+		Expect(len(resources)).To(Equal(3))
+
+		ExpectResource(resources[0], "tigera-fluentd", "", "", "v1", "Namespace")
+		ExpectResource(resources[1], "log-collector-splunk-credentials", "tigera-fluentd", "", "v1", "Secret")
+		ExpectResource(resources[2], "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet")
+
+		ds := resources[2].(*apps.DaemonSet)
+		Expect(ds.Spec.Template.Annotations).To(HaveKey("hash.operator.tigera.io/splunk-credentials"))
+		envs := ds.Spec.Template.Spec.Containers[0].Env
+		for _, expected := range []corev1.EnvVar{
+			{Name: "SPLUNK_FLOW_LOG", Value: "true"},
+			{Name: "SPLUNK_AUDIT_LOG", Value: "true"},
+			{Name: "SPLUNK_DNS_LOG", Value: "true"},
+			{Name: "SPLUNK_HEC_HOST", Value: "1.2.3.4"},
+			{Name: "SPLUNK_HEC_PORT", Value: "8088"},
+			{Name: "SPLUNK_PROTOCOL", Value: "https"},
+			{Name: "SPLUNK_INDEX", Value: "calico"},
+			{Name: "SPLUNK_FLUSH_INTERVAL", Value: "5s"},
+		} {
+			Expect(envs).To(ContainElement(expected))
+		}
+		Expect(envs).To(ContainElement(corev1.EnvVar{
+			Name: "SPLUNK_HEC_TOKEN",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "log-collector-splunk-credentials"},
+					Key:                  "token",
+				}},
+		}))
+	})
+
+	It("should render with Kafka configuration", func() {
+		instance.Spec.AdditionalStores = &operatorv1.AdditionalLogStoreSpec{
+			Kafka: &operatorv1.KafkaStoreSpec{
+				Brokers:       []string{"kafka-0:9093", "kafka-1:9093"},
+				Topic:         "calico",
+				TLS:           true,
+				SASLMechanism: operatorv1.KafkaSASLMechanismScramSHA512,
+			},
+		}
+		kafkaCreds := &render.KafkaCredential{
+			CA:       []byte("ca"),
+			Username: []byte("user"),
+			Password: []byte("password"),
+		}
+		component := render.Fluentd(instance, nil, esConfigMap, s3Creds, nil, nil, kafkaCreds, nil, filters, eksConfig, nil, nil, nil, installation)
+		resources, _ := component.Objects()
+		Expect(len(resources)).To(Equal(3))
+
+		ExpectResource(resources[1], "log-collector-kafka-credentials", "tigera-fluentd", "", "v1", "Secret")
+		secret := resources[1].(*corev1.Secret)
+		Expect(secret.Data).To(HaveLen(3))
+		Expect(secret.Data).ToNot(HaveKey("tls.crt"))
+
+		ds := resources[2].(*apps.DaemonSet)
+		Expect(ds.Spec.Template.Annotations).To(HaveKey("hash.operator.tigera.io/kafka-credentials"))
+		envs := ds.Spec.Template.Spec.Containers[0].Env
+		for _, expected := range []corev1.EnvVar{
+			{Name: "KAFKA_BROKERS", Value: "kafka-0:9093,kafka-1:9093"},
+			{Name: "KAFKA_TOPIC", Value: "calico"},
+			{Name: "KAFKA_SSL", Value: "true"},
+			{Name: "KAFKA_SSL_CA_CERT", Value: "/etc/fluentd/kafka/ca.crt"},
+			{Name: "KAFKA_SASL_MECHANISM", Value: "SCRAM-SHA-512"},
+		} {
+			Expect(envs).To(ContainElement(expected))
+		}
+		for _, name := range []string{"KAFKA_SSL_CLIENT_CERT", "KAFKA_SSL_CLIENT_CERT_KEY"} {
+			for _, env := range envs {
+				Expect(env.Name).ToNot(Equal(name))
+			}
+		}
+		Expect(envs).To(ContainElement(corev1.EnvVar{
+			Name: "KAFKA_PASSWORD",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "log-collector-kafka-credentials"},
+					Key:                  "password",
+				}},
+		}))
+
+		Expect(ds.Spec.Template.Spec.Volumes).To(ContainElement(corev1.Volume{
+			Name: "kafka-credentials",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: "log-collector-kafka-credentials",
+					Items:      []corev1.KeyToPath{{Key: "ca.crt", Path: "ca.crt"}},
+				},
+			},
+		}))
+		Expect(ds.Spec.Template.Spec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+			Name: "kafka-credentials", MountPath: "/etc/fluentd/kafka", ReadOnly: true,
+		}))
+	})
+
+	It("should render with HTTP configuration", func() {
+		instance.Spec.AdditionalStores = &operatorv1.AdditionalLogStoreSpec{
+			HTTP: &operatorv1.HTTPStoreSpec{
+				Endpoint: "https://logs.example.com/ingest",
+				Headers:  map[string]string{"X-Tenant": "a", "X-Cluster": "b"},
+				Auth:     operatorv1.HTTPStoreAuthTypeBearer,
+			},
+		}
+		httpCreds := &render.HTTPCredential{Token: []byte("token")}
+		component := render.Fluentd(instance, nil, esConfigMap, s3Creds, nil, nil, nil, httpCreds, filters, eksConfig, nil, nil, nil, installation)
+		resources, _ := component.Objects()
+		Expect(len(resources)).To(Equal(3))
+
+		ExpectResource(resources[1], "log-collector-http-credentials", "tigera-fluentd", "", "v1", "Secret")
+
+		ds := resources[2].(*apps.DaemonSet)
+		envs := ds.Spec.Template.Spec.Containers[0].Env
+		for _, expected := range []corev1.EnvVar{
+			{Name: "HTTP_ENDPOINT", Value: "https://logs.example.com/ingest"},
+			{Name: "HTTP_HEADERS", Value: `{"X-Cluster":"b","X-Tenant":"a"}`},
+			{Name: "HTTP_AUTH", Value: "bearer"},
+		} {
+			Expect(envs).To(ContainElement(expected))
+		}
+		Expect(envs).To(ContainElement(corev1.EnvVar{
+			Name: "HTTP_TOKEN",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "log-collector-http-credentials"},
+					Key:                  "token",
+				}},
+		}))
+	})
+
+	It("should render with buffer, flush and resource settings", func() {
+		limit := resource.MustParse("1Gi")
+		instance.Spec.Buffer = &operatorv1.FluentdBufferSpec{
+			TotalLimitSize: &limit,
+			FlushInterval:  10,
+			RetryTimeout:   3600,
+		}
+		instance.Spec.ResourceRequirements = &corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+		}
+		instance.Spec.AdditionalStores = &operatorv1.AdditionalLogStoreSpec{
+			Splunk: &operatorv1.SplunkStoreSpec{
+				Endpoint:      "https://1.2.3.4:8088",
+				FlushInterval: 30,
+			},
+		}
+		component := render.Fluentd(instance, nil, esConfigMap, s3Creds, nil, &render.SplunkCredential{Token: []byte("token")}, nil, nil, filters, eksConfig, nil, nil, nil, installation)
+		resources, _ := component.Objects()
+		ds := GetResource(resources, "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*apps.DaemonSet)
+
+		container := ds.Spec.Template.Spec.Containers[0]
+		Expect(container.Resources).To(Equal(*instance.Spec.ResourceRequirements))
+		Expect(container.Ports).To(ContainElement(corev1.ContainerPort{Name: "monitor", ContainerPort: render.FluentdMonitorPort}))
+		for _, expected := range []corev1.EnvVar{
+			{Name: "FLUENTD_BUFFER_TYPE", Value: "file"},
+			{Name: "FLUENTD_BUFFER_PATH", Value: "/fluentd/buffers"},
+			{Name: "FLUENTD_BUFFER_TOTAL_LIMIT_SIZE", Value: "1073741824"},
+			{Name: "FLUENTD_RETRY_WAIT", Value: "1s"},
+			{Name: "FLUENTD_RETRY_MAX_INTERVAL", Value: "60s"},
+			{Name: "FLUENTD_RETRY_TIMEOUT", Value: "3600s"},
+			{Name: "ELASTIC_FLUSH_INTERVAL", Value: "10s"},
+			{Name: "SPLUNK_FLUSH_INTERVAL", Value: "30s"},
+		} {
+			Expect(container.Env).To(ContainElement(expected))
+		}
+		Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: "fluentd-buffers", MountPath: "/fluentd/buffers"}))
+
+		var buffers *corev1.Volume
+		for i, v := range ds.Spec.Template.Spec.Volumes {
+			if v.Name == "fluentd-buffers" {
+				buffers = &ds.Spec.Template.Spec.Volumes[i]
+			}
+		}
+		Expect(buffers).NotTo(BeNil())
+		Expect(buffers.HostPath.Path).To(Equal("/var/lib/calico/fluentd-buffers"))
+	})
+
+	It("should buffer logs in files in managed clusters", func() {
+		installation.Spec.ClusterManagementType = operatorv1.ClusterManagementTypeManaged
+		component := render.Fluentd(instance, nil, esConfigMap, s3Creds, nil, nil, nil, nil, filters, eksConfig, nil, nil, nil, installation)
+		resources, _ := component.Objects()
+		ds := GetResource(resources, "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*apps.DaemonSet)
+
+		container := ds.Spec.Template.Spec.Containers[0]
+		Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "FLUENTD_BUFFER_TYPE", Value: "file"}))
+		Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "FLUENTD_BUFFER_TOTAL_LIMIT_SIZE", Value: "536870912"}))
+		Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: "fluentd-buffers", MountPath: "/fluentd/buffers"}))
+	})
+
+	It("should render with component logs", func() {
+		instance.Spec.ComponentLogs = &operatorv1.ComponentLogsSpec{
+			Containers:                true,
+			KubeAPIServerAuditLogPath: "/var/log/kubernetes/audit/audit.log",
+		}
+		component := render.Fluentd(instance, nil, esConfigMap, s3Creds, nil, nil, nil, nil, filters, eksConfig, nil, nil, nil, installation)
+		resources, _ := component.Objects()
+		ds := GetResource(resources, "fluentd-node", "tigera-fluentd", "apps", "v1", "DaemonSet").(*apps.DaemonSet)
+
+		container := ds.Spec.Template.Spec.Containers[0]
+		var containerLogFiles string
+		for _, env := range container.Env {
+			if env.Name == "CONTAINER_LOG_FILES" {
+				containerLogFiles = env.Value
+			}
+		}
+		files := strings.Split(containerLogFiles, ",")
+		Expect(files).To(ContainElement("/var/log/containers/*_tigera-operator_*.log"))
+		Expect(files).To(ContainElement("/var/log/containers/*_tigera-system_*.log"))
+		Expect(files).To(ContainElement("/var/log/containers/*_tigera-compliance_*.log"))
+		Expect(files).To(ContainElement("/var/log/containers/*_tigera-manager_*.log"))
+		Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "KUBE_AUDIT_LOG_FILE", Value: "/var/log/kube-apiserver-audit/audit.log"}))
+		Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "ELASTIC_COMPONENT_LOGS_INDEX_REPLICAS", Value: "1"}))
+
+		for _, expected := range []corev1.VolumeMount{
+			{Name: "var-log-containers", MountPath: "/var/log/containers", ReadOnly: true},
+			{Name: "var-log-pods", MountPath: "/var/log/pods", ReadOnly: true},
+			{Name: "docker-containers", MountPath: "/var/lib/docker/containers", ReadOnly: true},
+			{Name: "kube-audit-log", MountPath: "/var/log/kube-apiserver-audit", ReadOnly: true},
+		} {
+			Expect(container.VolumeMounts).To(ContainElement(expected))
+		}
+
+		var auditLog *corev1.Volume
+		for i, v := range ds.Spec.Template.Spec.Volumes {
+			if v.Name == "kube-audit-log" {
+				auditLog = &ds.Spec.Template.Spec.Volumes[i]
+			}
+		}
+		Expect(auditLog).NotTo(BeNil())
+		Expect(auditLog.HostPath.Path).To(Equal("/var/log/kubernetes/audit"))
+	})
+
 	It("should render with filter", func() {
 		filters = &render.FluentdFilters{
 			Flow: "flow-filter",
 		}
-		component := render.Fluentd(instance, nil, esConfigMap, s3Creds, filters, eksConfig, nil, installation)
+		component := render.Fluentd(instance, nil, esConfigMap, s3Creds, nil, nil, nil, nil, filters, eksConfig, nil, nil, nil, installation)
 		resources, _ := component.Objects()
 		Expect(len(resources)).To(Equal(3))
 
@@ -248,6 +588,25 @@ var _ = Describe("Tigera Secure Fluentd rendering tests", func() {
 		Expect(envs).ToNot(ContainElement(corev1.EnvVar{Name: "FLUENTD_DNS_FILTERS", Value: "true"}))
 	})
 
+	It("should render the replicas and shards of each index type once", func() {
+		esConfigMap.SetIndexSettings(render.FlowsIndex, 2, 10)
+		component := render.Fluentd(instance, nil, esConfigMap, s3Creds, nil, nil, nil, nil, filters, eksConfig, nil, nil, nil, installation)
+		resources, _ := component.Objects()
+		ds := resources[1].(*apps.DaemonSet)
+		envs := ds.Spec.Template.Spec.Containers[0].Env
+
+		Expect(envs).To(ContainElement(corev1.EnvVar{Name: "ELASTIC_FLOWS_INDEX_REPLICAS", Value: "2"}))
+		Expect(envs).To(ContainElement(corev1.EnvVar{Name: "ELASTIC_FLOWS_INDEX_SHARDS", Value: "10"}))
+		Expect(envs).To(ContainElement(corev1.EnvVar{Name: "ELASTIC_DNS_INDEX_SHARDS", Value: "1"}))
+		Expect(envs).To(ContainElement(corev1.EnvVar{Name: "ELASTIC_AUDIT_INDEX_REPLICAS", Value: "1"}))
+
+		names := map[string]bool{}
+		for _, env := range envs {
+			Expect(names).NotTo(HaveKey(env.Name))
+			names[env.Name] = true
+		}
+	})
+
 	It("should render with EKS Cloudwatch Log", func() {
 		fetchInterval := int32(900)
 		eksConfig = &render.EksCloudwatchLogConfig{
@@ -262,7 +621,7 @@ var _ = Describe("Tigera Secure Fluentd rendering tests", func() {
 				KubernetesProvider: operatorv1.ProviderEKS,
 			},
 		}
-		component := render.Fluentd(instance, nil, esConfigMap, s3Creds, filters, eksConfig, nil, installation)
+		component := render.Fluentd(instance, nil, esConfigMap, s3Creds, nil, nil, nil, nil, filters, eksConfig, nil, nil, nil, installation)
 		resources, _ := component.Objects()
 		Expect(len(resources)).To(Equal(5))
 
@@ -299,4 +658,101 @@ var _ = Describe("Tigera Secure Fluentd rendering tests", func() {
 		fetchIntervalVal := "900"
 		Expect(envs).To(ContainElement(corev1.EnvVar{Name: "EKS_CLOUDWATCH_LOG_FETCH_INTERVAL", Value: fetchIntervalVal}))
 	})
+	It("should render with EKS Cloudwatch Log using IRSA", func() {
+		eksConfig = &render.EksCloudwatchLogConfig{
+			AwsRegion:     "us-west-1",
+			GroupName:     "dummy-eks-cluster-cloudwatch-log-group",
+			FetchInterval: 600,
+			RoleARN:       "arn:aws:iam::123456789012:role/eks-log-forwarder",
+		}
+		installation.Spec.KubernetesProvider = operatorv1.ProviderEKS
+		component := render.Fluentd(instance, nil, esConfigMap, s3Creds, nil, nil, nil, nil, filters, eksConfig, nil, nil, nil, installation)
+		resources, toDelete := component.Objects()
+		Expect(len(resources)).To(Equal(4))
+
+		By("removing the AWS keys of the log forwarder")
+		Expect(toDelete).To(HaveLen(3))
+		ExpectResource(toDelete[2], "tigera-eks-log-forwarder-secret", "tigera-fluentd", "", "v1", "Secret")
+
+		ExpectResource(resources[1], "eks-log-forwarder", "tigera-fluentd", "", "v1", "ServiceAccount")
+		ExpectResource(resources[2], "eks-log-forwarder", "tigera-fluentd", "apps", "v1", "Deployment")
+		sa := resources[1].(*corev1.ServiceAccount)
+		Expect(sa.Annotations).To(Equal(map[string]string{"eks.amazonaws.com/role-arn": "arn:aws:iam::123456789012:role/eks-log-forwarder"}))
+
+		deploy := resources[2].(*apps.Deployment)
+		for _, env := range deploy.Spec.Template.Spec.Containers[0].Env {
+			Expect(env.Name).NotTo(Equal("AWS_ACCESS_KEY_ID"))
+			Expect(env.Name).NotTo(Equal("AWS_SECRET_ACCESS_KEY"))
+		}
+	})
+
+	It("should render with Azure Monitor Log", func() {
+		azureConfig := &render.AzureMonitorLogConfig{
+			TenantId:      []byte("tenant"),
+			ClientId:      []byte("client"),
+			ClientSecret:  []byte("secret"),
+			WorkspaceId:   "workspace",
+			FetchInterval: 600,
+		}
+		installation.Spec.KubernetesProvider = operatorv1.ProviderAKS
+		component := render.Fluentd(instance, nil, esConfigMap, s3Creds, nil, nil, nil, nil, filters, nil, azureConfig, nil, nil, installation)
+		resources, _ := component.Objects()
+		Expect(len(resources)).To(Equal(5))
+
+		ExpectResource(resources[1], "aks-log-forwarder", "tigera-fluentd", "", "v1", "ServiceAccount")
+		ExpectResource(resources[2], "tigera-aks-log-forwarder-secret", "tigera-fluentd", "", "v1", "Secret")
+		ExpectResource(resources[3], "aks-log-forwarder", "tigera-fluentd", "apps", "v1", "Deployment")
+
+		deploy := resources[3].(*apps.Deployment)
+		Expect(deploy.Spec.Template.Annotations).To(HaveKey("hash.operator.tigera.io/azure-monitor-log-credentials"))
+		Expect(deploy.Spec.Template.Spec.InitContainers[0].Command).To(Equal([]string{"/bin/aks-log-forwarder-startup"}))
+		envs := deploy.Spec.Template.Spec.Containers[0].Env
+		Expect(envs).To(ContainElement(corev1.EnvVar{Name: "K8S_PLATFORM", Value: "aks"}))
+		Expect(envs).To(ContainElement(corev1.EnvVar{Name: "AZURE_MONITOR_WORKSPACE_ID", Value: "workspace"}))
+		Expect(envs).To(ContainElement(corev1.EnvVar{Name: "ELASTIC_HOST", Value: "tigera-secure-es-http.tigera-elasticsearch.svc"}))
+		Expect(envs).To(ContainElement(corev1.EnvVar{
+			Name: "AZURE_CLIENT_SECRET",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "tigera-aks-log-forwarder-secret"},
+					Key:                  "client-secret",
+				}},
+		}))
+	})
+
+	It("should render with GCP Logging Log", func() {
+		gcpConfig := &render.GcpLoggingLogConfig{
+			Key:           []byte("{}"),
+			ProjectId:     "project",
+			ClusterName:   "cluster",
+			FetchInterval: 600,
+		}
+		installation.Spec.KubernetesProvider = operatorv1.ProviderGKE
+		component := render.Fluentd(instance, nil, esConfigMap, s3Creds, nil, nil, nil, nil, filters, nil, nil, gcpConfig, nil, installation)
+		resources, _ := component.Objects()
+		Expect(len(resources)).To(Equal(5))
+
+		ExpectResource(resources[1], "gke-log-forwarder", "tigera-fluentd", "", "v1", "ServiceAccount")
+		ExpectResource(resources[2], "tigera-gke-log-forwarder-secret", "tigera-fluentd", "", "v1", "Secret")
+		ExpectResource(resources[3], "gke-log-forwarder", "tigera-fluentd", "apps", "v1", "Deployment")
+
+		deploy := resources[3].(*apps.Deployment)
+		container := deploy.Spec.Template.Spec.Containers[0]
+		Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "K8S_PLATFORM", Value: "gke"}))
+		Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "GCP_PROJECT_ID", Value: "project"}))
+		Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "GKE_CLUSTER_NAME", Value: "cluster"}))
+		Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "GOOGLE_APPLICATION_CREDENTIALS", Value: "/etc/fluentd/gcp/key.json"}))
+		Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: "gcp-credentials", MountPath: "/etc/fluentd/gcp", ReadOnly: true}))
+
+		// With Workload Identity the service account is annotated and there is no key.
+		gcpConfig.Key = nil
+		gcpConfig.ServiceAccount = "gke-log-forwarder@project.iam.gserviceaccount.com"
+		component = render.Fluentd(instance, nil, esConfigMap, s3Creds, nil, nil, nil, nil, filters, nil, nil, gcpConfig, nil, installation)
+		resources, _ = component.Objects()
+		Expect(len(resources)).To(Equal(4))
+		sa := resources[1].(*corev1.ServiceAccount)
+		Expect(sa.Annotations).To(HaveKeyWithValue("iam.gke.io/gcp-service-account", "gke-log-forwarder@project.iam.gserviceaccount.com"))
+		deploy = resources[2].(*apps.Deployment)
+		Expect(deploy.Spec.Template.Spec.Volumes).To(HaveLen(2))
+	})
 })
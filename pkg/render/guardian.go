@@ -17,6 +17,7 @@
 package render
 
 import (
+	operator "github.com/tigera/operator/pkg/apis/operator/v1"
 	"github.com/tigera/operator/pkg/components"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -44,14 +45,14 @@ func Guardian(
 	url string,
 	pullSecrets []*corev1.Secret,
 	openshift bool,
-	registry string,
+	installation *operator.Installation,
 	tunnelSecret *corev1.Secret,
 ) Component {
 	return &GuardianComponent{
 		url:          url,
 		pullSecrets:  pullSecrets,
 		openshift:    openshift,
-		registry:     registry,
+		installation: installation,
 		tunnelSecret: tunnelSecret,
 	}
 }
@@ -60,7 +61,7 @@ type GuardianComponent struct {
 	url          string
 	pullSecrets  []*v1.Secret
 	openshift    bool
-	registry     string
+	installation *operator.Installation
 	tunnelSecret *corev1.Secret
 }
 
@@ -229,7 +230,7 @@ func (c *GuardianComponent) container() []v1.Container {
 	return []corev1.Container{
 		{
 			Name:  GuardianDeploymentName,
-			Image: components.GetReference(components.ComponentGuardian, c.registry),
+			Image: components.GetReference(components.ComponentGuardian, c.installation),
 			Env: []corev1.EnvVar{
 				{Name: "GUARDIAN_PORT", Value: "9443"},
 				{Name: "GUARDIAN_LOGLEVEL", Value: "INFO"},
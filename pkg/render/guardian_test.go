@@ -17,6 +17,7 @@ package render_test
 import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	operator "github.com/tigera/operator/pkg/apis/operator/v1"
 	"github.com/tigera/operator/pkg/components"
 	"github.com/tigera/operator/pkg/render"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -53,7 +54,7 @@ var _ = Describe("Rendering tests", func() {
 				},
 			}},
 			false,
-			"my-reg/",
+			&operator.Installation{Spec: operator.InstallationSpec{Registry: "my-reg/"}},
 			secret,
 		)
 		resources, _ = g.Objects()
@@ -15,6 +15,7 @@
 package render
 
 import (
+	operator "github.com/tigera/operator/pkg/apis/operator/v1"
 	"github.com/tigera/operator/pkg/components"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
@@ -37,7 +38,7 @@ const (
 func IntrusionDetection(
 	esSecrets []*corev1.Secret,
 	kibanaCertSecret *corev1.Secret,
-	registry string,
+	installation *operator.Installation,
 	esClusterConfig *ElasticsearchClusterConfig,
 	pullSecrets []*corev1.Secret,
 	openshift bool,
@@ -45,7 +46,7 @@ func IntrusionDetection(
 	return &intrusionDetectionComponent{
 		esSecrets:        esSecrets,
 		kibanaCertSecret: kibanaCertSecret,
-		registry:         registry,
+		installation:     installation,
 		esClusterConfig:  esClusterConfig,
 		pullSecrets:      pullSecrets,
 		openshift:        openshift,
@@ -55,7 +56,7 @@ func IntrusionDetection(
 type intrusionDetectionComponent struct {
 	esSecrets        []*corev1.Secret
 	kibanaCertSecret *corev1.Secret
-	registry         string
+	installation     *operator.Installation
 	esClusterConfig  *ElasticsearchClusterConfig
 	pullSecrets      []*corev1.Secret
 	openshift        bool
@@ -65,14 +66,21 @@ func (c *intrusionDetectionComponent) Objects() ([]runtime.Object, []runtime.Obj
 	objs := []runtime.Object{createNamespace(IntrusionDetectionNamespace, c.openshift)}
 	objs = append(objs, copyImagePullSecrets(c.pullSecrets, IntrusionDetectionNamespace)...)
 	objs = append(objs, secretsToRuntimeObjects(CopySecrets(IntrusionDetectionNamespace, c.esSecrets...)...)...)
-	objs = append(objs, secretsToRuntimeObjects(CopySecrets(IntrusionDetectionNamespace, c.kibanaCertSecret)...)...)
+	// The installer job sets up the Kibana dashboards. There is no Kibana when an external Elasticsearch
+	// cluster is used or Kibana is disabled.
+	installKibana := c.esClusterConfig.Kibana()
+	if installKibana {
+		objs = append(objs, secretsToRuntimeObjects(CopySecrets(IntrusionDetectionNamespace, c.kibanaCertSecret)...)...)
+	}
 	objs = append(objs, c.intrusionDetectionServiceAccount(),
 		c.intrusionDetectionClusterRole(),
 		c.intrusionDetectionClusterRoleBinding(),
 		c.intrusionDetectionRole(),
 		c.intrusionDetectionRoleBinding(),
-		c.intrusionDetectionDeployment(),
-		c.intrusionDetectionElasticsearchJob())
+		c.intrusionDetectionDeployment())
+	if installKibana {
+		objs = append(objs, c.intrusionDetectionElasticsearchJob())
+	}
 
 	return objs, nil
 }
@@ -90,7 +98,7 @@ func (c *intrusionDetectionComponent) intrusionDetectionElasticsearchJob() *batc
 			RestartPolicy:    v1.RestartPolicyOnFailure,
 			ImagePullSecrets: getImagePullSecretReferenceList(c.pullSecrets),
 			Containers: []v1.Container{
-				ElasticsearchContainerDecorate(c.intrusionDetectionJobContainer(), c.esClusterConfig.ClusterName(), ElasticsearchIntrusionDetectionJobUserSecret),
+				ElasticsearchContainerDecorate(c.intrusionDetectionJobContainer(), c.esClusterConfig, ElasticsearchIntrusionDetectionJobUserSecret),
 			},
 			Volumes: []corev1.Volume{{
 				Name: "kibana-ca-cert-volume",
@@ -128,7 +136,7 @@ func (c *intrusionDetectionComponent) intrusionDetectionJobContainer() v1.Contai
 	secretName := ElasticsearchIntrusionDetectionJobUserSecret
 	return corev1.Container{
 		Name:  "elasticsearch-job-installer",
-		Image: components.GetReference(components.ComponentElasticTseeInstaller, c.registry),
+		Image: components.GetReference(components.ComponentElasticTseeInstaller, c.installation),
 		Env: []corev1.EnvVar{
 			{
 				Name:  "KIBANA_HOST",
@@ -313,8 +321,8 @@ func (c *intrusionDetectionComponent) deploymentPodTemplate() *corev1.PodTemplat
 			ImagePullSecrets:   ps,
 			Containers: []corev1.Container{
 				ElasticsearchContainerDecorateIndexCreator(
-					ElasticsearchContainerDecorate(c.intrusionDetectionControllerContainer(), c.esClusterConfig.ClusterName(), ElasticsearchIntrusionDetectionUserSecret),
-					c.esClusterConfig.Replicas(), c.esClusterConfig.Shards()),
+					ElasticsearchContainerDecorate(c.intrusionDetectionControllerContainer(), c.esClusterConfig, ElasticsearchIntrusionDetectionUserSecret),
+					c.esClusterConfig, EventsIndex),
 			},
 		}),
 	}, c.esClusterConfig, c.esSecrets).(*corev1.PodTemplateSpec)
@@ -323,7 +331,7 @@ func (c *intrusionDetectionComponent) deploymentPodTemplate() *corev1.PodTemplat
 func (c *intrusionDetectionComponent) intrusionDetectionControllerContainer() v1.Container {
 	return corev1.Container{
 		Name:  "controller",
-		Image: components.GetReference(components.ComponentIntrusionDetectionController, c.registry),
+		Image: components.GetReference(components.ComponentIntrusionDetectionController, c.installation),
 		Env: []corev1.EnvVar{
 			{
 				Name:  "CLUSTER_NAME",
@@ -17,16 +17,20 @@ package render_test
 import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	operator "github.com/tigera/operator/pkg/apis/operator/v1"
+	"github.com/tigera/operator/pkg/components"
 	"github.com/tigera/operator/pkg/render"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 var _ = Describe("Intrusion Detection rendering tests", func() {
 	It("should render all resources for a default configuration", func() {
-		esConfigMap := render.NewElasticsearchClusterConfig("clusterTestName", 1, 1)
+		esConfigMap := render.NewElasticsearchClusterConfig("clusterTestName", 1, 1, "")
 
-		component := render.IntrusionDetection(nil, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.TigeraKibanaCertSecret}}, "testregistry.com/", esConfigMap, nil, notOpenshift)
+		component := render.IntrusionDetection(nil, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.TigeraKibanaCertSecret}}, &operator.Installation{Spec: operator.InstallationSpec{Registry: "testregistry.com/"}}, esConfigMap, nil, notOpenshift)
 		resources, _ := component.Objects()
 		Expect(len(resources)).To(Equal(9))
 
@@ -55,4 +59,33 @@ var _ = Describe("Intrusion Detection rendering tests", func() {
 			i++
 		}
 	})
+
+	It("should not render the Kibana installer job for an external Elasticsearch cluster", func() {
+		esConfigMap := render.NewElasticsearchClusterConfig("clusterTestName", 1, 1, "https://es.example.com:9200")
+
+		component := render.IntrusionDetection(nil, nil, &operator.Installation{}, esConfigMap, nil, notOpenshift)
+		resources, _ := component.Objects()
+		Expect(resources).To(HaveLen(7))
+		for _, r := range resources {
+			_, isJob := r.(*batchv1.Job)
+			Expect(isJob).To(BeFalse())
+		}
+
+		deployment := resources[6].(*appsv1.Deployment)
+		Expect(deployment.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "ELASTIC_HOST", Value: "es.example.com"}))
+	})
+
+	It("should apply the image settings of the installation to the installer job", func() {
+		esConfigMap := render.NewElasticsearchClusterConfig("clusterTestName", 1, 1, "")
+		installation := &operator.Installation{Spec: operator.InstallationSpec{
+			Registry:           "testregistry.com/",
+			ImageReferenceMode: operator.ImageReferenceModeTag,
+			ImageOverrides:     []operator.ImageOverride{{Component: components.ComponentElasticTseeInstaller.Image, Image: "mirror/installer"}},
+		}}
+
+		component := render.IntrusionDetection(nil, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.TigeraKibanaCertSecret}}, installation, esConfigMap, nil, notOpenshift)
+		resources, _ := component.Objects()
+		job := resources[8].(*batchv1.Job)
+		Expect(job.Spec.Template.Spec.Containers[0].Image).To(Equal("testregistry.com/mirror/installer:" + components.ComponentElasticTseeInstaller.Version))
+	})
 })
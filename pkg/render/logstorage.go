@@ -16,6 +16,8 @@ package render
 
 import (
 	"fmt"
+	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/elastic/cloud-on-k8s/operators/pkg/utils/stringsutil"
@@ -27,7 +29,6 @@ import (
 	"github.com/tigera/operator/pkg/components"
 	"gopkg.in/inf.v0"
 	appsv1 "k8s.io/api/apps/v1"
-	batchv1 "k8s.io/api/batch/v1"
 	batchv1beta "k8s.io/api/batch/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
@@ -50,6 +51,10 @@ const (
 	ElasticsearchConfigMapName = "tigera-secure-elasticsearch"
 	ElasticsearchServiceName   = "tigera-secure-es-http"
 
+	// ElasticsearchAdminUserSecret is the secret, created by ECK, holding the password of the ElasticsearchAdminUser.
+	ElasticsearchAdminUserSecret = "tigera-secure-es-elastic-user"
+	ElasticsearchAdminUser       = "elastic"
+
 	KibanaHTTPURL          = "tigera-secure-kb-http.tigera-kibana.svc"
 	KibanaHTTPSEndpoint    = "https://tigera-secure-kb-http.tigera-kibana.svc:5601"
 	KibanaName             = "tigera-secure"
@@ -59,30 +64,27 @@ const (
 	KibanaDefaultCertPath  = "/etc/ssl/kibana/ca.pem"
 	KibanaBasePath         = "tigera-kibana"
 
+	// KibanaOIDCRealm and KibanaSAMLRealm are the Elasticsearch realms that provide single sign-on to Kibana.
+	KibanaOIDCRealm = "oidc1"
+	KibanaSAMLRealm = "saml1"
+
+	// ElasticsearchDataTierAttribute is the node attribute holding the data tier of an Elasticsearch data node.
+	ElasticsearchDataTierAttribute = "node.attr.data"
+
+	// ElasticsearchSecureSettingsSecret holds the credentials of the snapshot repository and the client secret of the
+	// OpenID Connect provider, which ECK adds to the keystore of each Elasticsearch node.
+	ElasticsearchSecureSettingsSecret = "tigera-secure-es-secure-settings"
+	// ElasticsearchSnapshotPath is where the volume of a file system snapshot repository is mounted.
+	ElasticsearchSnapshotPath = "/usr/share/elasticsearch/snapshots"
+
 	DefaultElasticsearchClusterName = "cluster"
 	DefaultElasticsearchReplicas    = 0
 
 	LogStorageFinalizer = "tigera.io/eck-cleanup"
 
+	// EsCuratorName is the name of the CronJob that used to enforce retention before index lifecycle management
+	// policies were used.
 	EsCuratorName = "elastic-curator"
-
-	// As soon as the total disk utilization exceeds the max-total-storage-percent,
-	// indices will be removed starting with the oldest. Picking a low value leads
-	// to low disk utilization, while a high value might result in unexpected
-	// behaviour.
-	// Default: 80
-	// +optional
-	maxTotalStoragePercent int32 = 80
-
-	// TSEE will remove dns and flow log indices once the combined data exceeds this
-	// threshold. The default value (70% of the cluster size) is used because flow
-	// logs and dns logs often use the most disk space; this allows compliance and
-	// security indices to be retained longer. The oldest indices are removed first.
-	// Set this value to be lower than or equal to, the value for
-	// max-total-storage-pct.
-	// Default: 70
-	// +optional
-	maxLogsStoragePercent int32 = 70
 )
 
 // Elasticsearch renders the
@@ -92,12 +94,12 @@ func LogStorage(
 	elasticsearch *esv1alpha1.Elasticsearch,
 	kibana *kbv1alpha1.Kibana,
 	clusterConfig *ElasticsearchClusterConfig,
+	heldNodeCounts map[string]int32,
 	elasticsearchSecrets []*corev1.Secret,
 	kibanaSecrets []*corev1.Secret,
 	createWebhookSecret bool,
 	pullSecrets []*corev1.Secret,
 	provider operatorv1.Provider,
-	curatorSecrets []*corev1.Secret,
 	esService *corev1.Service,
 	clusterDNS string) Component {
 
@@ -107,9 +109,9 @@ func LogStorage(
 		elasticsearch:        elasticsearch,
 		kibana:               kibana,
 		clusterConfig:        clusterConfig,
+		heldNodeCounts:       heldNodeCounts,
 		elasticsearchSecrets: elasticsearchSecrets,
 		kibanaSecrets:        kibanaSecrets,
-		curatorSecrets:       curatorSecrets,
 		createWebhookSecret:  createWebhookSecret,
 		pullSecrets:          pullSecrets,
 		provider:             provider,
@@ -124,9 +126,9 @@ type elasticsearchComponent struct {
 	elasticsearch        *esv1alpha1.Elasticsearch
 	kibana               *kbv1alpha1.Kibana
 	clusterConfig        *ElasticsearchClusterConfig
+	heldNodeCounts       map[string]int32
 	elasticsearchSecrets []*corev1.Secret
 	kibanaSecrets        []*corev1.Secret
-	curatorSecrets       []*corev1.Secret
 	createWebhookSecret  bool
 	pullSecrets          []*corev1.Secret
 	provider             operatorv1.Provider
@@ -173,62 +175,86 @@ func (es *elasticsearchComponent) Objects() ([]runtime.Object, []runtime.Object)
 		// Write back LogStorage CR to persist any changes
 		toCreate = append(toCreate, es.logStorage)
 
-		// ECK CRs
-		toCreate = append(toCreate,
-			createNamespace(ECKOperatorNamespace, es.provider == operatorv1.ProviderOpenShift),
-		)
-
-		toCreate = append(toCreate, secretsToRuntimeObjects(CopySecrets(ECKOperatorNamespace, es.pullSecrets...)...)...)
-
-		toCreate = append(toCreate,
-			es.eckOperatorClusterRole(),
-			es.eckOperatorClusterRoleBinding(),
-			es.eckOperatorServiceAccount(),
-		)
-		// This is needed for the operator to be able to set privileged mode for pods.
-		// https://docs.docker.com/ee/ucp/authorization/#secure-kubernetes-defaults
-		if es.provider == operatorv1.ProviderDockerEE {
-			toCreate = append(toCreate, es.eckOperatorClusterAdminClusterRoleBinding())
-		}
-
-		if es.createWebhookSecret {
-			toCreate = append(toCreate, es.eckOperatorWebhookSecret())
-		}
-		toCreate = append(toCreate, es.eckOperatorStatefulSet())
+		if es.logStorage.Spec.External != nil {
+			// An external Elasticsearch cluster only needs the secrets to access it and the cluster configuration.
+			// Remove anything left over from a cluster managed by the operator.
+			if len(es.elasticsearchSecrets) > 0 {
+				toCreate = append(toCreate, secretsToRuntimeObjects(es.elasticsearchSecrets...)...)
+			}
 
-		// Elasticsearch CRs
-		toCreate = append(toCreate, createNamespace(ElasticsearchNamespace, es.provider == operatorv1.ProviderOpenShift))
+			toCreate = append(toCreate, es.clusterConfig.ConfigMap())
 
-		if len(es.pullSecrets) > 0 {
-			toCreate = append(toCreate, secretsToRuntimeObjects(CopySecrets(ElasticsearchNamespace, es.pullSecrets...)...)...)
-		}
+			if es.elasticsearch != nil {
+				toDelete = append(toDelete, es.elasticsearch)
+			}
+			if es.kibana != nil {
+				toDelete = append(toDelete, es.kibana)
+			}
+		} else {
+			// ECK CRs
+			toCreate = append(toCreate,
+				createNamespace(ECKOperatorNamespace, es.provider == operatorv1.ProviderOpenShift),
+			)
+
+			toCreate = append(toCreate, secretsToRuntimeObjects(CopySecrets(ECKOperatorNamespace, es.pullSecrets...)...)...)
+
+			toCreate = append(toCreate,
+				es.eckOperatorClusterRole(),
+				es.eckOperatorClusterRoleBinding(),
+				es.eckOperatorServiceAccount(),
+			)
+			// This is needed for the operator to be able to set privileged mode for pods.
+			// https://docs.docker.com/ee/ucp/authorization/#secure-kubernetes-defaults
+			if es.provider == operatorv1.ProviderDockerEE {
+				toCreate = append(toCreate, es.eckOperatorClusterAdminClusterRoleBinding())
+			}
 
-		if len(es.elasticsearchSecrets) > 0 {
-			toCreate = append(toCreate, secretsToRuntimeObjects(es.elasticsearchSecrets...)...)
-		}
+			if es.createWebhookSecret {
+				toCreate = append(toCreate, es.eckOperatorWebhookSecret())
+			}
+			toCreate = append(toCreate, es.eckOperatorStatefulSet())
 
-		toCreate = append(toCreate, es.clusterConfig.ConfigMap())
-		toCreate = append(toCreate, es.elasticsearchCluster())
+			// Elasticsearch CRs
+			toCreate = append(toCreate, createNamespace(ElasticsearchNamespace, es.provider == operatorv1.ProviderOpenShift))
 
-		// Kibana CRs
-		toCreate = append(toCreate, createNamespace(KibanaNamespace, false))
+			if len(es.pullSecrets) > 0 {
+				toCreate = append(toCreate, secretsToRuntimeObjects(CopySecrets(ElasticsearchNamespace, es.pullSecrets...)...)...)
+			}
 
-		if len(es.pullSecrets) > 0 {
-			toCreate = append(toCreate, secretsToRuntimeObjects(CopySecrets(KibanaNamespace, es.pullSecrets...)...)...)
-		}
+			if len(es.elasticsearchSecrets) > 0 {
+				toCreate = append(toCreate, secretsToRuntimeObjects(es.elasticsearchSecrets...)...)
+			}
 
-		if len(es.kibanaSecrets) > 0 {
-			toCreate = append(toCreate, secretsToRuntimeObjects(es.kibanaSecrets...)...)
+			toCreate = append(toCreate, es.clusterConfig.ConfigMap())
+			toCreate = append(toCreate, es.elasticsearchCluster())
+
+			// Kibana CRs
+			if es.logStorage.KibanaDisabled() {
+				// Remove Kibana and the copy of its certificate that components use to reach it.
+				if es.kibana != nil {
+					toDelete = append(toDelete, es.kibana)
+				}
+				toDelete = append(toDelete, &corev1.Secret{
+					TypeMeta:   metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+					ObjectMeta: metav1.ObjectMeta{Name: KibanaPublicCertSecret, Namespace: OperatorNamespace()},
+				})
+			} else {
+				toCreate = append(toCreate, createNamespace(KibanaNamespace, false))
+
+				if len(es.pullSecrets) > 0 {
+					toCreate = append(toCreate, secretsToRuntimeObjects(CopySecrets(KibanaNamespace, es.pullSecrets...)...)...)
+				}
+
+				if len(es.kibanaSecrets) > 0 {
+					toCreate = append(toCreate, secretsToRuntimeObjects(es.kibanaSecrets...)...)
+				}
+
+				toCreate = append(toCreate, es.kibanaCR())
+			}
 		}
 
-		toCreate = append(toCreate, es.kibanaCR())
-
-		// Curator CRs
-		// If we have the curator secrets then create curator
-		if len(es.curatorSecrets) > 0 {
-			toCreate = append(toCreate, secretsToRuntimeObjects(CopySecrets(ElasticsearchNamespace, es.curatorSecrets...)...)...)
-			toCreate = append(toCreate, es.curatorCronJob())
-		}
+		// Retention is enforced by index lifecycle management policies, remove the curator that used to enforce it.
+		toDelete = append(toDelete, es.curatorDeprecatedObjects()...)
 
 		// If we converted from a ManagedCluster to a Standalone or Management then we need to delete the elasticsearch
 		// service as it differs between these cluster types
@@ -263,48 +289,201 @@ func (es elasticsearchComponent) elasticsearchExtrenalService() *corev1.Service
 	}
 }
 
+// ElasticsearchNodeSets returns the node sets of the Elasticsearch cluster. If none are configured a single node set is
+// returned for the identical nodes, each of type master, data, and ingest, defined by the Count and
+// ResourceRequirements.
+func ElasticsearchNodeSets(ls *operatorv1.LogStorage) []operatorv1.NodeSet {
+	nodes := ls.Spec.Nodes
+	if nodes == nil {
+		return []operatorv1.NodeSet{{}}
+	}
+	if len(nodes.NodeSets) > 0 {
+		return nodes.NodeSets
+	}
+
+	nodeSet := operatorv1.NodeSet{Count: nodes.Count, ResourceRequirements: nodes.ResourceRequirements}
+	// The storage size used to be set with the resource requirements.
+	if nodes.ResourceRequirements != nil {
+		if storage, ok := nodes.ResourceRequirements.Requests["storage"]; ok {
+			nodeSet.StorageSize = &storage
+		}
+	}
+	return []operatorv1.NodeSet{nodeSet}
+}
+
+// ElasticsearchStatefulSetName returns the name of the StatefulSet ECK creates for the nodes of the node set. The
+// Elasticsearch nodes are named after their pods, <StatefulSet name>-<ordinal>.
+func ElasticsearchStatefulSetName(nodeSetName string) string {
+	return fmt.Sprintf("%s-es-%s", ElasticsearchName, nodeSetName)
+}
+
+// ElasticsearchStorageSize returns the size of the data volume of each node in the node set.
+func ElasticsearchStorageSize(nodeSet operatorv1.NodeSet) resource.Quantity {
+	if nodeSet.StorageSize != nil {
+		return *nodeSet.StorageSize
+	}
+	return resource.MustParse("10Gi")
+}
+
+// nodeConfig returns the Elasticsearch configuration for the nodes in the node set.
+func (es elasticsearchComponent) nodeConfig(nodeSet operatorv1.NodeSet) map[string]interface{} {
+	config := map[string]interface{}{
+		"node.master": strconv.FormatBool(nodeSet.HasRole(operatorv1.ElasticsearchNodeRoleMaster)),
+		"node.data":   strconv.FormatBool(nodeSet.HasRole(operatorv1.ElasticsearchNodeRoleData)),
+		"node.ingest": strconv.FormatBool(nodeSet.HasRole(operatorv1.ElasticsearchNodeRoleIngest)),
+	}
+
+	// Data nodes in named node sets are tagged with their tier so that indices can be allocated to them.
+	if nodeSet.Name != "" && nodeSet.HasRole(operatorv1.ElasticsearchNodeRoleData) {
+		tier := nodeSet.DataTier
+		if tier == "" {
+			tier = operatorv1.ElasticsearchDataTierHot
+		}
+		config[ElasticsearchDataTierAttribute] = strings.ToLower(string(tier))
+	}
+
+	if snapshots := es.logStorage.Spec.Snapshots; snapshots != nil {
+		// The S3 client of every node connects to the S3 compatible service instead of Amazon S3.
+		if s3 := snapshots.Repository.S3; s3 != nil && s3.Endpoint != "" {
+			if u, err := url.Parse(s3.Endpoint); err == nil {
+				config["s3.client.default.endpoint"] = u.Host
+				config["s3.client.default.protocol"] = u.Scheme
+			}
+		}
+		if snapshots.Repository.FileSystem != nil {
+			config["path.repo"] = ElasticsearchSnapshotPath
+		}
+	}
+
+	if KibanaSSORealm(es.logStorage) != "" {
+		kibanaSSOConfig(config, es.logStorage.Spec.Kibana.Auth)
+	}
+
+	return config
+}
+
+// ElasticsearchHasSecureSettings returns true if the Elasticsearch nodes need settings from the keystore: the
+// credentials of the snapshot repository or the client secret of the OpenID Connect provider.
+func ElasticsearchHasSecureSettings(ls *operatorv1.LogStorage) bool {
+	if snapshots := ls.Spec.Snapshots; snapshots != nil && snapshotCredentialsSecretName(snapshots.Repository) != "" {
+		return true
+	}
+	return KibanaSSORealm(ls) == KibanaOIDCRealm
+}
+
+// KibanaSSORealm returns the Elasticsearch realm that provides single sign-on to Kibana, or an empty string if users
+// only log in with Elasticsearch usernames and passwords.
+func KibanaSSORealm(ls *operatorv1.LogStorage) string {
+	if ls.KibanaDisabled() || ls.Spec.Kibana == nil || ls.Spec.Kibana.Auth == nil {
+		return ""
+	}
+	switch ls.Spec.Kibana.Auth.Type {
+	case operatorv1.KibanaAuthTypeOIDC:
+		return KibanaOIDCRealm
+	case operatorv1.KibanaAuthTypeSAML:
+		return KibanaSAMLRealm
+	}
+	return ""
+}
+
+// kibanaSSOConfig adds the realm that provides single sign-on to Kibana to the configuration of an Elasticsearch node.
+// Once any realm is configured, the file and native realms holding the users of ECK and the operator are only enabled
+// if they are configured too.
+func kibanaSSOConfig(config map[string]interface{}, auth *operatorv1.KibanaAuth) {
+	baseURL := strings.TrimSuffix(auth.BaseURL, "/")
+	config["xpack.security.authc.token.enabled"] = "true"
+	config["xpack.security.authc.realms.file.file1.order"] = "0"
+	config["xpack.security.authc.realms.native.native1.order"] = "1"
+
+	switch {
+	case auth.Type == operatorv1.KibanaAuthTypeOIDC && auth.OIDC != nil:
+		principal := auth.OIDC.PrincipalClaim
+		if principal == "" {
+			principal = "sub"
+		}
+		prefix := "xpack.security.authc.realms.oidc." + KibanaOIDCRealm + "."
+		config[prefix+"order"] = "2"
+		config[prefix+"rp.client_id"] = auth.OIDC.ClientID
+		config[prefix+"rp.response_type"] = "code"
+		config[prefix+"rp.redirect_uri"] = baseURL + "/api/security/v1/oidc"
+		config[prefix+"rp.post_logout_redirect_uri"] = baseURL + "/logged_out"
+		config[prefix+"op.issuer"] = auth.OIDC.Authority
+		config[prefix+"op.authorization_endpoint"] = auth.OIDC.AuthorizationEndpoint
+		config[prefix+"op.token_endpoint"] = auth.OIDC.TokenEndpoint
+		config[prefix+"op.jwkset_path"] = auth.OIDC.JWKSetURL
+		config[prefix+"claims.principal"] = principal
+	case auth.Type == operatorv1.KibanaAuthTypeSAML && auth.SAML != nil:
+		principal := auth.SAML.PrincipalAttribute
+		if principal == "" {
+			principal = "nameid"
+		}
+		prefix := "xpack.security.authc.realms.saml." + KibanaSAMLRealm + "."
+		config[prefix+"order"] = "2"
+		config[prefix+"idp.metadata.path"] = auth.SAML.IDPMetadataURL
+		config[prefix+"idp.entity_id"] = auth.SAML.IDPEntityID
+		config[prefix+"sp.entity_id"] = baseURL
+		config[prefix+"sp.acs"] = baseURL + "/api/security/v1/saml"
+		config[prefix+"sp.logout"] = baseURL + "/logout"
+		config[prefix+"attributes.principal"] = principal
+	}
+}
+
+// snapshotRepositoryPlugin returns the Elasticsearch plugin that provides the type of the snapshot repository, or an
+// empty string if the type is built in. The plugin is the URL of its zip file if one is set, otherwise its name, which
+// Elasticsearch downloads from the Elastic plugin repository.
+func snapshotRepositoryPlugin(repository operatorv1.SnapshotRepository) string {
+	if repository.FileSystem == nil && repository.PluginURL != "" {
+		return repository.PluginURL
+	}
+	switch {
+	case repository.S3 != nil:
+		return "repository-s3"
+	case repository.GCS != nil:
+		return "repository-gcs"
+	case repository.Azure != nil:
+		return "repository-azure"
+	}
+	return ""
+}
+
+// snapshotCredentialsSecretName returns the name of the secret holding the credentials of the snapshot repository,
+// if it has any.
+func snapshotCredentialsSecretName(repository operatorv1.SnapshotRepository) string {
+	switch {
+	case repository.S3 != nil:
+		return repository.S3.CredentialsSecretName
+	case repository.GCS != nil:
+		return repository.GCS.CredentialsSecretName
+	case repository.Azure != nil:
+		return repository.Azure.CredentialsSecretName
+	}
+	return ""
+}
+
 // generate the PVC required for the Elasticsearch nodes
-func (es elasticsearchComponent) pvcTemplate() corev1.PersistentVolumeClaim {
+func (es elasticsearchComponent) pvcTemplate(nodeSet operatorv1.NodeSet) corev1.PersistentVolumeClaim {
 	storageClassName := ElasticsearchStorageClass
-	pvcTemplate := corev1.PersistentVolumeClaim{
+	if nodeSet.StorageClassName != "" {
+		storageClassName = nodeSet.StorageClassName
+	}
+	return corev1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "elasticsearch-data", // ECK requires this name
 		},
 		Spec: corev1.PersistentVolumeClaimSpec{
 			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
 			Resources: corev1.ResourceRequirements{
-				Limits: corev1.ResourceList{
-					"cpu":    resource.MustParse("2"),
-					"memory": resource.MustParse("3Gi"),
-				},
 				Requests: corev1.ResourceList{
-					"cpu":     resource.MustParse("1"),
-					"memory":  resource.MustParse("2Gi"),
-					"storage": resource.MustParse("10Gi"),
+					"storage": ElasticsearchStorageSize(nodeSet),
 				},
 			},
 			StorageClassName: &storageClassName,
 		},
 	}
-
-	// If the user has provided resource requirements, then use the user overrides instead
-	if es.logStorage.Spec.Nodes != nil && es.logStorage.Spec.Nodes.ResourceRequirements != nil {
-		userOverrides := *es.logStorage.Spec.Nodes.ResourceRequirements
-
-		// If the user provided overrides does not contain a storage quantity, then we still need to
-		// set a default
-		if _, ok := userOverrides.Requests["storage"]; !ok {
-			userOverrides.Requests["storage"] = resource.MustParse("10Gi")
-		}
-
-		pvcTemplate.Spec.Resources = userOverrides
-	}
-
-	return pvcTemplate
 }
 
 // Generate the pod template required for the ElasticSearch nodes (controls the ElasticSearch container)
-func (es elasticsearchComponent) podTemplate() corev1.PodTemplateSpec {
+func (es elasticsearchComponent) podTemplate(nodeSet operatorv1.NodeSet) corev1.PodTemplateSpec {
 	// Setup default configuration for ES container
 	esContainer := corev1.Container{
 		Name: "elasticsearch",
@@ -336,8 +515,8 @@ func (es elasticsearchComponent) podTemplate() corev1.PodTemplateSpec {
 	}
 
 	// If the user has provided resource requirements, then use the user overrides instead
-	if es.logStorage.Spec.Nodes != nil && es.logStorage.Spec.Nodes.ResourceRequirements != nil {
-		userOverrides := *es.logStorage.Spec.Nodes.ResourceRequirements
+	if nodeSet.ResourceRequirements != nil {
+		userOverrides := *nodeSet.ResourceRequirements
 		esContainer.Resources = corev1.ResourceRequirements{
 			Limits: corev1.ResourceList{
 				"cpu":    *userOverrides.Limits.Cpu(),
@@ -364,9 +543,33 @@ func (es elasticsearchComponent) podTemplate() corev1.PodTemplateSpec {
 		Spec: corev1.PodSpec{
 			Containers:       []corev1.Container{esContainer},
 			ImagePullSecrets: getImagePullSecretReferenceList(es.pullSecrets),
+			NodeSelector:     nodeSet.NodeSelector,
+			Tolerations:      nodeSet.Tolerations,
+			Affinity:         nodeSet.Affinity,
 		},
 	}
 
+	if snapshots := es.logStorage.Spec.Snapshots; snapshots != nil {
+		// ECK shares the plugins directory of the Elasticsearch container with the init containers.
+		if plugin := snapshotRepositoryPlugin(snapshots.Repository); plugin != "" {
+			podTemplate.Spec.InitContainers = []corev1.Container{{
+				Name:    "install-repository-plugin",
+				Image:   components.GetReference(components.ComponentElasticsearch, es.installation),
+				Command: []string{"sh", "-c", fmt.Sprintf("bin/elasticsearch-plugin install --batch %s", plugin)},
+			}}
+		}
+
+		if fs := snapshots.Repository.FileSystem; fs != nil {
+			podTemplate.Spec.Volumes = []corev1.Volume{{
+				Name: "snapshots",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: fs.PersistentVolumeClaimName},
+				},
+			}}
+			podTemplate.Spec.Containers[0].VolumeMounts = []corev1.VolumeMount{{Name: "snapshots", MountPath: ElasticsearchSnapshotPath}}
+		}
+	}
+
 	return podTemplate
 }
 
@@ -444,7 +647,54 @@ func memoryQuantityToJVMHeapSize(q *resource.Quantity) string {
 
 // render the Elasticsearch CR that the ECK operator uses to create elasticsearch cluster
 func (es elasticsearchComponent) elasticsearchCluster() *esv1alpha1.Elasticsearch {
-	nodeConfig := es.logStorage.Spec.Nodes
+	current := map[string]esv1alpha1.NodeSpec{}
+	if es.elasticsearch != nil {
+		for _, node := range es.elasticsearch.Spec.Nodes {
+			current[node.Name] = node
+		}
+	}
+
+	var nodes []esv1alpha1.NodeSpec
+	desired := map[string]bool{}
+	for _, nodeSet := range ElasticsearchNodeSets(es.logStorage) {
+		desired[nodeSet.Name] = true
+
+		// The nodes removed from a node set are kept while their shards are moved to other nodes.
+		count := int32(nodeSet.Count)
+		if held, ok := es.heldNodeCounts[nodeSet.Name]; ok {
+			count = held
+		}
+
+		// The volume claim templates of an existing node set can't be changed, the volumes are expanded by updating
+		// the claims instead.
+		volumeClaimTemplates := []corev1.PersistentVolumeClaim{es.pvcTemplate(nodeSet)}
+		if node, ok := current[nodeSet.Name]; ok && len(node.VolumeClaimTemplates) > 0 {
+			volumeClaimTemplates = node.VolumeClaimTemplates
+		}
+
+		nodes = append(nodes, esv1alpha1.NodeSpec{
+			Name:                 nodeSet.Name,
+			NodeCount:            count,
+			Config:               &cmneckalpha1.Config{Data: es.nodeConfig(nodeSet)},
+			VolumeClaimTemplates: volumeClaimTemplates,
+			PodTemplate:          es.podTemplate(nodeSet),
+		})
+	}
+
+	// Node sets removed from the LogStorage are kept until the shards have been moved off their nodes.
+	if es.elasticsearch != nil {
+		for _, node := range es.elasticsearch.Spec.Nodes {
+			if held, ok := es.heldNodeCounts[node.Name]; ok && !desired[node.Name] {
+				node.NodeCount = held
+				nodes = append(nodes, node)
+			}
+		}
+	}
+
+	var secureSettings *cmneckalpha1.SecretRef
+	if ElasticsearchHasSecureSettings(es.logStorage) {
+		secureSettings = &cmneckalpha1.SecretRef{SecretName: ElasticsearchSecureSettingsSecret}
+	}
 
 	return &esv1alpha1.Elasticsearch{
 		TypeMeta: metav1.TypeMeta{Kind: "Elasticsearch", APIVersion: "elasticsearch.k8s.elastic.co/v1alpha1"},
@@ -457,7 +707,7 @@ func (es elasticsearchComponent) elasticsearchCluster() *esv1alpha1.Elasticsearc
 		},
 		Spec: esv1alpha1.ElasticsearchSpec{
 			Version: components.ComponentElasticsearch.Version,
-			Image:   components.GetReference(components.ComponentElasticsearch, es.installation.Spec.Registry),
+			Image:   components.GetReference(components.ComponentElasticsearch, es.installation),
 			HTTP: cmneckalpha1.HTTPConfig{
 				TLS: cmneckalpha1.TLSOptions{
 					Certificate: cmneckalpha1.SecretRef{
@@ -465,20 +715,8 @@ func (es elasticsearchComponent) elasticsearchCluster() *esv1alpha1.Elasticsearc
 					},
 				},
 			},
-			Nodes: []esv1alpha1.NodeSpec{
-				{
-					NodeCount: int32(nodeConfig.Count),
-					Config: &cmneckalpha1.Config{
-						Data: map[string]interface{}{
-							"node.master": "true",
-							"node.data":   "true",
-							"node.ingest": "true",
-						},
-					},
-					VolumeClaimTemplates: []corev1.PersistentVolumeClaim{es.pvcTemplate()},
-					PodTemplate:          es.podTemplate(),
-				},
-			},
+			Nodes:          nodes,
+			SecureSettings: secureSettings,
 		},
 	}
 }
@@ -628,7 +866,7 @@ func (es elasticsearchComponent) eckOperatorStatefulSet() *appsv1.StatefulSet {
 					ServiceAccountName: "elastic-operator",
 					ImagePullSecrets:   getImagePullSecretReferenceList(es.pullSecrets),
 					Containers: []corev1.Container{{
-						Image: components.GetReference(components.ComponentElasticsearchOperator, es.installation.Spec.Registry),
+						Image: components.GetReference(components.ComponentElasticsearchOperator, es.installation),
 						Name:  "manager",
 						Args:  []string{"manager", "--operator-roles", "all", "--enable-debug-logs=false"},
 						Env: []corev1.EnvVar{
@@ -682,6 +920,63 @@ func (es elasticsearchComponent) eckOperatorStatefulSet() *appsv1.StatefulSet {
 }
 
 func (es elasticsearchComponent) kibanaCR() *kbv1alpha1.Kibana {
+	server := map[string]interface{}{
+		"basePath":        fmt.Sprintf("/%s", KibanaBasePath),
+		"rewriteBasePath": true,
+	}
+	config := map[string]interface{}{"server": server}
+
+	var count int32 = 1
+	container := corev1.Container{
+		Name: "kibana",
+		ReadinessProbe: &corev1.Probe{
+			Handler: corev1.Handler{
+				HTTPGet: &corev1.HTTPGetAction{
+					Path: fmt.Sprintf("/%s/login", KibanaBasePath),
+					Port: intstr.IntOrString{
+						IntVal: 5601,
+					},
+					Scheme: corev1.URISchemeHTTPS,
+				},
+			},
+		},
+	}
+
+	if kibana := es.logStorage.Spec.Kibana; kibana != nil {
+		if kibana.Count > 0 {
+			count = kibana.Count
+		}
+		if kibana.ResourceRequirements != nil {
+			container.Resources = *kibana.ResourceRequirements
+		}
+
+		// Users are sent to the identity provider, and can still log in with Elasticsearch usernames and passwords.
+		switch KibanaSSORealm(es.logStorage) {
+		case KibanaOIDCRealm:
+			config["xpack"] = map[string]interface{}{
+				"security": map[string]interface{}{
+					"authc": map[string]interface{}{
+						"providers": []interface{}{"oidc", "basic"},
+						"oidc":      map[string]interface{}{"realm": KibanaOIDCRealm},
+					},
+				},
+			}
+			// Logins started by the OpenID Connect provider are posted to Kibana without an XSRF header.
+			server["xsrf"] = map[string]interface{}{
+				"whitelist": []interface{}{"/api/security/v1/oidc"},
+			}
+		case KibanaSAMLRealm:
+			config["xpack"] = map[string]interface{}{
+				"security": map[string]interface{}{
+					"authc": map[string]interface{}{
+						"providers": []interface{}{"saml", "basic"},
+						"saml":      map[string]interface{}{"realm": KibanaSAMLRealm},
+					},
+				},
+			}
+		}
+	}
+
 	return &kbv1alpha1.Kibana{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      KibanaName,
@@ -695,16 +990,11 @@ func (es elasticsearchComponent) kibanaCR() *kbv1alpha1.Kibana {
 		},
 		Spec: kbv1alpha1.KibanaSpec{
 			Version: components.ComponentEckKibana.Version,
-			Image:   components.GetReference(components.ComponentKibana, es.installation.Spec.Registry),
+			Image:   components.GetReference(components.ComponentKibana, es.installation),
 			Config: &cmneckalpha1.Config{
-				Data: map[string]interface{}{
-					"server": map[string]interface{}{
-						"basePath":        fmt.Sprintf("/%s", KibanaBasePath),
-						"rewriteBasePath": true,
-					},
-				},
+				Data: config,
 			},
-			NodeCount: 1,
+			NodeCount: count,
 			HTTP: cmneckalpha1.HTTPConfig{
 				TLS: cmneckalpha1.TLSOptions{
 					Certificate: cmneckalpha1.SecretRef{
@@ -726,87 +1016,23 @@ func (es elasticsearchComponent) kibanaCR() *kbv1alpha1.Kibana {
 				},
 				Spec: corev1.PodSpec{
 					ImagePullSecrets: getImagePullSecretReferenceList(es.pullSecrets),
-					Containers: []corev1.Container{{
-						Name: "kibana",
-						ReadinessProbe: &corev1.Probe{
-							Handler: corev1.Handler{
-								HTTPGet: &corev1.HTTPGetAction{
-									Path: fmt.Sprintf("/%s/login", KibanaBasePath),
-									Port: intstr.IntOrString{
-										IntVal: 5601,
-									},
-									Scheme: corev1.URISchemeHTTPS,
-								},
-							},
-						},
-					}},
+					Containers:       []corev1.Container{container},
 				},
 			},
 		},
 	}
 }
 
-func (es elasticsearchComponent) curatorCronJob() *batchv1beta.CronJob {
-	var f = false
-	var elasticCuratorLivenessProbe = &corev1.Probe{
-		Handler: corev1.Handler{
-			Exec: &corev1.ExecAction{
-				Command: []string{
-					"/usr/bin/curator",
-					"--config",
-					"/curator/curator_config.yaml",
-					"--dry-run",
-					"/curator/curator_action.yaml",
-				},
-			},
+// curatorDeprecatedObjects returns the objects that were rendered for the curator.
+func (es elasticsearchComponent) curatorDeprecatedObjects() []runtime.Object {
+	return []runtime.Object{
+		&batchv1beta.CronJob{
+			TypeMeta:   metav1.TypeMeta{Kind: "CronJob", APIVersion: "batch/v1beta1"},
+			ObjectMeta: metav1.ObjectMeta{Name: EsCuratorName, Namespace: ElasticsearchNamespace},
 		},
-	}
-
-	const schedule = "@hourly"
-
-	return &batchv1beta.CronJob{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      EsCuratorName,
-			Namespace: ElasticsearchNamespace,
+		&corev1.Secret{
+			TypeMeta:   metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: ElasticsearchCuratorUserSecret, Namespace: ElasticsearchNamespace},
 		},
-		Spec: batchv1beta.CronJobSpec{
-			Schedule: schedule,
-			JobTemplate: batchv1beta.JobTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					Name: EsCuratorName,
-				},
-				Spec: batchv1.JobSpec{
-					Template: corev1.PodTemplateSpec{
-						Spec: ElasticsearchPodSpecDecorate(corev1.PodSpec{
-							Containers: []corev1.Container{
-								ElasticsearchContainerDecorate(corev1.Container{
-									Name:          EsCuratorName,
-									Image:         components.GetReference(components.ComponentEsCurator, es.installation.Spec.Registry),
-									Env:           es.curatorEnvVars(),
-									LivenessProbe: elasticCuratorLivenessProbe,
-									SecurityContext: &corev1.SecurityContext{
-										RunAsNonRoot:             &f,
-										AllowPrivilegeEscalation: &f,
-									},
-								}, DefaultElasticsearchClusterName, ElasticsearchCuratorUserSecret),
-							},
-							ImagePullSecrets: getImagePullSecretReferenceList(es.pullSecrets),
-							RestartPolicy:    corev1.RestartPolicyOnFailure,
-						}),
-					},
-				},
-			},
-		},
-	}
-}
-
-func (es elasticsearchComponent) curatorEnvVars() []corev1.EnvVar {
-	return []corev1.EnvVar{
-		{Name: "EE_FLOWS_INDEX_RETENTION_PERIOD", Value: fmt.Sprint(*es.logStorage.Spec.Retention.Flows)},
-		{Name: "EE_AUDIT_INDEX_RETENTION_PERIOD", Value: fmt.Sprint(*es.logStorage.Spec.Retention.AuditReports)},
-		{Name: "EE_SNAPSHOT_INDEX_RETENTION_PERIOD", Value: fmt.Sprint(*es.logStorage.Spec.Retention.Snapshots)},
-		{Name: "EE_COMPLIANCE_REPORT_INDEX_RETENTION_PERIOD", Value: fmt.Sprint(*es.logStorage.Spec.Retention.ComplianceReports)},
-		{Name: "EE_MAX_TOTAL_STORAGE_PCT", Value: fmt.Sprint(maxTotalStoragePercent)},
-		{Name: "EE_MAX_LOGS_STORAGE_PCT", Value: fmt.Sprint(maxLogsStoragePercent)},
 	}
 }
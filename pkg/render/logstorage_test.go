@@ -28,6 +28,7 @@ import (
 	batchv1beta "k8s.io/api/batch/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -46,6 +47,27 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 		replicas := int32(1)
 		retention := int32(1)
 		var esConfig *render.ElasticsearchClusterConfig
+		// The curator was replaced by index lifecycle management policies and is always removed.
+		curatorResources := []resourceTestObj{
+			{render.EsCuratorName, render.ElasticsearchNamespace, &batchv1beta.CronJob{}, nil},
+			{render.ElasticsearchCuratorUserSecret, render.ElasticsearchNamespace, &corev1.Secret{}, nil},
+		}
+		getElasticsearch := func(resources []runtime.Object) *esv1alpha1.Elasticsearch {
+			for _, r := range resources {
+				if es, ok := r.(*esv1alpha1.Elasticsearch); ok {
+					return es
+				}
+			}
+			return nil
+		}
+		getKibana := func(resources []runtime.Object) *kbv1alpha1.Kibana {
+			for _, r := range resources {
+				if kb, ok := r.(*kbv1alpha1.Kibana); ok {
+					return kb
+				}
+			}
+			return nil
+		}
 		BeforeEach(func() {
 			logStorage = &operator.LogStorage{
 				ObjectMeta: metav1.ObjectMeta{
@@ -78,7 +100,7 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 					ClusterManagementType: operatorv1.ClusterManagementTypeStandalone,
 				},
 			}
-			esConfig = render.NewElasticsearchClusterConfig("cluster", 1, 5)
+			esConfig = render.NewElasticsearchClusterConfig("cluster", 1, 5, "")
 		})
 
 		Context("Initial creation", func() {
@@ -112,6 +134,7 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 					logStorage,
 					installation, nil, nil,
 					esConfig,
+					nil,
 					[]*corev1.Secret{
 						{ObjectMeta: metav1.ObjectMeta{Name: render.TigeraElasticsearchCertSecret, Namespace: render.OperatorNamespace()}},
 						{ObjectMeta: metav1.ObjectMeta{Name: render.TigeraElasticsearchCertSecret, Namespace: render.ElasticsearchNamespace}},
@@ -122,12 +145,12 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 					}, true,
 					[]*corev1.Secret{
 						{ObjectMeta: metav1.ObjectMeta{Name: "tigera-pull-secret"}},
-					}, operator.ProviderNone, nil, nil, "cluster.local")
+					}, operator.ProviderNone, nil, "cluster.local")
 
 				createResources, deleteResources := component.Objects()
 
 				compareResources(createResources, expectedCreateResources)
-				compareResources(deleteResources, []resourceTestObj{})
+				compareResources(deleteResources, curatorResources)
 			})
 			It("should render an elasticsearchComponent and delete the ExternalService", func() {
 				expectedCreateResources := []resourceTestObj{
@@ -155,14 +178,15 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 					{render.KibanaName, render.KibanaNamespace, &kbv1alpha1.Kibana{}, nil},
 				}
 
-				expectedDeleteResources := []resourceTestObj{
-					{render.ElasticsearchServiceName, render.ElasticsearchNamespace, &corev1.Service{}, nil},
-				}
+				expectedDeleteResources := append(curatorResources,
+					resourceTestObj{render.ElasticsearchServiceName, render.ElasticsearchNamespace, &corev1.Service{}, nil},
+				)
 
 				component := render.LogStorage(
 					logStorage,
 					installation, nil, nil,
 					esConfig,
+					nil,
 					[]*corev1.Secret{
 						{ObjectMeta: metav1.ObjectMeta{Name: render.TigeraElasticsearchCertSecret, Namespace: render.OperatorNamespace()}},
 						{ObjectMeta: metav1.ObjectMeta{Name: render.TigeraElasticsearchCertSecret, Namespace: render.ElasticsearchNamespace}},
@@ -173,7 +197,7 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 					}, true,
 					[]*corev1.Secret{
 						{ObjectMeta: metav1.ObjectMeta{Name: "tigera-pull-secret"}},
-					}, operator.ProviderNone, nil,
+					}, operator.ProviderNone,
 					&corev1.Service{
 						ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchServiceName, Namespace: render.ElasticsearchNamespace},
 						Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeExternalName},
@@ -213,15 +237,13 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 					{render.TigeraKibanaCertSecret, render.KibanaNamespace, &corev1.Secret{}, nil},
 					{render.KibanaPublicCertSecret, render.OperatorNamespace(), &corev1.Secret{}, nil},
 					{render.KibanaName, render.KibanaNamespace, &kbv1alpha1.Kibana{}, nil},
-					{render.ElasticsearchCuratorUserSecret, render.ElasticsearchNamespace, &corev1.Secret{}, nil},
-					{render.ElasticsearchPublicCertSecret, render.ElasticsearchNamespace, &corev1.Secret{}, nil},
-					{render.EsCuratorName, render.ElasticsearchNamespace, &batchv1beta.CronJob{}, nil},
 				}
 
 				component := render.LogStorage(
 					logStorage,
 					installation, nil, nil,
 					esConfig,
+					nil,
 					[]*corev1.Secret{
 						{ObjectMeta: metav1.ObjectMeta{Name: render.TigeraElasticsearchCertSecret, Namespace: render.OperatorNamespace()}},
 						{ObjectMeta: metav1.ObjectMeta{Name: render.TigeraElasticsearchCertSecret, Namespace: render.ElasticsearchNamespace}},
@@ -235,16 +257,344 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 					[]*corev1.Secret{
 						{ObjectMeta: metav1.ObjectMeta{Name: "tigera-pull-secret"}},
 					}, operator.ProviderNone,
+					nil, "cluster.local")
+
+				createResources, deleteResources := component.Objects()
+
+				compareResources(createResources, expectedCreateResources)
+				compareResources(deleteResources, curatorResources)
+			})
+		})
+
+		Context("Node sets", func() {
+			It("should only request storage for the volume of each node", func() {
+				logStorage.Spec.Nodes.ResourceRequirements = &corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{
+						"cpu":    resource.MustParse("2"),
+						"memory": resource.MustParse("4Gi"),
+					},
+					Requests: corev1.ResourceList{
+						"cpu":     resource.MustParse("1"),
+						"memory":  resource.MustParse("4Gi"),
+						"storage": resource.MustParse("50Gi"),
+					},
+				}
+
+				component := render.LogStorage(logStorage, installation, nil, nil, esConfig, nil, nil, nil, false, nil, operator.ProviderNone, nil, "cluster.local")
+				createResources, _ := component.Objects()
+				es := getElasticsearch(createResources)
+				Expect(es).NotTo(BeNil())
+				Expect(es.Spec.Nodes).To(HaveLen(1))
+
+				node := es.Spec.Nodes[0]
+				Expect(node.Name).To(BeEmpty())
+				Expect(node.Config.Data).To(Equal(map[string]interface{}{"node.master": "true", "node.data": "true", "node.ingest": "true"}))
+				Expect(node.VolumeClaimTemplates[0].Spec.Resources).To(Equal(corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{"storage": resource.MustParse("50Gi")},
+				}))
+				Expect(node.PodTemplate.Spec.Containers[0].Resources.Limits.Memory().String()).To(Equal("4Gi"))
+			})
+
+			It("should render a node spec for each node set", func() {
+				storageSize := resource.MustParse("500Gi")
+				logStorage.Spec.Nodes = &operatorv1.Nodes{
+					NodeSets: []operatorv1.NodeSet{
+						{Name: "master", Count: 3, Roles: []operatorv1.ElasticsearchNodeRole{operatorv1.ElasticsearchNodeRoleMaster}},
+						{Name: "hot", Count: 2, Roles: []operatorv1.ElasticsearchNodeRole{operatorv1.ElasticsearchNodeRoleData, operatorv1.ElasticsearchNodeRoleIngest}},
+						{
+							Name:             "warm",
+							Count:            4,
+							Roles:            []operatorv1.ElasticsearchNodeRole{operatorv1.ElasticsearchNodeRoleData},
+							DataTier:         operatorv1.ElasticsearchDataTierWarm,
+							StorageSize:      &storageSize,
+							StorageClassName: "slow",
+							NodeSelector:     map[string]string{"disk": "hdd"},
+							Tolerations:      []corev1.Toleration{{Key: "warm", Operator: corev1.TolerationOpExists}},
+						},
+					},
+				}
+
+				component := render.LogStorage(logStorage, installation, nil, nil, esConfig, nil, nil, nil, false, nil, operator.ProviderNone, nil, "cluster.local")
+				createResources, _ := component.Objects()
+				es := getElasticsearch(createResources)
+				Expect(es).NotTo(BeNil())
+				Expect(es.Spec.Nodes).To(HaveLen(3))
+
+				master := es.Spec.Nodes[0]
+				Expect(master.Name).To(Equal("master"))
+				Expect(master.NodeCount).To(Equal(int32(3)))
+				Expect(master.Config.Data).To(Equal(map[string]interface{}{"node.master": "true", "node.data": "false", "node.ingest": "false"}))
+				Expect(*master.VolumeClaimTemplates[0].Spec.StorageClassName).To(Equal(render.ElasticsearchStorageClass))
+
+				hot := es.Spec.Nodes[1]
+				Expect(hot.Config.Data).To(Equal(map[string]interface{}{
+					"node.master": "false", "node.data": "true", "node.ingest": "true", render.ElasticsearchDataTierAttribute: "hot",
+				}))
+
+				warm := es.Spec.Nodes[2]
+				Expect(warm.NodeCount).To(Equal(int32(4)))
+				Expect(warm.Config.Data).To(HaveKeyWithValue(render.ElasticsearchDataTierAttribute, "warm"))
+				Expect(*warm.VolumeClaimTemplates[0].Spec.StorageClassName).To(Equal("slow"))
+				Expect(warm.VolumeClaimTemplates[0].Spec.Resources.Requests["storage"]).To(Equal(storageSize))
+				Expect(warm.PodTemplate.Spec.NodeSelector).To(Equal(map[string]string{"disk": "hdd"}))
+				Expect(warm.PodTemplate.Spec.Tolerations).To(HaveLen(1))
+			})
+
+			It("should keep the nodes being drained and the volume claim templates of existing node sets", func() {
+				storageSize := resource.MustParse("100Gi")
+				logStorage.Spec.Nodes = &operatorv1.Nodes{
+					NodeSets: []operatorv1.NodeSet{
+						{Name: "hot", Count: 2, StorageSize: &storageSize},
+					},
+				}
+				current := &esv1alpha1.Elasticsearch{
+					ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchName, Namespace: render.ElasticsearchNamespace},
+					Spec: esv1alpha1.ElasticsearchSpec{
+						Nodes: []esv1alpha1.NodeSpec{
+							{
+								Name:      "hot",
+								NodeCount: 3,
+								VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{
+									ObjectMeta: metav1.ObjectMeta{Name: "elasticsearch-data"},
+									Spec: corev1.PersistentVolumeClaimSpec{
+										Resources: corev1.ResourceRequirements{
+											Requests: corev1.ResourceList{"storage": resource.MustParse("10Gi")},
+										},
+									},
+								}},
+							},
+							{Name: "warm", NodeCount: 2},
+						},
+					},
+				}
+
+				component := render.LogStorage(logStorage, installation, current, nil, esConfig, map[string]int32{"hot": 3, "warm": 2},
+					nil, nil, false, nil, operator.ProviderNone, nil, "cluster.local")
+				createResources, _ := component.Objects()
+				es := getElasticsearch(createResources)
+				Expect(es).NotTo(BeNil())
+				Expect(es.Spec.Nodes).To(HaveLen(2))
+
+				hot := es.Spec.Nodes[0]
+				Expect(hot.NodeCount).To(Equal(int32(3)))
+				Expect(hot.VolumeClaimTemplates[0].Spec.Resources.Requests["storage"]).To(Equal(resource.MustParse("10Gi")))
+
+				warm := es.Spec.Nodes[1]
+				Expect(warm.Name).To(Equal("warm"))
+				Expect(warm.NodeCount).To(Equal(int32(2)))
+
+				By("removing the nodes once they have been drained")
+				component = render.LogStorage(logStorage, installation, current, nil, esConfig, nil,
+					nil, nil, false, nil, operator.ProviderNone, nil, "cluster.local")
+				createResources, _ = component.Objects()
+				es = getElasticsearch(createResources)
+				Expect(es.Spec.Nodes).To(HaveLen(1))
+				Expect(es.Spec.Nodes[0].NodeCount).To(Equal(int32(2)))
+			})
+		})
+
+		Context("Snapshots", func() {
+			It("should install the repository plugin and add the credentials to the keystore", func() {
+				logStorage.Spec.Snapshots = &operatorv1.Snapshots{
+					Repository: operatorv1.SnapshotRepository{
+						S3: &operatorv1.S3SnapshotRepository{
+							Bucket:                "snapshots",
+							Endpoint:              "http://minio.minio.svc:9000",
+							CredentialsSecretName: "snapshot-credentials",
+						},
+					},
+				}
+
+				component := render.LogStorage(logStorage, installation, nil, nil, esConfig, nil, nil, nil, false, nil, operator.ProviderNone, nil, "cluster.local")
+				createResources, _ := component.Objects()
+				es := getElasticsearch(createResources)
+				Expect(es).NotTo(BeNil())
+				Expect(es.Spec.SecureSettings.SecretName).To(Equal(render.ElasticsearchSecureSettingsSecret))
+
+				node := es.Spec.Nodes[0]
+				Expect(node.Config.Data).To(HaveKeyWithValue("s3.client.default.endpoint", "minio.minio.svc:9000"))
+				Expect(node.Config.Data).To(HaveKeyWithValue("s3.client.default.protocol", "http"))
+				Expect(node.PodTemplate.Spec.InitContainers).To(HaveLen(1))
+				Expect(node.PodTemplate.Spec.InitContainers[0].Image).To(Equal(node.PodTemplate.Spec.Containers[0].Image))
+				Expect(node.PodTemplate.Spec.InitContainers[0].Command).To(ContainElement(ContainSubstring("repository-s3")))
+			})
+
+			It("should install the repository plugin from the plugin URL", func() {
+				logStorage.Spec.Snapshots = &operatorv1.Snapshots{
+					Repository: operatorv1.SnapshotRepository{
+						GCS:       &operatorv1.GCSSnapshotRepository{Bucket: "snapshots"},
+						PluginURL: "https://mirror.example.com/repository-gcs-7.3.2.zip",
+					},
+				}
+
+				component := render.LogStorage(logStorage, installation, nil, nil, esConfig, nil, nil, nil, false, nil, operator.ProviderNone, nil, "cluster.local")
+				createResources, _ := component.Objects()
+				es := getElasticsearch(createResources)
+				Expect(es).NotTo(BeNil())
+
+				initContainers := es.Spec.Nodes[0].PodTemplate.Spec.InitContainers
+				Expect(initContainers).To(HaveLen(1))
+				Expect(initContainers[0].Command).To(ContainElement("bin/elasticsearch-plugin install --batch https://mirror.example.com/repository-gcs-7.3.2.zip"))
+			})
+
+			It("should mount the volume of a file system repository", func() {
+				logStorage.Spec.Snapshots = &operatorv1.Snapshots{
+					Repository: operatorv1.SnapshotRepository{
+						FileSystem: &operatorv1.FileSystemSnapshotRepository{PersistentVolumeClaimName: "snapshots"},
+					},
+				}
+
+				component := render.LogStorage(logStorage, installation, nil, nil, esConfig, nil, nil, nil, false, nil, operator.ProviderNone, nil, "cluster.local")
+				createResources, _ := component.Objects()
+				es := getElasticsearch(createResources)
+				Expect(es).NotTo(BeNil())
+				Expect(es.Spec.SecureSettings).To(BeNil())
+
+				node := es.Spec.Nodes[0]
+				Expect(node.Config.Data).To(HaveKeyWithValue("path.repo", render.ElasticsearchSnapshotPath))
+				Expect(node.PodTemplate.Spec.InitContainers).To(BeEmpty())
+				Expect(node.PodTemplate.Spec.Volumes).To(HaveLen(1))
+				Expect(node.PodTemplate.Spec.Volumes[0].PersistentVolumeClaim.ClaimName).To(Equal("snapshots"))
+				Expect(node.PodTemplate.Spec.Containers[0].VolumeMounts).To(ConsistOf(corev1.VolumeMount{Name: "snapshots", MountPath: render.ElasticsearchSnapshotPath}))
+			})
+		})
+
+		Context("Kibana", func() {
+			It("should set the number of instances and their resources", func() {
+				logStorage.Spec.Kibana = &operatorv1.Kibana{
+					Count: 2,
+					ResourceRequirements: &corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{"memory": resource.MustParse("2Gi")},
+					},
+				}
+
+				component := render.LogStorage(logStorage, installation, nil, nil, esConfig, nil, nil, nil, false, nil, operator.ProviderNone, nil, "cluster.local")
+				createResources, _ := component.Objects()
+				kb := getKibana(createResources)
+				Expect(kb).NotTo(BeNil())
+				Expect(kb.Spec.NodeCount).To(Equal(int32(2)))
+				Expect(kb.Spec.PodTemplate.Spec.Containers[0].Resources.Limits.Memory().String()).To(Equal("2Gi"))
+				Expect(kb.Spec.Config.Data).NotTo(HaveKey("xpack"))
+			})
+
+			It("should remove Kibana when it is disabled", func() {
+				logStorage.Spec.Kibana = &operatorv1.Kibana{Disabled: true}
+
+				component := render.LogStorage(logStorage, installation, nil,
+					&kbv1alpha1.Kibana{ObjectMeta: metav1.ObjectMeta{Name: render.KibanaName, Namespace: render.KibanaNamespace}},
+					esConfig, nil, nil, nil, false, nil, operator.ProviderNone, nil, "cluster.local")
+				createResources, deleteResources := component.Objects()
+				Expect(getKibana(createResources)).To(BeNil())
+				Expect(getElasticsearch(createResources)).NotTo(BeNil())
+				Expect(getKibana(deleteResources)).NotTo(BeNil())
+				compareResources(deleteResources[1:2], []resourceTestObj{
+					{render.KibanaPublicCertSecret, render.OperatorNamespace(), &corev1.Secret{}, nil},
+				})
+			})
+
+			It("should configure OIDC single sign-on in Elasticsearch and Kibana", func() {
+				logStorage.Spec.Kibana = &operatorv1.Kibana{
+					Auth: &operatorv1.KibanaAuth{
+						Type:    operatorv1.KibanaAuthTypeOIDC,
+						BaseURL: "https://manager.example.com/tigera-kibana/",
+						OIDC: &operatorv1.KibanaOIDC{
+							Authority:             "https://idp.example.com",
+							ClientID:              "kibana",
+							ClientSecretName:      "kibana-oidc",
+							AuthorizationEndpoint: "https://idp.example.com/authorize",
+							TokenEndpoint:         "https://idp.example.com/token",
+							JWKSetURL:             "https://idp.example.com/jwks",
+						},
+					},
+				}
+
+				component := render.LogStorage(logStorage, installation, nil, nil, esConfig, nil, nil, nil, false, nil, operator.ProviderNone, nil, "cluster.local")
+				createResources, _ := component.Objects()
+				es := getElasticsearch(createResources)
+				Expect(es.Spec.SecureSettings.SecretName).To(Equal(render.ElasticsearchSecureSettingsSecret))
+
+				config := es.Spec.Nodes[0].Config.Data
+				Expect(config).To(HaveKeyWithValue("xpack.security.authc.realms.native.native1.order", "1"))
+				Expect(config).To(HaveKeyWithValue("xpack.security.authc.realms.oidc.oidc1.op.issuer", "https://idp.example.com"))
+				Expect(config).To(HaveKeyWithValue("xpack.security.authc.realms.oidc.oidc1.rp.redirect_uri", "https://manager.example.com/tigera-kibana/api/security/v1/oidc"))
+				Expect(config).To(HaveKeyWithValue("xpack.security.authc.realms.oidc.oidc1.claims.principal", "sub"))
+
+				kb := getKibana(createResources)
+				Expect(kb.Spec.Config.Data["xpack"]).To(Equal(map[string]interface{}{
+					"security": map[string]interface{}{
+						"authc": map[string]interface{}{
+							"providers": []interface{}{"oidc", "basic"},
+							"oidc":      map[string]interface{}{"realm": render.KibanaOIDCRealm},
+						},
+					},
+				}))
+				// The ECK config is deep copied as JSON, which only allows untyped slices and maps.
+				Expect(kb.DeepCopy().Spec.Config).To(Equal(kb.Spec.Config))
+			})
+
+			It("should configure SAML single sign-on without secure settings", func() {
+				logStorage.Spec.Kibana = &operatorv1.Kibana{
+					Auth: &operatorv1.KibanaAuth{
+						Type:    operatorv1.KibanaAuthTypeSAML,
+						BaseURL: "https://manager.example.com/tigera-kibana",
+						SAML: &operatorv1.KibanaSAML{
+							IDPMetadataURL: "https://idp.example.com/metadata",
+							IDPEntityID:    "https://idp.example.com",
+						},
+					},
+				}
+
+				component := render.LogStorage(logStorage, installation, nil, nil, esConfig, nil, nil, nil, false, nil, operator.ProviderNone, nil, "cluster.local")
+				createResources, _ := component.Objects()
+				es := getElasticsearch(createResources)
+				Expect(es.Spec.SecureSettings).To(BeNil())
+				Expect(es.Spec.Nodes[0].Config.Data).To(HaveKeyWithValue("xpack.security.authc.realms.saml.saml1.sp.acs", "https://manager.example.com/tigera-kibana/api/security/v1/saml"))
+				Expect(es.Spec.Nodes[0].Config.Data).To(HaveKeyWithValue("xpack.security.authc.realms.saml.saml1.attributes.principal", "nameid"))
+			})
+		})
+
+		Context("External Elasticsearch", func() {
+			It("should render the cluster configuration without ECK and Kibana", func() {
+				logStorage.Spec.External = &operatorv1.ExternalElasticsearch{
+					Endpoint:        "https://es.example.com:9200",
+					CASecretName:    "es-ca",
+					AdminSecretName: "es-admin",
+				}
+				esConfig = render.NewElasticsearchClusterConfig("cluster", 1, 5, "https://es.example.com:9200")
+
+				expectedCreateResources := []resourceTestObj{
+					{"tigera-secure", "", &operatorv1.LogStorage{}, nil},
+					{render.ElasticsearchPublicCertSecret, render.OperatorNamespace(), &corev1.Secret{}, nil},
+					{render.ElasticsearchConfigMapName, render.OperatorNamespace(), &corev1.ConfigMap{}, func(resource runtime.Object) {
+						cm := resource.(*corev1.ConfigMap)
+						Expect(cm.Data["endpoint"]).To(Equal("https://es.example.com:9200"))
+					}},
+				}
+				expectedDeleteResources := append([]resourceTestObj{
+					{render.ElasticsearchName, render.ElasticsearchNamespace, &esv1alpha1.Elasticsearch{}, nil},
+					{render.KibanaName, render.KibanaNamespace, &kbv1alpha1.Kibana{}, nil},
+				}, curatorResources...)
+
+				component := render.LogStorage(
+					logStorage,
+					installation,
+					&esv1alpha1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchName, Namespace: render.ElasticsearchNamespace}},
+					&kbv1alpha1.Kibana{ObjectMeta: metav1.ObjectMeta{Name: render.KibanaName, Namespace: render.KibanaNamespace}},
+					esConfig,
+					nil,
 					[]*corev1.Secret{
-						{ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchCuratorUserSecret, Namespace: render.OperatorNamespace()}},
 						{ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchPublicCertSecret, Namespace: render.OperatorNamespace()}},
 					},
+					nil, false,
+					[]*corev1.Secret{
+						{ObjectMeta: metav1.ObjectMeta{Name: "tigera-pull-secret"}},
+					}, operator.ProviderNone,
 					nil, "cluster.local")
 
 				createResources, deleteResources := component.Objects()
 
 				compareResources(createResources, expectedCreateResources)
-				compareResources(deleteResources, []resourceTestObj{})
+				compareResources(deleteResources, expectedDeleteResources)
 			})
 		})
 
@@ -275,11 +625,11 @@ var _ = Describe("Elasticsearch rendering tests", func() {
 				}
 
 				component := render.LogStorage(
-					nil, installation, nil, nil, nil, nil, nil, false,
+					nil, installation, nil, nil, nil, nil, nil, nil, false,
 					[]*corev1.Secret{
 						{ObjectMeta: metav1.ObjectMeta{Name: "tigera-pull-secret"}},
 					}, operator.ProviderNone,
-					nil, nil, "cluster.local")
+					nil, "cluster.local")
 
 				createResources, deleteResources := component.Objects()
 
@@ -333,7 +683,7 @@ var deleteLogStorageTests = func(clusterType operatorv1.ClusterManagementType) f
 					ClusterManagementType: clusterType,
 				},
 			}
-			esConfig = render.NewElasticsearchClusterConfig("cluster", 1, 5)
+			esConfig = render.NewElasticsearchClusterConfig("cluster", 1, 5, "")
 		})
 		It("returns Elasticsearch and Kibana CR's to delete and keeps the finalizers on the LogStorage CR", func() {
 			expectedCreateResources := []resourceTestObj{
@@ -354,6 +704,7 @@ var deleteLogStorageTests = func(clusterType operatorv1.ClusterManagementType) f
 				&esv1alpha1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchName, Namespace: render.ElasticsearchNamespace}},
 				&kbv1alpha1.Kibana{ObjectMeta: metav1.ObjectMeta{Name: render.KibanaName, Namespace: render.KibanaNamespace}},
 				esConfig,
+				nil,
 				[]*corev1.Secret{
 					{ObjectMeta: metav1.ObjectMeta{Name: render.TigeraElasticsearchCertSecret, Namespace: render.OperatorNamespace()}},
 					{ObjectMeta: metav1.ObjectMeta{Name: render.TigeraElasticsearchCertSecret, Namespace: render.ElasticsearchNamespace}},
@@ -367,10 +718,6 @@ var deleteLogStorageTests = func(clusterType operatorv1.ClusterManagementType) f
 				[]*corev1.Secret{
 					{ObjectMeta: metav1.ObjectMeta{Name: "tigera-pull-secret"}},
 				}, operator.ProviderNone,
-				[]*corev1.Secret{
-					{ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchCuratorUserSecret, Namespace: render.OperatorNamespace()}},
-					{ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchPublicCertSecret, Namespace: render.OperatorNamespace()}},
-				},
 				nil, "cluster.local")
 
 			createResources, deleteResources := component.Objects()
@@ -393,6 +740,7 @@ var deleteLogStorageTests = func(clusterType operatorv1.ClusterManagementType) f
 				&esv1alpha1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchName, Namespace: render.ElasticsearchNamespace, DeletionTimestamp: &t}},
 				&kbv1alpha1.Kibana{ObjectMeta: metav1.ObjectMeta{Name: render.KibanaName, Namespace: render.KibanaNamespace, DeletionTimestamp: &t}},
 				esConfig,
+				nil,
 				[]*corev1.Secret{
 					{ObjectMeta: metav1.ObjectMeta{Name: render.TigeraElasticsearchCertSecret, Namespace: render.OperatorNamespace()}},
 					{ObjectMeta: metav1.ObjectMeta{Name: render.TigeraElasticsearchCertSecret, Namespace: render.ElasticsearchNamespace}},
@@ -406,10 +754,6 @@ var deleteLogStorageTests = func(clusterType operatorv1.ClusterManagementType) f
 				[]*corev1.Secret{
 					{ObjectMeta: metav1.ObjectMeta{Name: "tigera-pull-secret"}},
 				}, operator.ProviderNone,
-				[]*corev1.Secret{
-					{ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchCuratorUserSecret, Namespace: render.OperatorNamespace()}},
-					{ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchPublicCertSecret, Namespace: render.OperatorNamespace()}},
-				},
 				nil, "cluster.local")
 
 			createResources, deleteResources := component.Objects()
@@ -430,6 +774,7 @@ var deleteLogStorageTests = func(clusterType operatorv1.ClusterManagementType) f
 				installation,
 				nil, nil,
 				esConfig,
+				nil,
 				[]*corev1.Secret{
 					{ObjectMeta: metav1.ObjectMeta{Name: render.TigeraElasticsearchCertSecret, Namespace: render.OperatorNamespace()}},
 					{ObjectMeta: metav1.ObjectMeta{Name: render.TigeraElasticsearchCertSecret, Namespace: render.ElasticsearchNamespace}},
@@ -443,10 +788,6 @@ var deleteLogStorageTests = func(clusterType operatorv1.ClusterManagementType) f
 				[]*corev1.Secret{
 					{ObjectMeta: metav1.ObjectMeta{Name: "tigera-pull-secret"}},
 				}, operator.ProviderNone,
-				[]*corev1.Secret{
-					{ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchCuratorUserSecret, Namespace: render.OperatorNamespace()}},
-					{ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchPublicCertSecret, Namespace: render.OperatorNamespace()}},
-				},
 				nil, "cluster.local")
 
 			createResources, deleteResources := component.Objects()
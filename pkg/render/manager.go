@@ -67,7 +67,7 @@ func Manager(
 	tlsKeyPair *corev1.Secret,
 	pullSecrets []*corev1.Secret,
 	openshift bool,
-	registry string,
+	installation *operator.Installation,
 	oidcConfig *corev1.ConfigMap,
 	management bool,
 	tunnelSecret *corev1.Secret,
@@ -112,7 +112,7 @@ func Manager(
 		tlsSecrets:                 tlsSecrets,
 		pullSecrets:                pullSecrets,
 		openshift:                  openshift,
-		registry:                   registry,
+		installation:               installation,
 		oidcConfig:                 oidcConfig,
 		management:                 management,
 		tunnelSecrets:              tunnelSecrets,
@@ -128,7 +128,7 @@ type managerComponent struct {
 	tlsSecrets                 []*corev1.Secret
 	pullSecrets                []*corev1.Secret
 	openshift                  bool
-	registry                   string
+	installation               *operator.Installation
 	oidcConfig                 *corev1.ConfigMap
 	// If true, this is a management cluster.
 	management bool
@@ -213,8 +213,8 @@ func (c *managerComponent) managerDeployment() *appsv1.Deployment {
 			Tolerations:        c.managerTolerations(),
 			ImagePullSecrets:   getImagePullSecretReferenceList(c.pullSecrets),
 			Containers: []corev1.Container{
-				ElasticsearchContainerDecorate(c.managerContainer(), c.esClusterConfig.ClusterName(), ElasticsearchManagerUserSecret),
-				ElasticsearchContainerDecorate(c.managerEsProxyContainer(), c.esClusterConfig.ClusterName(), ElasticsearchManagerUserSecret),
+				ElasticsearchContainerDecorate(c.managerContainer(), c.esClusterConfig, ElasticsearchManagerUserSecret),
+				ElasticsearchContainerDecorate(c.managerEsProxyContainer(), c.esClusterConfig, ElasticsearchManagerUserSecret),
 				c.managerProxyContainer(),
 			},
 			Volumes: c.managerVolumes(),
@@ -249,6 +249,8 @@ func (c *managerComponent) managerDeployment() *appsv1.Deployment {
 // managerVolumes returns the volumes for the Tigera Secure manager component.
 func (c *managerComponent) managerVolumes() []v1.Volume {
 	optional := true
+	// There is no Kibana when an external Elasticsearch cluster is used or Kibana is disabled.
+	kibanaOptional := !c.esClusterConfig.Kibana()
 	v := []v1.Volume{
 		{
 			Name: ManagerTLSSecretName,
@@ -263,6 +265,7 @@ func (c *managerComponent) managerVolumes() []v1.Volume {
 			VolumeSource: v1.VolumeSource{
 				Secret: &v1.SecretVolumeSource{
 					SecretName: KibanaPublicCertSecret,
+					Optional:   &kibanaOptional,
 				},
 			},
 		},
@@ -376,7 +379,7 @@ func (c *managerComponent) managerEnvVars() []v1.EnvVar {
 func (c *managerComponent) managerContainer() corev1.Container {
 	tm := corev1.Container{
 		Name:            "tigera-manager",
-		Image:           components.GetReference(components.ComponentManager, c.registry),
+		Image:           components.GetReference(components.ComponentManager, c.installation),
 		Env:             c.managerEnvVars(),
 		LivenessProbe:   c.managerProbe(),
 		SecurityContext: securityContext(),
@@ -420,7 +423,7 @@ func (c *managerComponent) managerOAuth2EnvVars() []v1.EnvVar {
 func (c *managerComponent) managerProxyContainer() corev1.Container {
 	return corev1.Container{
 		Name:  VoltronName,
-		Image: components.GetReference(components.ComponentManagerProxy, c.registry),
+		Image: components.GetReference(components.ComponentManagerProxy, c.installation),
 		Env: []corev1.EnvVar{
 			{Name: "VOLTRON_PORT", Value: defaultVoltronPort},
 			{Name: "VOLTRON_COMPLIANCE_ENDPOINT", Value: fmt.Sprintf("https://compliance.%s.svc", ComplianceNamespace)},
@@ -446,7 +449,7 @@ func (c *managerComponent) managerProxyContainer() corev1.Container {
 func (c *managerComponent) managerEsProxyContainer() corev1.Container {
 	apiServer := corev1.Container{
 		Name:            "tigera-es-proxy",
-		Image:           components.GetReference(components.ComponentEsProxy, c.registry),
+		Image:           components.GetReference(components.ComponentEsProxy, c.installation),
 		LivenessProbe:   c.managerEsProxyProbe(),
 		SecurityContext: securityContext(),
 	}
@@ -38,9 +38,10 @@ const (
 	nodeCertHashAnnotation     = "hash.operator.tigera.io/node-cert"
 )
 
-// Node creates the node daemonset and other resources for the daemonset to operate normally.
-func Node(cr *operator.Installation, p operator.Provider, nc NetworkConfig, bt map[string]string, tnTLS *TyphaNodeTLS, migrate bool) Component {
-	return &nodeComponent{cr: cr, provider: p, netConfig: nc, birdTemplates: bt, typhaNodeTLS: tnTLS, migrationNeeded: migrate}
+// Node creates the node daemonset and other resources for the daemonset to operate normally. The LogCollector, if
+// there is one, decides whether flow logs are written.
+func Node(cr *operator.Installation, p operator.Provider, nc NetworkConfig, bt map[string]string, tnTLS *TyphaNodeTLS, migrate bool, lc *operator.LogCollector) Component {
+	return &nodeComponent{cr: cr, provider: p, netConfig: nc, birdTemplates: bt, typhaNodeTLS: tnTLS, migrationNeeded: migrate, logCollector: lc}
 }
 
 type nodeComponent struct {
@@ -50,6 +51,13 @@ type nodeComponent struct {
 	birdTemplates   map[string]string
 	typhaNodeTLS    *TyphaNodeTLS
 	migrationNeeded bool
+	logCollector    *operator.LogCollector
+}
+
+// FlowLogsFileEnabled returns whether Felix writes flow logs to files with the flow log settings of the LogCollector.
+// They are written unless the LogCollector sets their destination to None, including when there is no LogCollector.
+func FlowLogsFileEnabled(flowLogs *operator.FlowLogsSpec) bool {
+	return flowLogs == nil || flowLogs.Destination != operator.FlowLogsDestinationNone
 }
 
 func (c *nodeComponent) Objects() ([]runtime.Object, []runtime.Object) {
@@ -445,6 +453,11 @@ func (c *nodeComponent) nodeDaemonset() *apps.DaemonSet {
 		ds.Spec.Template.Spec.InitContainers = append(ds.Spec.Template.Spec.InitContainers, c.cniContainer())
 	}
 
+	// With a controlled update strategy the operator decides when each calico/node pod is replaced.
+	if c.cr.Spec.NodeUpdateStrategy != nil {
+		ds.Spec.UpdateStrategy = apps.DaemonSetUpdateStrategy{Type: apps.OnDeleteDaemonSetStrategyType}
+	}
+
 	setCriticalPod(&(ds.Spec.Template))
 	if c.migrationNeeded {
 		migration.LimitDaemonSetToMigratedNodes(&ds)
@@ -575,7 +588,7 @@ func (c *nodeComponent) cniContainer() v1.Container {
 
 	return v1.Container{
 		Name:         "install-cni",
-		Image:        components.GetReference(components.ComponentCalicoCNI, c.cr.Spec.Registry),
+		Image:        components.GetReference(components.ComponentCalicoCNI, c.cr),
 		Command:      []string{"/install-cni.sh"},
 		Env:          cniEnv,
 		VolumeMounts: cniVolumeMounts,
@@ -591,7 +604,7 @@ func (c *nodeComponent) flexVolumeContainer() v1.Container {
 
 	return v1.Container{
 		Name:         "flexvol-driver",
-		Image:        components.GetReference(components.ComponentFlexVolume, c.cr.Spec.Registry),
+		Image:        components.GetReference(components.ComponentFlexVolume, c.cr),
 		VolumeMounts: flexVolumeMounts,
 	}
 }
@@ -629,9 +642,9 @@ func (c *nodeComponent) nodeContainer() v1.Container {
 	isPrivileged := true
 
 	// Select which image to use.
-	image := components.GetReference(components.ComponentCalicoNode, c.cr.Spec.Registry)
+	image := components.GetReference(components.ComponentCalicoNode, c.cr)
 	if c.cr.Spec.Variant == operator.TigeraSecureEnterprise {
-		image = components.GetReference(components.ComponentTigeraNode, c.cr.Spec.Registry)
+		image = components.GetReference(components.ComponentTigeraNode, c.cr)
 	}
 	return v1.Container{
 		Name:            "calico-node",
@@ -858,9 +871,13 @@ func (c *nodeComponent) nodeEnvVars() []v1.EnvVar {
 	}
 
 	if c.cr.Spec.Variant == operator.TigeraSecureEnterprise {
+		var flowLogs *operator.FlowLogsSpec
+		if c.logCollector != nil {
+			flowLogs = c.logCollector.Spec.FlowLogs
+		}
 		extraNodeEnv := []v1.EnvVar{
 			{Name: "FELIX_PROMETHEUSREPORTERENABLED", Value: "true"},
-			{Name: "FELIX_FLOWLOGSFILEENABLED", Value: "true"},
+			{Name: "FELIX_FLOWLOGSFILEENABLED", Value: strconv.FormatBool(FlowLogsFileEnabled(flowLogs))},
 			{Name: "FELIX_FLOWLOGSFILEINCLUDELABELS", Value: "true"},
 			{Name: "FELIX_FLOWLOGSFILEINCLUDEPOLICIES", Value: "true"},
 			{Name: "FELIX_FLOWLOGSENABLENETWORKSETS", Value: "true"},
@@ -57,7 +57,7 @@ var _ = Describe("Node rendering tests", func() {
 	})
 
 	It("should render all resources for a default configuration", func() {
-		component := render.Node(defaultInstance, operator.ProviderNone, render.NetworkConfig{CNI: render.CNICalico}, nil, typhaNodeTLS, false)
+		component := render.Node(defaultInstance, operator.ProviderNone, render.NetworkConfig{CNI: render.CNICalico}, nil, typhaNodeTLS, false, nil)
 		resources, _ := component.Objects()
 		Expect(len(resources)).To(Equal(5))
 
@@ -233,7 +233,7 @@ var _ = Describe("Node rendering tests", func() {
 		defaultInstance.Spec.Variant = operator.TigeraSecureEnterprise
 		defaultInstance.Spec.NodeMetricsPort = &nodeMetricsPort
 
-		component := render.Node(defaultInstance, operator.ProviderNone, render.NetworkConfig{CNI: render.CNICalico}, nil, typhaNodeTLS, false)
+		component := render.Node(defaultInstance, operator.ProviderNone, render.NetworkConfig{CNI: render.CNICalico}, nil, typhaNodeTLS, false, nil)
 		resources, _ := component.Objects()
 		Expect(len(resources)).To(Equal(6))
 
@@ -324,7 +324,7 @@ var _ = Describe("Node rendering tests", func() {
 	})
 
 	It("should render all resources when running on openshift", func() {
-		component := render.Node(defaultInstance, operator.ProviderOpenShift, render.NetworkConfig{CNI: render.CNICalico}, nil, typhaNodeTLS, false)
+		component := render.Node(defaultInstance, operator.ProviderOpenShift, render.NetworkConfig{CNI: render.CNICalico}, nil, typhaNodeTLS, false, nil)
 		resources, _ := component.Objects()
 		Expect(len(resources)).To(Equal(5))
 
@@ -445,7 +445,7 @@ var _ = Describe("Node rendering tests", func() {
 		defaultInstance.Spec.Variant = operator.TigeraSecureEnterprise
 		defaultInstance.Spec.NodeMetricsPort = &nodeMetricsPort
 
-		component := render.Node(defaultInstance, operator.ProviderOpenShift, render.NetworkConfig{CNI: render.CNICalico}, nil, typhaNodeTLS, false)
+		component := render.Node(defaultInstance, operator.ProviderOpenShift, render.NetworkConfig{CNI: render.CNICalico}, nil, typhaNodeTLS, false, nil)
 		resources, _ := component.Objects()
 		Expect(len(resources)).To(Equal(6))
 
@@ -544,7 +544,7 @@ var _ = Describe("Node rendering tests", func() {
 		bt := map[string]string{
 			"template-1.yaml": "dataforTemplate1 that is not used here",
 		}
-		component := render.Node(defaultInstance, operator.ProviderOpenShift, render.NetworkConfig{CNI: render.CNICalico}, bt, typhaNodeTLS, false)
+		component := render.Node(defaultInstance, operator.ProviderOpenShift, render.NetworkConfig{CNI: render.CNICalico}, bt, typhaNodeTLS, false, nil)
 		resources, _ := component.Objects()
 		Expect(len(resources)).To(Equal(6))
 
@@ -589,7 +589,7 @@ var _ = Describe("Node rendering tests", func() {
 		It("should support canReach", func() {
 			defaultInstance.Spec.CalicoNetwork.NodeAddressAutodetectionV4.FirstFound = nil
 			defaultInstance.Spec.CalicoNetwork.NodeAddressAutodetectionV4.CanReach = "1.1.1.1"
-			component := render.Node(defaultInstance, operator.ProviderNone, render.NetworkConfig{CNI: render.CNICalico}, nil, typhaNodeTLS, false)
+			component := render.Node(defaultInstance, operator.ProviderNone, render.NetworkConfig{CNI: render.CNICalico}, nil, typhaNodeTLS, false, nil)
 			resources, _ := component.Objects()
 			Expect(len(resources)).To(Equal(5))
 
@@ -604,7 +604,7 @@ var _ = Describe("Node rendering tests", func() {
 		It("should support interface regex", func() {
 			defaultInstance.Spec.CalicoNetwork.NodeAddressAutodetectionV4.FirstFound = nil
 			defaultInstance.Spec.CalicoNetwork.NodeAddressAutodetectionV4.Interface = "eth*"
-			component := render.Node(defaultInstance, operator.ProviderNone, render.NetworkConfig{CNI: render.CNICalico}, nil, typhaNodeTLS, false)
+			component := render.Node(defaultInstance, operator.ProviderNone, render.NetworkConfig{CNI: render.CNICalico}, nil, typhaNodeTLS, false, nil)
 			resources, _ := component.Objects()
 			Expect(len(resources)).To(Equal(5))
 
@@ -619,7 +619,7 @@ var _ = Describe("Node rendering tests", func() {
 		It("should support skip-interface regex", func() {
 			defaultInstance.Spec.CalicoNetwork.NodeAddressAutodetectionV4.FirstFound = nil
 			defaultInstance.Spec.CalicoNetwork.NodeAddressAutodetectionV4.SkipInterface = "eth*"
-			component := render.Node(defaultInstance, operator.ProviderNone, render.NetworkConfig{CNI: render.CNICalico}, nil, typhaNodeTLS, false)
+			component := render.Node(defaultInstance, operator.ProviderNone, render.NetworkConfig{CNI: render.CNICalico}, nil, typhaNodeTLS, false, nil)
 			resources, _ := component.Objects()
 			Expect(len(resources)).To(Equal(5))
 
@@ -633,7 +633,7 @@ var _ = Describe("Node rendering tests", func() {
 	})
 
 	It("should include updates needed for the core upgrade", func() {
-		component := render.Node(defaultInstance, operator.ProviderOpenShift, render.NetworkConfig{CNI: render.CNICalico}, nil, typhaNodeTLS, true)
+		component := render.Node(defaultInstance, operator.ProviderOpenShift, render.NetworkConfig{CNI: render.CNICalico}, nil, typhaNodeTLS, true, nil)
 		resources, _ := component.Objects()
 		Expect(len(resources)).To(Equal(5), fmt.Sprintf("resources are %v", resources))
 
@@ -668,7 +668,7 @@ var _ = Describe("Node rendering tests", func() {
 		func(pool operator.IPPool, expect map[string]string) {
 			// Provider does not matter for IPPool configuration
 			defaultInstance.Spec.CalicoNetwork.IPPools = []operator.IPPool{pool}
-			component := render.Node(defaultInstance, operator.ProviderNone, render.NetworkConfig{CNI: render.CNICalico}, nil, typhaNodeTLS, false)
+			component := render.Node(defaultInstance, operator.ProviderNone, render.NetworkConfig{CNI: render.CNICalico}, nil, typhaNodeTLS, false, nil)
 			resources, _ := component.Objects()
 			Expect(len(resources)).To(Equal(5))
 
@@ -795,7 +795,7 @@ var _ = Describe("Node rendering tests", func() {
 	It("should not export FELIX_PROMETHEUSREPORTERPORT if NodeMetricsPort is nil", func() {
 		defaultInstance.Spec.Variant = operator.TigeraSecureEnterprise
 		defaultInstance.Spec.NodeMetricsPort = nil
-		component := render.Node(defaultInstance, operator.ProviderNone, render.NetworkConfig{CNI: render.CNICalico}, nil, typhaNodeTLS, false)
+		component := render.Node(defaultInstance, operator.ProviderNone, render.NetworkConfig{CNI: render.CNICalico}, nil, typhaNodeTLS, false, nil)
 		resources, _ := component.Objects()
 		Expect(len(resources)).To(Equal(5))
 
@@ -811,7 +811,7 @@ var _ = Describe("Node rendering tests", func() {
 		var nodeMetricsPort int32 = 1234
 		defaultInstance.Spec.Variant = operator.TigeraSecureEnterprise
 		defaultInstance.Spec.NodeMetricsPort = &nodeMetricsPort
-		component := render.Node(defaultInstance, operator.ProviderNone, render.NetworkConfig{CNI: render.CNICalico}, nil, typhaNodeTLS, false)
+		component := render.Node(defaultInstance, operator.ProviderNone, render.NetworkConfig{CNI: render.CNICalico}, nil, typhaNodeTLS, false, nil)
 		resources, _ := component.Objects()
 		Expect(len(resources)).To(Equal(6))
 
@@ -822,6 +822,39 @@ var _ = Describe("Node rendering tests", func() {
 		ds := dsResource.(*apps.DaemonSet)
 		Expect(ds.Spec.Template.Spec.Containers[0].Env).To(ContainElement(expectedEnvVar))
 	})
+
+	It("should disable the flow log files if the LogCollector sets their destination to None", func() {
+		defaultInstance.Spec.Variant = operator.TigeraSecureEnterprise
+		lc := &operator.LogCollector{
+			Spec: operator.LogCollectorSpec{
+				FlowLogs: &operator.FlowLogsSpec{Destination: operator.FlowLogsDestinationNone},
+			},
+		}
+		component := render.Node(defaultInstance, operator.ProviderNone, render.NetworkConfig{CNI: render.CNICalico}, nil, typhaNodeTLS, false, lc)
+		resources, _ := component.Objects()
+
+		dsResource := GetResource(resources, "calico-node", "calico-system", "apps", "v1", "DaemonSet")
+		Expect(dsResource).ToNot(BeNil())
+
+		ds := dsResource.(*apps.DaemonSet)
+		Expect(ds.Spec.Template.Spec.Containers[0].Env).To(ContainElement(v1.EnvVar{Name: "FELIX_FLOWLOGSFILEENABLED", Value: "false"}))
+		Expect(ds.Spec.Template.Spec.Containers[0].Env).ToNot(ContainElement(v1.EnvVar{Name: "FELIX_FLOWLOGSFILEENABLED", Value: "true"}))
+	})
+
+	It("should let the operator replace pods when a node update strategy is set", func() {
+		component := render.Node(defaultInstance, operator.ProviderNone, render.NetworkConfig{CNI: render.CNICalico}, nil, typhaNodeTLS, false, nil)
+		resources, _ := component.Objects()
+		ds := GetResource(resources, "calico-node", "calico-system", "apps", "v1", "DaemonSet").(*apps.DaemonSet)
+		Expect(ds.Spec.UpdateStrategy.RollingUpdate).NotTo(BeNil())
+
+		defaultInstance.Spec.NodeUpdateStrategy = &operator.NodeUpdateStrategy{
+			CanaryNodeSelector: map[string]string{"canary": "true"},
+		}
+		component = render.Node(defaultInstance, operator.ProviderNone, render.NetworkConfig{CNI: render.CNICalico}, nil, typhaNodeTLS, false, nil)
+		resources, _ = component.Objects()
+		ds = GetResource(resources, "calico-node", "calico-system", "apps", "v1", "DaemonSet").(*apps.DaemonSet)
+		Expect(ds.Spec.UpdateStrategy).To(Equal(apps.DaemonSetUpdateStrategy{Type: apps.OnDeleteDaemonSetStrategyType}))
+	})
 })
 
 // verifyProbes asserts the expected node liveness and readiness probe.
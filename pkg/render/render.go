@@ -66,6 +66,7 @@ func Calico(
 	p operator.Provider,
 	nc NetworkConfig,
 	up bool,
+	lc *operator.LogCollector,
 ) (Renderer, error) {
 
 	tcms := []*corev1.ConfigMap{}
@@ -117,6 +118,7 @@ func Calico(
 		provider:      p,
 		networkConfig: nc,
 		upgrade:       up,
+		logCollector:  lc,
 	}, nil
 }
 
@@ -186,6 +188,7 @@ type calicoRenderer struct {
 	provider      operator.Provider
 	networkConfig NetworkConfig
 	upgrade       bool
+	logCollector  *operator.LogCollector
 }
 
 func (r calicoRenderer) Render() []Component {
@@ -196,7 +199,7 @@ func (r calicoRenderer) Render() []Component {
 	components = appendNotNil(components, ConfigMaps(r.tlsConfigMaps))
 	components = appendNotNil(components, Secrets(r.tlsSecrets))
 	components = appendNotNil(components, Typha(r.installation, r.provider, r.typhaNodeTLS, r.upgrade))
-	components = appendNotNil(components, Node(r.installation, r.provider, r.networkConfig, r.birdTemplates, r.typhaNodeTLS, r.upgrade))
+	components = appendNotNil(components, Node(r.installation, r.provider, r.networkConfig, r.birdTemplates, r.typhaNodeTLS, r.upgrade, r.logCollector))
 	components = appendNotNil(components, KubeControllers(r.installation))
 	return components
 }
@@ -393,9 +393,9 @@ func (c *typhaComponent) typhaContainer() v1.Container {
 	lp, rp := c.livenessReadinessProbes()
 
 	// Select which image to use.
-	image := components.GetReference(components.ComponentCalicoTypha, c.cr.Spec.Registry)
+	image := components.GetReference(components.ComponentCalicoTypha, c.cr)
 	if c.cr.Spec.Variant == operator.TigeraSecureEnterprise {
-		image = components.GetReference(components.ComponentTigeraTypha, c.cr.Spec.Registry)
+		image = components.GetReference(components.ComponentTigeraTypha, c.cr)
 	}
 	return v1.Container{
 		Name:           "calico-typha",